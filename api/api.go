@@ -3,4 +3,8 @@ package api
 type Api interface {
 	StartServer()
 	GracefulStopServer()
+	// SetApiKey replaces the api key used to authenticate requests
+	SetApiKey(apiKey string) error
+	// SetAdminApiKey replaces the api key used to authenticate admin requests
+	SetAdminApiKey(adminApiKey string) error
 }
@@ -0,0 +1,571 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/client"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mvt"
+	"github.com/golang/mock/gomock"
+)
+
+// jsonSchema describes the json encoding of the type by field names and
+// kinds, so that the request and response types of the server and the client
+// can be compared
+func jsonSchema(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "time"
+	case t.Kind() == reflect.Slice:
+		return "[]" + jsonSchema(t.Elem())
+	case t.Kind() == reflect.Map:
+		return "map[" + t.Key().Kind().String() + "]" + jsonSchema(t.Elem())
+	case t.Kind() != reflect.Struct:
+		return t.Kind().String()
+	}
+
+	fields := jsonFields(t)
+	sort.Strings(fields)
+	return "{" + strings.Join(fields, " ") + "}"
+}
+
+// jsonFields returns the json fields of the struct with their schema, the
+// fields of the embedded structs are inlined as by encoding/json
+func jsonFields(t reflect.Type) []string {
+	fields := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(opts, "omitempty") {
+			name += ",omitempty"
+		}
+		fields = append(fields, name+":"+jsonSchema(f.Type))
+	}
+	return fields
+}
+
+func TestClientTypesInSync(t *testing.T) {
+	tests := []struct {
+		server interface{}
+		client interface{}
+		// optionalID is true if the id is optional in the server request and
+		// omitted by the client if it is empty, or the response has the id
+		// of the event sent by the client
+		optionalID bool
+	}{
+		{server: getAvailableScootersResponse{}, client: struct {
+			Scooters []client.Scooter `json:"scooters"`
+		}{}},
+		{server: scooter{}, client: client.Scooter{}},
+		{server: geoLocation{}, client: client.GeoLocation{}},
+		{server: beginTripRequest{}, client: client.BeginTripRequest{}},
+		{server: beginTripResponse{}, client: client.BeginTripResponse{}},
+		{server: endTripRequest{}, client: client.EndTripRequest{}},
+		{server: endTripResponse{}, client: client.EndTripResponse{}},
+		{server: tripEventResult{}, client: client.TripEventResult{}},
+		{server: fieldError{}, client: client.FieldError{}},
+		{server: deadLetterEvent{}, client: client.DeadLetterEvent{}},
+		{server: scooterPosition{}, client: client.ScooterPosition{}},
+		{server: health.Report{}, client: client.HealthReport{}},
+		{server: health.CheckResult{}, client: client.HealthCheck{}},
+		{server: saveScooterTripEventRequest{}, client: client.TripEvent{}, optionalID: true},
+		{server: trip{}, client: client.Trip{}},
+		{server: startTripRequest{}, client: client.StartTripRequest{}},
+		{server: finishTripRequest{}, client: client.FinishTripRequest{}},
+		{server: createScooterEventRequest{}, client: client.ScooterEvent{}, optionalID: true},
+		{server: tripEvent{}, client: client.TripEvent{}, optionalID: true},
+		{server: tripEventPage{}, client: client.TripEventPage{}, optionalID: true},
+		{server: scooterMatch{}, client: client.ScooterMatch{}},
+		{server: scooterPage{}, client: client.ScooterPage{}},
+		{server: scooterCluster{}, client: client.ScooterCluster{}},
+		{server: scooterClustersResponse{}, client: client.ScooterClusters{}},
+	}
+	for _, tt := range tests {
+		serverType, clientType := reflect.TypeOf(tt.server), reflect.TypeOf(tt.client)
+		t.Run(serverType.Name(), func(t *testing.T) {
+			want := jsonSchema(serverType)
+			if tt.optionalID {
+				want = strings.NewReplacer("{id:string", "{id,omitempty:string", " id:string", " id,omitempty:string").Replace(want)
+			}
+			if got := jsonSchema(clientType); got != want {
+				t.Errorf("client %v = %v, want server %v", clientType, got, want)
+			}
+		})
+	}
+
+	// the error codes of the client are the codes returned by the service
+	codes := map[client.ErrorCode]app.ErrorCode{
+		client.CodeValidationFailed:             app.CodeValidationFailed,
+		client.CodeUnauthorized:                 codeUnauthorized,
+		client.CodeNotFound:                     app.CodeNotFound,
+		client.CodeOperationNotAllowed:          app.CodeOperationNotAllowed,
+		client.CodeServiceUnavailable:           app.CodeServiceUnavailable,
+		client.CodeInternal:                     app.CodeInternal,
+		client.CodeScooterNotFound:              app.CodeScooterNotFound,
+		client.CodeScooterUnavailable:           app.CodeScooterUnavailable,
+		client.CodeScooterNotInUse:              app.CodeScooterNotInUse,
+		client.CodeScooterInUseByOther:          app.CodeScooterInUseByOther,
+		client.CodeTripNotFound:                 app.CodeTripNotFound,
+		client.CodeTripAlreadyEnded:             app.CodeTripAlreadyEnded,
+		client.CodeDeadLetterEventNotFound:      app.CodeDeadLetterEventNotFound,
+		client.CodeDeadLetterEventAlreadyReplay: app.CodeDeadLetterEventAlreadyReplay,
+	}
+	for clientCode, serverCode := range codes {
+		if string(clientCode) != string(serverCode) {
+			t.Errorf("client code = %v, want server %v", clientCode, serverCode)
+		}
+	}
+}
+
+// TestClientCoversRoutes checks that each route of the api except the swagger
+// docs and metrics scraped by prometheus has the client method
+func TestClientCoversRoutes(t *testing.T) {
+	methods := map[string]string{
+		"GET /api/v1/auth/user/available-scooters":              "GetAvailableScooters",
+		"PUT /api/v1/auth/user/begin-trip":                      "BeginTrip",
+		"PUT /api/v1/auth/user/end-trip":                        "EndTrip",
+		"POST /api/v1/auth/scooter/trip-event":                  "SaveTripEvent",
+		"POST /api/v1/auth/scooter/trip-events":                 "SaveTripEvents",
+		"GET /api/v1/auth/admin/dead-letter-events":             "GetDeadLetterEvents",
+		"GET /api/v1/auth/admin/dead-letter-events/:id":         "GetDeadLetterEvent",
+		"PUT /api/v1/auth/admin/dead-letter-events/:id":         "UpdateDeadLetterEvent",
+		"POST /api/v1/auth/admin/dead-letter-events/:id/replay": "ReplayDeadLetterEvent",
+		"GET /api/v1/auth/admin/scooters/:id/position":          "GetScooterPosition",
+		"GET /api/v2/scooters":                                  "SearchScooters",
+		"GET /api/v2/scooters/clusters":                         "GetScooterClusters",
+		"GET /api/v2/scooters/:id":                              "GetScooter",
+		"POST /api/v2/scooters/:id/events":                      "CreateScooterEvent",
+		"POST /api/v2/trips":                                    "StartTrip",
+		"GET /api/v2/trips/:id":                                 "GetTrip",
+		"POST /api/v2/trips/:id/end":                            "FinishTrip",
+		"GET /api/v2/trips/:id/events":                          "ListTripEvents",
+		"GET /api/v2/tiles/:z/:x/:y":                            "GetTile",
+		"GET /healthz":                                          "Healthz",
+		"GET /readyz":                                           "Readyz",
+	}
+	notCovered := map[string]bool{
+		"GET /api/v1/swagger/*any": true,
+		"GET /metrics":             true,
+	}
+
+	api := &apiDetails{}
+	routes := map[string]bool{}
+	for _, route := range api.setupRouter().Routes() {
+		key := route.Method + " " + route.Path
+		routes[key] = true
+		if notCovered[key] {
+			continue
+		}
+		method, ok := methods[key]
+		if !ok {
+			t.Errorf("route %v has no client method", key)
+			continue
+		}
+		if _, ok := reflect.TypeOf(&client.Client{}).MethodByName(method); !ok {
+			t.Errorf("client method %v of route %v does not exist", method, key)
+		}
+	}
+	for key := range methods {
+		if !routes[key] {
+			t.Errorf("client method %v has no route %v", methods[key], key)
+		}
+	}
+}
+
+// newClientTestServer serves the api with mocked app, it returns the client
+// of the api and the url of the server
+func newClientTestServer(t *testing.T) (*mocks.MockApp, *client.Client, string) {
+	ctrl := gomock.NewController(t)
+	appInstance := mocks.NewMockApp(ctrl)
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+	}
+	server := httptest.NewServer(api.setupRouter())
+	t.Cleanup(server.Close)
+
+	c, err := client.NewClient(server.URL, "testkey", client.WithRetry(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appInstance, c, server.URL
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	userID, scooterID := "8a4f6f0c-0a43-4b5c-9c43-7a7f37b5f7a1", "0b7f8a3c-4d2e-4f5a-8b6c-1d2e3f4a5b6c"
+	location := client.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	createdAt := time.Date(2022, 7, 10, 10, 0, 0, 0, time.UTC)
+
+	t.Run("get available scooters", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		appInstance.EXPECT().GetNearbyAvailableScooters(gomock.Any(), domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}, 100).
+			Return([]domain.Scooter{{ID: scooterID, Name: "scooter", Location: domain.GeoLocation{Latitude: 52.521, Longitude: 13.404}, IsAvailable: true}}, nil)
+
+		scooters, err := c.GetAvailableScooters(ctx, location, 100)
+		want := []client.Scooter{{ID: scooterID, Name: "scooter", Location: client.GeoLocation{Latitude: 52.521, Longitude: 13.404}, IsAvailable: true}}
+		if err != nil || !reflect.DeepEqual(scooters, want) {
+			t.Errorf("GetAvailableScooters() = %+v, %v, want %+v", scooters, err, want)
+		}
+	})
+
+	t.Run("begin and end trip", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		appInstance.EXPECT().BeginTrip(gomock.Any(), userID, scooterID).Return(nil)
+		appInstance.EXPECT().EndTrip(gomock.Any(), userID, scooterID, domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}).Return(nil)
+
+		begun, err := c.BeginTrip(ctx, userID, scooterID)
+		if err != nil || begun.UserID != userID || begun.ScooterID != scooterID {
+			t.Errorf("BeginTrip() = %+v, %v", begun, err)
+		}
+		ended, err := c.EndTrip(ctx, userID, scooterID, location)
+		if err != nil || ended.UserID != userID || ended.ScooterID != scooterID {
+			t.Errorf("EndTrip() = %+v, %v", ended, err)
+		}
+	})
+
+	t.Run("begin trip with unavailable scooter", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		appInstance.EXPECT().BeginTrip(gomock.Any(), userID, scooterID).Return(app.ErrScooterUnavailable)
+
+		_, err := c.BeginTrip(ctx, userID, scooterID)
+		apiErr := &client.Error{}
+		if !errors.Is(err, client.ErrBadRequest) || !errors.As(err, &apiErr) || apiErr.Code != client.CodeScooterUnavailable || apiErr.Message != "scooter is unavailable" {
+			t.Errorf("BeginTrip() error = %v, want %v", err, client.CodeScooterUnavailable)
+		}
+	})
+
+	t.Run("begin trip with invalid request", func(t *testing.T) {
+		_, c, _ := newClientTestServer(t)
+
+		_, err := c.BeginTrip(ctx, "user", "")
+		apiErr := &client.Error{}
+		if !errors.As(err, &apiErr) || apiErr.Code != client.CodeValidationFailed {
+			t.Fatalf("BeginTrip() error = %v, want %v", err, client.CodeValidationFailed)
+		}
+		want := []client.FieldError{
+			{Field: "user_id", Rule: "uuid4", Message: "user_id must be uuid4"},
+			{Field: "scooter_id", Rule: "required", Message: "scooter_id is required"},
+		}
+		if !reflect.DeepEqual(apiErr.FieldErrors, want) {
+			t.Errorf("BeginTrip() field errors = %+v, want %+v", apiErr.FieldErrors, want)
+		}
+	})
+
+	t.Run("internal error is not returned", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		appInstance.EXPECT().EndTrip(gomock.Any(), userID, scooterID, gomock.Any()).Return(errors.New("db error: connection refused to 10.0.0.1"))
+
+		_, err := c.EndTrip(ctx, userID, scooterID, location)
+		apiErr := &client.Error{}
+		if !errors.Is(err, client.ErrInternal) || !errors.As(err, &apiErr) || apiErr.Code != client.CodeInternal || strings.Contains(apiErr.Message, "10.0.0.1") {
+			t.Errorf("EndTrip() error = %v, want internal error without detail", err)
+		}
+	})
+
+	t.Run("save trip events", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		event := client.TripEvent{
+			UserID:    userID,
+			ScooterID: scooterID,
+			Location:  location,
+			CreatedAt: createdAt,
+			Type:      client.TripStartEvent,
+		}
+		appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.TripEvent) error {
+			if e.ID == "" || e.UserID != userID || e.Type != domain.TripStartEvent || !e.CreatedAt.Equal(createdAt) {
+				t.Errorf("saved event = %+v, want event with generated id", e)
+			}
+			return nil
+		})
+		appInstance.EXPECT().SaveScooterTripEvents(gomock.Any(), gomock.Len(2)).Return([]error{nil, fmt.Errorf("invalid location: %w", app.ErrInvalidArg)}, nil)
+		appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), "invalid location: invalid argument").Return(nil)
+
+		err := c.SaveTripEvent(ctx, event)
+		if err != nil {
+			t.Errorf("SaveTripEvent() error = %v", err)
+		}
+		results, err := c.SaveTripEvents(ctx, []client.TripEvent{event, event})
+		if err != nil || len(results) != 2 || !results[0].Success || results[1].Success || results[1].ErrorCode != client.CodeValidationFailed || results[1].ErrorMessage != "invalid location: invalid argument" {
+			t.Errorf("SaveTripEvents() = %+v, %v", results, err)
+		}
+	})
+
+	t.Run("dead letter events", func(t *testing.T) {
+		appInstance, c, url := newClientTestServer(t)
+		_, err := c.GetDeadLetterEvents(ctx)
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("GetDeadLetterEvents() error = %v with api key, want %v", err, client.ErrUnauthorized)
+		}
+
+		c, err = client.NewClient(url, "testadminkey", client.WithRetry(1, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := domain.DeadLetterEvent{ID: "62c857a4cf8be7f22f19f0d6", Payload: "{}", Error: "invalid", Attempts: 1, CreatedAt: createdAt, UpdatedAt: createdAt}
+		appInstance.EXPECT().GetDeadLetterEvents(gomock.Any()).Return([]domain.DeadLetterEvent{event}, nil)
+		appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), event.ID).Return(&event, nil).Times(2)
+		appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), "missing").Return(nil, app.ErrDeadLetterEventNotFound)
+		appInstance.EXPECT().UpdateDeadLetterEventPayload(gomock.Any(), event.ID, `{"id":"x"}`).Return(&event, nil)
+
+		events, err := c.GetDeadLetterEvents(ctx)
+		if err != nil || len(events) != 1 || events[0].ID != event.ID || !events[0].CreatedAt.Equal(createdAt) {
+			t.Errorf("GetDeadLetterEvents() = %+v, %v", events, err)
+		}
+		got, err := c.GetDeadLetterEvent(ctx, event.ID)
+		if err != nil || got.Payload != event.Payload {
+			t.Errorf("GetDeadLetterEvent() = %+v, %v", got, err)
+		}
+		_, err = c.GetDeadLetterEvent(ctx, "missing")
+		if !errors.Is(err, client.ErrNotFound) || client.Code(err) != client.CodeDeadLetterEventNotFound {
+			t.Errorf("GetDeadLetterEvent() error = %v, want %v", err, client.CodeDeadLetterEventNotFound)
+		}
+		_, err = c.UpdateDeadLetterEvent(ctx, event.ID, `{"id":"x"}`)
+		if err != nil {
+			t.Errorf("UpdateDeadLetterEvent() error = %v", err)
+		}
+		// the payload is not a valid trip event
+		err = c.ReplayDeadLetterEvent(ctx, event.ID)
+		if !errors.Is(err, client.ErrBadRequest) {
+			t.Errorf("ReplayDeadLetterEvent() error = %v, want %v", err, client.ErrBadRequest)
+		}
+	})
+
+	t.Run("scooter position", func(t *testing.T) {
+		appInstance, c, url := newClientTestServer(t)
+		_, err := c.GetScooterPosition(ctx, scooterID)
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("GetScooterPosition() error = %v with api key, want %v", err, client.ErrUnauthorized)
+		}
+
+		c, err = client.NewClient(url, "testadminkey", client.WithRetry(1, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		position := &domain.ScooterPosition{ScooterID: scooterID, Location: domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}, UpdatedAt: createdAt, Age: 90 * time.Second, InTrip: true}
+		appInstance.EXPECT().GetScooterPosition(gomock.Any(), scooterID).Return(position, nil)
+		appInstance.EXPECT().GetScooterPosition(gomock.Any(), "missing").Return(nil, app.ErrScooterNotFound)
+
+		got, err := c.GetScooterPosition(ctx, scooterID)
+		if err != nil || got.ScooterID != scooterID || got.Location != location || got.UpdatedAt == nil || !got.UpdatedAt.Equal(createdAt) || got.AgeSeconds == nil || *got.AgeSeconds != 90 || !got.InTrip || got.Stale {
+			t.Errorf("GetScooterPosition() = %+v, %v", got, err)
+		}
+		_, err = c.GetScooterPosition(ctx, "missing")
+		if !errors.Is(err, client.ErrNotFound) || client.Code(err) != client.CodeScooterNotFound {
+			t.Errorf("GetScooterPosition() error = %v, want %v", err, client.CodeScooterNotFound)
+		}
+	})
+
+	t.Run("v2 scooters and trips", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		tripID := "62c857a4cf8be7f22f19f0d6"
+		startedAt := createdAt.Add(-time.Minute)
+		started := &domain.Trip{ID: tripID, UserID: userID, ScooterID: scooterID, StartLocation: domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}, StartedAt: startedAt}
+		ended := *started
+		ended.EndLocation = &domain.GeoLocation{Latitude: 52.53, Longitude: 13.41}
+		ended.EndedAt = &createdAt
+		events := []domain.TripEvent{
+			{ID: "event1", UserID: userID, ScooterID: scooterID, Type: domain.TripStartEvent, CreatedAt: startedAt},
+			{ID: "event2", UserID: userID, ScooterID: scooterID, Type: domain.TripLocationUpdateEvent, CreatedAt: startedAt.Add(time.Second)},
+			{ID: "event3", UserID: userID, ScooterID: scooterID, Type: domain.TripLocationUpdateEvent, CreatedAt: startedAt.Add(2 * time.Second)},
+		}
+		appInstance.EXPECT().GetScooter(gomock.Any(), scooterID).Return(&domain.Scooter{ID: scooterID, Name: "scooter", IsAvailable: true}, nil)
+		appInstance.EXPECT().GetScooter(gomock.Any(), "missing").Return(nil, app.ErrScooterNotFound)
+		appInstance.EXPECT().StartTrip(gomock.Any(), userID, scooterID).Return(started, nil)
+		appInstance.EXPECT().GetTrip(gomock.Any(), tripID).Return(started, nil)
+		appInstance.EXPECT().FinishTrip(gomock.Any(), tripID, domain.GeoLocation{Latitude: 52.53, Longitude: 13.41}).Return(&ended, nil)
+		appInstance.EXPECT().GetTripEvents(gomock.Any(), tripID).Return(events, nil).Times(2)
+		appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.TripEvent) error {
+			if e.ID == "" || e.ScooterID != scooterID || e.UserID != userID || !e.CreatedAt.Equal(createdAt) {
+				t.Errorf("saved event = %+v, want event of the scooter with generated id", e)
+			}
+			return nil
+		})
+
+		s, err := c.GetScooter(ctx, scooterID)
+		if err != nil || s.ID != scooterID || !s.IsAvailable {
+			t.Errorf("GetScooter() = %+v, %v", s, err)
+		}
+		_, err = c.GetScooter(ctx, "missing")
+		if !errors.Is(err, client.ErrNotFound) || client.Code(err) != client.CodeScooterNotFound {
+			t.Errorf("GetScooter() error = %v, want %v", err, client.CodeScooterNotFound)
+		}
+
+		trip, err := c.StartTrip(ctx, userID, scooterID)
+		if err != nil || trip.ID != tripID || trip.Status != client.TripInProgress || !trip.StartedAt.Equal(startedAt) {
+			t.Errorf("StartTrip() = %+v, %v", trip, err)
+		}
+		trip, err = c.GetTrip(ctx, tripID)
+		if err != nil || trip.ID != tripID || trip.EndedAt != nil {
+			t.Errorf("GetTrip() = %+v, %v", trip, err)
+		}
+		trip, err = c.FinishTrip(ctx, tripID, client.GeoLocation{Latitude: 52.53, Longitude: 13.41})
+		if err != nil || trip.Status != client.TripEnded || trip.EndLocation == nil || trip.EndedAt == nil {
+			t.Errorf("FinishTrip() = %+v, %v", trip, err)
+		}
+
+		event, err := c.CreateScooterEvent(ctx, scooterID, client.ScooterEvent{UserID: userID, Location: location, CreatedAt: createdAt, Type: client.TripStartEvent})
+		if err != nil || event.ID == "" || event.ScooterID != scooterID {
+			t.Errorf("CreateScooterEvent() = %+v, %v", event, err)
+		}
+
+		page, err := c.ListTripEvents(ctx, tripID, client.TripEventQuery{Type: client.TripLocationUpdateEvent, Limit: 1})
+		if err != nil || len(page.Items) != 1 || page.Items[0].ID != "event2" || page.NextCursor == "" {
+			t.Fatalf("ListTripEvents() = %+v, %v", page, err)
+		}
+		page, err = c.ListTripEvents(ctx, tripID, client.TripEventQuery{Type: client.TripLocationUpdateEvent, Limit: 1, Cursor: page.NextCursor})
+		if err != nil || len(page.Items) != 1 || page.Items[0].ID != "event3" || page.NextCursor != "" {
+			t.Errorf("ListTripEvents() next page = %+v, %v", page, err)
+		}
+	})
+
+	t.Run("search scooters", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		distance := 12.5
+		near := client.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+		want := domain.ScooterQuery{
+			Near:   &domain.GeoLocation{Latitude: 52.52, Longitude: 13.405},
+			Radius: 250.5,
+			BoundingBox: &domain.BoundingBox{
+				SouthWest: domain.GeoLocation{Latitude: 52.51, Longitude: 13.39},
+				NorthEast: domain.GeoLocation{Latitude: 52.53, Longitude: 13.42},
+			},
+			VehicleType:     "scooter",
+			MinBatteryLevel: 20,
+			Limit:           2,
+		}
+		appInstance.EXPECT().SearchAvailableScooters(gomock.Any(), want).Return([]domain.ScooterMatch{
+			{Scooter: domain.Scooter{ID: scooterID, Name: "scooter", IsAvailable: true, BatteryLevel: 80}, Distance: &distance},
+			{Scooter: domain.Scooter{ID: userID, Name: "next page", IsAvailable: true}},
+		}, nil)
+		polygon := []domain.GeoLocation{{Latitude: 52.51, Longitude: 13.39}, {Latitude: 52.53, Longitude: 13.39}, {Latitude: 52.53, Longitude: 13.42}}
+		appInstance.EXPECT().SearchAvailableScooters(gomock.Any(), domain.ScooterQuery{Polygon: polygon, Limit: 21}).Return([]domain.ScooterMatch{}, nil)
+
+		page, err := c.SearchScooters(ctx, client.ScooterQuery{
+			Near:   &near,
+			Radius: 250.5,
+			BoundingBox: &client.BoundingBox{
+				SouthWest: client.GeoLocation{Latitude: 52.51, Longitude: 13.39},
+				NorthEast: client.GeoLocation{Latitude: 52.53, Longitude: 13.42},
+			},
+			VehicleType: "scooter",
+			MinBattery:  20,
+			Limit:       1,
+		})
+		if err != nil || len(page.Items) != 1 || page.Items[0].ID != scooterID || page.Items[0].BatteryLevel != 80 || page.Items[0].Distance == nil || *page.Items[0].Distance != distance || page.NextCursor == "" {
+			t.Errorf("SearchScooters() = %+v, %v", page, err)
+		}
+		page, err = c.SearchScooters(ctx, client.ScooterQuery{
+			Polygon: []client.GeoLocation{{Latitude: 52.51, Longitude: 13.39}, {Latitude: 52.53, Longitude: 13.39}, {Latitude: 52.53, Longitude: 13.42}},
+		})
+		if err != nil || len(page.Items) != 0 || page.NextCursor != "" {
+			t.Errorf("SearchScooters() = %+v, %v", page, err)
+		}
+	})
+
+	t.Run("scooter clusters", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		boundingBox := domain.BoundingBox{
+			SouthWest: domain.GeoLocation{Latitude: 52.45, Longitude: 13.3},
+			NorthEast: domain.GeoLocation{Latitude: 52.55, Longitude: 13.5},
+		}
+		appInstance.EXPECT().GetScooterClusters(gomock.Any(), boundingBox, 12).Return([]domain.ScooterCluster{
+			{Center: domain.GeoLocation{Latitude: 52.5, Longitude: 13.4}, Count: 3},
+			{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 1, Scooter: &domain.Scooter{ID: scooterID, IsAvailable: true}},
+		}, nil)
+		appInstance.EXPECT().GetScooterClusters(gomock.Any(), boundingBox, 0).Return(nil, fmt.Errorf("bounding box too large: %w", app.ErrInvalidArg))
+
+		clientBox := client.BoundingBox{
+			SouthWest: client.GeoLocation{Latitude: 52.45, Longitude: 13.3},
+			NorthEast: client.GeoLocation{Latitude: 52.55, Longitude: 13.5},
+		}
+		clusters, err := c.GetScooterClusters(ctx, clientBox, 12)
+		if err != nil || clusters.Zoom != 12 || len(clusters.Clusters) != 2 || clusters.Clusters[0].Count != 3 || clusters.Clusters[1].Scooter == nil || clusters.Clusters[1].Scooter.ID != scooterID {
+			t.Errorf("GetScooterClusters() = %+v, %v", clusters, err)
+		}
+		_, err = c.GetScooterClusters(ctx, clientBox, 0)
+		if !errors.Is(err, client.ErrBadRequest) {
+			t.Errorf("GetScooterClusters() error = %v, want %v", err, client.ErrBadRequest)
+		}
+	})
+
+	t.Run("tile", func(t *testing.T) {
+		appInstance, c, _ := newClientTestServer(t)
+		tile := domain.Tile{Z: 12, X: 2200, Y: 1343}
+		mapTile := &domain.MapTile{
+			Tile:     tile,
+			Scooters: []domain.ScooterCluster{{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 7}},
+			TripHeat: []domain.HeatCell{{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 42}},
+		}
+		wantTile, err := mvt.Encode(newTileLayers(mapTile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		appInstance.EXPECT().GetMapTile(gomock.Any(), tile, true).Return(mapTile, nil)
+		appInstance.EXPECT().GetMapTile(gomock.Any(), tile, false).Return(nil, errors.New("db down"))
+
+		got, err := c.GetTile(ctx, 12, 2200, 1343, "scooters", "trip_heat")
+		if err != nil || !bytes.Equal(got, wantTile) {
+			t.Errorf("GetTile() = %v, %v, want %v", got, err, wantTile)
+		}
+		_, err = c.GetTile(ctx, 12, 2200, 1343)
+		if !errors.Is(err, client.ErrInternal) {
+			t.Errorf("GetTile() error = %v, want %v", err, client.ErrInternal)
+		}
+		_, err = c.GetTile(ctx, 12, 2200, 1343, "zones")
+		if !errors.Is(err, client.ErrBadRequest) {
+			t.Errorf("GetTile() error = %v, want %v", err, client.ErrBadRequest)
+		}
+	})
+
+	t.Run("health", func(t *testing.T) {
+		_, c, _ := newClientTestServer(t)
+		report, err := c.Healthz(ctx)
+		if err != nil || report.Status != health.StatusOK {
+			t.Errorf("Healthz() = %+v, %v", report, err)
+		}
+		report, err = c.Readyz(ctx)
+		if err != nil || report.Status != health.StatusOK {
+			t.Errorf("Readyz() = %+v, %v", report, err)
+		}
+	})
+
+	t.Run("invalid api key", func(t *testing.T) {
+		_, _, url := newClientTestServer(t)
+		c, err := client.NewClient(url, "invalid")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.GetDeadLetterEvents(ctx)
+		if !errors.Is(err, client.ErrUnauthorized) || client.Code(err) != client.CodeUnauthorized {
+			t.Errorf("GetDeadLetterEvents() error = %v, want %v", err, client.ErrUnauthorized)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type deadLetterEvent struct {
+	ID         string     `json:"id"`
+	Payload    string     `json:"payload"`
+	Error      string     `json:"error"`
+	Attempts   int        `json:"attempts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ReplayedAt *time.Time `json:"replayed_at"`
+}
+
+type getDeadLetterEventsResponse struct {
+	Events []deadLetterEvent `json:"events"`
+}
+
+type updateDeadLetterEventRequest struct {
+	Payload string `json:"payload" validate:"required"`
+}
+
+type replayDeadLetterEventResponse struct {
+	Success bool `json:"success"`
+}
+
+// transformToDeadLetterEvent creates dead letter event response from domain record
+func transformToDeadLetterEvent(event *domain.DeadLetterEvent) deadLetterEvent {
+	return deadLetterEvent{
+		ID:         event.ID,
+		Payload:    event.Payload,
+		Error:      event.Error,
+		Attempts:   event.Attempts,
+		CreatedAt:  event.CreatedAt,
+		UpdatedAt:  event.UpdatedAt,
+		ReplayedAt: event.ReplayedAt,
+	}
+}
+
+// getDeadLetterEvents godoc
+// @Summary returns dead letter events
+// @Description returns trip events which could not be saved along with the reason of failure
+// @Tags admin-api
+// @Accept  json
+// @Produce  json
+// @Param api_key query string true "admin api key"
+// @Success 200 {object} rest.getDeadLetterEventsResponse
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/admin/dead-letter-events [get]
+func (api *apiDetails) getDeadLetterEvents(c *gin.Context) {
+	events, err := api.app.GetDeadLetterEvents(c)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	resp := getDeadLetterEventsResponse{
+		Events: []deadLetterEvent{},
+	}
+	for i := range events {
+		resp.Events = append(resp.Events, transformToDeadLetterEvent(&events[i]))
+	}
+
+	c.IndentedJSON(http.StatusOK, resp)
+	c.Done()
+}
+
+// getDeadLetterEvent godoc
+// @Summary returns dead letter event
+// @Description returns dead letter event for given id along with its original payload
+// @Tags admin-api
+// @Accept  json
+// @Produce  json
+// @Param id path string true "dead letter event id"
+// @Param api_key query string true "admin api key"
+// @Success 200 {object} rest.deadLetterEvent
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/admin/dead-letter-events/{id} [get]
+func (api *apiDetails) getDeadLetterEvent(c *gin.Context) {
+	event, err := api.app.GetDeadLetterEvent(c, c.Param("id"))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, transformToDeadLetterEvent(event))
+	c.Done()
+}
+
+// updateDeadLetterEvent godoc
+// @Summary fixes up the dead letter event
+// @Description replaces the payload of dead letter event with the fixed one so that it can be replayed
+// @Tags admin-api
+// @Accept  json
+// @Produce  json
+// @Param id path string true "dead letter event id"
+// @Param updateDeadLetterEventRequest body rest.updateDeadLetterEventRequest true "update dead letter event request"
+// @Param api_key query string true "admin api key"
+// @Success 200 {object} rest.deadLetterEvent
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/admin/dead-letter-events/{id} [put]
+func (api *apiDetails) updateDeadLetterEvent(c *gin.Context) {
+	req := &updateDeadLetterEventRequest{}
+	err := c.BindJSON(req)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	event, err := api.app.UpdateDeadLetterEventPayload(c, c.Param("id"), req.Payload)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, transformToDeadLetterEvent(event))
+	c.Done()
+}
+
+// replayDeadLetterEvent godoc
+// @Summary replays the dead letter event
+// @Description parses the payload of dead letter event and saves it as trip event. The event is marked as replayed on success.
+// @Tags admin-api
+// @Accept  json
+// @Produce  json
+// @Param id path string true "dead letter event id"
+// @Param api_key query string true "admin api key"
+// @Success 200 {object} rest.replayDeadLetterEventResponse
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/admin/dead-letter-events/{id}/replay [post]
+func (api *apiDetails) replayDeadLetterEvent(c *gin.Context) {
+	event, err := api.app.GetDeadLetterEvent(c, c.Param("id"))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	tripEvent, err := parseTripEventPayload([]byte(event.Payload))
+	if err != nil {
+		createValidationErrorResponse(c, fmt.Errorf("invalid payload: %w", err))
+		return
+	}
+
+	err = api.app.ReplayDeadLetterEvent(c, event.ID, tripEvent)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	resp := replayDeadLetterEventResponse{
+		Success: true,
+	}
+
+	c.IndentedJSON(http.StatusOK, resp)
+	c.Done()
+}
@@ -0,0 +1,396 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+const (
+	testDeadLetterEventID = "62c857a4cf8be7f22f19f0d6"
+	testValidPayload      = `{
+		"created_at": "2022-07-09T17:49:09+00:00",
+		"location": {
+			"latitude": -73.856077,
+			"longitude": 40.848447
+		},
+		"scooter_id": "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+		"type": "trip_start",
+		"user_id": "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	}`
+)
+
+func (suite *HandlerTestSuite) Test_getDeadLetterEvents() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+	}
+	router := api.setupRouter()
+	deadLetterEventsApiPath := "/api/v1/auth/admin/dead-letter-events"
+
+	type args struct {
+		url string
+	}
+	type want struct {
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		args    args
+		want    want
+	}{
+		{
+			name:    "should return error for invalid api key",
+			prepare: func() {},
+			args: args{
+				url: deadLetterEventsApiPath + "?api_key=invalid",
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "should return error if get dead letter events returns error",
+			prepare: func() {
+				appInstance.EXPECT().GetDeadLetterEvents(gomock.Any()).Return(nil, errors.New("internal error")).Times(1)
+			},
+			args: args{
+				url: deadLetterEventsApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "should return dead letter events",
+			prepare: func() {
+				events := []domain.DeadLetterEvent{
+					{
+						ID:        testDeadLetterEventID,
+						Payload:   "{}",
+						Error:     "invalid argument",
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+					},
+				}
+				appInstance.EXPECT().GetDeadLetterEvents(gomock.Any()).Return(events, nil).Times(1)
+			},
+			args: args{
+				url: deadLetterEventsApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.args.url, nil)
+			router.ServeHTTP(w, req)
+
+			if tt.want.statusCode != w.Code {
+				t.Errorf("getDeadLetterEvents() status code  = %v, want status code %v", w.Code, tt.want.statusCode)
+				return
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) Test_getDeadLetterEvent() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+	}
+	router := api.setupRouter()
+	deadLetterEventApiPath := "/api/v1/auth/admin/dead-letter-events/" + testDeadLetterEventID
+
+	type args struct {
+		url string
+	}
+	type want struct {
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		args    args
+		want    want
+	}{
+		{
+			name:    "should return error for invalid api key",
+			prepare: func() {},
+			args: args{
+				url: deadLetterEventApiPath + "?api_key=invalid",
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "should return error if dead letter event not found",
+			prepare: func() {
+				appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), testDeadLetterEventID).Return(nil, app.ErrRecordNotFound).Times(1)
+			},
+			args: args{
+				url: deadLetterEventApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "should return dead letter event",
+			prepare: func() {
+				event := &domain.DeadLetterEvent{
+					ID:      testDeadLetterEventID,
+					Payload: "{}",
+					Error:   "invalid argument",
+				}
+				appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), testDeadLetterEventID).Return(event, nil).Times(1)
+			},
+			args: args{
+				url: deadLetterEventApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.args.url, nil)
+			router.ServeHTTP(w, req)
+
+			if tt.want.statusCode != w.Code {
+				t.Errorf("getDeadLetterEvent() status code  = %v, want status code %v", w.Code, tt.want.statusCode)
+				return
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) Test_updateDeadLetterEvent() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+	}
+	router := api.setupRouter()
+	deadLetterEventApiPath := "/api/v1/auth/admin/dead-letter-events/" + testDeadLetterEventID
+
+	type args struct {
+		url  string
+		body io.Reader
+	}
+	type want struct {
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		args    args
+		want    want
+	}{
+		{
+			name:    "should return error for invalid api key",
+			prepare: func() {},
+			args: args{
+				url: deadLetterEventApiPath + "?api_key=invalid",
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name:    "should return error for empty payload",
+			prepare: func() {},
+			args: args{
+				url:  deadLetterEventApiPath + "?api_key=testadminkey",
+				body: strings.NewReader(`{"payload": ""}`),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "should return error if event is already replayed",
+			prepare: func() {
+				appInstance.EXPECT().UpdateDeadLetterEventPayload(gomock.Any(), testDeadLetterEventID, "{}").Return(nil, app.ErrOperationNotAllowed).Times(1)
+			},
+			args: args{
+				url:  deadLetterEventApiPath + "?api_key=testadminkey",
+				body: strings.NewReader(`{"payload": "{}"}`),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "should return updated dead letter event",
+			prepare: func() {
+				event := &domain.DeadLetterEvent{
+					ID:      testDeadLetterEventID,
+					Payload: "{}",
+					Error:   "invalid argument",
+				}
+				appInstance.EXPECT().UpdateDeadLetterEventPayload(gomock.Any(), testDeadLetterEventID, "{}").Return(event, nil).Times(1)
+			},
+			args: args{
+				url:  deadLetterEventApiPath + "?api_key=testadminkey",
+				body: strings.NewReader(`{"payload": "{}"}`),
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPut, tt.args.url, tt.args.body)
+			router.ServeHTTP(w, req)
+
+			if tt.want.statusCode != w.Code {
+				t.Errorf("updateDeadLetterEvent() status code  = %v, want status code %v", w.Code, tt.want.statusCode)
+				return
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) Test_replayDeadLetterEvent() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+	}
+	router := api.setupRouter()
+	replayApiPath := "/api/v1/auth/admin/dead-letter-events/" + testDeadLetterEventID + "/replay"
+
+	type args struct {
+		url string
+	}
+	type want struct {
+		statusCode int
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		args    args
+		want    want
+	}{
+		{
+			name:    "should return error for invalid api key",
+			prepare: func() {},
+			args: args{
+				url: replayApiPath + "?api_key=invalid",
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "should return error if dead letter event not found",
+			prepare: func() {
+				appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), testDeadLetterEventID).Return(nil, app.ErrRecordNotFound).Times(1)
+			},
+			args: args{
+				url: replayApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "should return error if payload is still invalid",
+			prepare: func() {
+				event := &domain.DeadLetterEvent{
+					ID:      testDeadLetterEventID,
+					Payload: `{"type": "invalid"}`,
+				}
+				appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), testDeadLetterEventID).Return(event, nil).Times(1)
+			},
+			args: args{
+				url: replayApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "should return error if replay fails",
+			prepare: func() {
+				event := &domain.DeadLetterEvent{
+					ID:      testDeadLetterEventID,
+					Payload: testValidPayload,
+				}
+				gomock.InOrder(
+					appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), testDeadLetterEventID).Return(event, nil).Times(1),
+					appInstance.EXPECT().ReplayDeadLetterEvent(gomock.Any(), testDeadLetterEventID, gomock.Any()).Return(errors.New("internal error")).Times(1),
+				)
+			},
+			args: args{
+				url: replayApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "should return success if event is replayed",
+			prepare: func() {
+				event := &domain.DeadLetterEvent{
+					ID:      testDeadLetterEventID,
+					Payload: testValidPayload,
+				}
+				gomock.InOrder(
+					appInstance.EXPECT().GetDeadLetterEvent(gomock.Any(), testDeadLetterEventID).Return(event, nil).Times(1),
+					appInstance.EXPECT().ReplayDeadLetterEvent(gomock.Any(), testDeadLetterEventID, gomock.Any()).Return(nil).Times(1),
+				)
+			},
+			args: args{
+				url: replayApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.args.url, nil)
+			router.ServeHTTP(w, req)
+
+			if tt.want.statusCode != w.Code {
+				t.Errorf("replayDeadLetterEvent() status code  = %v, want status code %v", w.Code, tt.want.statusCode)
+				return
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package rest
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,12 +11,25 @@ import (
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
 	docs "github.com/ganeshdipdumbare/scootin-aboot-journey/docs"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+const (
+	// maxTripEventBatchSize is the max number of events accepted in one batch
+	maxTripEventBatchSize = 100
+	// maxTripEventSize is the max size in bytes of the trip event request
+	maxTripEventSize = 16 << 10
+	// maxRequestBodySize is the max size in bytes of the other requests
+	maxRequestBodySize = 1 << 20
+)
+
 var (
 	validate *validator.Validate
 )
@@ -29,6 +44,8 @@ type scooter struct {
 	Location      geoLocation `json:"location"`
 	CurrentUserID *string     `json:"current_user_id"`
 	IsAvailable   bool        `json:"is_available"`
+	VehicleType   string      `json:"vehicle_type,omitempty"`
+	BatteryLevel  int         `json:"battery_level,omitempty"`
 }
 
 type geoLocation struct {
@@ -59,6 +76,7 @@ type endTripResponse struct {
 }
 
 type saveScooterTripEventRequest struct {
+	ID        string      `json:"id" validate:"omitempty,uuid"`
 	UserID    string      `json:"user_id" validate:"required,uuid4"`
 	ScooterID string      `json:"scooter_id" validate:"required,uuid4"`
 	Location  geoLocation `json:"location" validate:"required"`
@@ -70,45 +88,82 @@ type saveScooterTripEventResponse struct {
 	Success bool `json:"success"`
 }
 
-type errorRespose struct {
-	ErrorMessage string `json:"errorMessage"`
+type saveScooterTripEventsRequest struct {
+	Events []json.RawMessage `json:"events" validate:"required,min=1" swaggertype:"array,object"`
 }
 
-func getErrHTTPStatusCode(err error) int {
-	httpCode := http.StatusInternalServerError
-	switch {
-	case errors.Is(err, app.ErrEmptyArg) || errors.Is(err, app.ErrInvalidArg) || errors.Is(err, app.ErrOperationNotAllowed):
-		httpCode = http.StatusBadRequest
-	case errors.Is(err, app.ErrRecordNotFound):
-		httpCode = http.StatusNotFound
-	}
-	return httpCode
+type tripEventResult struct {
+	Index        int    `json:"index"`
+	ID           string `json:"id"`
+	Success      bool   `json:"success"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	ErrorCode    string `json:"errorCode,omitempty"`
 }
 
-func createErrorResponse(c *gin.Context, code int, message string) {
-	c.IndentedJSON(code, &errorRespose{
-		ErrorMessage: message,
-	})
+// setError sets the error of the event which could not be saved
+func (r *tripEventResult) setError(p *problem) {
+	r.ErrorMessage = p.Detail
+	r.ErrorCode = string(p.Code)
+}
+
+type saveScooterTripEventsResponse struct {
+	Results []tripEventResult `json:"results"`
 }
 
 func (api *apiDetails) authenticate(c *gin.Context) {
 	apiKey := c.Query("api_key")
-	if apiKey != api.apiKey {
-		c.AbortWithStatus(http.StatusUnauthorized)
+	if apiKey != api.getApiKey() {
+		createUnauthorizedResponse(c)
+		return
+	}
+	c.Next()
+}
+
+// authenticateAdmin authenticates the admin request with the admin api key,
+// all the admin requests are rejected if the admin api key is not set
+func (api *apiDetails) authenticateAdmin(c *gin.Context) {
+	apiKey := c.Query("api_key")
+	adminApiKey := api.getAdminApiKey()
+	if adminApiKey == "" || apiKey != adminApiKey {
+		createUnauthorizedResponse(c)
 		return
 	}
 	c.Next()
 }
 
+// limitRequestBody returns the middleware which fails reading the request
+// body beyond max bytes, so that large body is not read into memory
+func limitRequestBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
 func (api *apiDetails) setupRouter() *gin.Engine {
 	validate = validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
 
 	apiV1 := "/api/v1"
-	docs.SwaggerInfo.BasePath = apiV1
+	docs.SwaggerInfo.BasePath = "/api"
+
+	if api.logger == nil {
+		api.logger = logger.Discard()
+	}
+	if api.health == nil {
+		api.health = health.NewChecker()
+	}
 
-	r := gin.Default()
+	r := gin.New()
+	// handlers pass gin context to the app, the fallback makes the request
+	// context e.g. trace span and request id available to the app
+	r.ContextWithFallback = true
+	r.Use(traceRequest, setRequestID, api.logRequest, gin.CustomRecovery(api.recoverPanic), recordMetrics, limitRequestBody(maxRequestBodySize))
 	v1group := r.Group(apiV1)
 	v1group.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", api.healthz)
+	r.GET("/readyz", api.readyz)
 
 	authUserGroup := v1group.Group("/auth/user")
 	authUserGroup.Use(api.authenticate)
@@ -118,12 +173,23 @@ func (api *apiDetails) setupRouter() *gin.Engine {
 
 	authScooterGroup := v1group.Group("/auth/scooter")
 	authScooterGroup.Use(api.authenticate)
-	authScooterGroup.POST("/trip-event", api.saveScooterTripEvent)
+	authScooterGroup.POST("/trip-event", limitRequestBody(maxTripEventSize), api.saveScooterTripEvent)
+	authScooterGroup.POST("/trip-events", api.saveScooterTripEvents)
+
+	authAdminGroup := v1group.Group("/auth/admin")
+	authAdminGroup.Use(api.authenticateAdmin)
+	authAdminGroup.GET("/dead-letter-events", api.getDeadLetterEvents)
+	authAdminGroup.GET("/dead-letter-events/:id", api.getDeadLetterEvent)
+	authAdminGroup.PUT("/dead-letter-events/:id", api.updateDeadLetterEvent)
+	authAdminGroup.POST("/dead-letter-events/:id/replay", api.replayDeadLetterEvent)
+	authAdminGroup.GET("/scooters/:id/position", api.getScooterPosition)
+
+	api.setupRouterV2(r)
 
 	return r
 }
 
-// @BasePath /api/v1
+// @BasePath /api
 
 // getAvailableScooters godoc
 // @Summary returns available scooters within given area
@@ -136,29 +202,29 @@ func (api *apiDetails) setupRouter() *gin.Engine {
 // @Param radius query integer true "radius(in meters)"
 // @Param api_key query string true "api_key"
 // @Success 200 {object} rest.getAvailableScootersResponse
-// @Failure 404 {object} rest.errorRespose
-// @Failure 400 {object} rest.errorRespose
-// @Failure 500 {object} rest.errorRespose
-// @Router /auth/user/available-scooters [get]
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/user/available-scooters [get]
 func (api *apiDetails) getAvailableScooters(c *gin.Context) {
 	lat := c.Query("latitude")
 	latitude, err := strconv.ParseFloat(lat, 64)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, "invalid latitude")
+		createValidationErrorResponse(c, &fieldError{Field: "latitude", Rule: "number", Message: "latitude must be a number"})
 		return
 	}
 
 	lng := c.Query("longitude")
 	longitude, err := strconv.ParseFloat(lng, 64)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, "invalid longitude")
+		createValidationErrorResponse(c, &fieldError{Field: "longitude", Rule: "number", Message: "longitude must be a number"})
 		return
 	}
 
 	rad := c.Query("radius")
 	radius, err := strconv.ParseInt(rad, 10, 64)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, "invalid radius")
+		createValidationErrorResponse(c, &fieldError{Field: "radius", Rule: "integer", Message: "radius must be an integer"})
 		return
 	}
 
@@ -168,28 +234,15 @@ func (api *apiDetails) getAvailableScooters(c *gin.Context) {
 	}
 	scooters, err := api.app.GetNearbyAvailableScooters(c, userLocation, int(radius))
 	if err != nil {
-		errStatusCode := getErrHTTPStatusCode(err)
-		createErrorResponse(c, errStatusCode, err.Error())
+		createErrorResponse(c, err)
 		return
 	}
 
 	resp := getAvailableScootersResponse{
 		Scooters: []scooter{},
 	}
-	for _, s := range scooters {
-		location := geoLocation{
-			Latitude:  s.Location.Latitude,
-			Longitude: s.Location.Longitude,
-		}
-
-		scooter := scooter{
-			ID:            s.ID,
-			Name:          s.Name,
-			Location:      location,
-			CurrentUserID: s.CurrentUserID,
-			IsAvailable:   s.IsAvailable,
-		}
-		resp.Scooters = append(resp.Scooters, scooter)
+	for i := range scooters {
+		resp.Scooters = append(resp.Scooters, newScooter(&scooters[i]))
 	}
 
 	c.IndentedJSON(http.StatusOK, resp)
@@ -205,28 +258,27 @@ func (api *apiDetails) getAvailableScooters(c *gin.Context) {
 // @Param beginTripRequest body rest.beginTripRequest true "begin trip request"
 // @Param api_key query string true "api_key"
 // @Success 200 {object} rest.beginTripResponse
-// @Failure 404 {object} rest.errorRespose
-// @Failure 400 {object} rest.errorRespose
-// @Failure 500 {object} rest.errorRespose
-// @Router /auth/user/begin-trip [put]
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/user/begin-trip [put]
 func (api *apiDetails) beginTrip(c *gin.Context) {
 	req := &beginTripRequest{}
 	err := c.BindJSON(req)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, err.Error())
+		createValidationErrorResponse(c, err)
 		return
 	}
 
 	err = validate.Struct(req)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, err.Error())
+		createValidationErrorResponse(c, err)
 		return
 	}
 
 	err = api.app.BeginTrip(c, req.UserID, req.ScooterID)
 	if err != nil {
-		errStatusCode := getErrHTTPStatusCode(err)
-		createErrorResponse(c, errStatusCode, err.Error())
+		createErrorResponse(c, err)
 		return
 	}
 
@@ -248,21 +300,21 @@ func (api *apiDetails) beginTrip(c *gin.Context) {
 // @Param endTripRequest body rest.endTripRequest true "end trip request"
 // @Param api_key query string true "api_key"
 // @Success 200 {object} rest.endTripResponse
-// @Failure 404 {object} rest.errorRespose
-// @Failure 400 {object} rest.errorRespose
-// @Failure 500 {object} rest.errorRespose
-// @Router /auth/user/end-trip [put]
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/user/end-trip [put]
 func (api *apiDetails) endTrip(c *gin.Context) {
 	req := &endTripRequest{}
 	err := c.BindJSON(req)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, err.Error())
+		createValidationErrorResponse(c, err)
 		return
 	}
 
 	err = validate.Struct(req)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, err.Error())
+		createValidationErrorResponse(c, err)
 		return
 	}
 
@@ -272,8 +324,7 @@ func (api *apiDetails) endTrip(c *gin.Context) {
 	}
 	err = api.app.EndTrip(c, req.UserID, req.ScooterID, location)
 	if err != nil {
-		errStatusCode := getErrHTTPStatusCode(err)
-		createErrorResponse(c, errStatusCode, err.Error())
+		createErrorResponse(c, err)
 		return
 	}
 
@@ -288,59 +339,178 @@ func (api *apiDetails) endTrip(c *gin.Context) {
 
 // saveScooterTripEvent godoc
 // @Summary saves the trip event generated by scooter
-// @Description saves the events generated by scooter when trip is started, ended and during the trip
+// @Description saves the events generated by scooter when trip is started, ended and during the trip. The events which could not be saved are moved to dead letter events.
 // @Tags scooter-api
 // @Accept  json
 // @Produce  json
 // @Param saveScooterTripEventRequest body rest.saveScooterTripEventRequest true "save trip event request"
 // @Param api_key query string true "api_key"
 // @Success 200 {object} rest.saveScooterTripEventResponse
-// @Failure 404 {object} rest.errorRespose
-// @Failure 400 {object} rest.errorRespose
-// @Failure 500 {object} rest.errorRespose
-// @Router /auth/scooter/trip-event [post]
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 413 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Failure 503 {object} rest.problem
+// @Router /v1/auth/scooter/trip-event [post]
 func (api *apiDetails) saveScooterTripEvent(c *gin.Context) {
-	req := &saveScooterTripEventRequest{}
+	payload, err := c.GetRawData()
+	if err != nil {
+		createRequestBodyErrorResponse(c, err)
+		return
+	}
+
+	tripEvent, err := parseTripEventPayload(payload)
+	if err != nil {
+		api.saveDeadLetterEvent(c, payload, err)
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	err = api.app.SaveScooterTripEvent(c, tripEvent)
+	if err != nil {
+		api.saveDeadLetterEvent(c, payload, err)
+		createErrorResponse(c, err)
+		return
+	}
+
+	resp := saveScooterTripEventResponse{
+		Success: true,
+	}
+
+	c.IndentedJSON(http.StatusCreated, resp)
+	c.Done()
+}
+
+// saveScooterTripEvents godoc
+// @Summary saves the batch of trip events generated by scooter
+// @Description saves the batch of events generated by scooter e.g. offline backlog of the scooter. Each event is validated independently and must have an id, saving already saved event is a no-op. The result of each event is returned at the same index as in the request. The events which could not be saved are moved to dead letter events.
+// @Tags scooter-api
+// @Accept  json
+// @Produce  json
+// @Param saveScooterTripEventsRequest body rest.saveScooterTripEventsRequest true "save trip events request"
+// @Param api_key query string true "api_key"
+// @Success 200 {object} rest.saveScooterTripEventsResponse
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Failure 503 {object} rest.problem
+// @Router /v1/auth/scooter/trip-events [post]
+func (api *apiDetails) saveScooterTripEvents(c *gin.Context) {
+	req := &saveScooterTripEventsRequest{}
 	err := c.BindJSON(req)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, err.Error())
+		createValidationErrorResponse(c, err)
 		return
 	}
 
 	err = validate.Struct(req)
 	if err != nil {
-		createErrorResponse(c, http.StatusBadRequest, err.Error())
+		createValidationErrorResponse(c, err)
 		return
 	}
 
-	if !domain.IsValidTripEventType(req.Type) {
-		createErrorResponse(c, http.StatusBadRequest, "invalid event type, valid values: trip_start,trip_stop and trip_location_update")
+	if len(req.Events) > maxTripEventBatchSize {
+		createValidationErrorResponse(c, &fieldError{
+			Field:   "events",
+			Rule:    "max",
+			Message: fmt.Sprintf("too many events, max allowed: %v", maxTripEventBatchSize),
+		})
 		return
 	}
 
+	resp := saveScooterTripEventsResponse{
+		Results: make([]tripEventResult, len(req.Events)),
+	}
+	tripEvents := []domain.TripEvent{}
+	tripEventIndexes := []int{}
+	for i, payload := range req.Events {
+		resp.Results[i].Index = i
+		tripEvent, err := parseTripEventPayload(payload)
+		if err == nil && tripEvent.ID == "" {
+			err = &fieldError{Field: "id", Rule: "required", Message: "id is required for batch events"}
+		}
+		if err != nil {
+			api.saveDeadLetterEvent(c, payload, err)
+			resp.Results[i].setError(newValidationProblem(err))
+			continue
+		}
+
+		resp.Results[i].ID = tripEvent.ID
+		tripEvents = append(tripEvents, *tripEvent)
+		tripEventIndexes = append(tripEventIndexes, i)
+	}
+
+	if len(tripEvents) != 0 {
+		results, err := api.app.SaveScooterTripEvents(c, tripEvents)
+		if err != nil {
+			for _, i := range tripEventIndexes {
+				api.saveDeadLetterEvent(c, req.Events[i], err)
+			}
+			createErrorResponse(c, err)
+			return
+		}
+
+		for j, i := range tripEventIndexes {
+			if results[j] != nil {
+				api.saveDeadLetterEvent(c, req.Events[i], results[j])
+				resp.Results[i].setError(newErrorProblem(results[j]))
+				continue
+			}
+			resp.Results[i].Success = true
+		}
+	}
+
+	c.IndentedJSON(http.StatusOK, resp)
+	c.Done()
+}
+
+// parseTripEventPayload parses and validates save trip event request payload
+// and returns domain trip event, otherwise returns error
+func parseTripEventPayload(payload []byte) (*domain.TripEvent, error) {
+	req := &saveScooterTripEventRequest{}
+	err := binding.JSON.BindBody(payload, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !domain.IsValidTripEventType(req.Type) {
+		return nil, &fieldError{
+			Field:   "type",
+			Rule:    "oneof",
+			Message: "invalid event type, valid values: trip_start,trip_stop and trip_location_update",
+		}
+	}
+
 	location := domain.GeoLocation{
 		Latitude:  req.Location.Latitude,
 		Longitude: req.Location.Longitude,
 	}
 	tripEvent := &domain.TripEvent{
+		ID:        req.ID,
 		UserID:    req.UserID,
 		ScooterID: req.ScooterID,
 		Location:  location,
 		Type:      domain.TripEventType(req.Type),
 		CreatedAt: req.CreatedAt.UTC(),
 	}
+	return tripEvent, nil
+}
 
-	err = api.app.SaveScooterTripEvent(c, tripEvent)
-	if err != nil {
-		errStatusCode := getErrHTTPStatusCode(err)
-		createErrorResponse(c, errStatusCode, err.Error())
+// saveDeadLetterEvent saves the trip event payload which could not be saved,
+// the error is only logged as the original error is returned to the client.
+// The events rejected due to backpressure are not saved as the client is
+// expected to retry them
+func (api *apiDetails) saveDeadLetterEvent(c *gin.Context, payload []byte, reason error) {
+	if errors.Is(reason, app.ErrServiceUnavailable) {
 		return
 	}
 
-	resp := saveScooterTripEventResponse{
-		Success: true,
+	err := api.app.SaveDeadLetterEvent(c, string(payload), reason.Error())
+	if err != nil {
+		api.log(c).WithError(err).Error("unable to save dead letter event")
 	}
-
-	c.IndentedJSON(http.StatusCreated, resp)
-	c.Done()
 }
@@ -1,17 +1,29 @@
 package rest
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/tracing"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type HandlerTestSuite struct {
@@ -416,8 +428,22 @@ func (suite *HandlerTestSuite) Test_saveScooterTripEvent() {
 			},
 		},
 		{
-			name:    "should return error for invalid body param",
+			// the too large body is not saved as dead letter event
+			name:    "should return error for too large body",
 			prepare: func() {},
+			args: args{
+				url:  saveTripEventApiPath + "?api_key=testkey",
+				body: strings.NewReader(`{"user_id": "` + strings.Repeat("x", maxTripEventSize) + `"}`),
+			},
+			want: want{
+				statusCode: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name: "should return error for invalid body param",
+			prepare: func() {
+				appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
 			args: args{
 				url: saveTripEventApiPath + "?api_key=testkey",
 				body: strings.NewReader(`{
@@ -436,8 +462,10 @@ func (suite *HandlerTestSuite) Test_saveScooterTripEvent() {
 			},
 		},
 		{
-			name:    "should return error for invalid body param type",
-			prepare: func() {},
+			name: "should return error for invalid body param type",
+			prepare: func() {
+				appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
 			args: args{
 				url: saveTripEventApiPath + "?api_key=testkey",
 				body: strings.NewReader(`{
@@ -456,8 +484,10 @@ func (suite *HandlerTestSuite) Test_saveScooterTripEvent() {
 			},
 		},
 		{
-			name:    "should return error for invalid event type",
-			prepare: func() {},
+			name: "should return error for invalid event type",
+			prepare: func() {
+				appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
 			args: args{
 				url: saveTripEventApiPath + "?api_key=testkey",
 				body: strings.NewReader(`{
@@ -478,7 +508,10 @@ func (suite *HandlerTestSuite) Test_saveScooterTripEvent() {
 		{
 			name: "should return error if error while saving trip event",
 			prepare: func() {
-				appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).Return(app.ErrInvalidArg).Times(1)
+				gomock.InOrder(
+					appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).Return(app.ErrInvalidArg).Times(1),
+					appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1),
+				)
 			},
 			args: args{
 				url: saveTripEventApiPath + "?api_key=testkey",
@@ -497,6 +530,28 @@ func (suite *HandlerTestSuite) Test_saveScooterTripEvent() {
 				statusCode: http.StatusBadRequest,
 			},
 		},
+		{
+			name: "should return error without dead letter if trip event queue is full",
+			prepare: func() {
+				appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).Return(app.ErrServiceUnavailable).Times(1)
+			},
+			args: args{
+				url: saveTripEventApiPath + "?api_key=testkey",
+				body: strings.NewReader(`{
+					"created_at": "2022-07-09T17:49:09+00:00",
+					"location": {
+					  "latitude": -73.856077,
+					  "longitude": 40.848447
+					},
+					"scooter_id": "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+					"type": "trip_start",
+					"user_id": "f3b9842c-182a-418b-92fd-95d4f46414c5"
+				  }`),
+			},
+			want: want{
+				statusCode: http.StatusServiceUnavailable,
+			},
+		},
 		{
 			name: "should return success if the trip event is saved successfully",
 			prepare: func() {
@@ -534,3 +589,440 @@ func (suite *HandlerTestSuite) Test_saveScooterTripEvent() {
 		})
 	}
 }
+
+func (suite *HandlerTestSuite) Test_saveScooterTripEvents() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	saveTripEventsApiPath := "/api/v1/auth/scooter/trip-events"
+	validEvent := `{
+		"id": "8c5a1f6e-4f0b-4d1e-9a4f-2a0c3b6f7d11",
+		"created_at": "2022-07-09T17:49:09+00:00",
+		"location": {
+			"latitude": -73.856077,
+			"longitude": 40.848447
+		},
+		"scooter_id": "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+		"type": "trip_location_update",
+		"user_id": "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	}`
+	eventWithoutID := `{
+		"created_at": "2022-07-09T17:49:09+00:00",
+		"location": {
+			"latitude": -73.856077,
+			"longitude": 40.848447
+		},
+		"scooter_id": "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+		"type": "trip_location_update",
+		"user_id": "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	}`
+
+	type args struct {
+		url  string
+		body io.Reader
+	}
+	type want struct {
+		statusCode int
+		results    []tripEventResult
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		args    args
+		want    want
+	}{
+		{
+			name:    "should return error for invalid api key",
+			prepare: func() {},
+			args: args{
+				url: saveTripEventsApiPath + "?api_key=invalid",
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name:    "should return error for empty events",
+			prepare: func() {},
+			args: args{
+				url:  saveTripEventsApiPath + "?api_key=testkey",
+				body: strings.NewReader(`{"events": []}`),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "should return error for too many events",
+			prepare: func() {},
+			args: args{
+				url:  saveTripEventsApiPath + "?api_key=testkey",
+				body: strings.NewReader(`{"events": [` + strings.Repeat(validEvent+",", maxTripEventBatchSize) + validEvent + `]}`),
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "should return error if whole batch could not be saved",
+			prepare: func() {
+				gomock.InOrder(
+					appInstance.EXPECT().SaveScooterTripEvents(gomock.Any(), gomock.Len(1)).Return(nil, errors.New("internal error")).Times(1),
+					appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1),
+				)
+			},
+			args: args{
+				url:  saveTripEventsApiPath + "?api_key=testkey",
+				body: strings.NewReader(`{"events": [` + validEvent + `]}`),
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "should return result of each event",
+			prepare: func() {
+				gomock.InOrder(
+					appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2),
+					appInstance.EXPECT().SaveScooterTripEvents(gomock.Any(), gomock.Len(3)).Return([]error{nil, fmt.Errorf("write error: %w", app.ErrInvalidArg), errors.New("disk full")}, nil).Times(1),
+					appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), "write error: invalid argument").Return(nil).Times(1),
+					appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), "disk full").Return(nil).Times(1),
+				)
+			},
+			args: args{
+				url:  saveTripEventsApiPath + "?api_key=testkey",
+				body: strings.NewReader(`{"events": [` + validEvent + `,` + eventWithoutID + `,{"type": "invalid"},` + validEvent + `,` + validEvent + `]}`),
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				results: []tripEventResult{
+					{Index: 0, ID: "8c5a1f6e-4f0b-4d1e-9a4f-2a0c3b6f7d11", Success: true},
+					{Index: 1, ErrorMessage: "id is required for batch events", ErrorCode: "VALIDATION_FAILED"},
+					{Index: 2, ErrorMessage: "required", ErrorCode: "VALIDATION_FAILED"},
+					{Index: 3, ID: "8c5a1f6e-4f0b-4d1e-9a4f-2a0c3b6f7d11", ErrorMessage: "write error", ErrorCode: "VALIDATION_FAILED"},
+					// the internal error is not returned
+					{Index: 4, ID: "8c5a1f6e-4f0b-4d1e-9a4f-2a0c3b6f7d11", ErrorMessage: "internal server error", ErrorCode: "INTERNAL_ERROR"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.args.url, tt.args.body)
+			router.ServeHTTP(w, req)
+
+			if tt.want.statusCode != w.Code {
+				t.Errorf("saveScooterTripEvents() status code  = %v, want status code %v", w.Code, tt.want.statusCode)
+				return
+			}
+
+			if tt.want.results == nil {
+				return
+			}
+			resp := saveScooterTripEventsResponse{}
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Results) != len(tt.want.results) {
+				t.Fatalf("saveScooterTripEvents() results = %v, want %v", resp.Results, tt.want.results)
+			}
+			for i, want := range tt.want.results {
+				got := resp.Results[i]
+				if got.Index != want.Index || got.ID != want.ID || got.Success != want.Success || !strings.Contains(got.ErrorMessage, want.ErrorMessage) || got.ErrorCode != want.ErrorCode {
+					t.Errorf("saveScooterTripEvents() result = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) Test_metrics() {
+	t := suite.T()
+	api := &apiDetails{
+		app:    suite.App,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+
+	// unauthorized request is recorded by route template and status
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/admin/dead-letter-events/someid?api_key=invalid", nil)
+	router.ServeHTTP(w, req)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	want := `scootin_http_requests_total{method="GET",route="/api/v1/auth/admin/dead-letter-events/:id",status="401"}`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Errorf("GET /metrics body does not contain %v", want)
+	}
+
+	// the expvars are not served as they include the command line flags
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/debug/vars", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /debug/vars status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func (suite *HandlerTestSuite) Test_traceRequest() {
+	t := suite.T()
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer provider.Shutdown(context.Background())
+
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	userID := "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	scooterID := "f691fd32-9b3f-4d71-b9b7-c48213bfd232"
+	appInstance.EXPECT().BeginTrip(gomock.Any(), userID, scooterID).DoAndReturn(
+		func(ctx context.Context, userID string, scooterID string) error {
+			// app receives the request span which continues client trace
+			if got := tracing.TraceID(ctx); got != traceID {
+				t.Errorf("BeginTrip() trace id = %v, want %v", got, traceID)
+			}
+			return app.ErrOperationNotAllowed
+		}).Times(1)
+
+	w := httptest.NewRecorder()
+	body := `{"user_id":"` + userID + `","scooter_id":"` + scooterID + `"}`
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/auth/user/begin-trip?api_key=testkey", strings.NewReader(body))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(w, req)
+
+	resp := &problem{}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TraceID != traceID {
+		t.Errorf("error response trace id = %v, want %v", resp.TraceID, traceID)
+	}
+	if got := w.Header().Get("Content-Type"); got != problemContentType {
+		t.Errorf("error response content type = %v, want %v", got, problemContentType)
+	}
+	if resp.Code != app.CodeOperationNotAllowed || resp.Status != http.StatusBadRequest || resp.Instance != "/api/v1/auth/user/begin-trip" {
+		t.Errorf("error response = %+v, want %v problem of the request", resp, app.CodeOperationNotAllowed)
+	}
+}
+
+func (suite *HandlerTestSuite) Test_setRequestID() {
+	t := suite.T()
+	out := &bytes.Buffer{}
+	requestLogger, err := logger.New(out, logger.FormatJSON, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appInstance := suite.App
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+		logger:      requestLogger,
+	}
+	router := api.setupRouter()
+
+	appInstance.EXPECT().GetDeadLetterEvents(gomock.Any()).DoAndReturn(
+		func(ctx context.Context) ([]domain.DeadLetterEvent, error) {
+			if got := logger.RequestID(ctx); got != "requestid" {
+				t.Errorf("GetDeadLetterEvents() request id = %v, want requestid", got)
+			}
+			return nil, nil
+		}).Times(1)
+
+	// request id sent by client is propagated
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/admin/dead-letter-events?api_key=testadminkey", nil)
+	req.Header.Set(logger.RequestIDHeader, "requestid")
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get(logger.RequestIDHeader); got != "requestid" {
+		t.Errorf("response request id = %v, want requestid", got)
+	}
+
+	// request id is generated if client did not send valid one
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/auth/admin/dead-letter-events?api_key=invalid", nil)
+	req.Header.Set(logger.RequestIDHeader, "invalid\nrequestid")
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get(logger.RequestIDHeader); got == "" || got == "invalid\nrequestid" {
+		t.Errorf("response request id = %q, want generated request id", got)
+	}
+
+	if strings.Contains(out.String(), "testadminkey") {
+		t.Errorf("request log contains api key: %v", out.String())
+	}
+	if !strings.Contains(out.String(), `"request_id":"requestid"`) {
+		t.Errorf("request log does not contain request id: %v", out.String())
+	}
+}
+
+func (suite *HandlerTestSuite) Test_readyz() {
+	t := suite.T()
+	checker := health.NewChecker()
+	mongoErr := error(nil)
+	checker.Add("mongo", func(ctx context.Context) (string, error) {
+		return "", mongoErr
+	})
+
+	appInstance := suite.App
+	api := &apiDetails{
+		app:        appInstance,
+		apiKey:     "testkey",
+		health:     checker,
+		drainDelay: 100 * time.Millisecond,
+		server:     &http.Server{},
+	}
+	router := api.setupRouter()
+
+	get := func(path string) (int, health.Report) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		report := health.Report{}
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, report
+	}
+
+	code, report := get("/readyz")
+	if code != http.StatusOK || report.Checks["mongo"].Status != health.StatusOK {
+		t.Errorf("GET /readyz = %v %v, want %v", code, report, http.StatusOK)
+	}
+
+	mongoErr = errors.New("unreachable")
+	code, report = get("/readyz")
+	if code != http.StatusServiceUnavailable || report.Checks["mongo"].Error != "unreachable" {
+		t.Errorf("GET /readyz = %v %v, want %v", code, report, http.StatusServiceUnavailable)
+	}
+
+	// readiness fails during drain delay while liveness still passes
+	mongoErr = nil
+	appInstance.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
+	stopped := make(chan struct{})
+	go func() {
+		api.GracefulStopServer()
+		close(stopped)
+	}()
+	for !checker.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	code, report = get("/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != health.StatusDraining {
+		t.Errorf("GET /readyz while draining = %v %v, want %v", code, report, http.StatusServiceUnavailable)
+	}
+	code, report = get("/healthz")
+	if code != http.StatusOK || report.Status != health.StatusOK {
+		t.Errorf("GET /healthz while draining = %v %v, want %v", code, report, http.StatusOK)
+	}
+	<-stopped
+}
+
+func (suite *HandlerTestSuite) TestGracefulStopServerStopsAppOnShutdownError() {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	api := &apiDetails{
+		app:             suite.App,
+		health:          health.NewChecker(),
+		server:          server.Config,
+		shutdownTimeout: 10 * time.Millisecond,
+		appStopTimeout:  time.Second,
+		logger:          logger.Discard(),
+	}
+	go http.Get(server.URL)
+	<-started
+
+	// the in-flight request outlives the shutdown timeout, the app must
+	// still be stopped so that the queued trip events are saved
+	suite.App.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
+	api.GracefulStopServer()
+}
+
+func (suite *HandlerTestSuite) TestSetApiKey() {
+	t := suite.T()
+	api := &apiDetails{
+		app:         suite.App,
+		apiKey:      "oldkey",
+		adminApiKey: "oldadminkey",
+	}
+	router := api.setupRouter()
+	suite.App.EXPECT().GetNearbyAvailableScooters(gomock.Any(), gomock.Any(), gomock.Any()).Return([]domain.Scooter{}, nil).Times(1)
+	suite.App.EXPECT().GetDeadLetterEvents(gomock.Any()).Return([]domain.DeadLetterEvent{}, nil).Times(1)
+
+	if err := api.SetApiKey(""); err == nil {
+		t.Errorf("SetApiKey() error = nil for empty key")
+	}
+	if err := api.SetApiKey("newkey"); err != nil {
+		t.Fatalf("SetApiKey() error = %v", err)
+	}
+	if err := api.SetAdminApiKey(""); err == nil {
+		t.Errorf("SetAdminApiKey() error = nil for empty key")
+	}
+	if err := api.SetAdminApiKey("newadminkey"); err != nil {
+		t.Fatalf("SetAdminApiKey() error = %v", err)
+	}
+
+	tests := []struct {
+		url        string
+		statusCode int
+	}{
+		{url: "/api/v1/auth/user/available-scooters?latitude=1&longitude=1&radius=2&api_key=oldkey", statusCode: http.StatusUnauthorized},
+		{url: "/api/v1/auth/user/available-scooters?latitude=1&longitude=1&radius=2&api_key=newkey", statusCode: http.StatusOK},
+		{url: "/api/v1/auth/admin/dead-letter-events?api_key=oldadminkey", statusCode: http.StatusUnauthorized},
+		// the api key of scooters and users is not accepted by the admin api
+		{url: "/api/v1/auth/admin/dead-letter-events?api_key=newkey", statusCode: http.StatusUnauthorized},
+		{url: "/api/v1/auth/admin/dead-letter-events?api_key=newadminkey", statusCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.statusCode {
+			t.Errorf("GET %v status = %v, want %v", tt.url, w.Code, tt.statusCode)
+		}
+	}
+}
+
+func (suite *HandlerTestSuite) TestAdminApiDisabledWithoutAdminApiKey() {
+	t := suite.T()
+	api := &apiDetails{
+		app:    suite.App,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+
+	for _, apiKey := range []string{"", "testkey"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/admin/dead-letter-events?api_key="+apiKey, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("GET dead-letter-events with %q status = %v, want %v", apiKey, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/gin-gonic/gin"
+)
+
+// healthz godoc
+// @Summary liveness of the service
+// @Description returns ok as long as the server is able to serve requests
+// @Tags health
+// @Produce  json
+// @Success 200 {object} health.Report
+// @Router /healthz [get]
+func (api *apiDetails) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{
+		Status: health.StatusOK,
+		Checks: map[string]health.CheckResult{},
+	})
+}
+
+// readyz godoc
+// @Summary readiness of the service
+// @Description returns the result of each dependency check, fails once the service is draining
+// @Tags health
+// @Produce  json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (api *apiDetails) readyz(c *gin.Context) {
+	report := api.health.Check(c)
+	if report.Status != health.StatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+	"unicode"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxRequestIDLength is the max length of request id accepted from client
+	maxRequestIDLength = 128
+)
+
+// validRequestID returns true if the request id sent by client is safe to log
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// setRequestID propagates the request id sent by client or generates new one,
+// the request id is attached to the request context and echoed in the response
+func setRequestID(c *gin.Context) {
+	requestID := c.GetHeader(logger.RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+	c.Header(logger.RequestIDHeader, requestID)
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request_id", requestID))
+	c.Next()
+}
+
+// logRequest logs each request once it is served, the sensitive query params
+// e.g. api key are redacted
+func (api *apiDetails) logRequest(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	fields := logrus.Fields{
+		"method":     c.Request.Method,
+		"path":       c.Request.URL.Path,
+		"query":      logger.RedactQuery(c.Request.URL.RawQuery),
+		"route":      c.FullPath(),
+		"status":     c.Writer.Status(),
+		"latency":    time.Since(start).String(),
+		"client_ip":  c.ClientIP(),
+		"size_bytes": c.Writer.Size(),
+	}
+	entry := api.log(c).WithFields(fields)
+	if len(c.Errors) > 0 {
+		entry = entry.WithField("errors", c.Errors.String())
+	}
+
+	switch status := c.Writer.Status(); {
+	case status >= 500:
+		entry.Error("request served")
+	case status >= 400:
+		entry.Warn("request served")
+	default:
+		entry.Info("request served")
+	}
+}
+
+// log returns the logger with request id and trace id of the request
+func (api *apiDetails) log(c *gin.Context) logrus.FieldLogger {
+	return logger.FromContext(c.Request.Context(), api.logger)
+}
+
+// recoverPanic logs the panic recovered while serving request
+func (api *apiDetails) recoverPanic(c *gin.Context, err interface{}) {
+	api.log(c).WithField("panic", err).Error("panic recovered")
+	c.Abort()
+	writeProblem(c, newProblem(http.StatusInternalServerError, app.CodeInternal, "internal server error"))
+}
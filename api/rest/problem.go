@@ -0,0 +1,215 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// problemContentType is the content type of the error responses
+	problemContentType = "application/problem+json"
+
+	// codeUnauthorized is the error code of the request with invalid api key,
+	// the other error codes are the app error codes
+	codeUnauthorized app.ErrorCode = "UNAUTHORIZED"
+	// codeRequestTooLarge is the error code of the request with body larger
+	// than allowed
+	codeRequestTooLarge app.ErrorCode = "REQUEST_TOO_LARGE"
+
+	// errRequestTooLarge is the error message of reading body beyond the
+	// limit of http.MaxBytesReader
+	errRequestTooLarge = "http: request body too large"
+)
+
+// problem is the error response as per RFC 7807, code is the stable code of
+// the error which the clients can rely on unlike the detail
+type problem struct {
+	Type     string        `json:"type"`
+	Title    string        `json:"title"`
+	Status   int           `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Instance string        `json:"instance,omitempty"`
+	Code     app.ErrorCode `json:"code" swaggertype:"string"`
+	TraceID  string        `json:"traceId,omitempty"`
+	Errors   []fieldError  `json:"errors,omitempty"`
+}
+
+// fieldError is the validation failure of the request field, rule is the
+// failed validation e.g. required or uuid4
+type fieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *fieldError) Error() string {
+	return e.Message
+}
+
+func getErrHTTPStatusCode(err error) int {
+	httpCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, app.ErrEmptyArg) || errors.Is(err, app.ErrInvalidArg) || errors.Is(err, app.ErrOperationNotAllowed):
+		httpCode = http.StatusBadRequest
+	case errors.Is(err, app.ErrRecordNotFound):
+		httpCode = http.StatusNotFound
+	case errors.Is(err, app.ErrServiceUnavailable):
+		httpCode = http.StatusServiceUnavailable
+	}
+	return httpCode
+}
+
+// newProblem creates problem of the status, the type is about:blank so the
+// title is the text of the status
+func newProblem(status int, code app.ErrorCode, detail string) *problem {
+	return &problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// newErrorProblem creates problem of the app error. The detail of the
+// internal error is not returned to the client.
+func newErrorProblem(err error) *problem {
+	status := getErrHTTPStatusCode(err)
+	if status == http.StatusInternalServerError {
+		return newProblem(status, app.CodeInternal, "internal server error")
+	}
+	return newProblem(status, app.Code(err), err.Error())
+}
+
+// newValidationProblem creates problem of the request which could not be
+// parsed or validated, with the failure of each field
+func newValidationProblem(err error) *problem {
+	p := newProblem(http.StatusBadRequest, app.CodeValidationFailed, err.Error())
+	p.Errors = fieldErrors(err)
+	if len(p.Errors) > 0 {
+		messages := make([]string, len(p.Errors))
+		for i := range p.Errors {
+			messages[i] = p.Errors[i].Message
+		}
+		p.Detail = strings.Join(messages, ", ")
+	}
+	return p
+}
+
+// writeProblem writes the problem response with the status of the problem
+func writeProblem(c *gin.Context, p *problem) {
+	p.Instance = c.Request.URL.Path
+	p.TraceID = tracing.TraceID(c.Request.Context())
+
+	c.Header("Content-Type", problemContentType)
+	c.IndentedJSON(p.Status, p)
+}
+
+// createErrorResponse writes the problem response of the app error, the
+// internal error is logged with the request
+func createErrorResponse(c *gin.Context, err error) {
+	p := newErrorProblem(err)
+	if p.Code == app.CodeInternal {
+		_ = c.Error(err)
+	}
+	writeProblem(c, p)
+}
+
+// createValidationErrorResponse writes the problem response of the request
+// which could not be parsed or validated
+func createValidationErrorResponse(c *gin.Context, err error) {
+	writeProblem(c, newValidationProblem(err))
+}
+
+// createRequestBodyErrorResponse writes the problem response of the request
+// whose body could not be read
+func createRequestBodyErrorResponse(c *gin.Context, err error) {
+	if err.Error() == errRequestTooLarge {
+		writeProblem(c, newProblem(http.StatusRequestEntityTooLarge, codeRequestTooLarge, err.Error()))
+		return
+	}
+	createValidationErrorResponse(c, err)
+}
+
+// createUnauthorizedResponse writes the problem response of the request with
+// invalid api key and aborts the request
+func createUnauthorizedResponse(c *gin.Context) {
+	c.Abort()
+	writeProblem(c, newProblem(http.StatusUnauthorized, codeUnauthorized, "invalid api key"))
+}
+
+// fieldErrors returns the failed fields of the parse or validation error, nil
+// if the error is not about the fields
+func fieldErrors(err error) []fieldError {
+	validationErrs := validator.ValidationErrors{}
+	typeErr := &json.UnmarshalTypeError{}
+	fieldErr := &fieldError{}
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]fieldError, len(validationErrs))
+		for i, e := range validationErrs {
+			fields[i] = newFieldError(e)
+		}
+		return fields
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return []fieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("%v must be %v", typeErr.Field, typeErr.Type.Kind()),
+		}}
+	case errors.As(err, &fieldErr):
+		return []fieldError{*fieldErr}
+	}
+	return nil
+}
+
+// newFieldError creates field error from the validator error, the field is
+// the json path of the field in the request e.g. location.latitude
+func newFieldError(e validator.FieldError) fieldError {
+	field := e.Namespace()
+	if _, path, ok := strings.Cut(field, "."); ok {
+		field = path
+	}
+
+	var message string
+	switch e.Tag() {
+	case "required":
+		message = "is required"
+	case "uuid4":
+		message = "must be uuid4"
+	case "latitude", "longitude":
+		message = "must be valid " + e.Tag()
+	case "min":
+		message = "must have at least " + e.Param() + " items"
+	default:
+		message = "failed on " + e.Tag() + " validation"
+	}
+
+	return fieldError{
+		Field:   field,
+		Rule:    e.Tag(),
+		Message: field + " " + message,
+	}
+}
+
+// jsonFieldName returns the json name of the struct field which is used in
+// validation errors
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
@@ -3,27 +3,86 @@ package rest
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/api"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	ErrNilArg   = "nil %v not allowed"
 	ErrEmptyArg = "empty %v not allowed"
+
+	// defaultShutdownTimeout is the max time to wait for in-flight requests
+	defaultShutdownTimeout = 3 * time.Second
+	// defaultAppStopTimeout is the max time to wait for app to save queued trip events
+	defaultAppStopTimeout = 30 * time.Second
 )
 
 type apiDetails struct {
-	app    app.App
-	server *http.Server
-	apiKey string
+	app         app.App
+	server      *http.Server
+	apiKeyMu    sync.RWMutex
+	apiKey      string
+	adminApiKey string
+	logger      logrus.FieldLogger
+	health      *health.Checker
+	drainDelay  time.Duration
+
+	shutdownTimeout time.Duration
+	appStopTimeout  time.Duration
+}
+
+// Option configures optional behaviour of the api
+type Option func(a *apiDetails)
+
+// WithLogger sets the logger used for request logs and errors
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(a *apiDetails) {
+		a.logger = l
+	}
+}
+
+// WithAdminApiKey sets the api key used to authenticate the admin requests,
+// it must differ from the api key of the scooters and users
+func WithAdminApiKey(adminApiKey string) Option {
+	return func(a *apiDetails) {
+		a.adminApiKey = adminApiKey
+	}
+}
+
+// WithHealthChecker sets the checker used for readiness, it is drained when
+// the server is stopped
+func WithHealthChecker(checker *health.Checker) Option {
+	return func(a *apiDetails) {
+		a.health = checker
+	}
+}
+
+// WithDrainDelay sets the time to wait after readiness starts failing before
+// the listener is closed, so that load balancers stop sending requests
+func WithDrainDelay(d time.Duration) Option {
+	return func(a *apiDetails) {
+		a.drainDelay = d
+	}
+}
+
+// WithStopTimeouts sets the max time to wait for in-flight requests and the
+// max time to wait for app to save queued trip events when server is stopped
+func WithStopTimeouts(shutdownTimeout time.Duration, appStopTimeout time.Duration) Option {
+	return func(a *apiDetails) {
+		a.shutdownTimeout = shutdownTimeout
+		a.appStopTimeout = appStopTimeout
+	}
 }
 
 // NewApi creates new api instance, otherwise returns error
-func NewApi(a app.App, port string, apiKey string) (api.Api, error) {
+func NewApi(a app.App, port string, apiKey string, opts ...Option) (api.Api, error) {
 	if a == nil {
 		return nil, fmt.Errorf(ErrNilArg, "app")
 	}
@@ -39,6 +98,13 @@ func NewApi(a app.App, port string, apiKey string) (api.Api, error) {
 	api := &apiDetails{
 		app:    a,
 		apiKey: apiKey,
+		logger: logger.Discard(),
+
+		shutdownTimeout: defaultShutdownTimeout,
+		appStopTimeout:  defaultAppStopTimeout,
+	}
+	for _, opt := range opts {
+		opt(api)
 	}
 
 	router := api.setupRouter()
@@ -50,23 +116,75 @@ func NewApi(a app.App, port string, apiKey string) (api.Api, error) {
 	return api, nil
 }
 
+// SetApiKey replaces the api key used to authenticate requests, the requests
+// in flight are authenticated with either the old or the new key
+func (a *apiDetails) SetApiKey(apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf(ErrEmptyArg, "apiKey")
+	}
+
+	a.apiKeyMu.Lock()
+	defer a.apiKeyMu.Unlock()
+	a.apiKey = apiKey
+	return nil
+}
+
+// getApiKey returns the api key used to authenticate requests
+func (a *apiDetails) getApiKey() string {
+	a.apiKeyMu.RLock()
+	defer a.apiKeyMu.RUnlock()
+	return a.apiKey
+}
+
+// SetAdminApiKey replaces the api key used to authenticate admin requests
+func (a *apiDetails) SetAdminApiKey(adminApiKey string) error {
+	if adminApiKey == "" {
+		return fmt.Errorf(ErrEmptyArg, "adminApiKey")
+	}
+
+	a.apiKeyMu.Lock()
+	defer a.apiKeyMu.Unlock()
+	a.adminApiKey = adminApiKey
+	return nil
+}
+
+// getAdminApiKey returns the api key used to authenticate admin requests
+func (a *apiDetails) getAdminApiKey() string {
+	a.apiKeyMu.RLock()
+	defer a.apiKeyMu.RUnlock()
+	return a.adminApiKey
+}
+
 // StartServer starts rest server and wait for kill signal to stop it gracefully
 // otherwise returns error
 func (a *apiDetails) StartServer() {
 	go func() {
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			a.logger.WithError(err).Fatal("unable to listen")
 		}
 	}()
 }
 
-// GracefulStopServer gracefully stops the rest server
+// GracefulStopServer gracefully stops the rest server, the readiness fails
+// during drain delay before the listener is closed. Once the in-flight
+// requests are done, the app is stopped so that queued trip events are saved
 func (a *apiDetails) GracefulStopServer() {
+	a.health.Drain()
+	if a.drainDelay > 0 {
+		a.logger.WithField("drain_delay", a.drainDelay.String()).Info("draining server")
+		time.Sleep(a.drainDelay)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
 	defer cancel()
 	if err := a.server.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		a.logger.WithError(err).Error("server forced to shutdown")
+	}
+
+	appCtx, appCancel := context.WithTimeout(context.Background(), a.appStopTimeout)
+	defer appCancel()
+	if err := a.app.Stop(appCtx); err != nil {
+		a.logger.WithError(err).Error("app forced to stop")
 	}
-	log.Println("Server exiting")
+	a.logger.Info("server exiting")
 }
@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type scooterPosition struct {
+	ScooterID string      `json:"scooter_id"`
+	Location  geoLocation `json:"location"`
+	// UpdatedAt is null if the time of the location is unknown
+	UpdatedAt  *time.Time `json:"updated_at"`
+	AgeSeconds *float64   `json:"age_seconds"`
+	InTrip     bool       `json:"in_trip"`
+	Stale      bool       `json:"stale"`
+}
+
+// transformToScooterPosition creates scooter position response from domain record
+func transformToScooterPosition(position *domain.ScooterPosition) scooterPosition {
+	resp := scooterPosition{
+		ScooterID: position.ScooterID,
+		Location: geoLocation{
+			Latitude:  position.Location.Latitude,
+			Longitude: position.Location.Longitude,
+		},
+		InTrip: position.InTrip,
+		Stale:  position.Stale,
+	}
+	if !position.UpdatedAt.IsZero() {
+		updatedAt := position.UpdatedAt
+		ageSeconds := position.Age.Seconds()
+		resp.UpdatedAt = &updatedAt
+		resp.AgeSeconds = &ageSeconds
+	}
+	return resp
+}
+
+// getScooterPosition godoc
+// @Summary returns last known position of the scooter
+// @Description returns last known location of the scooter updated by its trip location events, along with the time of the location and whether it is stale. The location is stale if its time is unknown or the scooter in trip has not sent its location for 5 minutes.
+// @Tags admin-api
+// @Accept  json
+// @Produce  json
+// @Param id path string true "scooter id"
+// @Param api_key query string true "admin api key"
+// @Success 200 {object} rest.scooterPosition
+// @Failure 404 {object} rest.problem
+// @Failure 400 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v1/auth/admin/scooters/{id}/position [get]
+func (api *apiDetails) getScooterPosition(c *gin.Context) {
+	position, err := api.app.GetScooterPosition(c, c.Param("id"))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, transformToScooterPosition(position))
+	c.Done()
+}
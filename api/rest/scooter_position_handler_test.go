@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+func (suite *HandlerTestSuite) Test_getScooterPosition() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:         appInstance,
+		apiKey:      "testkey",
+		adminApiKey: "testadminkey",
+	}
+	router := api.setupRouter()
+	scooterPositionApiPath := "/api/v1/auth/admin/scooters/scooterid/position"
+	updatedAt := time.Date(2022, 7, 9, 17, 49, 9, 0, time.UTC)
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+
+	type args struct {
+		url string
+	}
+	type want struct {
+		statusCode int
+		position   *scooterPosition
+	}
+	tests := []struct {
+		name    string
+		prepare func()
+		args    args
+		want    want
+	}{
+		{
+			name:    "should return error for invalid api key",
+			prepare: func() {},
+			args: args{
+				url: scooterPositionApiPath + "?api_key=invalid",
+			},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "should return error if scooter is not found",
+			prepare: func() {
+				appInstance.EXPECT().GetScooterPosition(gomock.Any(), "scooterid").Return(nil, app.ErrScooterNotFound).Times(1)
+			},
+			args: args{
+				url: scooterPositionApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "should return error if get scooter position returns error",
+			prepare: func() {
+				appInstance.EXPECT().GetScooterPosition(gomock.Any(), "scooterid").Return(nil, errors.New("internal error")).Times(1)
+			},
+			args: args{
+				url: scooterPositionApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "should return position with its age",
+			prepare: func() {
+				appInstance.EXPECT().GetScooterPosition(gomock.Any(), "scooterid").Return(&domain.ScooterPosition{
+					ScooterID: "scooterid",
+					Location:  location,
+					UpdatedAt: updatedAt,
+					Age:       90 * time.Second,
+					InTrip:    true,
+				}, nil).Times(1)
+			},
+			args: args{
+				url: scooterPositionApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				position: &scooterPosition{
+					ScooterID:  "scooterid",
+					Location:   geoLocation{Latitude: 52.52, Longitude: 13.405},
+					UpdatedAt:  &updatedAt,
+					AgeSeconds: func() *float64 { age := 90.0; return &age }(),
+					InTrip:     true,
+				},
+			},
+		},
+		{
+			name: "should return stale position without time",
+			prepare: func() {
+				appInstance.EXPECT().GetScooterPosition(gomock.Any(), "scooterid").Return(&domain.ScooterPosition{
+					ScooterID: "scooterid",
+					Location:  location,
+					Stale:     true,
+				}, nil).Times(1)
+			},
+			args: args{
+				url: scooterPositionApiPath + "?api_key=testadminkey",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				position: &scooterPosition{
+					ScooterID: "scooterid",
+					Location:  geoLocation{Latitude: 52.52, Longitude: 13.405},
+					Stale:     true,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.args.url, nil)
+			router.ServeHTTP(w, req)
+
+			if tt.want.statusCode != w.Code {
+				t.Errorf("getScooterPosition() status code  = %v, want status code %v", w.Code, tt.want.statusCode)
+				return
+			}
+			if tt.want.position == nil {
+				return
+			}
+
+			got := scooterPosition{}
+			err := json.Unmarshal(w.Body.Bytes(), &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := json.Marshal(tt.want.position)
+			gotJSON, _ := json.Marshal(got)
+			if string(gotJSON) != string(want) {
+				t.Errorf("getScooterPosition() = %s, want %s", gotJSON, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,756 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyHeader is the header of the api key of v2 requests
+	apiKeyHeader = "X-API-Key"
+
+	// defaultPageLimit is the number of items of the page if limit is not set
+	defaultPageLimit = 20
+	// maxPageLimit is the max number of items of the page
+	maxPageLimit = 100
+)
+
+// scooterEventIDNamespace is the namespace of the ids derived from the scooter
+// events which are sent without id
+var scooterEventIDNamespace = uuid.MustParse("6f1c3b2e-9a4d-4e8f-b5c7-2d0a1e3f4b6c")
+
+// tripStatus is the status of the trip resource
+type tripStatus string
+
+const (
+	tripStatusInProgress tripStatus = "in_progress"
+	tripStatusEnded      tripStatus = "ended"
+)
+
+// scooterMatch is the scooter found by the search, the distance(in meters) is
+// set if the search is near the location
+type scooterMatch struct {
+	scooter
+	Distance *float64 `json:"distance,omitempty"`
+}
+
+type scooterPage struct {
+	Items      []scooterMatch `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type trip struct {
+	ID            string       `json:"id"`
+	UserID        string       `json:"user_id"`
+	ScooterID     string       `json:"scooter_id"`
+	Status        tripStatus   `json:"status" swaggertype:"string" enums:"in_progress,ended"`
+	StartLocation geoLocation  `json:"start_location"`
+	EndLocation   *geoLocation `json:"end_location,omitempty"`
+	StartedAt     time.Time    `json:"started_at"`
+	EndedAt       *time.Time   `json:"ended_at,omitempty"`
+}
+
+type startTripRequest struct {
+	UserID    string `json:"user_id" validate:"required,uuid4"`
+	ScooterID string `json:"scooter_id" validate:"required,uuid4"`
+}
+
+type finishTripRequest struct {
+	Location geoLocation `json:"location" validate:"required"`
+}
+
+type tripEvent struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"`
+	ScooterID string      `json:"scooter_id"`
+	Type      string      `json:"type"`
+	Location  geoLocation `json:"location"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type tripEventPage struct {
+	Items      []tripEvent `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// createScooterEventRequest is the trip event sent by the scooter of the
+// path, the id is derived from the event if it is not set
+type createScooterEventRequest struct {
+	ID        string      `json:"id" validate:"omitempty,uuid"`
+	UserID    string      `json:"user_id" validate:"required,uuid4"`
+	Location  geoLocation `json:"location" validate:"required"`
+	CreatedAt time.Time   `json:"created_at" validate:"required"`
+	Type      string      `json:"type" validate:"required"`
+}
+
+// page is the requested page of the list, the cursor is the offset of the
+// first item of the page in the list
+type page struct {
+	offset int
+	limit  int
+}
+
+// setupRouterV2 registers the resource oriented v2 routes, the api key is
+// sent in the header instead of the query
+func (api *apiDetails) setupRouterV2(r *gin.Engine) {
+	v2group := r.Group("/api/v2")
+	v2group.Use(api.authenticateHeader)
+	v2group.GET("/scooters", api.listScooters)
+	v2group.GET("/scooters/clusters", api.listScooterClusters)
+	v2group.GET("/scooters/:id", api.getScooter)
+	v2group.POST("/scooters/:id/events", limitRequestBody(maxTripEventSize), api.createScooterEvent)
+	v2group.POST("/trips", api.createTrip)
+	v2group.GET("/trips/:id", api.getTrip)
+	v2group.POST("/trips/:id/end", api.finishTrip)
+	v2group.GET("/trips/:id/events", api.listTripEvents)
+	v2group.GET("/tiles/:z/:x/:y", api.getTile)
+}
+
+func (api *apiDetails) authenticateHeader(c *gin.Context) {
+	apiKey := c.GetHeader(apiKeyHeader)
+	if apiKey != api.getApiKey() {
+		createUnauthorizedResponse(c)
+		return
+	}
+	c.Next()
+}
+
+// listScooters godoc
+// @Summary searches available scooters
+// @Description searches available scooters near the location, within the bounding box e.g. map viewport or within the polygon. With near the scooters are sorted by nearest first and have the distance(in meters), with near and without radius, bbox or polygon the nearest scooters up to limit are returned. Without near the scooters are sorted by id. The scooters are paginated.
+// @Tags v2-scooters
+// @Produce  json
+// @Param near query string false "location as latitude,longitude e.g. 52.52,13.405"
+// @Param radius query number false "radius(in meters) from near"
+// @Param bbox query string false "bounding box as south west latitude,longitude,north east latitude,longitude e.g. 52.51,13.39,52.53,13.42"
+// @Param polygon query string false "polygon as latitude,longitude of each point e.g. 52.51,13.39,52.53,13.39,52.53,13.42"
+// @Param vehicle_type query string false "vehicle type e.g. scooter"
+// @Param min_battery query integer false "min battery level(in percent)" minimum(0) maximum(100)
+// @Param limit query integer false "max number of scooters of the page" default(20) maximum(100)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param If-None-Match header string false "etag of the cached response"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {object} rest.scooterPage
+// @Success 304
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/scooters [get]
+func (api *apiDetails) listScooters(c *gin.Context) {
+	query, err := parseScooterQuery(c)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	pg, err := parsePageQuery(c)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	// one more scooter than the page is requested to know if there is next
+	// page
+	query.Offset = pg.offset
+	query.Limit = pg.limit + 1
+	scooters, err := api.app.SearchAvailableScooters(c, *query)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	resp := scooterPage{
+		Items: []scooterMatch{},
+	}
+	if len(scooters) > pg.limit {
+		scooters = scooters[:pg.limit]
+		resp.NextCursor = encodeCursor(pg.offset + pg.limit)
+	}
+	for i := range scooters {
+		resp.Items = append(resp.Items, scooterMatch{
+			scooter:  newScooter(&scooters[i].Scooter),
+			Distance: scooters[i].Distance,
+		})
+	}
+
+	writeCacheableJSON(c, resp)
+}
+
+// getScooter godoc
+// @Summary returns the scooter
+// @Description returns the scooter for given id
+// @Tags v2-scooters
+// @Produce  json
+// @Param id path string true "scooter id"
+// @Param If-None-Match header string false "etag of the cached response"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {object} rest.scooter
+// @Success 304
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 404 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/scooters/{id} [get]
+func (api *apiDetails) getScooter(c *gin.Context) {
+	s, err := api.app.GetScooter(c, c.Param("id"))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	writeCacheableJSON(c, newScooter(s))
+}
+
+// createScooterEvent godoc
+// @Summary saves the trip event generated by the scooter
+// @Description saves the event generated by the scooter when trip is started, ended and during the trip. The id of the event is derived from the event if it is not set, so that the retried event gets the same id, saving already saved event is a no-op. Hence the identical events (same user, location, created_at and type) sent without id are saved once, the scooter which can send such events must set the id. The events which could not be saved are moved to dead letter events.
+// @Tags v2-scooters
+// @Accept  json
+// @Produce  json
+// @Param id path string true "scooter id"
+// @Param createScooterEventRequest body rest.createScooterEventRequest true "trip event"
+// @Param X-API-Key header string true "api key"
+// @Success 201 {object} rest.tripEvent
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 413 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Failure 503 {object} rest.problem
+// @Router /v2/scooters/{id}/events [post]
+func (api *apiDetails) createScooterEvent(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		createRequestBodyErrorResponse(c, err)
+		return
+	}
+
+	// the dead letter event payload is the v1 trip event, so that it can be
+	// replayed
+	payload = scooterEventPayload(c.Param("id"), payload)
+	event, err := parseTripEventPayload(payload)
+	if err != nil {
+		api.saveDeadLetterEvent(c, payload, err)
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	err = api.app.SaveScooterTripEvent(c, event)
+	if err != nil {
+		api.saveDeadLetterEvent(c, payload, err)
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, newTripEvent(event))
+}
+
+// createTrip godoc
+// @Summary starts the trip
+// @Description starts the trip for given user with given scooter, scooter becomes unavailable for other users once the trip starts
+// @Tags v2-trips
+// @Accept  json
+// @Produce  json
+// @Param startTripRequest body rest.startTripRequest true "start trip request"
+// @Param X-API-Key header string true "api key"
+// @Success 201 {object} rest.trip
+// @Header 201 {string} Location "url of the trip"
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 404 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/trips [post]
+func (api *apiDetails) createTrip(c *gin.Context) {
+	req := &startTripRequest{}
+	err := c.BindJSON(req)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	t, err := api.app.StartTrip(c, req.UserID, req.ScooterID)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+t.ID)
+	c.IndentedJSON(http.StatusCreated, newTrip(t))
+}
+
+// getTrip godoc
+// @Summary returns the trip
+// @Description returns the trip for given id
+// @Tags v2-trips
+// @Produce  json
+// @Param id path string true "trip id"
+// @Param If-None-Match header string false "etag of the cached response"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {object} rest.trip
+// @Success 304
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 404 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/trips/{id} [get]
+func (api *apiDetails) getTrip(c *gin.Context) {
+	t, err := api.app.GetTrip(c, c.Param("id"))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	writeCacheableJSON(c, newTrip(t))
+}
+
+// finishTrip godoc
+// @Summary ends the trip
+// @Description ends the trip for given id, scooter becomes available for other users at the location once the trip ends
+// @Tags v2-trips
+// @Accept  json
+// @Produce  json
+// @Param id path string true "trip id"
+// @Param finishTripRequest body rest.finishTripRequest true "end trip request"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {object} rest.trip
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 404 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/trips/{id}/end [post]
+func (api *apiDetails) finishTrip(c *gin.Context) {
+	req := &finishTripRequest{}
+	err := c.BindJSON(req)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	location := domain.GeoLocation{
+		Latitude:  req.Location.Latitude,
+		Longitude: req.Location.Longitude,
+	}
+	t, err := api.app.FinishTrip(c, c.Param("id"), location)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, newTrip(t))
+}
+
+// listTripEvents godoc
+// @Summary lists the events of the trip
+// @Description lists the events sent by the scooter during the trip in order of creation, the events are paginated
+// @Tags v2-trips
+// @Produce  json
+// @Param id path string true "trip id"
+// @Param type query string false "event type" Enums(trip_start,trip_stop,trip_location_update)
+// @Param since query string false "events created at or after the time(RFC 3339)"
+// @Param until query string false "events created before the time(RFC 3339)"
+// @Param limit query integer false "max number of events of the page" default(20) maximum(100)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param If-None-Match header string false "etag of the cached response"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {object} rest.tripEventPage
+// @Success 304
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 404 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/trips/{id}/events [get]
+func (api *apiDetails) listTripEvents(c *gin.Context) {
+	eventType := c.Query("type")
+	if eventType != "" && !domain.IsValidTripEventType(eventType) {
+		createValidationErrorResponse(c, &fieldError{
+			Field:   "type",
+			Rule:    "oneof",
+			Message: "invalid event type, valid values: trip_start,trip_stop and trip_location_update",
+		})
+		return
+	}
+
+	since, err := parseTimeQuery(c, "since")
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	until, err := parseTimeQuery(c, "until")
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	pg, err := parsePageQuery(c)
+	if err != nil {
+		createValidationErrorResponse(c, err)
+		return
+	}
+
+	events, err := api.app.GetTripEvents(c, c.Param("id"))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	filtered := []tripEvent{}
+	for i := range events {
+		e := &events[i]
+		if eventType != "" && string(e.Type) != eventType {
+			continue
+		}
+		if !since.IsZero() && e.CreatedAt.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !e.CreatedAt.Before(until) {
+			continue
+		}
+		filtered = append(filtered, newTripEvent(e))
+	}
+
+	start, end, nextCursor := pg.bounds(len(filtered))
+	resp := tripEventPage{
+		Items:      filtered[start:end],
+		NextCursor: nextCursor,
+	}
+
+	writeCacheableJSON(c, resp)
+}
+
+// writeCacheableJSON writes the response with the etag of the body, not
+// modified is written if the etag matches the If-None-Match of the request
+func writeCacheableJSON(c *gin.Context, resp interface{}) {
+	body, err := json.MarshalIndent(resp, "", "    ")
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	writeCacheable(c, gin.MIMEJSON+"; charset=utf-8", body)
+}
+
+// writeCacheable writes the body of the content type with the etag of the
+// body, not modified is written if the etag matches the If-None-Match
+func writeCacheable(c *gin.Context, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// etagMatches returns true if the If-None-Match header has the etag, the
+// weak etags are compared by their value
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePageQuery parses the limit and the cursor of the requested page
+func parsePageQuery(c *gin.Context) (page, error) {
+	pg := page{limit: defaultPageLimit}
+	if l := c.Query("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return pg, &fieldError{
+				Field:   "limit",
+				Rule:    "range",
+				Message: fmt.Sprintf("limit must be an integer between 1 and %v", maxPageLimit),
+			}
+		}
+		pg.limit = limit
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return pg, &fieldError{Field: "cursor", Rule: "cursor", Message: "cursor is invalid"}
+		}
+		pg.offset = offset
+	}
+	return pg, nil
+}
+
+// bounds returns the bounds of the page in the list of given size, the next
+// cursor is empty if it is the last page
+func (p page) bounds(size int) (int, int, string) {
+	start := p.offset
+	if start > size {
+		start = size
+	}
+	end := start + p.limit
+	if end >= size {
+		return start, size, ""
+	}
+	return start, end, encodeCursor(end)
+}
+
+// encodeCursor returns the opaque cursor of the offset, the clients must not
+// rely on its format
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of the cursor
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	offset, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %v", offset)
+	}
+	return offset, nil
+}
+
+// parseScooterQuery parses the search of the scooters, one of near, bbox and
+// polygon is required and bbox and polygon can not be used together
+func parseScooterQuery(c *gin.Context) (*domain.ScooterQuery, error) {
+	query := &domain.ScooterQuery{
+		VehicleType: c.Query("vehicle_type"),
+	}
+
+	if near := c.Query("near"); near != "" {
+		location, err := parseLocation(near)
+		if err != nil {
+			return nil, &fieldError{Field: "near", Rule: "location", Message: "near must be latitude,longitude"}
+		}
+		query.Near = &location
+	}
+
+	if rad := c.Query("radius"); rad != "" {
+		radius, err := strconv.ParseFloat(rad, 64)
+		if err != nil || radius <= 0 || query.Near == nil {
+			return nil, &fieldError{Field: "radius", Rule: "number", Message: "radius must be a positive number along with near"}
+		}
+		query.Radius = radius
+	}
+
+	if bbox := c.Query("bbox"); bbox != "" {
+		boundingBox, err := parseBoundingBox(bbox)
+		if err != nil {
+			return nil, &fieldError{Field: "bbox", Rule: "bbox", Message: "bbox must be south west latitude,longitude,north east latitude,longitude"}
+		}
+		query.BoundingBox = boundingBox
+	}
+
+	if polygon := c.Query("polygon"); polygon != "" {
+		points, err := parseLocations(polygon)
+		if err != nil || len(points) < 3 {
+			return nil, &fieldError{Field: "polygon", Rule: "polygon", Message: "polygon must be at least 3 latitude,longitude points"}
+		}
+		query.Polygon = points
+	}
+
+	switch {
+	case query.Near == nil && query.BoundingBox == nil && query.Polygon == nil:
+		return nil, &fieldError{Field: "near", Rule: "required_without_all", Message: "one of near, bbox and polygon is required"}
+	case query.BoundingBox != nil && query.Polygon != nil:
+		return nil, &fieldError{Field: "polygon", Rule: "excluded_with", Message: "polygon is not allowed along with bbox"}
+	}
+
+	if minBattery := c.Query("min_battery"); minBattery != "" {
+		level, err := strconv.Atoi(minBattery)
+		if err != nil || level < 0 || level > 100 {
+			return nil, &fieldError{Field: "min_battery", Rule: "range", Message: "min_battery must be an integer between 0 and 100"}
+		}
+		query.MinBatteryLevel = level
+	}
+	return query, nil
+}
+
+// parseBoundingBox parses the bounding box as south west latitude,longitude
+// and north east latitude,longitude e.g. 52.51,13.39,52.53,13.42
+func parseBoundingBox(value string) (*domain.BoundingBox, error) {
+	corners, err := parseLocations(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(corners) != 2 {
+		return nil, fmt.Errorf("bounding box %q does not have 2 corners", value)
+	}
+
+	return &domain.BoundingBox{
+		SouthWest: corners[0],
+		NorthEast: corners[1],
+	}, nil
+}
+
+// parseLocations parses the list of latitude,longitude pairs e.g.
+// 52.51,13.39,52.53,13.39,52.53,13.42
+func parseLocations(value string) ([]domain.GeoLocation, error) {
+	coordinates := strings.Split(value, ",")
+	if len(coordinates)%2 != 0 {
+		return nil, fmt.Errorf("locations %q have odd number of coordinates", value)
+	}
+
+	locations := make([]domain.GeoLocation, 0, len(coordinates)/2)
+	for i := 0; i < len(coordinates); i += 2 {
+		location, err := parseLocation(coordinates[i] + "," + coordinates[i+1])
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+// parseLocation parses the location as latitude,longitude e.g. 52.52,13.405
+func parseLocation(value string) (domain.GeoLocation, error) {
+	lat, lng, ok := strings.Cut(value, ",")
+	if !ok {
+		return domain.GeoLocation{}, fmt.Errorf("location %q is not latitude,longitude", value)
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return domain.GeoLocation{}, fmt.Errorf("invalid latitude %q", lat)
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return domain.GeoLocation{}, fmt.Errorf("invalid longitude %q", lng)
+	}
+
+	return domain.GeoLocation{
+		Latitude:  latitude,
+		Longitude: longitude,
+	}, nil
+}
+
+// parseTimeQuery parses the RFC 3339 time of the query, zero time if it is
+// not set
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &fieldError{Field: name, Rule: "datetime", Message: name + " must be RFC 3339 time"}
+	}
+	return t, nil
+}
+
+// scooterEventPayload returns the v1 trip event payload of the scooter event,
+// the scooter id is of the path and the id is derived from the payload if not
+// set, so that the retry of the event gets the same id. The payload which is
+// not a json object is returned as is.
+func scooterEventPayload(scooterID string, payload []byte) []byte {
+	fields := map[string]json.RawMessage{}
+	err := json.Unmarshal(payload, &fields)
+	if err != nil || fields == nil {
+		return payload
+	}
+
+	fields["scooter_id"], _ = json.Marshal(scooterID)
+	if id, ok := fields["id"]; !ok || string(id) == `""` || string(id) == "null" {
+		delete(fields, "id")
+		// the fields are marshalled compact in order of keys, hence the
+		// same event gets the same id regardless of its formatting
+		event, err := json.Marshal(fields)
+		if err != nil {
+			return payload
+		}
+		fields["id"], _ = json.Marshal(uuid.NewSHA1(scooterEventIDNamespace, event).String())
+	}
+
+	v1Payload, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return v1Payload
+}
+
+func newScooter(s *domain.Scooter) scooter {
+	return scooter{
+		ID:   s.ID,
+		Name: s.Name,
+		Location: geoLocation{
+			Latitude:  s.Location.Latitude,
+			Longitude: s.Location.Longitude,
+		},
+		CurrentUserID: s.CurrentUserID,
+		IsAvailable:   s.IsAvailable,
+		VehicleType:   s.VehicleType,
+		BatteryLevel:  s.BatteryLevel,
+	}
+}
+
+func newTrip(t *domain.Trip) trip {
+	resp := trip{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		ScooterID: t.ScooterID,
+		Status:    tripStatusInProgress,
+		StartLocation: geoLocation{
+			Latitude:  t.StartLocation.Latitude,
+			Longitude: t.StartLocation.Longitude,
+		},
+		StartedAt: t.StartedAt,
+		EndedAt:   t.EndedAt,
+	}
+	if t.IsEnded() {
+		resp.Status = tripStatusEnded
+	}
+	if t.EndLocation != nil {
+		resp.EndLocation = &geoLocation{
+			Latitude:  t.EndLocation.Latitude,
+			Longitude: t.EndLocation.Longitude,
+		}
+	}
+	return resp
+}
+
+func newTripEvent(e *domain.TripEvent) tripEvent {
+	return tripEvent{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		ScooterID: e.ScooterID,
+		Type:      string(e.Type),
+		Location: geoLocation{
+			Latitude:  e.Location.Latitude,
+			Longitude: e.Location.Longitude,
+		},
+		CreatedAt: e.CreatedAt,
+	}
+}
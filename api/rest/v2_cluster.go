@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type scooterCluster struct {
+	Center  geoLocation `json:"center"`
+	Count   int         `json:"count"`
+	Scooter *scooter    `json:"scooter,omitempty"`
+}
+
+type scooterClustersResponse struct {
+	Zoom     int              `json:"zoom"`
+	Clusters []scooterCluster `json:"clusters"`
+}
+
+// listScooterClusters godoc
+// @Summary returns the clusters of available scooters within the viewport
+// @Description returns the available scooters within the bounding box e.g. map viewport grouped into clusters for the zoom level of the map, the cluster has the number of scooters and their centroid. The cluster of one scooter has the scooter, above zoom level 16 each scooter is returned as its own cluster up to 500 scooters. The bounding box more than 8 map tiles of the zoom level across is rejected.
+// @Tags v2-scooters
+// @Produce  json
+// @Param bbox query string true "bounding box as south west latitude,longitude,north east latitude,longitude e.g. 52.51,13.39,52.53,13.42"
+// @Param zoom query integer true "zoom level of the map" minimum(0) maximum(22)
+// @Param If-None-Match header string false "etag of the cached response"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {object} rest.scooterClustersResponse
+// @Success 304
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/scooters/clusters [get]
+func (api *apiDetails) listScooterClusters(c *gin.Context) {
+	boundingBox, err := parseBoundingBox(c.Query("bbox"))
+	if err != nil {
+		createValidationErrorResponse(c, &fieldError{Field: "bbox", Rule: "bbox", Message: "bbox must be south west latitude,longitude,north east latitude,longitude"})
+		return
+	}
+
+	zoom, err := strconv.Atoi(c.Query("zoom"))
+	if err != nil {
+		createValidationErrorResponse(c, &fieldError{Field: "zoom", Rule: "integer", Message: "zoom must be an integer"})
+		return
+	}
+
+	clusters, err := api.app.GetScooterClusters(c, *boundingBox, zoom)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	resp := scooterClustersResponse{
+		Zoom:     zoom,
+		Clusters: make([]scooterCluster, 0, len(clusters)),
+	}
+	for _, cl := range clusters {
+		cluster := scooterCluster{
+			Center: geoLocation{
+				Latitude:  cl.Center.Latitude,
+				Longitude: cl.Center.Longitude,
+			},
+			Count: cl.Count,
+		}
+		if cl.Scooter != nil {
+			s := newScooter(cl.Scooter)
+			cluster.Scooter = &s
+		}
+		resp.Clusters = append(resp.Clusters, cluster)
+	}
+
+	writeCacheableJSON(c, resp)
+}
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+func (suite *HandlerTestSuite) Test_listScooterClusters() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	boundingBox := domain.BoundingBox{
+		SouthWest: domain.GeoLocation{Latitude: 52.51, Longitude: 13.39},
+		NorthEast: domain.GeoLocation{Latitude: 52.53, Longitude: 13.42},
+	}
+	s := &domain.Scooter{ID: "scooterid", IsAvailable: true}
+
+	tests := []struct {
+		name           string
+		prepare        func()
+		url            string
+		wantStatusCode int
+		wantCounts     []int
+	}{
+		{
+			name:           "should return error for missing bbox",
+			prepare:        func() {},
+			url:            "/api/v2/scooters/clusters?zoom=12",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid zoom",
+			prepare:        func() {},
+			url:            "/api/v2/scooters/clusters?bbox=52.51,13.39,52.53,13.42&zoom=far",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return error if app returns error",
+			prepare: func() {
+				appInstance.EXPECT().GetScooterClusters(gomock.Any(), boundingBox, 23).Return(nil, app.ErrInvalidArg).Times(1)
+			},
+			url:            "/api/v2/scooters/clusters?bbox=52.51,13.39,52.53,13.42&zoom=23",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return clusters",
+			prepare: func() {
+				clusters := []domain.ScooterCluster{
+					{Center: domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}, Count: 7},
+					{Center: s.Location, Count: 1, Scooter: s},
+				}
+				appInstance.EXPECT().GetScooterClusters(gomock.Any(), boundingBox, 12).Return(clusters, nil).Times(1)
+			},
+			url:            "/api/v2/scooters/clusters?bbox=52.51,13.39,52.53,13.42&zoom=12",
+			wantStatusCode: http.StatusOK,
+			wantCounts:     []int{7, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := serveV2(router, http.MethodGet, tt.url, nil, nil)
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("listScooterClusters() status code = %v, want status code %v", w.Code, tt.wantStatusCode)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			resp := scooterClustersResponse{}
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Clusters) != len(tt.wantCounts) || w.Header().Get("ETag") == "" {
+				t.Fatalf("listScooterClusters() = %+v, want %v clusters with etag", resp, len(tt.wantCounts))
+			}
+			for i, count := range tt.wantCounts {
+				if resp.Clusters[i].Count != count || (resp.Clusters[i].Scooter != nil) != (count == 1) {
+					t.Errorf("listScooterClusters() clusters[%d] = %+v, want count %v", i, resp.Clusters[i], count)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+)
+
+// serveV2 serves the v2 request with the api key header
+func serveV2(router *gin.Engine, method string, url string, body io.Reader, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, url, body)
+	req.Header.Set(apiKeyHeader, "testkey")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *HandlerTestSuite) Test_v2Authenticate() {
+	t := suite.T()
+	api := &apiDetails{
+		app:    suite.App,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+
+	// the api key in the query is not accepted by v2
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v2/trips/tripid?api_key=testkey", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("getTrip() status code = %v, want status code %v", w.Code, http.StatusUnauthorized)
+	}
+
+	w = serveV2(router, http.MethodGet, "/api/v2/trips/tripid", nil, map[string]string{apiKeyHeader: "invalid"})
+	if w.Code != http.StatusUnauthorized || w.Header().Get("Content-Type") != problemContentType {
+		t.Errorf("getTrip() status code = %v, content type = %v, want unauthorized problem", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func (suite *HandlerTestSuite) Test_listScooters() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	distance := 12.5
+	scooters := []domain.ScooterMatch{
+		{Scooter: domain.Scooter{ID: "s1", Name: "scooter 1", IsAvailable: true}, Distance: &distance},
+		{Scooter: domain.Scooter{ID: "s2", Name: "scooter 2", IsAvailable: true}, Distance: &distance},
+		{Scooter: domain.Scooter{ID: "s3", Name: "scooter 3", IsAvailable: true}, Distance: &distance},
+	}
+	near := &domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+
+	tests := []struct {
+		name           string
+		prepare        func()
+		url            string
+		wantStatusCode int
+		wantIDs        []string
+		wantNextCursor string
+	}{
+		{
+			name:           "should return error without near, bbox or polygon",
+			prepare:        func() {},
+			url:            "/api/v2/scooters",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid near",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?near=91,13.405",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for radius without near",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?bbox=52.51,13.39,52.53,13.42&radius=100",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid bbox",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?bbox=52.51,13.39,52.53",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for polygon with less than 3 points",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?polygon=52.51,13.39,52.53,13.39",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for bbox with polygon",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?bbox=52.51,13.39,52.53,13.42&polygon=52.51,13.39,52.53,13.39,52.53,13.42",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid min battery",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?near=52.52,13.405&min_battery=101",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid limit",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?near=52.52,13.405&limit=101",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid cursor",
+			prepare:        func() {},
+			url:            "/api/v2/scooters?near=52.52,13.405&cursor=invalid!",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return error if search returns error",
+			prepare: func() {
+				appInstance.EXPECT().SearchAvailableScooters(gomock.Any(), gomock.Any()).Return(nil, app.ErrInvalidArg).Times(1)
+			},
+			url:            "/api/v2/scooters?bbox=52.53,13.39,52.51,13.42",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return nearest scooters with next cursor",
+			prepare: func() {
+				query := domain.ScooterQuery{Near: near, Radius: 250.5, VehicleType: "scooter", MinBatteryLevel: 20, Limit: 3}
+				appInstance.EXPECT().SearchAvailableScooters(gomock.Any(), query).Return(scooters, nil).Times(1)
+			},
+			url:            "/api/v2/scooters?near=52.52,13.405&radius=250.5&vehicle_type=scooter&min_battery=20&limit=2",
+			wantStatusCode: http.StatusOK,
+			wantIDs:        []string{"s1", "s2"},
+			wantNextCursor: encodeCursor(2),
+		},
+		{
+			name: "should return last page of the cursor",
+			prepare: func() {
+				polygon := []domain.GeoLocation{{Latitude: 52.51, Longitude: 13.39}, {Latitude: 52.53, Longitude: 13.39}, {Latitude: 52.53, Longitude: 13.42}}
+				query := domain.ScooterQuery{Polygon: polygon, Offset: 2, Limit: 3}
+				appInstance.EXPECT().SearchAvailableScooters(gomock.Any(), query).Return(scooters[2:], nil).Times(1)
+			},
+			url:            "/api/v2/scooters?polygon=52.51,13.39,52.53,13.39,52.53,13.42&limit=2&cursor=" + encodeCursor(2),
+			wantStatusCode: http.StatusOK,
+			wantIDs:        []string{"s3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := serveV2(router, http.MethodGet, tt.url, nil, nil)
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("listScooters() status code = %v, want status code %v", w.Code, tt.wantStatusCode)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			resp := scooterPage{}
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Items) != len(tt.wantIDs) || resp.NextCursor != tt.wantNextCursor {
+				t.Fatalf("listScooters() = %+v, want ids %v and next cursor %v", resp, tt.wantIDs, tt.wantNextCursor)
+			}
+			for i, id := range tt.wantIDs {
+				if resp.Items[i].ID != id || resp.Items[i].Distance == nil || *resp.Items[i].Distance != distance {
+					t.Errorf("listScooters() items[%d] = %+v, want %v at distance %v", i, resp.Items[i], id, distance)
+				}
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) Test_getTripETag() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	startedAt := time.Date(2022, 7, 9, 18, 59, 21, 0, time.UTC)
+	tr := &domain.Trip{ID: "tripid", UserID: "userid", ScooterID: "scooterid", StartedAt: startedAt}
+	appInstance.EXPECT().GetTrip(gomock.Any(), "tripid").Return(tr, nil).Times(3)
+
+	w := serveV2(router, http.MethodGet, "/api/v2/trips/tripid", nil, nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("getTrip() status code = %v, etag = %v, want ok with etag", w.Code, etag)
+	}
+	resp := trip{}
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	if err != nil || resp.Status != tripStatusInProgress || resp.EndLocation != nil {
+		t.Errorf("getTrip() = %+v, %v, want trip in progress", resp, err)
+	}
+
+	w = serveV2(router, http.MethodGet, "/api/v2/trips/tripid", nil, map[string]string{"If-None-Match": `"other", ` + etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("getTrip() status code = %v, body = %v, want not modified", w.Code, w.Body.String())
+	}
+
+	w = serveV2(router, http.MethodGet, "/api/v2/trips/tripid", nil, map[string]string{"If-None-Match": `"other"`})
+	if w.Code != http.StatusOK {
+		t.Errorf("getTrip() status code = %v, want status code %v", w.Code, http.StatusOK)
+	}
+
+	appInstance.EXPECT().GetTrip(gomock.Any(), "missing").Return(nil, app.ErrTripNotFound).Times(1)
+	w = serveV2(router, http.MethodGet, "/api/v2/trips/missing", nil, nil)
+	p := problem{}
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusNotFound || p.Code != app.CodeTripNotFound || w.Header().Get("ETag") != "" {
+		t.Errorf("getTrip() status code = %v, code = %v, want not found without etag", w.Code, p.Code)
+	}
+}
+
+func (suite *HandlerTestSuite) Test_createAndFinishTrip() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	userID := "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	scooterID := "f691fd32-9b3f-4d71-b9b7-c48213bfd232"
+	tr := &domain.Trip{ID: "tripid", UserID: userID, ScooterID: scooterID, StartedAt: time.Now().UTC()}
+
+	w := serveV2(router, http.MethodPost, "/api/v2/trips", strings.NewReader(`{"user_id":"invalid"}`), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("createTrip() status code = %v, want status code %v", w.Code, http.StatusBadRequest)
+	}
+
+	appInstance.EXPECT().StartTrip(gomock.Any(), userID, scooterID).Return(tr, nil).Times(1)
+	w = serveV2(router, http.MethodPost, "/api/v2/trips", strings.NewReader(`{"user_id":"`+userID+`","scooter_id":"`+scooterID+`"}`), nil)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/v2/trips/tripid" {
+		t.Errorf("createTrip() status code = %v, location = %v, want created with trip location", w.Code, w.Header().Get("Location"))
+	}
+
+	appInstance.EXPECT().StartTrip(gomock.Any(), userID, scooterID).Return(nil, app.ErrScooterUnavailable).Times(1)
+	w = serveV2(router, http.MethodPost, "/api/v2/trips", strings.NewReader(`{"user_id":"`+userID+`","scooter_id":"`+scooterID+`"}`), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("createTrip() status code = %v, want status code %v", w.Code, http.StatusBadRequest)
+	}
+
+	w = serveV2(router, http.MethodPost, "/api/v2/trips/tripid/end", strings.NewReader(`{}`), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("finishTrip() status code = %v, want status code %v", w.Code, http.StatusBadRequest)
+	}
+
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	endedAt := time.Now().UTC()
+	endedTrip := *tr
+	endedTrip.EndedAt = &endedAt
+	endedTrip.EndLocation = &location
+	appInstance.EXPECT().FinishTrip(gomock.Any(), "tripid", location).Return(&endedTrip, nil).Times(1)
+	w = serveV2(router, http.MethodPost, "/api/v2/trips/tripid/end", strings.NewReader(`{"location":{"latitude":52.52,"longitude":13.405}}`), nil)
+	resp := trip{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Status != tripStatusEnded || resp.EndLocation == nil {
+		t.Errorf("finishTrip() status code = %v, trip = %+v, want ended trip", w.Code, resp)
+	}
+}
+
+func (suite *HandlerTestSuite) Test_listTripEvents() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	start := time.Date(2022, 7, 9, 18, 0, 0, 0, time.UTC)
+	events := []domain.TripEvent{
+		{ID: "e1", Type: domain.TripStartEvent, CreatedAt: start},
+		{ID: "e2", Type: domain.TripLocationUpdateEvent, CreatedAt: start.Add(time.Minute)},
+		{ID: "e3", Type: domain.TripLocationUpdateEvent, CreatedAt: start.Add(2 * time.Minute)},
+		{ID: "e4", Type: domain.TripStopEvent, CreatedAt: start.Add(3 * time.Minute)},
+	}
+
+	tests := []struct {
+		name           string
+		prepare        func()
+		url            string
+		wantStatusCode int
+		wantIDs        []string
+	}{
+		{
+			name:           "should return error for invalid type",
+			prepare:        func() {},
+			url:            "/api/v2/trips/tripid/events?type=invalid",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid since",
+			prepare:        func() {},
+			url:            "/api/v2/trips/tripid/events?since=yesterday",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return error if trip not found",
+			prepare: func() {
+				appInstance.EXPECT().GetTripEvents(gomock.Any(), "tripid").Return(nil, app.ErrTripNotFound).Times(1)
+			},
+			url:            "/api/v2/trips/tripid/events",
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "should return events filtered by type and time",
+			prepare: func() {
+				appInstance.EXPECT().GetTripEvents(gomock.Any(), "tripid").Return(events, nil).Times(1)
+			},
+			url:            "/api/v2/trips/tripid/events?type=trip_location_update&since=2022-07-09T18:01:00Z&until=2022-07-09T18:02:00Z",
+			wantStatusCode: http.StatusOK,
+			wantIDs:        []string{"e2"},
+		},
+		{
+			name: "should return all events without filters",
+			prepare: func() {
+				appInstance.EXPECT().GetTripEvents(gomock.Any(), "tripid").Return(events, nil).Times(1)
+			},
+			url:            "/api/v2/trips/tripid/events",
+			wantStatusCode: http.StatusOK,
+			wantIDs:        []string{"e1", "e2", "e3", "e4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := serveV2(router, http.MethodGet, tt.url, nil, nil)
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("listTripEvents() status code = %v, want status code %v", w.Code, tt.wantStatusCode)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			resp := tripEventPage{}
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Items) != len(tt.wantIDs) {
+				t.Fatalf("listTripEvents() = %+v, want ids %v", resp, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if resp.Items[i].ID != id {
+					t.Errorf("listTripEvents() items[%d].ID = %v, want %v", i, resp.Items[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) Test_createScooterEvent() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	scooterID := "f691fd32-9b3f-4d71-b9b7-c48213bfd232"
+	url := "/api/v2/scooters/" + scooterID + "/events"
+	body := `{"user_id":"f3b9842c-182a-418b-92fd-95d4f46414c5","location":{"latitude":52.52,"longitude":13.405},"created_at":"2022-07-09T18:59:21Z","type":"trip_start"}`
+
+	// the scooter id is of the path and the id is derived from the event, so
+	// that the retry of the event has the same id
+	eventIDs := []string{}
+	appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, e *domain.TripEvent) error {
+		if e.ScooterID != scooterID || e.ID == "" {
+			t.Errorf("SaveScooterTripEvent() event = %+v, want event of the scooter with id", e)
+		}
+		eventIDs = append(eventIDs, e.ID)
+		return nil
+	}).Times(3)
+	for _, b := range []string{body, strings.ReplaceAll(body, ",", ", "), strings.Replace(body, "trip_start", "trip_stop", 1)} {
+		w := serveV2(router, http.MethodPost, url, strings.NewReader(b), nil)
+		resp := tripEvent{}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusCreated || resp.ID == "" || resp.ScooterID != scooterID {
+			t.Errorf("createScooterEvent() status code = %v, event = %+v, want created event", w.Code, resp)
+		}
+	}
+	if len(eventIDs) != 3 || eventIDs[0] != eventIDs[1] || eventIDs[0] == eventIDs[2] {
+		t.Errorf("createScooterEvent() event ids = %v, want same id for same event", eventIDs)
+	}
+
+	// the identical events sent without id collapse into one event as they get
+	// the same id, while the events with their own ids are kept apart
+	eventIDs = []string{}
+	appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, e *domain.TripEvent) error {
+		eventIDs = append(eventIDs, e.ID)
+		return nil
+	}).Times(4)
+	withID := func(id string) string {
+		return strings.Replace(body, "{", `{"id":"`+id+`",`, 1)
+	}
+	for _, b := range []string{body, body, withID("4b1f7a52-2a1e-4c2b-9b4e-6a0c1f3f6d01"), withID("4b1f7a52-2a1e-4c2b-9b4e-6a0c1f3f6d02")} {
+		w := serveV2(router, http.MethodPost, url, strings.NewReader(b), nil)
+		if w.Code != http.StatusCreated {
+			t.Errorf("createScooterEvent() status code = %v, want status code %v", w.Code, http.StatusCreated)
+		}
+	}
+	if len(eventIDs) != 4 || eventIDs[0] != eventIDs[1] || eventIDs[2] == eventIDs[3] {
+		t.Errorf("createScooterEvent() event ids = %v, want same id only for identical events without id", eventIDs)
+	}
+
+	// the event which could not be saved is moved to dead letter events as
+	// v1 trip event
+	gomock.InOrder(
+		appInstance.EXPECT().SaveScooterTripEvent(gomock.Any(), gomock.Any()).Return(errors.New("internal error")).Times(1),
+		appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), gomock.Any(), "internal error").DoAndReturn(func(_ interface{}, payload string, _ string) error {
+			if _, err := parseTripEventPayload([]byte(payload)); err != nil {
+				t.Errorf("SaveDeadLetterEvent() payload = %v, want v1 trip event: %v", payload, err)
+			}
+			return nil
+		}).Times(1),
+	)
+	w := serveV2(router, http.MethodPost, url, strings.NewReader(body), nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("createScooterEvent() status code = %v, want status code %v", w.Code, http.StatusInternalServerError)
+	}
+
+	appInstance.EXPECT().SaveDeadLetterEvent(gomock.Any(), "not json", gomock.Any()).Return(nil).Times(1)
+	w = serveV2(router, http.MethodPost, url, strings.NewReader("not json"), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("createScooterEvent() status code = %v, want status code %v", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mvt"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	scootersLayer = "scooters"
+	tripHeatLayer = "trip_heat"
+
+	tileExtension = ".mvt"
+	// tileCacheControl lets the clients reuse the tile for short time, the
+	// scooters move and the tile is revalidated with its etag afterwards
+	tileCacheControl = "private, max-age=15"
+)
+
+// getTile godoc
+// @Summary returns the map tile of available scooters
+// @Description returns Mapbox Vector Tile of the Web Mercator tile grid. The scooters layer has the available scooters, the scooters within the same pixel of the rendered tile are merged into one point with their count. The trip_heat layer has the number of trip events of the last 24 hours in cells of 4 pixels. The service has no geofence zones, hence there is no zones layer. The layers without features are left out of the tile.
+// @Tags v2-tiles
+// @Produce  application/vnd.mapbox-vector-tile
+// @Param z path integer true "zoom level" minimum(0) maximum(22)
+// @Param x path integer true "tile column"
+// @Param y path string true "tile row with .mvt extension e.g. 1343.mvt"
+// @Param layers query string false "comma separated layers, scooters and trip_heat" default(scooters)
+// @Param If-None-Match header string false "etag of the cached response"
+// @Param X-API-Key header string true "api key"
+// @Success 200 {file} binary
+// @Success 304
+// @Failure 400 {object} rest.problem
+// @Failure 401 {object} rest.problem
+// @Failure 500 {object} rest.problem
+// @Router /v2/tiles/{z}/{x}/{y}.mvt [get]
+func (api *apiDetails) getTile(c *gin.Context) {
+	tile, fieldErr := parseTile(c)
+	if fieldErr != nil {
+		createValidationErrorResponse(c, fieldErr)
+		return
+	}
+
+	withTripHeat := false
+	for _, layer := range strings.Split(c.DefaultQuery("layers", scootersLayer), ",") {
+		switch layer {
+		case scootersLayer:
+		case tripHeatLayer:
+			withTripHeat = true
+		default:
+			createValidationErrorResponse(c, &fieldError{Field: "layers", Rule: "oneof", Message: "layers must be scooters or trip_heat"})
+			return
+		}
+	}
+
+	mapTile, err := api.app.GetMapTile(c, tile, withTripHeat)
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	body, err := mvt.Encode(newTileLayers(mapTile))
+	if err != nil {
+		createErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", tileCacheControl)
+	writeCacheable(c, mvt.ContentType, body)
+}
+
+// parseTile parses the tile of the path, the row has the .mvt extension
+func parseTile(c *gin.Context) (domain.Tile, *fieldError) {
+	tile := domain.Tile{}
+	coordinates := []struct {
+		field string
+		value string
+		dest  *int
+	}{
+		{field: "z", value: c.Param("z"), dest: &tile.Z},
+		{field: "x", value: c.Param("x"), dest: &tile.X},
+		{field: "y", value: strings.TrimSuffix(c.Param("y"), tileExtension), dest: &tile.Y},
+	}
+
+	if !strings.HasSuffix(c.Param("y"), tileExtension) {
+		return tile, &fieldError{Field: "y", Rule: "extension", Message: "tile must have .mvt extension"}
+	}
+
+	for _, coordinate := range coordinates {
+		v, err := strconv.Atoi(coordinate.value)
+		if err != nil {
+			return tile, &fieldError{Field: coordinate.field, Rule: "integer", Message: coordinate.field + " must be an integer"}
+		}
+		*coordinate.dest = v
+	}
+	return tile, nil
+}
+
+// newTileLayers returns the layers of the map tile in the tile coordinates
+func newTileLayers(mapTile *domain.MapTile) []mvt.Layer {
+	scooters := mvt.Layer{
+		Name:     scootersLayer,
+		Features: make([]mvt.Feature, 0, len(mapTile.Scooters)),
+	}
+	for _, cluster := range mapTile.Scooters {
+		properties := []mvt.Property{{Key: "count", Value: cluster.Count}}
+		if s := cluster.Scooter; s != nil {
+			properties = append(properties,
+				mvt.Property{Key: "id", Value: s.ID},
+				mvt.Property{Key: "name", Value: s.Name},
+			)
+			if s.VehicleType != "" {
+				properties = append(properties, mvt.Property{Key: "vehicle_type", Value: s.VehicleType})
+			}
+			if s.BatteryLevel != 0 {
+				properties = append(properties, mvt.Property{Key: "battery_level", Value: s.BatteryLevel})
+			}
+		}
+		scooters.Features = append(scooters.Features, mvt.Feature{
+			Point:      mvt.Project(cluster.Center, mapTile.Tile),
+			Properties: properties,
+		})
+	}
+
+	tripHeat := mvt.Layer{
+		Name:     tripHeatLayer,
+		Features: make([]mvt.Feature, 0, len(mapTile.TripHeat)),
+	}
+	for _, cell := range mapTile.TripHeat {
+		tripHeat.Features = append(tripHeat.Features, mvt.Feature{
+			Point:      mvt.Project(cell.Center, mapTile.Tile),
+			Properties: []mvt.Property{{Key: "count", Value: cell.Count}},
+		})
+	}
+
+	return []mvt.Layer{scooters, tripHeat}
+}
@@ -0,0 +1,143 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mvt"
+	"github.com/golang/mock/gomock"
+)
+
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func (suite *HandlerTestSuite) Test_getTile() {
+	t := suite.T()
+	appInstance := suite.App
+	api := &apiDetails{
+		app:    appInstance,
+		apiKey: "testkey",
+	}
+	router := api.setupRouter()
+	tile := domain.Tile{Z: 12, X: 2200, Y: 1343}
+	s := &domain.Scooter{ID: "scooterid", Name: "Scooter", Location: domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}, IsAvailable: true, BatteryLevel: 80}
+	mapTile := &domain.MapTile{
+		Tile: tile,
+		Scooters: []domain.ScooterCluster{
+			{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 7},
+			{Center: s.Location, Count: 1, Scooter: s},
+		},
+		TripHeat: []domain.HeatCell{{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 42}},
+	}
+	wantBody, err := mvt.Encode(newTileLayers(mapTile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		prepare        func()
+		url            string
+		header         map[string]string
+		wantStatusCode int
+	}{
+		{
+			name:           "should return error for tile without extension",
+			prepare:        func() {},
+			url:            "/api/v2/tiles/12/2200/1343",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for invalid tile",
+			prepare:        func() {},
+			url:            "/api/v2/tiles/12/west/1343.mvt",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "should return error for unknown layer",
+			prepare:        func() {},
+			url:            "/api/v2/tiles/12/2200/1343.mvt?layers=scooters,zones",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return error if app returns error",
+			prepare: func() {
+				appInstance.EXPECT().GetMapTile(gomock.Any(), domain.Tile{Z: 12, X: 5000, Y: 1343}, false).Return(nil, app.ErrInvalidArg).Times(1)
+			},
+			url:            "/api/v2/tiles/12/5000/1343.mvt",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "should return tile with layers",
+			prepare: func() {
+				appInstance.EXPECT().GetMapTile(gomock.Any(), tile, true).Return(mapTile, nil).Times(1)
+			},
+			url:            "/api/v2/tiles/12/2200/1343.mvt?layers=scooters,trip_heat",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "should return not modified for cached tile",
+			prepare: func() {
+				appInstance.EXPECT().GetMapTile(gomock.Any(), tile, true).Return(mapTile, nil).Times(1)
+			},
+			url:            "/api/v2/tiles/12/2200/1343.mvt?layers=trip_heat",
+			header:         map[string]string{"If-None-Match": etagOf(wantBody)},
+			wantStatusCode: http.StatusNotModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			w := serveV2(router, http.MethodGet, tt.url, nil, tt.header)
+			if w.Code != tt.wantStatusCode {
+				t.Fatalf("getTile() status code = %v, want status code %v", w.Code, tt.wantStatusCode)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != mvt.ContentType {
+				t.Errorf("getTile() content type = %v, want %v", got, mvt.ContentType)
+			}
+			if w.Header().Get("ETag") != etagOf(wantBody) || w.Header().Get("Cache-Control") != tileCacheControl {
+				t.Errorf("getTile() headers = %v, want etag and cache control", w.Header())
+			}
+			if !bytes.Equal(w.Body.Bytes(), wantBody) {
+				t.Errorf("getTile() body = %x, want %x", w.Body.Bytes(), wantBody)
+			}
+		})
+	}
+}
+
+func Test_newTileLayers(t *testing.T) {
+	tile := domain.Tile{Z: 0, X: 0, Y: 0}
+	s := &domain.Scooter{ID: "scooterid", Name: "Scooter", VehicleType: "kick", BatteryLevel: 80}
+	layers := newTileLayers(&domain.MapTile{
+		Tile: tile,
+		Scooters: []domain.ScooterCluster{
+			{Center: domain.GeoLocation{Latitude: 0, Longitude: 0}, Count: 1, Scooter: s},
+			{Center: domain.GeoLocation{Latitude: 0, Longitude: 90}, Count: 2},
+		},
+	})
+
+	if len(layers) != 2 || layers[0].Name != scootersLayer || layers[1].Name != tripHeatLayer || len(layers[1].Features) != 0 {
+		t.Fatalf("newTileLayers() = %+v, want scooters and empty trip heat layers", layers)
+	}
+	scooters := layers[0].Features
+	if len(scooters) != 2 {
+		t.Fatalf("newTileLayers() scooters = %+v, want 2 features", scooters)
+	}
+	if scooters[0].Point != (mvt.Point{X: 2048, Y: 2048}) || len(scooters[0].Properties) != 5 {
+		t.Errorf("newTileLayers() scooters[0] = %+v, want scooter at center with its properties", scooters[0])
+	}
+	if scooters[1].Point != (mvt.Point{X: 3072, Y: 2048}) || len(scooters[1].Properties) != 1 || scooters[1].Properties[0].Value != 2 {
+		t.Errorf("newTileLayers() scooters[1] = %+v, want cluster with count", scooters[1])
+	}
+}
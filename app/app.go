@@ -7,37 +7,140 @@ import (
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
-)
-
-var (
-	ErrInvalidArg          = errors.New("invalid argument")
-	ErrEmptyArg            = errors.New("empty argument")
-	ErrRecordNotFound      = errors.New("record not found")
-	ErrOperationNotAllowed = errors.New("operation not allowed")
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/sirupsen/logrus"
 )
 
 //go:generate mockgen -destination=../mocks/mock_app.go -package=mocks github.com/ganeshdipdumbare/scootin-aboot-journey/app App
 // App interface which consists of business logic/use cases
 type App interface {
 	GetNearbyAvailableScooters(ctx context.Context, location domain.GeoLocation, radius int) ([]domain.Scooter, error)
+	SearchAvailableScooters(ctx context.Context, query domain.ScooterQuery) ([]domain.ScooterMatch, error)
+	GetScooterClusters(ctx context.Context, boundingBox domain.BoundingBox, zoom int) ([]domain.ScooterCluster, error)
+	GetMapTile(ctx context.Context, tile domain.Tile, withTripHeat bool) (*domain.MapTile, error)
 	BeginTrip(ctx context.Context, userID string, scooterID string) error
 	EndTrip(ctx context.Context, userID string, scooterID string, location domain.GeoLocation) error
 	SaveScooterTripEvent(ctx context.Context, event *domain.TripEvent) error
+	SaveScooterTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error)
+
+	// trip use cases, the trip started with BeginTrip is ended by FinishTrip
+	// and the other way around
+	GetScooter(ctx context.Context, scooterID string) (*domain.Scooter, error)
+	// GetScooterPosition returns the last known location of the scooter
+	// updated by the trip events and how fresh it is
+	GetScooterPosition(ctx context.Context, scooterID string) (*domain.ScooterPosition, error)
+	StartTrip(ctx context.Context, userID string, scooterID string) (*domain.Trip, error)
+	GetTrip(ctx context.Context, tripID string) (*domain.Trip, error)
+	FinishTrip(ctx context.Context, tripID string, location domain.GeoLocation) (*domain.Trip, error)
+	GetTripEvents(ctx context.Context, tripID string) ([]domain.TripEvent, error)
+
+	// dead letter use cases
+	SaveDeadLetterEvent(ctx context.Context, payload string, reason string) error
+	GetDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error)
+	GetDeadLetterEvent(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error)
+	UpdateDeadLetterEventPayload(ctx context.Context, eventID string, payload string) (*domain.DeadLetterEvent, error)
+	ReplayDeadLetterEvent(ctx context.Context, eventID string, event *domain.TripEvent) error
+
+	// Ready returns error if the background workers of the app can not
+	// accept work e.g. trip event queue is full
+	Ready(ctx context.Context) error
+	// Stop stops the background workers of the app, queued trip events are
+	// saved before it returns
+	Stop(ctx context.Context) error
 }
 
 type appDetails struct {
-	database db.DB
+	database       db.DB
+	writeBehind    WriteBehindConfig
+	tripEventQueue *tripEventQueue
+	logger         logrus.FieldLogger
+}
+
+// Option configures optional behaviour of the app
+type Option func(a *appDetails) error
+
+// WithWriteBehind enables asynchronous saving of trip events, the accepted
+// events are put on bounded queue and saved in batches by pool of workers
+func WithWriteBehind(config WriteBehindConfig) Option {
+	return func(a *appDetails) error {
+		err := config.validate()
+		if err != nil {
+			return fmt.Errorf("write behind config: %w", err)
+		}
+		a.writeBehind = config
+		return nil
+	}
+}
+
+// WithLogger sets the logger used by background workers of the app
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(a *appDetails) error {
+		if l == nil {
+			return fmt.Errorf("logger: %w", ErrInvalidArg)
+		}
+		a.logger = l
+		return nil
+	}
+}
+
+// TripEventReplayer replays the trip events which were saved while the
+// database was unavailable e.g. write ahead log
+type TripEventReplayer interface {
+	OnReplay(handler func(ctx context.Context, events []domain.TripEvent))
+}
+
+// WithTripEventReplayer updates the scooter locations with the trip events
+// replayed by the replayer the same way as with the saved events
+func WithTripEventReplayer(r TripEventReplayer) Option {
+	return func(a *appDetails) error {
+		if r == nil {
+			return fmt.Errorf("replayer: %w", ErrInvalidArg)
+		}
+		r.OnReplay(a.updateScooterLocations)
+		return nil
+	}
 }
 
 // NewApp creates new app instance
-func NewApp(database db.DB) (App, error) {
+func NewApp(database db.DB, opts ...Option) (App, error) {
 	if database == nil {
 		return nil, fmt.Errorf("database: %w", ErrInvalidArg)
 	}
 
-	return &appDetails{
+	a := &appDetails{
 		database: database,
-	}, nil
+		logger:   logger.Discard(),
+	}
+	for _, opt := range opts {
+		err := opt(a)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.writeBehind.Workers > 0 {
+		a.tripEventQueue = newTripEventQueue(a.writeBehind, a.flushTripEvents)
+	}
+
+	return a, nil
+}
+
+// Ready returns error if the background workers of the app can not
+// accept work e.g. trip event queue is full
+func (a *appDetails) Ready(ctx context.Context) error {
+	if a.tripEventQueue == nil {
+		return nil
+	}
+	return a.tripEventQueue.ready()
+}
+
+// Stop stops the background workers of the app, queued trip events are
+// saved before it returns
+func (a *appDetails) Stop(ctx context.Context) error {
+	if a.tripEventQueue == nil {
+		return nil
+	}
+	return a.tripEventQueue.stop(ctx)
 }
 
 // GetNearbyAvailableScooters returns nearby scooters within radius(meters) from
@@ -69,37 +172,8 @@ func (a *appDetails) GetNearbyAvailableScooters(ctx context.Context, location do
 // scooter record is updated with current user and set to unavailable
 // returns error if scooter is not available
 func (a *appDetails) BeginTrip(ctx context.Context, userID string, scooterID string) error {
-	if userID == "" {
-		return fmt.Errorf("userID: %w", ErrEmptyArg)
-	}
-
-	if scooterID == "" {
-		return fmt.Errorf("scooterID: %w", ErrEmptyArg)
-	}
-
-	scooter, err := a.database.GetScooterByID(ctx, scooterID)
-	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			return fmt.Errorf("scooter not found: %w", ErrRecordNotFound)
-		}
-		return fmt.Errorf("unable to get scooter: %w", err)
-	}
-
-	if !scooter.IsAvailable {
-		return fmt.Errorf("scooter is unavailable: %w", ErrOperationNotAllowed)
-	}
-
-	updatedScooter := *scooter
-	currentUserID := userID
-	updatedScooter.CurrentUserID = &currentUserID
-	updatedScooter.IsAvailable = false
-
-	_, err = a.database.UpdateScooter(ctx, &updatedScooter)
-	if err != nil {
-		return fmt.Errorf("unable to update scooter: %w", err)
-	}
-
-	return nil
+	_, err := a.StartTrip(ctx, userID, scooterID)
+	return err
 }
 
 // EndTrip ends the trip for given user with given scooter
@@ -115,40 +189,70 @@ func (a *appDetails) EndTrip(ctx context.Context, userID string, scooterID strin
 		return fmt.Errorf("scooterID: %w", ErrEmptyArg)
 	}
 
-	scooter, err := a.database.GetScooterByID(ctx, scooterID)
+	scooter, err := a.getScooterInUse(ctx, userID, scooterID)
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			return fmt.Errorf("scooter not found: %w", ErrRecordNotFound)
-		}
-		return fmt.Errorf("unable to get scooter: %w", err)
+		return err
 	}
 
-	if scooter.IsAvailable {
-		return fmt.Errorf("scooter is available: %w", ErrOperationNotAllowed)
+	// the trip begun before the trips were saved has no record
+	trip, err := a.database.GetActiveTripByScooterID(ctx, scooterID)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return fmt.Errorf("unable to get trip: %w", err)
 	}
 
-	if scooter.CurrentUserID == nil || *scooter.CurrentUserID != userID {
-		return fmt.Errorf("scooter is used by other user: %w", ErrOperationNotAllowed)
+	_, err = a.endTrip(ctx, scooter, trip, location)
+	return err
+}
+
+// SaveScooterTripEvent saves event generated by scooter during trip in trip events.
+// With write behind enabled, the event is queued to be saved asynchronously and
+// error is returned if the queue is full
+func (a *appDetails) SaveScooterTripEvent(ctx context.Context, event *domain.TripEvent) error {
+	if a.tripEventQueue != nil {
+		if event == nil {
+			return fmt.Errorf("event: %w", ErrInvalidArg)
+		}
+		return a.tripEventQueue.enqueue(*event)
 	}
 
-	updatedScooter := *scooter
-	currentUserID := ""
-	updatedScooter.CurrentUserID = &currentUserID
-	updatedScooter.IsAvailable = true
-	updatedScooter.Location = location
-	_, err = a.database.UpdateScooter(ctx, &updatedScooter)
+	err := a.database.InsertTripEvent(ctx, event)
 	if err != nil {
-		return fmt.Errorf("unable to update scooter: %w", err)
+		if errors.Is(err, db.ErrInvalidArg) {
+			return fmt.Errorf("insert trip event failed: %w", ErrInvalidArg)
+		}
+		return err
 	}
 
+	a.updateScooterLocations(ctx, []domain.TripEvent{*event})
 	return nil
 }
 
-// SaveScooterTripEvent saves event generated by scooter during trip in trip events
-func (a *appDetails) SaveScooterTripEvent(ctx context.Context, event *domain.TripEvent) error {
-	err := a.database.InsertTripEvent(ctx, event)
-	if err != nil && errors.Is(err, db.ErrInvalidArg) {
-		return fmt.Errorf("insert trip event failed: %w", ErrInvalidArg)
+// SaveScooterTripEvents saves the batch of events generated by scooter e.g.
+// offline backlog of the scooter. It returns the error of each event at the
+// same index as in the input, saving already saved event is a no-op.
+// The error is returned if whole batch could not be saved. With write behind
+// enabled, the events are queued to be saved asynchronously
+func (a *appDetails) SaveScooterTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("events: %w", ErrEmptyArg)
 	}
-	return err
+
+	if a.tripEventQueue != nil {
+		results := make([]error, len(events))
+		for i := range events {
+			results[i] = a.tripEventQueue.enqueue(events[i])
+		}
+		return results, nil
+	}
+
+	results, err := a.database.InsertTripEvents(ctx, events)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidArg) {
+			return nil, fmt.Errorf("insert trip events failed: %w", ErrInvalidArg)
+		}
+		return nil, fmt.Errorf("insert trip events failed: %w", err)
+	}
+
+	a.updateScooterLocations(ctx, savedTripEvents(events, results))
+	return results, nil
 }
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
@@ -54,6 +56,7 @@ func (suite *AppTestSuite) TestNewApp() {
 			},
 			want: &appDetails{
 				database: suite.Database,
+				logger:   logger.Discard(),
 			},
 			wantErr: false,
 		},
@@ -330,7 +333,30 @@ func (suite *AppTestSuite) TestBeginTrip() {
 				}
 				gomock.InOrder(
 					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
-					database.EXPECT().UpdateScooter(ctx, gomock.Any()).Return(nil, errors.New("internal error")).Times(1),
+					database.EXPECT().ReserveScooter(ctx, "scooterid", "userid").Return(nil, errors.New("internal error")).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error if scooter is taken before reservation",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:       ctx,
+				userID:    "userid",
+				scooterID: "scooterid",
+			},
+			prepare: func() {
+				currentScooter := &domain.Scooter{
+					ID:          "scooterid",
+					Name:        "scooter 1",
+					IsAvailable: true,
+				}
+				gomock.InOrder(
+					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
+					database.EXPECT().ReserveScooter(ctx, "scooterid", "userid").Return(nil, db.ErrRecordNotFound).Times(1),
 				)
 			},
 			wantErr: true,
@@ -361,16 +387,44 @@ func (suite *AppTestSuite) TestBeginTrip() {
 
 				gomock.InOrder(
 					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
-					database.EXPECT().UpdateScooter(ctx, &updatedScooter).Return(&updatedScooter, nil).Times(1),
+					database.EXPECT().ReserveScooter(ctx, "scooterid", "userid").Return(&updatedScooter, nil).Times(1),
+					database.EXPECT().InsertTrip(ctx, gomock.Any()).Return(nil).Times(1),
 				)
 			},
 			wantErr: false,
 		},
+		{
+			name: "should release scooter if insert trip failed",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:       ctx,
+				userID:    "userid",
+				scooterID: "scooterid",
+			},
+			prepare: func() {
+				currentScooter := &domain.Scooter{
+					ID:          "scooterid",
+					Name:        "scooter 1",
+					IsAvailable: true,
+				}
+
+				gomock.InOrder(
+					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
+					database.EXPECT().ReserveScooter(ctx, "scooterid", "userid").Return(currentScooter, nil).Times(1),
+					database.EXPECT().InsertTrip(ctx, gomock.Any()).Return(errors.New("internal error")).Times(1),
+					database.EXPECT().UpdateScooter(ctx, currentScooter).Return(currentScooter, nil).Times(1),
+				)
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := &appDetails{
 				database: tt.fields.database,
+				logger:   logger.Discard(),
 			}
 			tt.prepare()
 			if err := a.BeginTrip(tt.args.ctx, tt.args.userID, tt.args.scooterID); (err != nil) != tt.wantErr {
@@ -505,11 +559,40 @@ func (suite *AppTestSuite) TestEndTrip() {
 				}
 				gomock.InOrder(
 					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
+					database.EXPECT().GetActiveTripByScooterID(ctx, "scooterid").Return(nil, db.ErrRecordNotFound).Times(1),
 					database.EXPECT().UpdateScooter(ctx, gomock.Any()).Return(nil, errors.New("internal error")).Times(1),
 				)
 			},
 			wantErr: true,
 		},
+		{
+			name: "should return error if update trip failed",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:       ctx,
+				userID:    "userid",
+				scooterID: "scooterid",
+				location:  domain.GeoLocation{},
+			},
+			prepare: func() {
+				userID := "userid"
+				currentScooter := &domain.Scooter{
+					ID:            "scooterid",
+					Name:          "scooter 1",
+					CurrentUserID: &userID,
+					IsAvailable:   false,
+				}
+				trip := &domain.Trip{ID: "tripid", UserID: userID, ScooterID: "scooterid"}
+				gomock.InOrder(
+					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
+					database.EXPECT().GetActiveTripByScooterID(ctx, "scooterid").Return(trip, nil).Times(1),
+					database.EXPECT().UpdateTrip(ctx, gomock.Any()).Return(nil, errors.New("internal error")).Times(1),
+				)
+			},
+			wantErr: true,
+		},
 		{
 			name: "should return error scooter is already available",
 			fields: fields{
@@ -561,9 +644,18 @@ func (suite *AppTestSuite) TestEndTrip() {
 				updatedScooter.CurrentUserID = &emptyUserID
 				updatedScooter.IsAvailable = true
 
+				trip := &domain.Trip{ID: "tripid", UserID: userID, ScooterID: "scooterid"}
+
 				gomock.InOrder(
 					database.EXPECT().GetScooterByID(ctx, gomock.Any()).Return(currentScooter, nil).Times(1),
-					database.EXPECT().UpdateScooter(ctx, &updatedScooter).Return(&updatedScooter, nil).Times(1),
+					database.EXPECT().GetActiveTripByScooterID(ctx, "scooterid").Return(trip, nil).Times(1),
+					database.EXPECT().UpdateTrip(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, tr *domain.Trip) (*domain.Trip, error) {
+						if !tr.IsEnded() || tr.EndLocation == nil {
+							t.Errorf("UpdateTrip() trip = %+v, want ended trip", tr)
+						}
+						return tr, nil
+					}).Times(1),
+					database.EXPECT().UpdateScooter(ctx, releasedScooterMatcher{scooter: updatedScooter}).Return(&updatedScooter, nil).Times(1),
 				)
 			},
 			wantErr: false,
@@ -586,6 +678,14 @@ func (suite *AppTestSuite) TestSaveScooterTripEvent() {
 	t := suite.T()
 	database := suite.Database
 	ctx := context.Background()
+	locationEvent := domain.TripEvent{
+		ID:        "eventid",
+		UserID:    "userid",
+		ScooterID: "scooterid",
+		Location:  domain.GeoLocation{Latitude: 52.52, Longitude: 13.405},
+		Type:      domain.TripLocationUpdateEvent,
+		CreatedAt: time.Now().UTC(),
+	}
 
 	type fields struct {
 		database db.DB
@@ -629,12 +729,30 @@ func (suite *AppTestSuite) TestSaveScooterTripEvent() {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should update scooter location if location update event is saved",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:   ctx,
+				event: &locationEvent,
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().InsertTripEvent(ctx, &locationEvent).Return(nil).Times(1),
+					database.EXPECT().UpdateScooterLocation(ctx, "scooterid", "userid", locationEvent.Location, locationEvent.CreatedAt).Return(&domain.Scooter{ID: "scooterid"}, nil).Times(1),
+				)
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.prepare()
 			a := &appDetails{
 				database: tt.fields.database,
+				logger:   logger.Discard(),
 			}
 			if err := a.SaveScooterTripEvent(tt.args.ctx, tt.args.event); (err != nil) != tt.wantErr {
 				t.Errorf("SaveScooterTripEvent() error = %v, wantErr %v", err, tt.wantErr)
@@ -642,3 +760,95 @@ func (suite *AppTestSuite) TestSaveScooterTripEvent() {
 		})
 	}
 }
+
+func (suite *AppTestSuite) TestSaveScooterTripEvents() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	createdAt := time.Now().UTC()
+	events := []domain.TripEvent{
+		{ID: "eventid1", UserID: "userid", ScooterID: "scooterid", Type: domain.TripLocationUpdateEvent, CreatedAt: createdAt},
+		{ID: "eventid2", UserID: "userid", ScooterID: "scooterid", Type: domain.TripLocationUpdateEvent, CreatedAt: createdAt.Add(time.Second)},
+	}
+
+	type fields struct {
+		database db.DB
+	}
+	type args struct {
+		ctx    context.Context
+		events []domain.TripEvent
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		prepare func()
+		want    []error
+		wantErr error
+	}{
+		{
+			name: "should return error for empty events",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:    ctx,
+				events: nil,
+			},
+			prepare: func() {},
+			want:    nil,
+			wantErr: ErrEmptyArg,
+		},
+		{
+			name: "should return error if db returns invalid arg error",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:    ctx,
+				events: events,
+			},
+			prepare: func() {
+				database.EXPECT().InsertTripEvents(ctx, events).Return(nil, db.ErrInvalidArg).Times(1)
+			},
+			want:    nil,
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name: "should return per event results if insert trip events is succeded",
+			fields: fields{
+				database: database,
+			},
+			args: args{
+				ctx:    ctx,
+				events: events,
+			},
+			prepare: func() {
+				// the location of the failed event is not applied even if it is newer
+				gomock.InOrder(
+					database.EXPECT().InsertTripEvents(ctx, events).Return([]error{nil, db.ErrInvalidArg}, nil).Times(1),
+					database.EXPECT().UpdateScooterLocation(ctx, "scooterid", "userid", events[0].Location, createdAt).Return(&domain.Scooter{ID: "scooterid"}, nil).Times(1),
+				)
+			},
+			want:    []error{nil, db.ErrInvalidArg},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			a := &appDetails{
+				database: tt.fields.database,
+				logger:   logger.Discard(),
+			}
+			got, err := a.SaveScooterTripEvents(tt.args.ctx, tt.args.events)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SaveScooterTripEvents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SaveScooterTripEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
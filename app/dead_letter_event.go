@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	// maxDeadLetterPayloadSize is the max size in bytes of the saved payload,
+	// the larger payload is truncated so that the clients can not fill the
+	// dead letter events with large invalid requests
+	maxDeadLetterPayloadSize = 4 << 10
+	// maxDeadLetterReasonSize is the max size in bytes of the saved reason
+	maxDeadLetterReasonSize = 1 << 10
+)
+
+// truncate returns the s cut to at most size bytes without splitting a
+// multi byte character
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	for size > 0 && !utf8.RuneStart(s[size]) {
+		size--
+	}
+	return s[:size]
+}
+
+// SaveDeadLetterEvent saves the trip event payload which could not be saved
+// along with the reason of failure, so that it can be fixed and replayed later
+func (a *appDetails) SaveDeadLetterEvent(ctx context.Context, payload string, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("reason: %w", ErrEmptyArg)
+	}
+
+	now := time.Now().UTC()
+	event := &domain.DeadLetterEvent{
+		Payload:   truncate(payload, maxDeadLetterPayloadSize),
+		Error:     truncate(reason, maxDeadLetterReasonSize),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := a.database.InsertDeadLetterEvent(ctx, event)
+	if err != nil {
+		return fmt.Errorf("unable to save dead letter event: %w", err)
+	}
+	return nil
+}
+
+// GetDeadLetterEvents returns all the dead letter events
+func (a *appDetails) GetDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error) {
+	events, err := a.database.GetAllDeadLetterEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("db error while getting dead letter events: %w", err)
+	}
+	return events, nil
+}
+
+// GetDeadLetterEvent returns dead letter event for given id
+func (a *appDetails) GetDeadLetterEvent(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error) {
+	if eventID == "" {
+		return nil, fmt.Errorf("eventID: %w", ErrEmptyArg)
+	}
+
+	event, err := a.database.GetDeadLetterEventByID(ctx, eventID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRecordNotFound):
+			return nil, ErrDeadLetterEventNotFound
+		case errors.Is(err, db.ErrInvalidArg):
+			return nil, fmt.Errorf("invalid dead letter event id: %w", ErrInvalidArg)
+		}
+		return nil, fmt.Errorf("unable to get dead letter event: %w", err)
+	}
+	return event, nil
+}
+
+// UpdateDeadLetterEventPayload replaces the payload of dead letter event with
+// the fixed one, returns error if the event is already replayed
+func (a *appDetails) UpdateDeadLetterEventPayload(ctx context.Context, eventID string, payload string) (*domain.DeadLetterEvent, error) {
+	if payload == "" {
+		return nil, fmt.Errorf("payload: %w", ErrEmptyArg)
+	}
+
+	if len(payload) > maxDeadLetterPayloadSize {
+		return nil, fmt.Errorf("payload must not be larger than %v bytes: %w", maxDeadLetterPayloadSize, ErrInvalidArg)
+	}
+
+	// the event is claimed while the payload is replaced so that a concurrent
+	// replay can not be marked as replayed with the old payload
+	now := time.Now().UTC()
+	event, err := a.claimDeadLetterEvent(ctx, eventID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedEvent := *event
+	updatedEvent.Payload = payload
+	updatedEvent.UpdatedAt = now
+	updatedEvent.ReplayedAt = nil
+	_, err = a.database.UpdateDeadLetterEvent(ctx, &updatedEvent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update dead letter event: %w", err)
+	}
+
+	return &updatedEvent, nil
+}
+
+// claimDeadLetterEvent marks the dead letter event as replayed at the given
+// time if it is not replayed yet, so that only one caller can replay it
+func (a *appDetails) claimDeadLetterEvent(ctx context.Context, eventID string, at time.Time) (*domain.DeadLetterEvent, error) {
+	if eventID == "" {
+		return nil, fmt.Errorf("eventID: %w", ErrEmptyArg)
+	}
+
+	event, err := a.database.ClaimDeadLetterEvent(ctx, eventID, at)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRecordNotFound):
+			// either the event does not exist or it is already claimed
+			if _, err := a.GetDeadLetterEvent(ctx, eventID); err != nil {
+				return nil, err
+			}
+			return nil, ErrDeadLetterEventAlreadyReplay
+		case errors.Is(err, db.ErrInvalidArg):
+			return nil, fmt.Errorf("invalid dead letter event id: %w", ErrInvalidArg)
+		}
+		return nil, fmt.Errorf("unable to claim dead letter event: %w", err)
+	}
+	return event, nil
+}
+
+// ReplayDeadLetterEvent saves the trip event parsed from the dead letter event
+// payload using SaveScooterTripEvent. The dead letter event is claimed before
+// the replay so that concurrent replays save it only once, on success it stays
+// marked as replayed, otherwise the claim is cleared and the attempt and its
+// error are recorded
+func (a *appDetails) ReplayDeadLetterEvent(ctx context.Context, eventID string, tripEvent *domain.TripEvent) error {
+	if tripEvent == nil {
+		return fmt.Errorf("tripEvent: %w", ErrInvalidArg)
+	}
+
+	now := time.Now().UTC()
+	event, err := a.claimDeadLetterEvent(ctx, eventID, now)
+	if err != nil {
+		return err
+	}
+
+	updatedEvent := *event
+	updatedEvent.Attempts++
+	updatedEvent.UpdatedAt = now
+	updatedEvent.ReplayedAt = &now
+
+	replayErr := a.SaveScooterTripEvent(ctx, tripEvent)
+	if replayErr != nil {
+		updatedEvent.Error = replayErr.Error()
+		updatedEvent.ReplayedAt = nil
+	}
+
+	_, err = a.database.UpdateDeadLetterEvent(ctx, &updatedEvent)
+	if err != nil {
+		return fmt.Errorf("unable to update dead letter event: %w", err)
+	}
+
+	if replayErr != nil {
+		return fmt.Errorf("replay failed: %w", replayErr)
+	}
+	return nil
+}
@@ -0,0 +1,428 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+// replayedMatcher matches dead letter event with the given attempts and
+// replayed state
+type replayedMatcher struct {
+	attempts int
+	replayed bool
+}
+
+func (m replayedMatcher) Matches(x interface{}) bool {
+	event, ok := x.(*domain.DeadLetterEvent)
+	return ok && event.Attempts == m.attempts && event.IsReplayed() == m.replayed
+}
+
+func (m replayedMatcher) String() string {
+	return fmt.Sprintf("dead letter event with replayed=%v after %v attempts", m.replayed, m.attempts)
+}
+
+func (suite *AppTestSuite) TestSaveDeadLetterEvent() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+
+	type args struct {
+		ctx     context.Context
+		payload string
+		reason  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		prepare func()
+		wantErr bool
+	}{
+		{
+			name: "should return error for empty reason",
+			args: args{
+				ctx:     ctx,
+				payload: "{}",
+				reason:  "",
+			},
+			prepare: func() {},
+			wantErr: true,
+		},
+		{
+			name: "should return error if insert dead letter event failed",
+			args: args{
+				ctx:     ctx,
+				payload: "{}",
+				reason:  "invalid argument",
+			},
+			prepare: func() {
+				database.EXPECT().InsertDeadLetterEvent(ctx, gomock.Any()).Return(errors.New("internal error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return success if dead letter event is saved",
+			args: args{
+				ctx:     ctx,
+				payload: "{}",
+				reason:  "invalid argument",
+			},
+			prepare: func() {
+				database.EXPECT().InsertDeadLetterEvent(ctx, gomock.Any()).Return(nil).Times(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "should truncate large payload and reason",
+			args: args{
+				ctx:     ctx,
+				payload: "{" + strings.Repeat("é", maxDeadLetterPayloadSize) + "}",
+				reason:  strings.Repeat("x", 2*maxDeadLetterReasonSize),
+			},
+			prepare: func() {
+				database.EXPECT().InsertDeadLetterEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *domain.DeadLetterEvent) error {
+					if len(event.Payload) > maxDeadLetterPayloadSize || !utf8.ValidString(event.Payload) || len(event.Error) != maxDeadLetterReasonSize {
+						t.Errorf("InsertDeadLetterEvent() payload size = %v, reason size = %v, want truncated", len(event.Payload), len(event.Error))
+					}
+					return nil
+				}).Times(1)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			if err := a.SaveDeadLetterEvent(tt.args.ctx, tt.args.payload, tt.args.reason); (err != nil) != tt.wantErr {
+				t.Errorf("SaveDeadLetterEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *AppTestSuite) TestGetDeadLetterEvent() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+
+	type args struct {
+		ctx     context.Context
+		eventID string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		prepare func()
+		wantErr error
+	}{
+		{
+			name: "should return error for empty eventID",
+			args: args{
+				ctx:     ctx,
+				eventID: "",
+			},
+			prepare: func() {},
+			wantErr: ErrEmptyArg,
+		},
+		{
+			name: "should return error if dead letter event not found",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+			},
+			prepare: func() {
+				database.EXPECT().GetDeadLetterEventByID(ctx, "eventid").Return(nil, db.ErrRecordNotFound).Times(1)
+			},
+			wantErr: ErrRecordNotFound,
+		},
+		{
+			name: "should return error for invalid eventID",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+			},
+			prepare: func() {
+				database.EXPECT().GetDeadLetterEventByID(ctx, "eventid").Return(nil, db.ErrInvalidArg).Times(1)
+			},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name: "should return dead letter event",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+			},
+			prepare: func() {
+				database.EXPECT().GetDeadLetterEventByID(ctx, "eventid").Return(&domain.DeadLetterEvent{ID: "eventid"}, nil).Times(1)
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			_, err := a.GetDeadLetterEvent(tt.args.ctx, tt.args.eventID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetDeadLetterEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *AppTestSuite) TestUpdateDeadLetterEventPayload() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	replayedAt := time.Now()
+
+	type args struct {
+		ctx     context.Context
+		eventID string
+		payload string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		prepare func()
+		wantErr bool
+	}{
+		{
+			name: "should return error for empty payload",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+				payload: "",
+			},
+			prepare: func() {},
+			wantErr: true,
+		},
+		{
+			name: "should return error for too large payload",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+				payload: strings.Repeat("x", maxDeadLetterPayloadSize+1),
+			},
+			prepare: func() {},
+			wantErr: true,
+		},
+		{
+			name: "should return error if event is already replayed",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+				payload: "{}",
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(nil, db.ErrRecordNotFound).Times(1),
+					database.EXPECT().GetDeadLetterEventByID(ctx, "eventid").Return(&domain.DeadLetterEvent{
+						ID:         "eventid",
+						ReplayedAt: &replayedAt,
+					}, nil).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error if event is not found",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+				payload: "{}",
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(nil, db.ErrRecordNotFound).Times(1),
+					database.EXPECT().GetDeadLetterEventByID(ctx, "eventid").Return(nil, db.ErrRecordNotFound).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error if update failed",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+				payload: "{}",
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(&domain.DeadLetterEvent{ID: "eventid", ReplayedAt: &replayedAt}, nil).Times(1),
+					database.EXPECT().UpdateDeadLetterEvent(ctx, replayedMatcher{attempts: 0, replayed: false}).Return(nil, errors.New("internal error")).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return success if payload is updated",
+			args: args{
+				ctx:     ctx,
+				eventID: "eventid",
+				payload: "{}",
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(&domain.DeadLetterEvent{ID: "eventid", ReplayedAt: &replayedAt}, nil).Times(1),
+					database.EXPECT().UpdateDeadLetterEvent(ctx, replayedMatcher{attempts: 0, replayed: false}).Return(&domain.DeadLetterEvent{ID: "eventid"}, nil).Times(1),
+				)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.UpdateDeadLetterEventPayload(tt.args.ctx, tt.args.eventID, tt.args.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateDeadLetterEventPayload() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Payload != tt.args.payload {
+				t.Errorf("UpdateDeadLetterEventPayload() payload = %v, want %v", got.Payload, tt.args.payload)
+			}
+		})
+	}
+}
+
+func (suite *AppTestSuite) TestReplayDeadLetterEvent() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	replayedAt := time.Now()
+	tripEvent := &domain.TripEvent{
+		UserID:    "userid",
+		ScooterID: "scooterid",
+		Type:      domain.TripStartEvent,
+		CreatedAt: time.Now(),
+	}
+
+	type args struct {
+		ctx       context.Context
+		eventID   string
+		tripEvent *domain.TripEvent
+	}
+	tests := []struct {
+		name    string
+		args    args
+		prepare func()
+		wantErr bool
+	}{
+		{
+			name: "should return error for nil trip event",
+			args: args{
+				ctx:       ctx,
+				eventID:   "eventid",
+				tripEvent: nil,
+			},
+			prepare: func() {},
+			wantErr: true,
+		},
+		{
+			name: "should return error if event is already replayed",
+			args: args{
+				ctx:       ctx,
+				eventID:   "eventid",
+				tripEvent: tripEvent,
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(nil, db.ErrRecordNotFound).Times(1),
+					database.EXPECT().GetDeadLetterEventByID(ctx, "eventid").Return(&domain.DeadLetterEvent{
+						ID:         "eventid",
+						ReplayedAt: &replayedAt,
+					}, nil).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error if claim failed",
+			args: args{
+				ctx:       ctx,
+				eventID:   "eventid",
+				tripEvent: tripEvent,
+			},
+			prepare: func() {
+				database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(nil, errors.New("internal error")).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should record failed attempt if save trip event failed",
+			args: args{
+				ctx:       ctx,
+				eventID:   "eventid",
+				tripEvent: tripEvent,
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(&domain.DeadLetterEvent{ID: "eventid", ReplayedAt: &replayedAt}, nil).Times(1),
+					database.EXPECT().InsertTripEvent(ctx, tripEvent).Return(errors.New("internal error")).Times(1),
+					database.EXPECT().UpdateDeadLetterEvent(ctx, replayedMatcher{attempts: 1, replayed: false}).Return(&domain.DeadLetterEvent{}, nil).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error if update dead letter event failed",
+			args: args{
+				ctx:       ctx,
+				eventID:   "eventid",
+				tripEvent: tripEvent,
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(&domain.DeadLetterEvent{ID: "eventid", ReplayedAt: &replayedAt}, nil).Times(1),
+					database.EXPECT().InsertTripEvent(ctx, tripEvent).Return(nil).Times(1),
+					database.EXPECT().UpdateDeadLetterEvent(ctx, replayedMatcher{attempts: 1, replayed: true}).Return(nil, errors.New("internal error")).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should mark event as replayed if save trip event succeeded",
+			args: args{
+				ctx:       ctx,
+				eventID:   "eventid",
+				tripEvent: tripEvent,
+			},
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().ClaimDeadLetterEvent(ctx, "eventid", gomock.Any()).Return(&domain.DeadLetterEvent{ID: "eventid", ReplayedAt: &replayedAt}, nil).Times(1),
+					database.EXPECT().InsertTripEvent(ctx, tripEvent).Return(nil).Times(1),
+					database.EXPECT().UpdateDeadLetterEvent(ctx, replayedMatcher{attempts: 1, replayed: true}).Return(&domain.DeadLetterEvent{}, nil).Times(1),
+				)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			if err := a.ReplayDeadLetterEvent(tt.args.ctx, tt.args.eventID, tt.args.tripEvent); (err != nil) != tt.wantErr {
+				t.Errorf("ReplayDeadLetterEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
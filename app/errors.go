@@ -0,0 +1,90 @@
+package app
+
+import (
+	"errors"
+)
+
+// ErrorCode is the stable code of the error returned to the clients, unlike
+// the error message it does not change, so that the clients can rely on it
+type ErrorCode string
+
+// error codes of the app errors
+const (
+	CodeValidationFailed             ErrorCode = "VALIDATION_FAILED"
+	CodeNotFound                     ErrorCode = "NOT_FOUND"
+	CodeOperationNotAllowed          ErrorCode = "OPERATION_NOT_ALLOWED"
+	CodeServiceUnavailable           ErrorCode = "SERVICE_UNAVAILABLE"
+	CodeInternal                     ErrorCode = "INTERNAL_ERROR"
+	CodeScooterNotFound              ErrorCode = "SCOOTER_NOT_FOUND"
+	CodeScooterUnavailable           ErrorCode = "SCOOTER_UNAVAILABLE"
+	CodeScooterNotInUse              ErrorCode = "SCOOTER_NOT_IN_USE"
+	CodeScooterInUseByOther          ErrorCode = "SCOOTER_IN_USE_BY_OTHER"
+	CodeTripNotFound                 ErrorCode = "TRIP_NOT_FOUND"
+	CodeTripAlreadyEnded             ErrorCode = "TRIP_ALREADY_ENDED"
+	CodeDeadLetterEventNotFound      ErrorCode = "DEAD_LETTER_EVENT_NOT_FOUND"
+	CodeDeadLetterEventAlreadyReplay ErrorCode = "DEAD_LETTER_EVENT_ALREADY_REPLAYED"
+)
+
+var (
+	ErrInvalidArg          = errors.New("invalid argument")
+	ErrEmptyArg            = errors.New("empty argument")
+	ErrRecordNotFound      = errors.New("record not found")
+	ErrOperationNotAllowed = errors.New("operation not allowed")
+	ErrServiceUnavailable  = errors.New("service unavailable")
+
+	// the errors below have their own code, they match the generic error
+	// they are kind of with errors.Is
+	ErrScooterNotFound              = newCodedError(CodeScooterNotFound, "scooter not found", ErrRecordNotFound)
+	ErrScooterUnavailable           = newCodedError(CodeScooterUnavailable, "scooter is unavailable", ErrOperationNotAllowed)
+	ErrScooterNotInUse              = newCodedError(CodeScooterNotInUse, "scooter is not in use", ErrOperationNotAllowed)
+	ErrScooterInUseByOther          = newCodedError(CodeScooterInUseByOther, "scooter is used by other user", ErrOperationNotAllowed)
+	ErrTripNotFound                 = newCodedError(CodeTripNotFound, "trip not found", ErrRecordNotFound)
+	ErrTripAlreadyEnded             = newCodedError(CodeTripAlreadyEnded, "trip has already ended", ErrOperationNotAllowed)
+	ErrDeadLetterEventNotFound      = newCodedError(CodeDeadLetterEventNotFound, "dead letter event not found", ErrRecordNotFound)
+	ErrDeadLetterEventAlreadyReplay = newCodedError(CodeDeadLetterEventAlreadyReplay, "dead letter event is already replayed", ErrOperationNotAllowed)
+)
+
+// codedError is the app error with its own code, kind is the generic error
+// it matches
+type codedError struct {
+	code    ErrorCode
+	message string
+	kind    error
+}
+
+func newCodedError(code ErrorCode, message string, kind error) error {
+	return &codedError{
+		code:    code,
+		message: message,
+		kind:    kind,
+	}
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+func (e *codedError) Unwrap() error {
+	return e.kind
+}
+
+// Code returns the code of the most specific app error in the chain of the
+// error, CodeInternal if it is not an app error
+func Code(err error) ErrorCode {
+	coded := &codedError{}
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, ErrInvalidArg) || errors.Is(err, ErrEmptyArg):
+		return CodeValidationFailed
+	case errors.Is(err, ErrRecordNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrOperationNotAllowed):
+		return CodeOperationNotAllowed
+	case errors.Is(err, ErrServiceUnavailable):
+		return CodeServiceUnavailable
+	}
+	return CodeInternal
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{name: "nil error", err: nil, want: ""},
+		{name: "invalid argument", err: fmt.Errorf("radius: %w", ErrInvalidArg), want: CodeValidationFailed},
+		{name: "empty argument", err: fmt.Errorf("userID: %w", ErrEmptyArg), want: CodeValidationFailed},
+		{name: "record not found", err: ErrRecordNotFound, want: CodeNotFound},
+		{name: "operation not allowed", err: ErrOperationNotAllowed, want: CodeOperationNotAllowed},
+		{name: "service unavailable", err: fmt.Errorf("trip event queue is full: %w", ErrServiceUnavailable), want: CodeServiceUnavailable},
+		{name: "scooter unavailable", err: ErrScooterUnavailable, want: CodeScooterUnavailable},
+		{name: "wrapped scooter in use by other", err: fmt.Errorf("end trip: %w", ErrScooterInUseByOther), want: CodeScooterInUseByOther},
+		{name: "replay of replayed dead letter event", err: fmt.Errorf("replay failed: %w", ErrDeadLetterEventAlreadyReplay), want: CodeDeadLetterEventAlreadyReplay},
+		{name: "unknown error", err: errors.New("connection refused"), want: CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Errorf("Code() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the coded errors match the generic error they are kind of
+	if !errors.Is(ErrScooterUnavailable, ErrOperationNotAllowed) || !errors.Is(ErrScooterNotFound, ErrRecordNotFound) {
+		t.Errorf("coded errors do not match their kind")
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	// tilePixels is the size of the rendered map tile, the scooters within
+	// the same pixel are merged into one cluster
+	tilePixels = 256
+	// tileBuffer is the part of the tile size added around the tile, so that
+	// the symbols close to the edge are drawn on both tiles
+	tileBuffer = 1.0 / 16
+
+	// maxMercatorLatitude is the latitude of the north edge of the Web
+	// Mercator map, the map does not cover the poles
+	maxMercatorLatitude = 85.05112878
+
+	// tripHeatWindow is how far back the trip events are in the trip heat
+	tripHeatWindow = 24 * time.Hour
+	// tripHeatCellsPerTile is the number of trip heat cells across the tile
+	tripHeatCellsPerTile = 64
+)
+
+// GetMapTile returns the available scooters within the map tile and with
+// trip heat the number of trip events of the last 24 hours within the tile.
+// The scooters and the trip events are grouped by the grid cells of about a
+// pixel and 4 pixels of the rendered tile, so that the size of the tile does
+// not grow with the number of scooters and events on zoomed out map.
+func (a *appDetails) GetMapTile(ctx context.Context, tile domain.Tile, withTripHeat bool) (*domain.MapTile, error) {
+	if !tile.IsValid() || tile.Z > maxZoom {
+		return nil, fmt.Errorf("tile must be on the grid of zoom level between 0 and %v: %w", maxZoom, ErrInvalidArg)
+	}
+
+	boundingBox := bufferedTileBounds(tile)
+	tileSize := 360 / math.Exp2(float64(tile.Z))
+
+	scooters, err := a.database.GetAvailableScooterClusters(ctx, &boundingBox, tileSize/tilePixels)
+	if err != nil {
+		return nil, fmt.Errorf("db error while getting scooters of tile: %w", transformMapTileError(err))
+	}
+
+	mapTile := &domain.MapTile{
+		Tile:     tile,
+		Scooters: scooters,
+	}
+	if !withTripHeat {
+		return mapTile, nil
+	}
+
+	since := time.Now().UTC().Add(-tripHeatWindow)
+	mapTile.TripHeat, err = a.database.GetTripEventHeat(ctx, &boundingBox, since, tileSize/tripHeatCellsPerTile)
+	if err != nil {
+		return nil, fmt.Errorf("db error while getting trip heat of tile: %w", transformMapTileError(err))
+	}
+	return mapTile, nil
+}
+
+// bufferedTileBounds returns the bounds of the tile with the buffer around it,
+// limited to the Web Mercator map
+func bufferedTileBounds(tile domain.Tile) domain.BoundingBox {
+	b := tile.Bounds()
+	latitudeBuffer := (b.NorthEast.Latitude - b.SouthWest.Latitude) * tileBuffer
+	longitudeBuffer := (b.NorthEast.Longitude - b.SouthWest.Longitude) * tileBuffer
+	return domain.BoundingBox{
+		SouthWest: domain.GeoLocation{
+			Latitude:  math.Max(b.SouthWest.Latitude-latitudeBuffer, -maxMercatorLatitude),
+			Longitude: math.Max(b.SouthWest.Longitude-longitudeBuffer, -180),
+		},
+		NorthEast: domain.GeoLocation{
+			Latitude:  math.Min(b.NorthEast.Latitude+latitudeBuffer, maxMercatorLatitude),
+			Longitude: math.Min(b.NorthEast.Longitude+longitudeBuffer, 180),
+		},
+	}
+}
+
+// transformMapTileError returns the app error for the db error
+func transformMapTileError(err error) error {
+	if errors.Is(err, db.ErrInvalidArg) {
+		return ErrInvalidArg
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+func (suite *AppTestSuite) TestGetMapTile() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	tile := domain.Tile{Z: 12, X: 2200, Y: 1343}
+	scooter := domain.Scooter{ID: "scooterid", Location: domain.GeoLocation{Latitude: 52.5, Longitude: 13.4}, IsAvailable: true}
+	clusters := []domain.ScooterCluster{
+		{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 3},
+		{Center: scooter.Location, Count: 1, Scooter: &scooter},
+	}
+	heat := []domain.HeatCell{{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 42}}
+
+	tests := []struct {
+		name         string
+		tile         domain.Tile
+		withTripHeat bool
+		prepare      func()
+		want         *domain.MapTile
+		wantErr      error
+	}{
+		{
+			name:    "should return error for tile outside of grid",
+			tile:    domain.Tile{Z: 2, X: 4, Y: 0},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:    "should return error for too big zoom",
+			tile:    domain.Tile{Z: maxZoom + 1},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name: "should return error if db returns error",
+			tile: tile,
+			prepare: func() {
+				database.EXPECT().GetAvailableScooterClusters(ctx, gomock.Any(), gomock.Any()).Return(nil, db.ErrUnavailable).Times(1)
+			},
+			wantErr: db.ErrUnavailable,
+		},
+		{
+			name: "should return scooters grouped by pixel of tile",
+			tile: tile,
+			prepare: func() {
+				database.EXPECT().GetAvailableScooterClusters(ctx, gomock.Any(), 360.0/4096/tilePixels).Return(clusters, nil).Times(1)
+			},
+			want: &domain.MapTile{Tile: tile, Scooters: clusters},
+		},
+		{
+			name:         "should return error if db returns error for trip heat",
+			tile:         tile,
+			withTripHeat: true,
+			prepare: func() {
+				database.EXPECT().GetAvailableScooterClusters(ctx, gomock.Any(), gomock.Any()).Return(clusters, nil).Times(1)
+				database.EXPECT().GetTripEventHeat(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, db.ErrInvalidArg).Times(1)
+			},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:         "should return scooters and trip heat",
+			tile:         tile,
+			withTripHeat: true,
+			prepare: func() {
+				database.EXPECT().GetAvailableScooterClusters(ctx, gomock.Any(), gomock.Any()).Return(clusters, nil).Times(1)
+				database.EXPECT().GetTripEventHeat(ctx, gomock.Any(), gomock.Any(), 360.0/4096/tripHeatCellsPerTile).Return(heat, nil).Times(1)
+			},
+			want: &domain.MapTile{Tile: tile, Scooters: clusters, TripHeat: heat},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.GetMapTile(ctx, tt.tile, tt.withTripHeat)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetMapTile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMapTile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_bufferedTileBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		tile domain.Tile
+		want domain.BoundingBox
+	}{
+		{
+			name: "should limit whole map to the Web Mercator map",
+			tile: domain.Tile{Z: 0, X: 0, Y: 0},
+			want: domain.BoundingBox{
+				SouthWest: domain.GeoLocation{Latitude: -maxMercatorLatitude, Longitude: -180},
+				NorthEast: domain.GeoLocation{Latitude: maxMercatorLatitude, Longitude: 180},
+			},
+		},
+		{
+			name: "should add buffer around tile",
+			tile: domain.Tile{Z: 1, X: 1, Y: 0},
+			want: domain.BoundingBox{
+				SouthWest: domain.GeoLocation{Latitude: -85.05112878 / 16, Longitude: -180.0 / 16},
+				NorthEast: domain.GeoLocation{Latitude: maxMercatorLatitude, Longitude: 180},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bufferedTileBounds(tt.tile)
+			for _, pair := range [][2]float64{
+				{got.SouthWest.Latitude, tt.want.SouthWest.Latitude},
+				{got.SouthWest.Longitude, tt.want.SouthWest.Longitude},
+				{got.NorthEast.Latitude, tt.want.NorthEast.Latitude},
+				{got.NorthEast.Longitude, tt.want.NorthEast.Longitude},
+			} {
+				if math.Abs(pair[0]-pair[1]) > 1e-6 {
+					t.Fatalf("bufferedTileBounds() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	// maxClusterZoom is the max zoom level of the map with clusters, the
+	// scooters are returned one by one when zoomed in more
+	maxClusterZoom = 16
+	// maxZoom is the max zoom level of the map
+	maxZoom = 22
+
+	// clusterCellsPerTile is the number of grid cells across the map tile of
+	// the zoom level, the cluster is about 64 pixels wide on 256 pixels tile
+	clusterCellsPerTile = 4
+	// maxViewportTiles is the max number of map tiles of the zoom level across
+	// the bounding box, the larger bounding box is not a map viewport
+	maxViewportTiles = 8
+	// maxScooterClusters is the max number of scooters returned one by one
+	// above maxClusterZoom
+	maxScooterClusters = 500
+)
+
+// GetScooterClusters returns the available scooters within the bounding box
+// e.g. map viewport grouped into clusters for the zoom level of the map. The
+// scooters are grouped by the cells of the grid which get smaller as the zoom
+// level increases. Above maxClusterZoom each scooter is its own cluster. The
+// bounding box larger than maxViewportTiles tiles of the zoom is rejected.
+func (a *appDetails) GetScooterClusters(ctx context.Context, boundingBox domain.BoundingBox, zoom int) ([]domain.ScooterCluster, error) {
+	if !isValidBoundingBox(boundingBox) {
+		return nil, fmt.Errorf("bounding box: %w", ErrInvalidArg)
+	}
+
+	if zoom < 0 || zoom > maxZoom {
+		return nil, fmt.Errorf("zoom must be between 0 and %v: %w", maxZoom, ErrInvalidArg)
+	}
+
+	tileSize := 360 / math.Pow(2, float64(zoom))
+	maxSize := tileSize * maxViewportTiles
+	if boundingBox.NorthEast.Latitude-boundingBox.SouthWest.Latitude > maxSize ||
+		boundingBox.NorthEast.Longitude-boundingBox.SouthWest.Longitude > maxSize {
+		return nil, fmt.Errorf("bounding box must be at most %v tiles across for zoom %v: %w", maxViewportTiles, zoom, ErrInvalidArg)
+	}
+
+	if zoom > maxClusterZoom {
+		return a.getScootersAsClusters(ctx, boundingBox)
+	}
+
+	cellSize := tileSize / clusterCellsPerTile
+	clusters, err := a.database.GetAvailableScooterClusters(ctx, &boundingBox, cellSize)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidArg) {
+			return nil, fmt.Errorf("db error while getting scooter clusters: %w", ErrInvalidArg)
+		}
+		return nil, fmt.Errorf("db error while getting scooter clusters: %w", err)
+	}
+	return clusters, nil
+}
+
+// getScootersAsClusters returns each available scooter within the bounding
+// box as a cluster, up to maxScooterClusters scooters
+func (a *appDetails) getScootersAsClusters(ctx context.Context, boundingBox domain.BoundingBox) ([]domain.ScooterCluster, error) {
+	scooters, err := a.SearchAvailableScooters(ctx, domain.ScooterQuery{BoundingBox: &boundingBox, Limit: maxScooterClusters})
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]domain.ScooterCluster, 0, len(scooters))
+	for i := range scooters {
+		clusters = append(clusters, domain.ScooterCluster{
+			Center:  scooters[i].Location,
+			Count:   1,
+			Scooter: &scooters[i].Scooter,
+		})
+	}
+	return clusters, nil
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+func (suite *AppTestSuite) TestGetScooterClusters() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	boundingBox := domain.BoundingBox{
+		SouthWest: domain.GeoLocation{Latitude: 52.45, Longitude: 13.30},
+		NorthEast: domain.GeoLocation{Latitude: 52.55, Longitude: 13.50},
+	}
+	scooter := domain.Scooter{ID: "scooterid", Location: domain.GeoLocation{Latitude: 52.5, Longitude: 13.4}, IsAvailable: true}
+	// the bounding box of about 4 tiles across at zoom 17
+	streetBoundingBox := domain.BoundingBox{
+		SouthWest: domain.GeoLocation{Latitude: 52.495, Longitude: 13.395},
+		NorthEast: domain.GeoLocation{Latitude: 52.505, Longitude: 13.405},
+	}
+	clusters := []domain.ScooterCluster{
+		{Center: domain.GeoLocation{Latitude: 52.51, Longitude: 13.41}, Count: 12},
+		{Center: scooter.Location, Count: 1, Scooter: &scooter},
+	}
+
+	tests := []struct {
+		name        string
+		boundingBox domain.BoundingBox
+		zoom        int
+		prepare     func()
+		want        []domain.ScooterCluster
+		wantErr     error
+	}{
+		{
+			name:        "should return error for invalid bounding box",
+			boundingBox: domain.BoundingBox{SouthWest: boundingBox.NorthEast, NorthEast: boundingBox.SouthWest},
+			zoom:        12,
+			prepare:     func() {},
+			wantErr:     ErrInvalidArg,
+		},
+		{
+			name:        "should return error for invalid zoom",
+			boundingBox: boundingBox,
+			zoom:        maxZoom + 1,
+			prepare:     func() {},
+			wantErr:     ErrInvalidArg,
+		},
+		{
+			name:        "should return error for bounding box too large for zoom",
+			boundingBox: boundingBox,
+			zoom:        maxClusterZoom + 1,
+			prepare:     func() {},
+			wantErr:     ErrInvalidArg,
+		},
+		{
+			name:        "should return error if db returns error",
+			boundingBox: boundingBox,
+			zoom:        12,
+			prepare: func() {
+				database.EXPECT().GetAvailableScooterClusters(ctx, &boundingBox, gomock.Any()).Return(nil, db.ErrUnavailable).Times(1)
+			},
+			wantErr: db.ErrUnavailable,
+		},
+		{
+			name:        "should return clusters of grid cells of the zoom",
+			boundingBox: boundingBox,
+			zoom:        12,
+			prepare: func() {
+				// 4 cells across the tile of 360/4096 degrees at zoom 12
+				database.EXPECT().GetAvailableScooterClusters(ctx, &boundingBox, 360.0/16384).Return(clusters, nil).Times(1)
+			},
+			want: clusters,
+		},
+		{
+			name:        "should return each scooter when zoomed in more than cluster zoom",
+			boundingBox: streetBoundingBox,
+			zoom:        maxClusterZoom + 1,
+			prepare: func() {
+				database.EXPECT().SearchAvailableScooters(ctx, &domain.ScooterQuery{BoundingBox: &streetBoundingBox, Limit: maxScooterClusters}).Return([]domain.ScooterMatch{{Scooter: scooter}}, nil).Times(1)
+			},
+			want: []domain.ScooterCluster{{Center: scooter.Location, Count: 1, Scooter: &scooter}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.GetScooterClusters(ctx, tt.boundingBox, tt.zoom)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetScooterClusters() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetScooterClusters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
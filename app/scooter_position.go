@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	// maxLocationClockSkew is how far in the future the time of the trip
+	// event can be, the location of the event too far in the future is not
+	// applied as it would block the updates until that time
+	maxLocationClockSkew = time.Minute
+	// staleTripLocationAge is the age after which the location of the
+	// scooter in trip is stale, the scooter sends its location every few
+	// seconds during the trip
+	staleTripLocationAge = 5 * time.Minute
+)
+
+// GetScooterPosition returns the last known location of the scooter and
+// whether it is stale
+func (a *appDetails) GetScooterPosition(ctx context.Context, scooterID string) (*domain.ScooterPosition, error) {
+	scooter, err := a.GetScooter(ctx, scooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	position := &domain.ScooterPosition{
+		ScooterID: scooter.ID,
+		Location:  scooter.Location,
+		UpdatedAt: scooter.LocationUpdatedAt,
+		InTrip:    !scooter.IsAvailable && scooter.CurrentUserID != nil && *scooter.CurrentUserID != "",
+	}
+	if position.UpdatedAt.IsZero() {
+		position.Stale = true
+		return position, nil
+	}
+
+	position.Age = time.Since(position.UpdatedAt)
+	if position.Age < 0 {
+		position.Age = 0
+	}
+	position.Stale = position.InTrip && position.Age > staleTripLocationAge
+	return position, nil
+}
+
+// updateScooterLocations updates the location of the scooters with the
+// latest location update event of each scooter among the saved events, the
+// location is applied only if the scooter is in trip of the user of the
+// event. The events are already saved, so the failed update is logged and
+// not returned, the location is updated again by the next event of the
+// scooter.
+func (a *appDetails) updateScooterLocations(ctx context.Context, events []domain.TripEvent) {
+	latest := map[string]domain.TripEvent{}
+	scooterIDs := []string{}
+	maxCreatedAt := time.Now().Add(maxLocationClockSkew)
+	for _, event := range events {
+		if event.Type != domain.TripLocationUpdateEvent || event.ScooterID == "" || event.UserID == "" || event.CreatedAt.IsZero() {
+			continue
+		}
+		if event.CreatedAt.After(maxCreatedAt) {
+			a.logger.WithField("event_id", event.ID).Warn("trip event is too far in the future to update scooter location")
+			continue
+		}
+
+		current, ok := latest[event.ScooterID]
+		if !ok {
+			scooterIDs = append(scooterIDs, event.ScooterID)
+		}
+		if !ok || event.CreatedAt.After(current.CreatedAt) {
+			latest[event.ScooterID] = event
+		}
+	}
+
+	for _, scooterID := range scooterIDs {
+		event := latest[scooterID]
+		_, err := a.database.UpdateScooterLocation(ctx, scooterID, event.UserID, event.Location, event.CreatedAt.UTC())
+		if err != nil {
+			a.logger.WithError(err).WithField("scooter_id", scooterID).Warn("unable to update scooter location")
+		}
+	}
+}
+
+// savedTripEvents returns the events which were saved by the batch insert
+// of given per event results
+func savedTripEvents(events []domain.TripEvent, results []error) []domain.TripEvent {
+	saved := make([]domain.TripEvent, 0, len(events))
+	for i := range events {
+		if results[i] == nil {
+			saved = append(saved, events[i])
+		}
+	}
+	return saved
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+)
+
+func (suite *AppTestSuite) TestGetScooterPosition() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	userID := "userid"
+	emptyUserID := ""
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name      string
+		scooterID string
+		prepare   func()
+		want      *domain.ScooterPosition
+		wantErr   error
+	}{
+		{
+			name:      "should return error for empty scooter id",
+			scooterID: "",
+			prepare:   func() {},
+			wantErr:   ErrEmptyArg,
+		},
+		{
+			name:      "should return error if scooter is not found",
+			scooterID: "scooterid",
+			prepare: func() {
+				database.EXPECT().GetScooterByID(ctx, "scooterid").Return(nil, db.ErrRecordNotFound).Times(1)
+			},
+			wantErr: ErrScooterNotFound,
+		},
+		{
+			name:      "should return stale position if location time is unknown",
+			scooterID: "scooterid",
+			prepare: func() {
+				database.EXPECT().GetScooterByID(ctx, "scooterid").Return(&domain.Scooter{
+					ID:            "scooterid",
+					Location:      location,
+					CurrentUserID: &emptyUserID,
+					IsAvailable:   true,
+				}, nil).Times(1)
+			},
+			want: &domain.ScooterPosition{ScooterID: "scooterid", Location: location, Stale: true},
+		},
+		{
+			name:      "should return fresh position of parked scooter",
+			scooterID: "scooterid",
+			prepare: func() {
+				database.EXPECT().GetScooterByID(ctx, "scooterid").Return(&domain.Scooter{
+					ID:                "scooterid",
+					Location:          location,
+					IsAvailable:       true,
+					LocationUpdatedAt: now.Add(-time.Hour),
+				}, nil).Times(1)
+			},
+			want: &domain.ScooterPosition{ScooterID: "scooterid", Location: location, UpdatedAt: now.Add(-time.Hour), Age: time.Hour},
+		},
+		{
+			name:      "should return stale position of scooter in trip without recent location",
+			scooterID: "scooterid",
+			prepare: func() {
+				database.EXPECT().GetScooterByID(ctx, "scooterid").Return(&domain.Scooter{
+					ID:                "scooterid",
+					Location:          location,
+					CurrentUserID:     &userID,
+					LocationUpdatedAt: now.Add(-time.Hour),
+				}, nil).Times(1)
+			},
+			want: &domain.ScooterPosition{ScooterID: "scooterid", Location: location, UpdatedAt: now.Add(-time.Hour), Age: time.Hour, InTrip: true, Stale: true},
+		},
+		{
+			name:      "should return fresh position of scooter in trip",
+			scooterID: "scooterid",
+			prepare: func() {
+				database.EXPECT().GetScooterByID(ctx, "scooterid").Return(&domain.Scooter{
+					ID:                "scooterid",
+					Location:          location,
+					CurrentUserID:     &userID,
+					LocationUpdatedAt: now.Add(-time.Second),
+				}, nil).Times(1)
+			},
+			want: &domain.ScooterPosition{ScooterID: "scooterid", Location: location, UpdatedAt: now.Add(-time.Second), Age: time.Second, InTrip: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.GetScooterPosition(ctx, tt.scooterID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetScooterPosition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.want == nil {
+				return
+			}
+
+			// the age grows while the test runs
+			if got.Age < tt.want.Age || got.Age > tt.want.Age+time.Minute {
+				t.Errorf("GetScooterPosition() age = %v, want about %v", got.Age, tt.want.Age)
+			}
+			got.Age = tt.want.Age
+			if *got != *tt.want {
+				t.Errorf("GetScooterPosition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func (suite *AppTestSuite) TestUpdateScooterLocations() {
+	database := suite.Database
+	ctx := context.Background()
+	now := time.Now().UTC()
+	first := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	second := domain.GeoLocation{Latitude: 52.53, Longitude: 13.41}
+
+	events := []domain.TripEvent{
+		{ID: "event1", UserID: "user1", ScooterID: "scooter1", Location: second, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+		// the late event of the same batch is not applied
+		{ID: "event2", UserID: "user1", ScooterID: "scooter1", Location: first, Type: domain.TripLocationUpdateEvent, CreatedAt: now.Add(-time.Second)},
+		{ID: "event3", UserID: "user2", ScooterID: "scooter2", Location: first, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+		// only the location update events are applied
+		{ID: "event4", UserID: "user3", ScooterID: "scooter3", Location: first, Type: domain.TripStartEvent, CreatedAt: now},
+		// the event too far in the future is not applied
+		{ID: "event5", UserID: "user4", ScooterID: "scooter4", Location: first, Type: domain.TripLocationUpdateEvent, CreatedAt: now.Add(time.Hour)},
+		// the event without user is not applied
+		{ID: "event6", ScooterID: "scooter5", Location: first, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+	}
+
+	database.EXPECT().UpdateScooterLocation(ctx, "scooter1", "user1", second, now).Return(&domain.Scooter{ID: "scooter1"}, nil).Times(1)
+	// the failed update is logged as the events are already saved
+	database.EXPECT().UpdateScooterLocation(ctx, "scooter2", "user2", first, now).Return(nil, db.ErrUnavailable).Times(1)
+
+	a := &appDetails{
+		database: database,
+		logger:   logger.Discard(),
+	}
+	a.updateScooterLocations(ctx, events)
+}
+
+// tripEventReplayer keeps the handler of replayed trip events
+type tripEventReplayer struct {
+	handler func(ctx context.Context, events []domain.TripEvent)
+}
+
+func (r *tripEventReplayer) OnReplay(handler func(ctx context.Context, events []domain.TripEvent)) {
+	r.handler = handler
+}
+
+func (suite *AppTestSuite) TestWithTripEventReplayer() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	now := time.Now().UTC()
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+
+	if _, err := NewApp(database, WithTripEventReplayer(nil)); !errors.Is(err, ErrInvalidArg) {
+		t.Errorf("NewApp() error = %v, want %v", err, ErrInvalidArg)
+	}
+
+	replayer := &tripEventReplayer{}
+	_, err := NewApp(database, WithTripEventReplayer(replayer))
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	if replayer.handler == nil {
+		t.Fatalf("NewApp() did not set the replay handler")
+	}
+
+	// the replayed events update the scooter location as the saved events
+	database.EXPECT().UpdateScooterLocation(ctx, "scooter1", "user1", location, now).Return(&domain.Scooter{ID: "scooter1"}, nil).Times(1)
+	replayer.handler(ctx, []domain.TripEvent{
+		{ID: "event1", UserID: "user1", ScooterID: "scooter1", Location: location, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+		{ID: "event2", UserID: "user1", ScooterID: "scooter1", Location: location, Type: domain.TripStopEvent, CreatedAt: now},
+	})
+}
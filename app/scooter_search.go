@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// SearchAvailableScooters returns the available scooters matching the query,
+// nearest first with their distance if the query has near location
+func (a *appDetails) SearchAvailableScooters(ctx context.Context, query domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	err := validateScooterQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	scooters, err := a.database.SearchAvailableScooters(ctx, &query)
+	if err != nil {
+		var returnErr error
+		switch {
+		case errors.Is(err, db.ErrInvalidArg):
+			returnErr = ErrInvalidArg
+		case errors.Is(err, db.ErrEmptyArg):
+			returnErr = ErrEmptyArg
+		default:
+			returnErr = err
+		}
+		return nil, fmt.Errorf("db error while searching scooters: %w", returnErr)
+	}
+
+	return scooters, nil
+}
+
+// validateScooterQuery returns error if the query is not valid. The query
+// must have near location, bounding box or polygon, and the search without
+// radius, bounding box or polygon must have limit so that it is bounded
+func validateScooterQuery(query *domain.ScooterQuery) error {
+	if query.Near == nil && query.BoundingBox == nil && len(query.Polygon) == 0 {
+		return fmt.Errorf("near, bounding box or polygon: %w", ErrEmptyArg)
+	}
+
+	if query.BoundingBox != nil && len(query.Polygon) != 0 {
+		return fmt.Errorf("only one of bounding box and polygon is allowed: %w", ErrInvalidArg)
+	}
+
+	if query.Near != nil && !isValidLocation(*query.Near) {
+		return fmt.Errorf("near: %w", ErrInvalidArg)
+	}
+
+	if query.Radius < 0 || (query.Radius > 0 && query.Near == nil) {
+		return fmt.Errorf("radius must be positive and with near: %w", ErrInvalidArg)
+	}
+
+	if query.BoundingBox != nil && !isValidBoundingBox(*query.BoundingBox) {
+		return fmt.Errorf("bounding box: %w", ErrInvalidArg)
+	}
+
+	if len(query.Polygon) != 0 {
+		if len(query.Polygon) < 3 {
+			return fmt.Errorf("polygon must have at least 3 points: %w", ErrInvalidArg)
+		}
+		for _, p := range query.Polygon {
+			if !isValidLocation(p) {
+				return fmt.Errorf("polygon: %w", ErrInvalidArg)
+			}
+		}
+	}
+
+	if query.MinBatteryLevel < 0 || query.MinBatteryLevel > 100 {
+		return fmt.Errorf("min battery level must be between 0 and 100: %w", ErrInvalidArg)
+	}
+
+	if query.Offset < 0 || query.Limit < 0 {
+		return fmt.Errorf("offset and limit must not be negative: %w", ErrInvalidArg)
+	}
+
+	if query.Limit == 0 && query.Radius == 0 && query.BoundingBox == nil && len(query.Polygon) == 0 {
+		return fmt.Errorf("limit is required for nearest scooters: %w", ErrEmptyArg)
+	}
+	return nil
+}
+
+// isValidLocation returns true if the latitude and the longitude are in range
+func isValidLocation(l domain.GeoLocation) bool {
+	return l.Latitude >= -90 && l.Latitude <= 90 && l.Longitude >= -180 && l.Longitude <= 180
+}
+
+// isValidBoundingBox returns true if the corners are valid and the south west
+// corner is below and left of the north east corner
+func isValidBoundingBox(b domain.BoundingBox) bool {
+	sw, ne := b.SouthWest, b.NorthEast
+	return isValidLocation(sw) && isValidLocation(ne) && sw.Latitude < ne.Latitude && sw.Longitude < ne.Longitude
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func (suite *AppTestSuite) TestSearchAvailableScooters() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	near := &domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	distance := 12.5
+	matches := []domain.ScooterMatch{
+		{Scooter: domain.Scooter{ID: "scooterid", IsAvailable: true}, Distance: &distance},
+	}
+
+	tests := []struct {
+		name    string
+		query   domain.ScooterQuery
+		prepare func()
+		want    []domain.ScooterMatch
+		wantErr error
+	}{
+		{
+			name:    "should return error for query without area",
+			query:   domain.ScooterQuery{Limit: 10},
+			prepare: func() {},
+			wantErr: ErrEmptyArg,
+		},
+		{
+			name: "should return error for bounding box with polygon",
+			query: domain.ScooterQuery{
+				BoundingBox: &domain.BoundingBox{NorthEast: domain.GeoLocation{Latitude: 1, Longitude: 1}},
+				Polygon:     []domain.GeoLocation{{}, {Latitude: 1}, {Longitude: 1}},
+			},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:    "should return error for radius without near",
+			query:   domain.ScooterQuery{Radius: 10, Polygon: []domain.GeoLocation{{}, {Latitude: 1}, {Longitude: 1}}},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name: "should return error for inverted bounding box",
+			query: domain.ScooterQuery{
+				BoundingBox: &domain.BoundingBox{
+					SouthWest: domain.GeoLocation{Latitude: 52.6, Longitude: 13.5},
+					NorthEast: domain.GeoLocation{Latitude: 52.5, Longitude: 13.3},
+				},
+			},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:    "should return error for polygon with less than 3 points",
+			query:   domain.ScooterQuery{Polygon: []domain.GeoLocation{{}, {Latitude: 1}}},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:    "should return error for invalid min battery level",
+			query:   domain.ScooterQuery{Near: near, Radius: 100, MinBatteryLevel: 101},
+			prepare: func() {},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:    "should return error for nearest scooters without limit",
+			query:   domain.ScooterQuery{Near: near},
+			prepare: func() {},
+			wantErr: ErrEmptyArg,
+		},
+		{
+			name:  "should return error if db returns invalid arg err",
+			query: domain.ScooterQuery{Near: near, Radius: 100},
+			prepare: func() {
+				database.EXPECT().SearchAvailableScooters(ctx, &domain.ScooterQuery{Near: near, Radius: 100}).Return(nil, db.ErrInvalidArg).Times(1)
+			},
+			wantErr: ErrInvalidArg,
+		},
+		{
+			name:  "should return error if db returns error",
+			query: domain.ScooterQuery{Near: near, Radius: 100},
+			prepare: func() {
+				database.EXPECT().SearchAvailableScooters(ctx, &domain.ScooterQuery{Near: near, Radius: 100}).Return(nil, db.ErrUnavailable).Times(1)
+			},
+			wantErr: db.ErrUnavailable,
+		},
+		{
+			name:  "should return k nearest scooters",
+			query: domain.ScooterQuery{Near: near, Limit: 5, VehicleType: "scooter", MinBatteryLevel: 20},
+			prepare: func() {
+				database.EXPECT().SearchAvailableScooters(ctx, &domain.ScooterQuery{Near: near, Limit: 5, VehicleType: "scooter", MinBatteryLevel: 20}).Return(matches, nil).Times(1)
+			},
+			want:    matches,
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.SearchAvailableScooters(ctx, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SearchAvailableScooters() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchAvailableScooters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// tripEventClockSkew is how much the time of the trip events sent by the
+	// scooter may be off from the time of the service, the events of the trip
+	// are looked up within the trip time widened by it
+	tripEventClockSkew = time.Minute
+)
+
+// GetScooter returns the scooter for given id
+func (a *appDetails) GetScooter(ctx context.Context, scooterID string) (*domain.Scooter, error) {
+	if scooterID == "" {
+		return nil, fmt.Errorf("scooterID: %w", ErrEmptyArg)
+	}
+
+	scooter, err := a.database.GetScooterByID(ctx, scooterID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ErrScooterNotFound
+		}
+		return nil, fmt.Errorf("unable to get scooter: %w", err)
+	}
+	return scooter, nil
+}
+
+// StartTrip starts trip for given user with given scooter and returns the
+// trip, the scooter becomes unavailable for other users
+func (a *appDetails) StartTrip(ctx context.Context, userID string, scooterID string) (*domain.Trip, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID: %w", ErrEmptyArg)
+	}
+
+	scooter, err := a.GetScooter(ctx, scooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !scooter.IsAvailable {
+		return nil, ErrScooterUnavailable
+	}
+
+	// the scooter is reserved only if it is still available, so that the
+	// concurrent trips can not take the same scooter
+	_, err = a.database.ReserveScooter(ctx, scooterID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ErrScooterUnavailable
+		}
+		return nil, fmt.Errorf("unable to update scooter: %w", err)
+	}
+
+	trip := &domain.Trip{
+		ID:            uuid.NewString(),
+		UserID:        userID,
+		ScooterID:     scooterID,
+		StartLocation: scooter.Location,
+		StartedAt:     time.Now().UTC(),
+	}
+	err = a.database.InsertTrip(ctx, trip)
+	if err != nil {
+		// the scooter is released, so that it is not in use without trip
+		_, releaseErr := a.database.UpdateScooter(ctx, scooter)
+		if releaseErr != nil {
+			a.logger.WithError(releaseErr).WithField("scooter_id", scooterID).Error("unable to release scooter of trip which could not be saved")
+		}
+		return nil, fmt.Errorf("unable to save trip: %w", err)
+	}
+
+	return trip, nil
+}
+
+// GetTrip returns the trip for given id
+func (a *appDetails) GetTrip(ctx context.Context, tripID string) (*domain.Trip, error) {
+	if tripID == "" {
+		return nil, fmt.Errorf("tripID: %w", ErrEmptyArg)
+	}
+
+	trip, err := a.database.GetTripByID(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ErrTripNotFound
+		}
+		return nil, fmt.Errorf("unable to get trip: %w", err)
+	}
+	return trip, nil
+}
+
+// FinishTrip ends the trip for given id at the location and returns the
+// ended trip, the scooter becomes available for other users at the location
+func (a *appDetails) FinishTrip(ctx context.Context, tripID string, location domain.GeoLocation) (*domain.Trip, error) {
+	trip, err := a.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.IsEnded() {
+		return nil, ErrTripAlreadyEnded
+	}
+
+	scooter, err := a.getScooterInUse(ctx, trip.UserID, trip.ScooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.endTrip(ctx, scooter, trip, location)
+}
+
+// GetTripEvents returns the events sent by the scooter of the trip for the
+// user of the trip during the trip, in order of creation
+func (a *appDetails) GetTripEvents(ctx context.Context, tripID string) ([]domain.TripEvent, error) {
+	trip, err := a.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now().UTC()
+	if trip.IsEnded() {
+		to = *trip.EndedAt
+	}
+	events, err := a.database.GetScooterTripEvents(ctx, trip.ScooterID, trip.StartedAt.Add(-tripEventClockSkew), to.Add(tripEventClockSkew))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get trip events: %w", err)
+	}
+
+	tripEvents := []domain.TripEvent{}
+	for _, event := range events {
+		if event.UserID == trip.UserID {
+			tripEvents = append(tripEvents, event)
+		}
+	}
+	return tripEvents, nil
+}
+
+// getScooterInUse returns the scooter for given id, returns error if the
+// scooter is not in use by the user
+func (a *appDetails) getScooterInUse(ctx context.Context, userID string, scooterID string) (*domain.Scooter, error) {
+	scooter, err := a.GetScooter(ctx, scooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if scooter.IsAvailable {
+		return nil, ErrScooterNotInUse
+	}
+
+	if scooter.CurrentUserID == nil || *scooter.CurrentUserID != userID {
+		return nil, ErrScooterInUseByOther
+	}
+	return scooter, nil
+}
+
+// endTrip ends the trip if it is saved and then releases the scooter at the
+// location, so that ending the trip again releases the scooter if it failed
+// earlier
+func (a *appDetails) endTrip(ctx context.Context, scooter *domain.Scooter, trip *domain.Trip, location domain.GeoLocation) (*domain.Trip, error) {
+	endedAt := time.Now().UTC()
+	if trip != nil {
+		endedTrip := *trip
+		endLocation := location
+		endedTrip.EndedAt = &endedAt
+		endedTrip.EndLocation = &endLocation
+		_, err := a.database.UpdateTrip(ctx, &endedTrip)
+		if err != nil {
+			return nil, fmt.Errorf("unable to update trip: %w", err)
+		}
+		trip = &endedTrip
+	}
+
+	updatedScooter := *scooter
+	currentUserID := ""
+	updatedScooter.CurrentUserID = &currentUserID
+	updatedScooter.IsAvailable = true
+	updatedScooter.Location = location
+	updatedScooter.LocationUpdatedAt = endedAt
+	_, err := a.database.UpdateScooter(ctx, &updatedScooter)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update scooter: %w", err)
+	}
+
+	return trip, nil
+}
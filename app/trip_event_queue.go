@@ -0,0 +1,274 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// the write behind metrics are exposed at /metrics along with the metrics of
+// metrics package, they are recorded here as only the queue sees them
+var (
+	writeBehindQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "scootin",
+		Subsystem: "write_behind",
+		Name:      "queue_depth",
+		Help:      "Number of trip events waiting in the queue to be flushed.",
+	})
+
+	writeBehindFlushDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "scootin",
+		Subsystem: "write_behind",
+		Name:      "flush_duration_seconds",
+		Help:      "Latency of flushing the batch of trip events.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	writeBehindFlushedEventsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "scootin",
+		Subsystem: "write_behind",
+		Name:      "flushed_events_total",
+		Help:      "Number of trip events flushed from the queue.",
+	})
+
+	writeBehindFlushErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "scootin",
+		Subsystem: "write_behind",
+		Name:      "flush_errors_total",
+		Help:      "Number of trip events which could not be saved by the flush.",
+	})
+)
+
+// WriteBehindConfig represents the configuration of write behind pipeline
+// used to save trip events asynchronously
+type WriteBehindConfig struct {
+	// QueueSize is the max number of events waiting to be flushed
+	QueueSize int
+	// BatchSize is the max number of events flushed in one insert
+	BatchSize int
+	// FlushInterval is the max time an event waits in the batch before flush
+	FlushInterval time.Duration
+	// FlushTimeout is the timeout of one flush
+	FlushTimeout time.Duration
+	// Workers is the number of workers flushing the events
+	Workers int
+}
+
+// DefaultWriteBehindConfig returns default write behind configuration
+func DefaultWriteBehindConfig() WriteBehindConfig {
+	return WriteBehindConfig{
+		QueueSize:     10000,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		FlushTimeout:  10 * time.Second,
+		Workers:       4,
+	}
+}
+
+// validate returns error if the configuration is invalid
+func (c WriteBehindConfig) validate() error {
+	switch {
+	case c.QueueSize <= 0:
+		return fmt.Errorf("queue size: %w", ErrInvalidArg)
+	case c.BatchSize <= 0:
+		return fmt.Errorf("batch size: %w", ErrInvalidArg)
+	case c.FlushInterval <= 0:
+		return fmt.Errorf("flush interval: %w", ErrInvalidArg)
+	case c.FlushTimeout <= 0:
+		return fmt.Errorf("flush timeout: %w", ErrInvalidArg)
+	case c.Workers <= 0:
+		return fmt.Errorf("workers: %w", ErrInvalidArg)
+	}
+	return nil
+}
+
+// tripEventQueue is bounded in-process queue of trip events, the events are
+// flushed in batches by size or time by the pool of workers
+type tripEventQueue struct {
+	config WriteBehindConfig
+	flush  func(events []domain.TripEvent)
+	events chan domain.TripEvent
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// newTripEventQueue creates the queue and starts its workers
+func newTripEventQueue(config WriteBehindConfig, flush func(events []domain.TripEvent)) *tripEventQueue {
+	q := &tripEventQueue{
+		config: config,
+		flush:  flush,
+		events: make(chan domain.TripEvent, config.QueueSize),
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// enqueue adds the event to the queue without blocking,
+// returns error if the queue is full or stopped
+func (q *tripEventQueue) enqueue(event domain.TripEvent) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return fmt.Errorf("trip event queue is stopped: %w", ErrServiceUnavailable)
+	}
+
+	select {
+	case q.events <- event:
+		writeBehindQueueDepth.Inc()
+		return nil
+	default:
+		return fmt.Errorf("trip event queue is full: %w", ErrServiceUnavailable)
+	}
+}
+
+// ready returns error if the queue is stopped or full
+func (q *tripEventQueue) ready() error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return fmt.Errorf("trip event queue is stopped: %w", ErrServiceUnavailable)
+	}
+	if len(q.events) == cap(q.events) {
+		return fmt.Errorf("trip event queue is full (%v events): %w", len(q.events), ErrServiceUnavailable)
+	}
+	return nil
+}
+
+// stop stops accepting new events and waits until all the queued events are flushed
+func (q *tripEventQueue) stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.events)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("trip event queue not drained: %w", ctx.Err())
+	}
+}
+
+// work collects the events in batch and flushes it when batch is full or
+// flush interval is elapsed, remaining events are flushed once queue is stopped
+func (q *tripEventQueue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.TripEvent, 0, q.config.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		start := time.Now()
+		q.flush(batch)
+		writeBehindFlushDuration.Observe(time.Since(start).Seconds())
+		writeBehindFlushedEventsTotal.Add(float64(len(batch)))
+		batch = make([]domain.TripEvent, 0, q.config.BatchSize)
+	}
+
+	for {
+		select {
+		case event, ok := <-q.events:
+			if !ok {
+				flush()
+				return
+			}
+			writeBehindQueueDepth.Dec()
+			batch = append(batch, event)
+			if len(batch) >= q.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// tripEventPayload represents trip event in the format of save trip event
+// request, so that the events failed during flush can be replayed from dead letter
+type tripEventPayload struct {
+	ID        string `json:"id,omitempty"`
+	UserID    string `json:"user_id"`
+	ScooterID string `json:"scooter_id"`
+	Location  struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"location"`
+	CreatedAt time.Time `json:"created_at"`
+	Type      string    `json:"type"`
+}
+
+// flushTripEvents saves the batch of trip events from the queue,
+// the events which could not be saved are moved to dead letter events
+func (a *appDetails) flushTripEvents(events []domain.TripEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.writeBehind.FlushTimeout)
+	defer cancel()
+
+	results, err := a.database.InsertTripEvents(ctx, events)
+	if err == nil {
+		a.updateScooterLocations(ctx, savedTripEvents(events, results))
+	}
+	for i := range events {
+		eventErr := err
+		if err == nil {
+			eventErr = results[i]
+		}
+		if eventErr == nil {
+			continue
+		}
+
+		writeBehindFlushErrorsTotal.Inc()
+		payload := tripEventPayload{
+			ID:        events[i].ID,
+			UserID:    events[i].UserID,
+			ScooterID: events[i].ScooterID,
+			CreatedAt: events[i].CreatedAt,
+			Type:      string(events[i].Type),
+		}
+		payload.Location.Latitude = events[i].Location.Latitude
+		payload.Location.Longitude = events[i].Location.Longitude
+		bs, jsonErr := json.Marshal(payload)
+		if jsonErr != nil {
+			a.logger.WithError(jsonErr).WithField("event_id", events[i].ID).Error("unable to encode trip event")
+			continue
+		}
+
+		a.saveDeadLetterEvent(string(bs), eventErr.Error())
+	}
+}
+
+// saveDeadLetterEvent saves the event failed during flush in dead letter
+// events, it uses own context as the flush context might be already expired
+func (a *appDetails) saveDeadLetterEvent(payload string, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.writeBehind.FlushTimeout)
+	defer cancel()
+
+	err := a.SaveDeadLetterEvent(ctx, payload, reason)
+	if err != nil {
+		a.logger.WithError(err).Error("unable to save dead letter event")
+	}
+}
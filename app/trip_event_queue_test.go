@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func (suite *AppTestSuite) TestWithWriteBehind() {
+	t := suite.T()
+
+	invalidConfig := DefaultWriteBehindConfig()
+	invalidConfig.Workers = 0
+
+	_, err := NewApp(suite.Database, WithWriteBehind(invalidConfig))
+	if !errors.Is(err, ErrInvalidArg) {
+		t.Errorf("NewApp() error = %v, want %v", err, ErrInvalidArg)
+	}
+
+	a, err := NewApp(suite.Database, WithWriteBehind(DefaultWriteBehindConfig()))
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	if err := a.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func (suite *AppTestSuite) TestSaveScooterTripEventWriteBehind() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+
+	config := DefaultWriteBehindConfig()
+	config.BatchSize = 2
+	config.Workers = 1
+	config.FlushInterval = time.Hour
+
+	events := []domain.TripEvent{
+		{ID: "eventid1", Type: domain.TripStartEvent},
+		{ID: "eventid2", Type: domain.TripLocationUpdateEvent},
+		{ID: "eventid3", Type: domain.TripStopEvent},
+	}
+
+	gomock.InOrder(
+		database.EXPECT().InsertTripEvents(gomock.Any(), events[:2]).Return([]error{nil, nil}, nil).Times(1),
+		database.EXPECT().InsertTripEvents(gomock.Any(), events[2:]).Return([]error{errors.New("write error")}, nil).Times(1),
+		database.EXPECT().InsertDeadLetterEvent(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+	)
+
+	a, err := NewApp(database, WithWriteBehind(config))
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	flushedEvents := testutil.ToFloat64(writeBehindFlushedEventsTotal)
+	flushErrors := testutil.ToFloat64(writeBehindFlushErrorsTotal)
+
+	for i := range events {
+		if err := a.SaveScooterTripEvent(ctx, &events[i]); err != nil {
+			t.Errorf("SaveScooterTripEvent() error = %v", err)
+		}
+	}
+
+	// remaining event is flushed when app is stopped
+	if err := a.Stop(ctx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+
+	if got := testutil.ToFloat64(writeBehindFlushedEventsTotal) - flushedEvents; got != 3 {
+		t.Errorf("flushed events = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(writeBehindFlushErrorsTotal) - flushErrors; got != 1 {
+		t.Errorf("flush errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(writeBehindQueueDepth); got != 0 {
+		t.Errorf("queue depth = %v after stop, want 0", got)
+	}
+
+	err = a.SaveScooterTripEvent(ctx, &events[0])
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("SaveScooterTripEvent() error = %v after stop, want %v", err, ErrServiceUnavailable)
+	}
+}
+
+func (suite *AppTestSuite) TestSaveScooterTripEventsWriteBehindQueueFull() {
+	t := suite.T()
+	ctx := context.Background()
+
+	flushed := make(chan []domain.TripEvent, 1)
+	unblock := make(chan struct{})
+	config := DefaultWriteBehindConfig()
+	config.QueueSize = 1
+	config.BatchSize = 1
+	config.Workers = 1
+
+	a := &appDetails{
+		database:    suite.Database,
+		writeBehind: config,
+	}
+	a.tripEventQueue = newTripEventQueue(config, func(events []domain.TripEvent) {
+		flushed <- events
+		<-unblock
+	})
+
+	// first event is taken by the worker which is blocked in flush
+	results, err := a.SaveScooterTripEvents(ctx, []domain.TripEvent{{ID: "eventid1"}})
+	if err != nil || results[0] != nil {
+		t.Fatalf("SaveScooterTripEvents() = %v, %v", results, err)
+	}
+	<-flushed
+
+	results, err = a.SaveScooterTripEvents(ctx, []domain.TripEvent{{ID: "eventid2"}, {ID: "eventid3"}})
+	if err != nil {
+		t.Fatalf("SaveScooterTripEvents() error = %v", err)
+	}
+	if results[0] != nil {
+		t.Errorf("SaveScooterTripEvents() result = %v, want nil", results[0])
+	}
+	if !errors.Is(results[1], ErrServiceUnavailable) {
+		t.Errorf("SaveScooterTripEvents() result = %v, want %v", results[1], ErrServiceUnavailable)
+	}
+	if err := a.Ready(ctx); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("Ready() error = %v, want %v", err, ErrServiceUnavailable)
+	}
+
+	close(unblock)
+	go func() {
+		for range flushed {
+		}
+	}()
+	if err := a.Stop(ctx); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	close(flushed)
+	if err := a.Ready(ctx); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("Ready() error after Stop() = %v, want %v", err, ErrServiceUnavailable)
+	}
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/golang/mock/gomock"
+)
+
+// releasedScooterMatcher matches the released scooter with its location
+// updated at the end of the trip
+type releasedScooterMatcher struct {
+	scooter domain.Scooter
+}
+
+func (m releasedScooterMatcher) Matches(x interface{}) bool {
+	scooter, ok := x.(*domain.Scooter)
+	if !ok || scooter.LocationUpdatedAt.IsZero() {
+		return false
+	}
+	released := *scooter
+	released.LocationUpdatedAt = time.Time{}
+	return reflect.DeepEqual(released, m.scooter)
+}
+
+func (m releasedScooterMatcher) String() string {
+	return fmt.Sprintf("scooter %+v with location updated at the end of trip", m.scooter)
+}
+
+func (suite *AppTestSuite) TestFinishTrip() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	userID := "userid"
+	otherUserID := "otheruserid"
+	endedAt := time.Now().UTC()
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	trip := &domain.Trip{ID: "tripid", UserID: userID, ScooterID: "scooterid"}
+
+	tests := []struct {
+		name    string
+		tripID  string
+		prepare func()
+		wantErr error
+	}{
+		{
+			name:    "should return error for empty tripID",
+			tripID:  "",
+			prepare: func() {},
+			wantErr: ErrEmptyArg,
+		},
+		{
+			name:   "should return error if trip not found",
+			tripID: "tripid",
+			prepare: func() {
+				database.EXPECT().GetTripByID(ctx, "tripid").Return(nil, db.ErrRecordNotFound).Times(1)
+			},
+			wantErr: ErrTripNotFound,
+		},
+		{
+			name:   "should return error if trip has already ended",
+			tripID: "tripid",
+			prepare: func() {
+				endedTrip := *trip
+				endedTrip.EndedAt = &endedAt
+				database.EXPECT().GetTripByID(ctx, "tripid").Return(&endedTrip, nil).Times(1)
+			},
+			wantErr: ErrTripAlreadyEnded,
+		},
+		{
+			name:   "should return error if scooter is used by other user",
+			tripID: "tripid",
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().GetTripByID(ctx, "tripid").Return(trip, nil).Times(1),
+					database.EXPECT().GetScooterByID(ctx, "scooterid").Return(&domain.Scooter{
+						ID:            "scooterid",
+						CurrentUserID: &otherUserID,
+					}, nil).Times(1),
+				)
+			},
+			wantErr: ErrScooterInUseByOther,
+		},
+		{
+			name:   "should end trip and release scooter at the location",
+			tripID: "tripid",
+			prepare: func() {
+				scooter := &domain.Scooter{ID: "scooterid", CurrentUserID: &userID}
+				emptyUserID := ""
+				releasedScooter := &domain.Scooter{
+					ID:            "scooterid",
+					CurrentUserID: &emptyUserID,
+					IsAvailable:   true,
+					Location:      location,
+				}
+				gomock.InOrder(
+					database.EXPECT().GetTripByID(ctx, "tripid").Return(trip, nil).Times(1),
+					database.EXPECT().GetScooterByID(ctx, "scooterid").Return(scooter, nil).Times(1),
+					database.EXPECT().UpdateTrip(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, tr *domain.Trip) (*domain.Trip, error) {
+						return tr, nil
+					}).Times(1),
+					database.EXPECT().UpdateScooter(ctx, releasedScooterMatcher{scooter: *releasedScooter}).Return(releasedScooter, nil).Times(1),
+				)
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.FinishTrip(ctx, tt.tripID, location)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FinishTrip() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (!got.IsEnded() || *got.EndLocation != location) {
+				t.Errorf("FinishTrip() = %+v, want trip ended at %+v", got, location)
+			}
+		})
+	}
+}
+
+func (suite *AppTestSuite) TestGetTripEvents() {
+	t := suite.T()
+	database := suite.Database
+	ctx := context.Background()
+	startedAt := time.Now().UTC().Add(-time.Hour)
+	endedAt := startedAt.Add(30 * time.Minute)
+	trip := &domain.Trip{
+		ID:        "tripid",
+		UserID:    "userid",
+		ScooterID: "scooterid",
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+	}
+
+	tests := []struct {
+		name    string
+		prepare func()
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name: "should return error if get scooter trip events failed",
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().GetTripByID(ctx, "tripid").Return(trip, nil).Times(1),
+					database.EXPECT().GetScooterTripEvents(ctx, "scooterid", gomock.Any(), gomock.Any()).Return(nil, errors.New("internal error")).Times(1),
+				)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return events of the user of the trip within the trip time",
+			prepare: func() {
+				gomock.InOrder(
+					database.EXPECT().GetTripByID(ctx, "tripid").Return(trip, nil).Times(1),
+					database.EXPECT().GetScooterTripEvents(ctx, "scooterid", startedAt.Add(-tripEventClockSkew), endedAt.Add(tripEventClockSkew)).Return([]domain.TripEvent{
+						{ID: "e1", UserID: "userid"},
+						{ID: "e2", UserID: "otheruserid"},
+						{ID: "e3", UserID: "userid"},
+					}, nil).Times(1),
+				)
+			},
+			wantIDs: []string{"e1", "e3"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &appDetails{
+				database: database,
+			}
+			tt.prepare()
+			got, err := a.GetTripEvents(ctx, "tripid")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTripEvents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("GetTripEvents() = %+v, want ids %v", got, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if got[i].ID != id {
+					t.Errorf("GetTripEvents()[%d].ID = %v, want %v", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
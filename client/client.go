@@ -0,0 +1,277 @@
+// Package client is the Go client of the Journey API, it is safe for
+// concurrent use
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// endpoints of the service, they are passed to the observer
+const (
+	EndpointAvailableScooters     = "available_scooters"
+	EndpointBeginTrip             = "begin_trip"
+	EndpointEndTrip               = "end_trip"
+	EndpointTripEvent             = "trip_event"
+	EndpointTripEvents            = "trip_events"
+	EndpointDeadLetterEvents      = "dead_letter_events"
+	EndpointDeadLetterEvent       = "dead_letter_event"
+	EndpointUpdateDeadLetterEvent = "update_dead_letter_event"
+	EndpointReplayDeadLetterEvent = "replay_dead_letter_event"
+	EndpointHealthz               = "healthz"
+	EndpointReadyz                = "readyz"
+	EndpointSearchScooters        = "search_scooters"
+	EndpointScooter               = "scooter"
+	EndpointScooterEvent          = "scooter_event"
+	EndpointStartTrip             = "start_trip"
+	EndpointTrip                  = "trip"
+	EndpointFinishTrip            = "finish_trip"
+	EndpointTripEventsOfTrip      = "trip_events_of_trip"
+	EndpointScooterClusters       = "scooter_clusters"
+	EndpointTile                  = "tile"
+	EndpointScooterPosition       = "scooter_position"
+)
+
+const (
+	apiV1 = "/api/v1"
+	apiV2 = "/api/v2"
+	// apiKeyHeader is the header of the api key of v2 requests, v1 requests
+	// send it in the query
+	apiKeyHeader = "X-API-Key"
+
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 2 * time.Second
+)
+
+// Observer is called after each request with the endpoint, latency, response
+// status code (0 if no response is received) and error (nil on success). It
+// is called for each attempt of the retried request.
+type Observer func(endpoint string, latency time.Duration, statusCode int, err error)
+
+// Client calls the apis of the service
+type Client struct {
+	apiKey     string
+	httpClient *resty.Client
+	observe    Observer
+	// maxAttempts is the number of attempts of the request including the first
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// Option configures the client
+type Option func(*Client)
+
+// WithObserver sets the observer which is called after each request
+func WithObserver(o Observer) Option {
+	return func(c *Client) {
+		c.observe = o
+	}
+}
+
+// WithTimeout sets the timeout of each attempt of the request, there is no
+// timeout by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.SetTimeout(timeout)
+	}
+}
+
+// WithTransport sets the transport of the requests, default is clone of
+// http.DefaultTransport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.SetTransport(transport)
+	}
+}
+
+// WithRetry sets the number of attempts of the request including the first,
+// 1 disables the retries. The backoff between the attempts doubles from the
+// initial backoff up to the max backoff with random jitter. By default the
+// request is attempted 3 times with backoff from 100ms up to 2s.
+func WithRetry(maxAttempts int, initialBackoff time.Duration, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.initialBackoff = initialBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// NewClient creates client for the service at base url e.g.
+// http://localhost:8080 which authenticates with the api key. The dead letter
+// event and scooter position methods need the client created with the admin
+// api key
+func NewClient(baseURL string, apiKey string, opts ...Option) (*Client, error) {
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("baseURL %q: %w", baseURL, ErrInvalidArg)
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("apiKey: %w", ErrInvalidArg)
+	}
+
+	c := &Client{
+		apiKey: apiKey,
+		httpClient: resty.New().
+			SetBaseURL(strings.TrimSuffix(baseURL, "/")).
+			SetTransport(http.DefaultTransport.(*http.Transport).Clone()).
+			SetHeader("Accept", "application/json, application/problem+json"),
+		observe:        func(string, time.Duration, int, error) {},
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxAttempts < 1 {
+		return nil, fmt.Errorf("maxAttempts: %w", ErrInvalidArg)
+	}
+	return c, nil
+}
+
+// request is the request to one of the endpoints
+type request struct {
+	endpoint   string
+	method     string
+	path       string
+	pathParams map[string]string
+	query      map[string]string
+	body       interface{}
+	// public is true for the endpoints which do not need api key
+	public         bool
+	wantStatusCode int
+	// idempotent is true if the request can be sent again even if it may
+	// have been processed by the service
+	idempotent bool
+	// result is decoded from successful response body
+	result interface{}
+	// rawResult is set to successful response body if it is set, the body
+	// which is not json e.g. map tile is returned as is
+	rawResult *[]byte
+	// errorResult is decoded from error response body if it is set
+	errorResult interface{}
+}
+
+// do sends the request and retries it as per the retry policy
+func (c *Client) do(ctx context.Context, r request) error {
+	for attempt := 1; ; attempt++ {
+		statusCode, err := c.send(ctx, r)
+		if err == nil || attempt >= c.maxAttempts || !retryable(ctx, r, statusCode, err) {
+			return err
+		}
+		if !sleep(ctx, c.backoff(attempt)) {
+			return err
+		}
+	}
+}
+
+// send sends one attempt of the request and reports it to the observer, the
+// response with status code other than wanted is returned as Error
+func (c *Client) send(ctx context.Context, r request) (int, error) {
+	req := c.httpClient.R().
+		SetContext(ctx).
+		SetPathParams(r.pathParams).
+		SetQueryParams(r.query)
+	switch {
+	case r.public:
+	case strings.HasPrefix(r.path, apiV2):
+		req.SetHeader(apiKeyHeader, c.apiKey)
+	default:
+		req.SetQueryParam("api_key", c.apiKey)
+	}
+	if r.body != nil {
+		req.SetBody(r.body)
+	}
+
+	start := time.Now()
+	resp, err := req.Execute(r.method, r.path)
+	latency := time.Since(start)
+	if err != nil {
+		c.observe(r.endpoint, latency, 0, err)
+		return 0, err
+	}
+
+	if resp.StatusCode() != r.wantStatusCode {
+		p := problem{}
+		_ = json.Unmarshal(resp.Body(), &p)
+		if r.errorResult != nil {
+			_ = json.Unmarshal(resp.Body(), r.errorResult)
+		}
+		err = &Error{
+			StatusCode:  resp.StatusCode(),
+			Code:        p.Code,
+			Message:     p.Detail,
+			TraceID:     p.TraceID,
+			FieldErrors: p.Errors,
+		}
+	} else if r.rawResult != nil {
+		*r.rawResult = resp.Body()
+	} else if r.result != nil {
+		err = json.Unmarshal(resp.Body(), r.result)
+		if err != nil {
+			err = fmt.Errorf("invalid response body: %w", err)
+		}
+	}
+	c.observe(r.endpoint, latency, resp.StatusCode(), err)
+	return resp.StatusCode(), err
+}
+
+// retryable returns true if the failed request can be sent again. The
+// request which is not idempotent is retried only if it was surely not
+// processed i.e. the connection could not be made or the service rejected it
+// as unavailable.
+func retryable(ctx context.Context, r request, statusCode int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	switch statusCode {
+	case 0:
+		var opErr *net.OpError
+		return r.idempotent || (errors.As(err, &opErr) && opErr.Op == "dial")
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return r.idempotent
+	}
+	return false
+}
+
+// backoff returns the wait before the next attempt, it is between half and
+// full of the exponential backoff
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.initialBackoff
+	for i := 1; i < attempt && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sleep waits for the duration, returns false if the context is done before
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
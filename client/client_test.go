@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer responds with the status codes in order, then with 200
+type flakyServer struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		code := CodeInternal
+		switch status {
+		case http.StatusBadRequest:
+			code = CodeValidationFailed
+		case http.StatusServiceUnavailable:
+			code = CodeServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, `{"type":"about:blank","status":%v,"code":%q,"detail":"try again","traceId":"abc"}`, status, code)
+		return
+	}
+	_, _ = w.Write([]byte(`{"scooters":[],"user_id":"u","scooter_id":"s"}`))
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		apiKey  string
+		opts    []Option
+	}{
+		{name: "invalid base url", baseURL: "localhost", apiKey: "key"},
+		{name: "empty api key", baseURL: "http://localhost:8080", apiKey: ""},
+		{name: "no attempts", baseURL: "http://localhost:8080", apiKey: "key", opts: []Option{WithRetry(0, 0, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(tt.baseURL, tt.apiKey, tt.opts...)
+			if !errors.Is(err, ErrInvalidArg) {
+				t.Errorf("NewClient() error = %v, want %v", err, ErrInvalidArg)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		statuses     []int
+		call         func(c *Client) error
+		wantRequests int
+		wantErr      error
+		wantCode     ErrorCode
+	}{
+		{
+			name:     "idempotent request is retried on bad gateway",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			call: func(c *Client) error {
+				_, err := c.GetAvailableScooters(ctx, GeoLocation{}, 100)
+				return err
+			},
+			wantRequests: 3,
+		},
+		{
+			name:     "request is not retried after max attempts",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			call: func(c *Client) error {
+				_, err := c.GetAvailableScooters(ctx, GeoLocation{}, 100)
+				return err
+			},
+			wantRequests: 3,
+			wantErr:      ErrUnavailable,
+			wantCode:     CodeServiceUnavailable,
+		},
+		{
+			name:     "begin trip is retried when service is unavailable",
+			statuses: []int{http.StatusServiceUnavailable},
+			call: func(c *Client) error {
+				_, err := c.BeginTrip(ctx, "u", "s")
+				return err
+			},
+			wantRequests: 2,
+		},
+		{
+			name:     "begin trip is not retried on bad gateway",
+			statuses: []int{http.StatusBadGateway},
+			call: func(c *Client) error {
+				_, err := c.BeginTrip(ctx, "u", "s")
+				return err
+			},
+			wantRequests: 1,
+			wantErr:      &Error{StatusCode: http.StatusBadGateway},
+			wantCode:     CodeInternal,
+		},
+		{
+			name:     "bad request is not retried",
+			statuses: []int{http.StatusBadRequest},
+			call: func(c *Client) error {
+				_, err := c.BeginTrip(ctx, "u", "s")
+				return err
+			},
+			wantRequests: 1,
+			wantErr:      ErrBadRequest,
+			wantCode:     CodeValidationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &flakyServer{statuses: tt.statuses}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			attempts := 0
+			c, err := NewClient(ts.URL, "key",
+				WithRetry(3, time.Millisecond, 5*time.Millisecond),
+				WithObserver(func(string, time.Duration, int, error) { attempts++ }))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.call(c)
+			if server.requests != tt.wantRequests || attempts != tt.wantRequests {
+				t.Errorf("requests = %v, observed = %v, want %v", server.requests, attempts, tt.wantRequests)
+			}
+			if got := Code(err); got != tt.wantCode {
+				t.Errorf("Code() = %v, want %v", got, tt.wantCode)
+			}
+			apiErr := &Error{}
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+			case *Error:
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != want.StatusCode || apiErr.TraceID != "abc" || apiErr.Message != "try again" {
+					t.Errorf("error = %v, want status %v", err, want.StatusCode)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	c, err := NewClient(ts.URL, "key", WithRetry(2, time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attempts := 0
+	c.observe = func(string, time.Duration, int, error) { attempts++ }
+
+	// the connection is not made, so even begin trip is retried
+	_, err = c.BeginTrip(context.Background(), "u", "s")
+	if err == nil || attempts != 2 {
+		t.Errorf("BeginTrip() error = %v after %v attempts, want error after 2 attempts", err, attempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	_, err = c.GetAvailableScooters(ctx, GeoLocation{}, 100)
+	if err == nil || attempts != 1 {
+		t.Errorf("GetAvailableScooters() error = %v after %v attempts, want error without retry", err, attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := &Client{initialBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 4, max: 800 * time.Millisecond},
+		{attempt: 10, max: time.Second},
+	}
+	for _, tt := range tests {
+		if got := c.backoff(tt.attempt); got < tt.max/2 || got > tt.max {
+			t.Errorf("backoff(%v) = %v, want between %v and %v", tt.attempt, got, tt.max/2, tt.max)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetDeadLetterEvents returns the trip events which could not be saved
+func (c *Client) GetDeadLetterEvents(ctx context.Context) ([]DeadLetterEvent, error) {
+	resp := getDeadLetterEventsResponse{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointDeadLetterEvents,
+		method:         http.MethodGet,
+		path:           apiV1 + "/auth/admin/dead-letter-events",
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         &resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// GetDeadLetterEvent returns the dead letter event, the error matches
+// ErrNotFound if there is no event with the id
+func (c *Client) GetDeadLetterEvent(ctx context.Context, id string) (*DeadLetterEvent, error) {
+	resp := &DeadLetterEvent{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointDeadLetterEvent,
+		method:         http.MethodGet,
+		path:           apiV1 + "/auth/admin/dead-letter-events/{id}",
+		pathParams:     map[string]string{"id": id},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// UpdateDeadLetterEvent replaces the payload of the dead letter event with
+// the fixed one, so that it can be replayed
+func (c *Client) UpdateDeadLetterEvent(ctx context.Context, id string, payload string) (*DeadLetterEvent, error) {
+	resp := &DeadLetterEvent{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointUpdateDeadLetterEvent,
+		method:         http.MethodPut,
+		path:           apiV1 + "/auth/admin/dead-letter-events/{id}",
+		pathParams:     map[string]string{"id": id},
+		body:           updateDeadLetterEventRequest{Payload: payload},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReplayDeadLetterEvent saves the payload of the dead letter event as trip
+// event, the error matches ErrBadRequest if the payload is still invalid or
+// the event is already replayed
+func (c *Client) ReplayDeadLetterEvent(ctx context.Context, id string) error {
+	return c.do(ctx, request{
+		endpoint:       EndpointReplayDeadLetterEvent,
+		method:         http.MethodPost,
+		path:           apiV1 + "/auth/admin/dead-letter-events/{id}/replay",
+		pathParams:     map[string]string{"id": id},
+		wantStatusCode: http.StatusOK,
+	})
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrInvalidArg is returned if the argument is invalid before the request
+	// is sent
+	ErrInvalidArg = errors.New("invalid argument")
+	// ErrBadRequest matches the error of the request rejected by the service
+	// e.g. validation failure or the scooter is not available
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized matches the error of the request with invalid api key
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound matches the error of the request for missing record
+	ErrNotFound = errors.New("not found")
+	// ErrUnavailable matches the error of the request which the service is not
+	// able to serve right now, it can be retried later
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrInternal matches the error of the request failed in the service
+	ErrInternal = errors.New("internal server error")
+)
+
+// ErrorCode is the stable code of the error returned by the service, unlike
+// the message it does not change
+type ErrorCode string
+
+// error codes returned by the service
+const (
+	CodeValidationFailed             ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthorized                 ErrorCode = "UNAUTHORIZED"
+	CodeNotFound                     ErrorCode = "NOT_FOUND"
+	CodeOperationNotAllowed          ErrorCode = "OPERATION_NOT_ALLOWED"
+	CodeServiceUnavailable           ErrorCode = "SERVICE_UNAVAILABLE"
+	CodeInternal                     ErrorCode = "INTERNAL_ERROR"
+	CodeScooterNotFound              ErrorCode = "SCOOTER_NOT_FOUND"
+	CodeScooterUnavailable           ErrorCode = "SCOOTER_UNAVAILABLE"
+	CodeScooterNotInUse              ErrorCode = "SCOOTER_NOT_IN_USE"
+	CodeScooterInUseByOther          ErrorCode = "SCOOTER_IN_USE_BY_OTHER"
+	CodeTripNotFound                 ErrorCode = "TRIP_NOT_FOUND"
+	CodeTripAlreadyEnded             ErrorCode = "TRIP_ALREADY_ENDED"
+	CodeDeadLetterEventNotFound      ErrorCode = "DEAD_LETTER_EVENT_NOT_FOUND"
+	CodeDeadLetterEventAlreadyReplay ErrorCode = "DEAD_LETTER_EVENT_ALREADY_REPLAYED"
+)
+
+// Error is returned when the service responds with error status code, it
+// matches one of the sentinel errors by status code with errors.Is
+type Error struct {
+	StatusCode int
+	// Code is the stable code of the error, it is empty if the response is
+	// not from the service e.g. from proxy
+	Code    ErrorCode
+	Message string
+	// TraceID is the id of the request trace in the service
+	TraceID string
+	// FieldErrors are the failed fields of the request rejected with
+	// CodeValidationFailed
+	FieldErrors []FieldError
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("unexpected status code %v", e.StatusCode)
+	if e.Code != "" {
+		msg += " " + string(e.Code)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Is matches the sentinel error of the status code
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	case ErrInternal:
+		return e.StatusCode == http.StatusInternalServerError
+	}
+	return false
+}
+
+// Code returns the code of the service error in the chain of the error, empty
+// if it is not the service error
+func Code(err error) ErrorCode {
+	e := &Error{}
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Healthz returns the liveness of the service
+func (c *Client) Healthz(ctx context.Context) (*HealthReport, error) {
+	resp := &HealthReport{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointHealthz,
+		method:         http.MethodGet,
+		path:           "/healthz",
+		public:         true,
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Readyz returns the readiness of the service. If the service is not ready
+// the report of the failed checks is returned along with the error matching
+// ErrUnavailable, the request is not retried as the unavailable service is
+// the answer.
+func (c *Client) Readyz(ctx context.Context) (*HealthReport, error) {
+	resp := &HealthReport{}
+	statusCode, err := c.send(ctx, request{
+		endpoint:       EndpointReadyz,
+		method:         http.MethodGet,
+		path:           "/readyz",
+		public:         true,
+		wantStatusCode: http.StatusOK,
+		result:         resp,
+		errorResult:    resp,
+	})
+	if statusCode == 0 {
+		return nil, err
+	}
+	return resp, err
+}
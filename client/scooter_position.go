@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetScooterPosition returns the last known position of the scooter, the
+// error matches ErrNotFound if there is no scooter with the id
+func (c *Client) GetScooterPosition(ctx context.Context, scooterID string) (*ScooterPosition, error) {
+	resp := &ScooterPosition{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointScooterPosition,
+		method:         http.MethodGet,
+		path:           apiV1 + "/auth/admin/scooters/{id}/position",
+		pathParams:     map[string]string{"id": scooterID},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
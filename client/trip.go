@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// GetAvailableScooters returns available scooters within radius(in meters)
+// of the location sorted by nearest first
+func (c *Client) GetAvailableScooters(ctx context.Context, location GeoLocation, radius int) ([]Scooter, error) {
+	resp := getAvailableScootersResponse{}
+	err := c.do(ctx, request{
+		endpoint: EndpointAvailableScooters,
+		method:   http.MethodGet,
+		path:     apiV1 + "/auth/user/available-scooters",
+		query: map[string]string{
+			"latitude":  fmt.Sprintf("%f", location.Latitude),
+			"longitude": fmt.Sprintf("%f", location.Longitude),
+			"radius":    fmt.Sprintf("%d", radius),
+		},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         &resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Scooters, nil
+}
+
+// BeginTrip begins the trip of the user with the scooter, the error matches
+// ErrBadRequest if the scooter is not available
+func (c *Client) BeginTrip(ctx context.Context, userID string, scooterID string) (*BeginTripResponse, error) {
+	resp := &BeginTripResponse{}
+	err := c.do(ctx, request{
+		endpoint: EndpointBeginTrip,
+		method:   http.MethodPut,
+		path:     apiV1 + "/auth/user/begin-trip",
+		body: BeginTripRequest{
+			UserID:    userID,
+			ScooterID: scooterID,
+		},
+		wantStatusCode: http.StatusOK,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EndTrip ends the trip of the user with the scooter at the location
+func (c *Client) EndTrip(ctx context.Context, userID string, scooterID string, location GeoLocation) (*EndTripResponse, error) {
+	resp := &EndTripResponse{}
+	err := c.do(ctx, request{
+		endpoint: EndpointEndTrip,
+		method:   http.MethodPut,
+		path:     apiV1 + "/auth/user/end-trip",
+		body: EndTripRequest{
+			UserID:    userID,
+			ScooterID: scooterID,
+			Location:  location,
+		},
+		wantStatusCode: http.StatusOK,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SaveTripEvent saves the event generated by the scooter during trip. The
+// event without id gets random one, so that it is not saved twice when the
+// request is retried.
+func (c *Client) SaveTripEvent(ctx context.Context, event TripEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	return c.do(ctx, request{
+		endpoint:       EndpointTripEvent,
+		method:         http.MethodPost,
+		path:           apiV1 + "/auth/scooter/trip-event",
+		body:           event,
+		wantStatusCode: http.StatusCreated,
+		idempotent:     true,
+	})
+}
+
+// SaveTripEvents saves the batch of events generated by the scooter e.g.
+// offline backlog of the scooter and returns the result of each event at
+// the same index. The events without id get random one.
+func (c *Client) SaveTripEvents(ctx context.Context, events []TripEvent) ([]TripEventResult, error) {
+	withIDs := make([]TripEvent, len(events))
+	for i, e := range events {
+		if e.ID == "" {
+			e.ID = uuid.NewString()
+		}
+		withIDs[i] = e
+	}
+
+	resp := saveTripEventsResponse{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointTripEvents,
+		method:         http.MethodPost,
+		path:           apiV1 + "/auth/scooter/trip-events",
+		body:           saveTripEventsRequest{Events: withIDs},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         &resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
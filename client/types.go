@@ -0,0 +1,275 @@
+package client
+
+import (
+	"time"
+)
+
+// TripEventType is the type of the event generated by scooter during trip
+type TripEventType string
+
+// trip event types accepted by the service
+const (
+	TripStartEvent          TripEventType = "trip_start"
+	TripStopEvent           TripEventType = "trip_stop"
+	TripLocationUpdateEvent TripEventType = "trip_location_update"
+)
+
+// GeoLocation is the location in degrees
+type GeoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Scooter is the scooter as returned by the service
+type Scooter struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Location      GeoLocation `json:"location"`
+	CurrentUserID *string     `json:"current_user_id"`
+	IsAvailable   bool        `json:"is_available"`
+	VehicleType   string      `json:"vehicle_type,omitempty"`
+	BatteryLevel  int         `json:"battery_level,omitempty"`
+}
+
+// BeginTripRequest is the request to begin the trip
+type BeginTripRequest struct {
+	UserID    string `json:"user_id"`
+	ScooterID string `json:"scooter_id"`
+}
+
+// BeginTripResponse is the trip which has begun
+type BeginTripResponse struct {
+	UserID    string `json:"user_id"`
+	ScooterID string `json:"scooter_id"`
+}
+
+// EndTripRequest is the request to end the trip at the location
+type EndTripRequest struct {
+	UserID    string      `json:"user_id"`
+	ScooterID string      `json:"scooter_id"`
+	Location  GeoLocation `json:"location"`
+}
+
+// EndTripResponse is the trip which has ended
+type EndTripResponse struct {
+	UserID    string      `json:"user_id"`
+	ScooterID string      `json:"scooter_id"`
+	Location  GeoLocation `json:"location"`
+}
+
+// TripEvent is the event generated by scooter during trip, the event with
+// same id is saved only once
+type TripEvent struct {
+	ID        string        `json:"id,omitempty"`
+	UserID    string        `json:"user_id"`
+	ScooterID string        `json:"scooter_id"`
+	Location  GeoLocation   `json:"location"`
+	CreatedAt time.Time     `json:"created_at"`
+	Type      TripEventType `json:"type"`
+}
+
+// TripEventResult is the result of the event saved in batch, it is at the
+// same index as the event in the batch
+type TripEventResult struct {
+	Index        int       `json:"index"`
+	ID           string    `json:"id"`
+	Success      bool      `json:"success"`
+	ErrorMessage string    `json:"errorMessage,omitempty"`
+	ErrorCode    ErrorCode `json:"errorCode,omitempty"`
+}
+
+// DeadLetterEvent is the trip event which could not be saved along with the
+// reason of failure
+type DeadLetterEvent struct {
+	ID         string     `json:"id"`
+	Payload    string     `json:"payload"`
+	Error      string     `json:"error"`
+	Attempts   int        `json:"attempts"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ReplayedAt *time.Time `json:"replayed_at"`
+}
+
+// ScooterPosition is the last known location of the scooter updated by its
+// trip location events
+type ScooterPosition struct {
+	ScooterID string      `json:"scooter_id"`
+	Location  GeoLocation `json:"location"`
+	// UpdatedAt and AgeSeconds are nil if the time of the location is
+	// unknown
+	UpdatedAt  *time.Time `json:"updated_at"`
+	AgeSeconds *float64   `json:"age_seconds"`
+	InTrip     bool       `json:"in_trip"`
+	Stale      bool       `json:"stale"`
+}
+
+// HealthReport is the liveness or readiness of the service with the result
+// of each dependency check
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the result of the dependency check
+type HealthCheck struct {
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// TripStatus is the status of the trip
+type TripStatus string
+
+// trip statuses returned by the service
+const (
+	TripInProgress TripStatus = "in_progress"
+	TripEnded      TripStatus = "ended"
+)
+
+// Trip is the trip of the user with the scooter, the end location and time
+// are set once the trip has ended
+type Trip struct {
+	ID            string       `json:"id"`
+	UserID        string       `json:"user_id"`
+	ScooterID     string       `json:"scooter_id"`
+	Status        TripStatus   `json:"status"`
+	StartLocation GeoLocation  `json:"start_location"`
+	EndLocation   *GeoLocation `json:"end_location,omitempty"`
+	StartedAt     time.Time    `json:"started_at"`
+	EndedAt       *time.Time   `json:"ended_at,omitempty"`
+}
+
+// StartTripRequest is the request to start the trip
+type StartTripRequest struct {
+	UserID    string `json:"user_id"`
+	ScooterID string `json:"scooter_id"`
+}
+
+// FinishTripRequest is the request to end the trip at the location
+type FinishTripRequest struct {
+	Location GeoLocation `json:"location"`
+}
+
+// ScooterEvent is the event generated by the scooter during trip, the event
+// with same id is saved only once
+type ScooterEvent struct {
+	ID        string        `json:"id,omitempty"`
+	UserID    string        `json:"user_id"`
+	Location  GeoLocation   `json:"location"`
+	CreatedAt time.Time     `json:"created_at"`
+	Type      TripEventType `json:"type"`
+}
+
+// BoundingBox is the area between the south west and north east corners e.g.
+// map viewport
+type BoundingBox struct {
+	SouthWest GeoLocation
+	NorthEast GeoLocation
+}
+
+// ScooterQuery is the search of available scooters near the location, within
+// the bounding box or within the polygon (at least 3 points), near can be
+// used along with the bounding box or the polygon. With near the scooters are
+// sorted by nearest first and are within the radius(in meters) if it is set.
+// The scooters are filtered by vehicle type and min battery level(in percent)
+// if they are set. The page has up to limit scooters starting at the cursor.
+// The zero fields are not sent.
+type ScooterQuery struct {
+	Near        *GeoLocation
+	Radius      float64
+	BoundingBox *BoundingBox
+	Polygon     []GeoLocation
+	VehicleType string
+	MinBattery  int
+	Limit       int
+	Cursor      string
+}
+
+// ScooterMatch is the scooter found by the search, the distance(in meters)
+// is set if the search is near the location
+type ScooterMatch struct {
+	Scooter
+	Distance *float64 `json:"distance,omitempty"`
+}
+
+// ScooterPage is the page of scooters found by the search, next cursor is
+// empty on the last page
+type ScooterPage struct {
+	Items      []ScooterMatch `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// ScooterCluster is the group of available scooters of the map viewport, the
+// center is the centroid of the scooters and the cluster of one scooter has
+// the scooter
+type ScooterCluster struct {
+	Center  GeoLocation `json:"center"`
+	Count   int         `json:"count"`
+	Scooter *Scooter    `json:"scooter,omitempty"`
+}
+
+// ScooterClusters are the clusters of the zoom level of the map
+type ScooterClusters struct {
+	Zoom     int              `json:"zoom"`
+	Clusters []ScooterCluster `json:"clusters"`
+}
+
+// TripEventQuery filters the events of the trip by type and creation time,
+// the events created at or after since and before until are returned. The
+// page has up to limit events starting at the cursor. The zero fields are
+// not sent.
+type TripEventQuery struct {
+	Type   TripEventType
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Cursor string
+}
+
+// TripEventPage is the page of the events of the trip, next cursor is empty
+// on the last page
+type TripEventPage struct {
+	Items      []TripEvent `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+type getAvailableScootersResponse struct {
+	Scooters []Scooter `json:"scooters"`
+}
+
+type saveTripEventsRequest struct {
+	Events []TripEvent `json:"events"`
+}
+
+type saveTripEventsResponse struct {
+	Results []TripEventResult `json:"results"`
+}
+
+type getDeadLetterEventsResponse struct {
+	Events []DeadLetterEvent `json:"events"`
+}
+
+type updateDeadLetterEventRequest struct {
+	Payload string `json:"payload"`
+}
+
+// FieldError is the validation failure of the request field e.g. user_id,
+// rule is the failed validation e.g. required or uuid4
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// problem is the application/problem+json error response of the service
+type problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     ErrorCode    `json:"code"`
+	TraceID  string       `json:"traceId,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
@@ -0,0 +1,268 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchScooters returns the page of available scooters found by the query,
+// the next page is requested with the NextCursor of the page
+func (c *Client) SearchScooters(ctx context.Context, query ScooterQuery) (*ScooterPage, error) {
+	resp := &ScooterPage{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointSearchScooters,
+		method:         http.MethodGet,
+		path:           apiV2 + "/scooters",
+		query:          query.params(),
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetScooterClusters returns the available scooters within the bounding box
+// e.g. map viewport grouped into clusters for the zoom level of the map, the
+// error matches ErrBadRequest if the bounding box is too large for the zoom
+func (c *Client) GetScooterClusters(ctx context.Context, boundingBox BoundingBox, zoom int) (*ScooterClusters, error) {
+	resp := &ScooterClusters{}
+	err := c.do(ctx, request{
+		endpoint: EndpointScooterClusters,
+		method:   http.MethodGet,
+		path:     apiV2 + "/scooters/clusters",
+		query: map[string]string{
+			"bbox": formatLocations(boundingBox.SouthWest, boundingBox.NorthEast),
+			"zoom": strconv.Itoa(zoom),
+		},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetTile returns the Mapbox Vector Tile of the Web Mercator tile grid with
+// the layers e.g. scooters and trip_heat, the tile has the scooters layer if
+// no layer is given
+func (c *Client) GetTile(ctx context.Context, z, x, y int, layers ...string) ([]byte, error) {
+	query := map[string]string{}
+	if len(layers) > 0 {
+		query["layers"] = strings.Join(layers, ",")
+	}
+
+	var tile []byte
+	err := c.do(ctx, request{
+		endpoint: EndpointTile,
+		method:   http.MethodGet,
+		path:     apiV2 + "/tiles/{z}/{x}/{y}.mvt",
+		pathParams: map[string]string{
+			"z": strconv.Itoa(z),
+			"x": strconv.Itoa(x),
+			"y": strconv.Itoa(y),
+		},
+		query:          query,
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		rawResult:      &tile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tile, nil
+}
+
+// GetScooter returns the scooter, the error matches ErrNotFound if there is
+// no scooter with the id
+func (c *Client) GetScooter(ctx context.Context, id string) (*Scooter, error) {
+	resp := &Scooter{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointScooter,
+		method:         http.MethodGet,
+		path:           apiV2 + "/scooters/{id}",
+		pathParams:     map[string]string{"id": id},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateScooterEvent saves the event generated by the scooter during trip and
+// returns the saved event. The event without id gets random one, so that it
+// is not saved twice when the request is retried and the identical events are
+// not saved as one.
+func (c *Client) CreateScooterEvent(ctx context.Context, scooterID string, event ScooterEvent) (*TripEvent, error) {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+
+	resp := &TripEvent{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointScooterEvent,
+		method:         http.MethodPost,
+		path:           apiV2 + "/scooters/{id}/events",
+		pathParams:     map[string]string{"id": scooterID},
+		body:           event,
+		wantStatusCode: http.StatusCreated,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// StartTrip starts the trip of the user with the scooter, the error matches
+// ErrBadRequest if the scooter is not available
+func (c *Client) StartTrip(ctx context.Context, userID string, scooterID string) (*Trip, error) {
+	resp := &Trip{}
+	err := c.do(ctx, request{
+		endpoint: EndpointStartTrip,
+		method:   http.MethodPost,
+		path:     apiV2 + "/trips",
+		body: StartTripRequest{
+			UserID:    userID,
+			ScooterID: scooterID,
+		},
+		wantStatusCode: http.StatusCreated,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetTrip returns the trip, the error matches ErrNotFound if there is no trip
+// with the id
+func (c *Client) GetTrip(ctx context.Context, id string) (*Trip, error) {
+	resp := &Trip{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointTrip,
+		method:         http.MethodGet,
+		path:           apiV2 + "/trips/{id}",
+		pathParams:     map[string]string{"id": id},
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FinishTrip ends the trip at the location, the trip begun with BeginTrip can
+// be ended too
+func (c *Client) FinishTrip(ctx context.Context, id string, location GeoLocation) (*Trip, error) {
+	resp := &Trip{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointFinishTrip,
+		method:         http.MethodPost,
+		path:           apiV2 + "/trips/{id}/end",
+		pathParams:     map[string]string{"id": id},
+		body:           FinishTripRequest{Location: location},
+		wantStatusCode: http.StatusOK,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListTripEvents returns the page of the events of the trip in order of
+// creation, the next page is requested with the NextCursor of the page
+func (c *Client) ListTripEvents(ctx context.Context, tripID string, query TripEventQuery) (*TripEventPage, error) {
+	resp := &TripEventPage{}
+	err := c.do(ctx, request{
+		endpoint:       EndpointTripEventsOfTrip,
+		method:         http.MethodGet,
+		path:           apiV2 + "/trips/{id}/events",
+		pathParams:     map[string]string{"id": tripID},
+		query:          query.params(),
+		wantStatusCode: http.StatusOK,
+		idempotent:     true,
+		result:         resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// params returns the query params of the set fields
+func (q ScooterQuery) params() map[string]string {
+	params := map[string]string{}
+	if q.Near != nil {
+		params["near"] = formatLocations(*q.Near)
+	}
+	if q.Radius > 0 {
+		params["radius"] = strconv.FormatFloat(q.Radius, 'f', -1, 64)
+	}
+	if q.BoundingBox != nil {
+		params["bbox"] = formatLocations(q.BoundingBox.SouthWest, q.BoundingBox.NorthEast)
+	}
+	if len(q.Polygon) > 0 {
+		params["polygon"] = formatLocations(q.Polygon...)
+	}
+	if q.VehicleType != "" {
+		params["vehicle_type"] = q.VehicleType
+	}
+	if q.MinBattery > 0 {
+		params["min_battery"] = strconv.Itoa(q.MinBattery)
+	}
+	setPageParams(params, q.Limit, q.Cursor)
+	return params
+}
+
+// params returns the query params of the set fields
+func (q TripEventQuery) params() map[string]string {
+	params := map[string]string{}
+	if q.Type != "" {
+		params["type"] = string(q.Type)
+	}
+	if !q.Since.IsZero() {
+		params["since"] = q.Since.Format(time.RFC3339Nano)
+	}
+	if !q.Until.IsZero() {
+		params["until"] = q.Until.Format(time.RFC3339Nano)
+	}
+	setPageParams(params, q.Limit, q.Cursor)
+	return params
+}
+
+// setPageParams sets the page query params if they are set
+func setPageParams(params map[string]string, limit int, cursor string) {
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+	if cursor != "" {
+		params["cursor"] = cursor
+	}
+}
+
+// formatLocations returns the locations as comma separated latitude,longitude
+// of each location
+func formatLocations(locations ...GeoLocation) string {
+	values := make([]string, 0, 2*len(locations))
+	for _, l := range locations {
+		values = append(values, strconv.FormatFloat(l.Latitude, 'f', -1, 64), strconv.FormatFloat(l.Longitude, 'f', -1, 64))
+	}
+	return strings.Join(values, ",")
+}
@@ -1,29 +1,451 @@
 package config
 
-import "github.com/ganeshdipdumbare/goenv"
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
-type EnvVar struct {
-	MongoUri           string `json:"mongo_uri"`
-	MongoDb            string `json:"mongo_db"`
-	Port               string `json:"port"`
-	MigrationFilesPath string `json:"migration_files_path"`
-	ApiKey             string `json:"api_key"`
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/tracing"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFileEnv is the env var of config file path, the --config flag overrides it
+	configFileEnv = "CONFIG_FILE"
+
+	// redacted replaces the secrets when the config is printed
+	redacted = "REDACTED"
+)
+
+// Config represents the configuration of the service, it is loaded from
+// defaults, config file, env vars and flags in increasing order of precedence
+type Config struct {
+	Server       ServerConfig       `mapstructure:"server" yaml:"server"`
+	Mongo        MongoConfig        `mapstructure:"mongo" yaml:"mongo"`
+	Migration    MigrationConfig    `mapstructure:"migration" yaml:"migration"`
+	TripEvent    TripEventConfig    `mapstructure:"trip_event" yaml:"trip_event"`
+	Wal          WalConfig          `mapstructure:"wal" yaml:"wal"`
+	ScooterIndex ScooterIndexConfig `mapstructure:"scooter_index" yaml:"scooter_index"`
+	Log          LogConfig          `mapstructure:"log" yaml:"log"`
+	Tracing      TracingConfig      `mapstructure:"tracing" yaml:"tracing"`
+	Simulator    SimulatorConfig    `mapstructure:"simulator" yaml:"simulator"`
+
+	// file is the path of config file, empty if the config is not loaded from file
+	file string
+}
+
+type ServerConfig struct {
+	Port   string `mapstructure:"port" yaml:"port"`
+	ApiKey string `mapstructure:"api_key" yaml:"api_key"`
+	// AdminApiKey authenticates the admin requests e.g. dead letter events,
+	// it must differ from the api key of the scooters and users
+	AdminApiKey string `mapstructure:"admin_api_key" yaml:"admin_api_key"`
+	// DrainDelay is how long readiness fails before the server stops
+	// accepting requests during shutdown
+	DrainDelay time.Duration `mapstructure:"drain_delay" yaml:"drain_delay"`
+	// ShutdownTimeout is the max time to wait for in-flight requests
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" yaml:"shutdown_timeout"`
+	// AppStopTimeout is the max time to wait for app to save queued trip events
+	AppStopTimeout time.Duration `mapstructure:"app_stop_timeout" yaml:"app_stop_timeout"`
+}
+
+type MongoConfig struct {
+	Uri string `mapstructure:"uri" yaml:"uri"`
+	Db  string `mapstructure:"db" yaml:"db"`
+	// ConnectTimeout is the timeout of establishing a connection
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout" yaml:"connect_timeout"`
+	// MaxPoolSize is the max number of connections, zero is driver default
+	MaxPoolSize uint64 `mapstructure:"max_pool_size" yaml:"max_pool_size"`
+}
+
+type MigrationConfig struct {
+	// FilesPath is the source url of migrations e.g. file://migration, empty
+	// value uses the migrations embedded in the binary
+	FilesPath string `mapstructure:"files_path" yaml:"files_path"`
+	// Auto migrates the database up when the server is started
+	Auto bool `mapstructure:"auto" yaml:"auto"`
+}
+
+type TripEventConfig struct {
+	// IngestionMode is either sync or async, in async mode trip events are
+	// queued and saved in batches in background
+	IngestionMode string `mapstructure:"ingestion_mode" yaml:"ingestion_mode"`
+	// Retention is how long trip events are kept, zero keeps them forever
+	Retention   time.Duration     `mapstructure:"retention" yaml:"retention"`
+	WriteBehind WriteBehindConfig `mapstructure:"write_behind" yaml:"write_behind"`
+}
+
+// WriteBehindConfig represents the write behind pipeline used in async
+// ingestion mode
+type WriteBehindConfig struct {
+	QueueSize     int           `mapstructure:"queue_size" yaml:"queue_size"`
+	BatchSize     int           `mapstructure:"batch_size" yaml:"batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval" yaml:"flush_interval"`
+	FlushTimeout  time.Duration `mapstructure:"flush_timeout" yaml:"flush_timeout"`
+	Workers       int           `mapstructure:"workers" yaml:"workers"`
+}
+
+type WalConfig struct {
+	// Dir is the dir of write ahead log used to keep trip events during
+	// database outages, empty value disables it
+	Dir            string        `mapstructure:"dir" yaml:"dir"`
+	MaxSegmentSize int64         `mapstructure:"max_segment_size" yaml:"max_segment_size"`
+	ReplayInterval time.Duration `mapstructure:"replay_interval" yaml:"replay_interval"`
+}
+
+type ScooterIndexConfig struct {
+	// Enabled answers the nearby scooter searches from in memory index of
+	// available scooters instead of the database
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// CellSize is the size in degrees of the grid cells of the index
+	CellSize float64 `mapstructure:"cell_size" yaml:"cell_size"`
+	// ResyncInterval is how often all the scooters are loaded into the index
+	ResyncInterval time.Duration `mapstructure:"resync_interval" yaml:"resync_interval"`
+}
+
+type LogConfig struct {
+	// Format is json or text
+	Format string `mapstructure:"format" yaml:"format"`
+	// Level is one of panic, fatal, error, warn, info, debug or trace
+	Level string `mapstructure:"level" yaml:"level"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp, the otlp exporter is configured
+	// with OTEL_EXPORTER_OTLP_* env vars
+	Exporter string `mapstructure:"exporter" yaml:"exporter"`
+}
+
+type SimulatorConfig struct {
+	// BaseURL is the url of the service the simulated users travel with,
+	// it is used if the scenario file has no base url
+	BaseURL string `mapstructure:"base_url" yaml:"base_url"`
+	// ScenarioFile is the path of simulation scenario file, empty uses the
+	// default scenario
+	ScenarioFile string `mapstructure:"scenario_file" yaml:"scenario_file"`
 }
 
 var (
-	envVars = &EnvVar{
-		Port:               "8080",
-		MongoDb:            "scootin-aboot-db",
-		MigrationFilesPath: "file://migration",
-		MongoUri:           "mongodb://localhost:27017",
-		ApiKey:             "secretkey",
+	defaults = map[string]interface{}{
+		"server.port":                            "8080",
+		"server.api_key":                         "secretkey",
+		"server.admin_api_key":                   "adminsecretkey",
+		"server.drain_delay":                     5 * time.Second,
+		"server.shutdown_timeout":                3 * time.Second,
+		"server.app_stop_timeout":                30 * time.Second,
+		"mongo.uri":                              "mongodb://localhost:27017",
+		"mongo.db":                               "scootin-aboot-db",
+		"mongo.connect_timeout":                  10 * time.Second,
+		"mongo.max_pool_size":                    0,
+		"migration.files_path":                   "",
+		"migration.auto":                         true,
+		"trip_event.ingestion_mode":              "sync",
+		"trip_event.retention":                   time.Duration(0),
+		"trip_event.write_behind.queue_size":     10000,
+		"trip_event.write_behind.batch_size":     100,
+		"trip_event.write_behind.flush_interval": time.Second,
+		"trip_event.write_behind.flush_timeout":  10 * time.Second,
+		"trip_event.write_behind.workers":        4,
+		"wal.dir":                                "wal",
+		"wal.max_segment_size":                   64 << 20,
+		"wal.replay_interval":                    5 * time.Second,
+		"scooter_index.enabled":                  false,
+		"scooter_index.cell_size":                0.01,
+		"scooter_index.resync_interval":          5 * time.Minute,
+		"log.format":                             logger.FormatJSON,
+		"log.level":                              "info",
+		"tracing.exporter":                       tracing.ExporterNone,
+		"simulator.base_url":                     "http://localhost:8080",
+		"simulator.scenario_file":                "",
 	}
+
+	// envAliases are the env vars of keys which do not follow section_key
+	// naming, they are kept for backward compatibility
+	envAliases = map[string]string{
+		"server.port":    "PORT",
+		"server.api_key": "API_KEY",
+	}
+
+	// current is the loaded *Config, it is replaced on reload
+	current atomic.Value
+	// loadArgs are the args given to Load, they are used again on reload
+	loadArgs []string
 )
 
 func init() {
-	goenv.SyncEnvVar(&envVars)
+	current.Store(&Config{})
+}
+
+// Load loads the config from defaults, config file, env vars and flags in
+// args in increasing order of precedence and validates it. The config file
+// is given with --config flag or CONFIG_FILE env var, yaml, toml and json
+// files are supported. The env var of key is its upper case name with dots
+// replaced by underscores e.g. MONGO_URI and the flag is its name with dots
+// and underscores replaced by dashes e.g. --mongo-uri. Returns the
+// remaining positional args, otherwise error
+func Load(args []string) ([]string, error) {
+	c, positional, err := load(args)
+	if err != nil {
+		return nil, err
+	}
+
+	loadArgs = args
+	current.Store(c)
+	return positional, nil
+}
+
+// Reload loads the config again from the config file, env vars and the flags
+// given to Load. Only the reloadable settings i.e. server.api_key,
+// server.admin_api_key and log.level are applied, restartRequired is true if other settings are
+// changed. The invalid config is rejected and the current config is kept
+func Reload() (c *Config, restartRequired bool, err error) {
+	loaded, _, err := load(loadArgs)
+	if err != nil {
+		return nil, false, err
+	}
+
+	old := Get()
+	reloaded := *old
+	reloaded.Server.ApiKey = loaded.Server.ApiKey
+	reloaded.Server.AdminApiKey = loaded.Server.AdminApiKey
+	reloaded.Log.Level = loaded.Log.Level
+
+	current.Store(&reloaded)
+	return &reloaded, !reflect.DeepEqual(withoutReloadable(old), withoutReloadable(loaded)), nil
+}
+
+// withoutReloadable returns the copy of the config with the reloadable
+// settings and the config file path cleared, so that the configs which differ
+// only in them are equal
+func withoutReloadable(c *Config) Config {
+	masked := *c
+	masked.Server.ApiKey = ""
+	masked.Server.AdminApiKey = ""
+	masked.Log.Level = ""
+	masked.file = ""
+	return masked
+}
+
+// load loads and validates the config, returns the config and the
+// positional args, otherwise error
+func load(args []string) (*Config, []string, error) {
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	// empty env var is a value e.g. empty WAL_DIR disables write ahead log
+	v.AllowEmptyEnv(true)
+	v.AutomaticEnv()
+	for key, env := range envAliases {
+		err := v.BindEnv(key, env)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fs := pflag.NewFlagSet("scootin-aboot-journey", pflag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv(configFileEnv), "config file (yaml, toml or json)")
+	for _, key := range keys() {
+		name := flagName(key)
+		fs.String(name, fmt.Sprint(defaults[key]), key)
+		err := v.BindPFlag(key, fs.Lookup(name))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile != "" {
+		v.SetConfigFile(*configFile)
+		err := v.ReadInConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read config file %v: %w", *configFile, err)
+		}
+	}
+
+	c := &Config{file: *configFile}
+	err = v.UnmarshalExact(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, fs.Args(), nil
+}
+
+// Get returns the loaded config
+func Get() *Config {
+	return current.Load().(*Config)
+}
+
+// File returns the path of config file, empty if the config is not loaded
+// from file
+func (c *Config) File() string {
+	return c.file
+}
+
+// Validate returns error which lists all the invalid values of the config
+func (c *Config) Validate() error {
+	problems := []string{}
+	invalid := func(key string, format string, args ...interface{}) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	if err != nil || port < 1 || port > 65535 {
+		invalid("server.port", "%q is not a valid port", c.Server.Port)
+	}
+	if c.Server.ApiKey == "" {
+		invalid("server.api_key", "must not be empty")
+	}
+	if c.Server.AdminApiKey == "" {
+		invalid("server.admin_api_key", "must not be empty")
+	} else if c.Server.AdminApiKey == c.Server.ApiKey {
+		invalid("server.admin_api_key", "must differ from server.api_key")
+	}
+	if c.Server.DrainDelay < 0 {
+		invalid("server.drain_delay", "must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout", "must be positive")
+	}
+	if c.Server.AppStopTimeout <= 0 {
+		invalid("server.app_stop_timeout", "must be positive")
+	}
+
+	if _, err := connstring.ParseAndValidate(c.Mongo.Uri); err != nil {
+		invalid("mongo.uri", "%v", err)
+	}
+	if c.Mongo.Db == "" {
+		invalid("mongo.db", "must not be empty")
+	}
+	if c.Mongo.ConnectTimeout <= 0 {
+		invalid("mongo.connect_timeout", "must be positive")
+	}
+
+	if u, err := url.Parse(c.Simulator.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		invalid("simulator.base_url", "%q is not a valid url", c.Simulator.BaseURL)
+	}
+
+	switch c.TripEvent.IngestionMode {
+	case "sync", "async":
+	default:
+		invalid("trip_event.ingestion_mode", "%q is not one of sync or async", c.TripEvent.IngestionMode)
+	}
+	if c.TripEvent.Retention < 0 {
+		invalid("trip_event.retention", "must not be negative")
+	}
+	writeBehind := c.TripEvent.WriteBehind
+	if writeBehind.QueueSize <= 0 {
+		invalid("trip_event.write_behind.queue_size", "must be positive")
+	}
+	if writeBehind.BatchSize <= 0 {
+		invalid("trip_event.write_behind.batch_size", "must be positive")
+	}
+	if writeBehind.FlushInterval <= 0 {
+		invalid("trip_event.write_behind.flush_interval", "must be positive")
+	}
+	if writeBehind.FlushTimeout <= 0 {
+		invalid("trip_event.write_behind.flush_timeout", "must be positive")
+	}
+	if writeBehind.Workers <= 0 {
+		invalid("trip_event.write_behind.workers", "must be positive")
+	}
+
+	if c.Wal.MaxSegmentSize <= 0 {
+		invalid("wal.max_segment_size", "must be positive")
+	}
+	if c.Wal.ReplayInterval <= 0 {
+		invalid("wal.replay_interval", "must be positive")
+	}
+
+	if c.ScooterIndex.CellSize <= 0 || c.ScooterIndex.CellSize > 180 {
+		invalid("scooter_index.cell_size", "must be positive and at most 180")
+	}
+	if c.ScooterIndex.ResyncInterval <= 0 {
+		invalid("scooter_index.resync_interval", "must be positive")
+	}
+
+	switch c.Log.Format {
+	case logger.FormatJSON, logger.FormatText:
+	default:
+		invalid("log.format", "%q is not one of json or text", c.Log.Format)
+	}
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		invalid("log.level", "%v", err)
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		invalid("tracing.exporter", "%q is not one of none, stdout or otlp", c.Tracing.Exporter)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  %v", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Print writes the config as yaml, the secrets are redacted
+func (c *Config) Print(w io.Writer) error {
+	printed := *c
+	printed.Server.ApiKey = redacted
+	printed.Server.AdminApiKey = redacted
+	printed.Mongo.Uri = redactUri(c.Mongo.Uri)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(printed)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// redactUri returns the uri with the password redacted
+func redactUri(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return redacted
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
+
+// keys returns the sorted config keys
+func keys() []string {
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-func Get() *EnvVar {
-	return envVars
+// flagName returns the flag name of config key
+func flagName(key string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(key)
 }
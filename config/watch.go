@@ -0,0 +1,90 @@
+package config
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// watchDebounce is the time to wait for more writes before the change is
+	// reported, editors often write the file in several steps
+	watchDebounce = 100 * time.Millisecond
+)
+
+// Watcher reports the changes of config file
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	changes chan struct{}
+	done    chan struct{}
+}
+
+// WatchFile watches the given config file, the dir of the file is watched so
+// that the file replaced by editors is also reported. Returns error if the
+// dir can not be watched
+func WatchFile(file string) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	file = filepath.Clean(file)
+	err = fsWatcher.Add(filepath.Dir(file))
+	if err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		watcher: fsWatcher,
+		changes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go w.watch(file)
+	return w, nil
+}
+
+// Changes returns the channel which receives once the file is changed
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching the file
+func (w *Watcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+// watch reports the write, create and rename events of the file after
+// debounce
+func (w *Watcher) watch(file string) {
+	defer close(w.done)
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != file ||
+				event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			debounce.Reset(watchDebounce)
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-debounce.C:
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(file, []byte("log:\n  level: info\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := WatchFile(file)
+	if err != nil {
+		t.Fatalf("WatchFile() error = %v", err)
+	}
+	defer w.Close()
+
+	// other files in the dir are ignored
+	err = os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("other"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Changes():
+		t.Fatalf("Changes() received for other file")
+	case <-time.After(3 * watchDebounce):
+	}
+
+	// the file replaced by editor is reported
+	tmp := filepath.Join(dir, "config.yaml.tmp")
+	err = os.WriteFile(tmp, []byte("log:\n  level: debug\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Rename(tmp, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Changes():
+	case <-time.After(5 * time.Second):
+		t.Errorf("Changes() not received after file is replaced")
+	}
+}
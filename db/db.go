@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 )
@@ -11,6 +12,7 @@ var (
 	ErrInvalidArg     = errors.New("invalid argument")
 	ErrEmptyArg       = errors.New("empty argument not allowed")
 	ErrRecordNotFound = errors.New("record not found")
+	ErrUnavailable    = errors.New("database unavailable")
 )
 
 //go:generate mockgen -destination=../mocks/mock_db.go -package=mocks github.com/ganeshdipdumbare/scootin-aboot-journey/db DB
@@ -18,14 +20,66 @@ var (
 type DB interface {
 	// scooter functions
 	GetAvailableScootersWithinRadius(ctx context.Context, location *domain.GeoLocation, radius int) ([]domain.Scooter, error)
+	// SearchAvailableScooters returns the available scooters matching the
+	// query, nearest first if the query has near location
+	SearchAvailableScooters(ctx context.Context, query *domain.ScooterQuery) ([]domain.ScooterMatch, error)
+	// GetAvailableScooterClusters returns the available scooters within the
+	// bounding box grouped by the cells of the grid of given size in degrees
+	GetAvailableScooterClusters(ctx context.Context, boundingBox *domain.BoundingBox, cellSize float64) ([]domain.ScooterCluster, error)
 	GetScooterByID(ctx context.Context, scooterID string) (*domain.Scooter, error)
 	UpdateScooter(ctx context.Context, updatedScooter *domain.Scooter) (*domain.Scooter, error)
+	// ReserveScooter sets the user as current user of the scooter and makes
+	// it unavailable only if it is available, it returns the updated scooter
+	// or ErrRecordNotFound if no available scooter has the id
+	ReserveScooter(ctx context.Context, scooterID string, userID string) (*domain.Scooter, error)
+	// UpdateScooterLocation sets the location of the scooter in trip of the
+	// user at given time if the current location is older, it returns the
+	// updated scooter or nil if the scooter is not updated
+	UpdateScooterLocation(ctx context.Context, scooterID string, userID string, location domain.GeoLocation, at time.Time) (*domain.Scooter, error)
 	GetAllScooters(ctx context.Context) ([]domain.Scooter, error)
+	// CountScooters returns the number of available scooters and the number
+	// of scooters in trip
+	CountScooters(ctx context.Context) (available int64, inTrip int64, err error)
+	UpsertScooters(ctx context.Context, scooters []domain.Scooter) error
+	// WatchScooters calls onChange with the scooter after each change of the
+	// scooters until the context is done or the watch fails
+	WatchScooters(ctx context.Context, onChange func(scooter *domain.Scooter)) error
 	InsertTripEvent(ctx context.Context, event *domain.TripEvent) error
+	InsertTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error)
 	GetAllTripEvents(ctx context.Context) ([]domain.TripEvent, error)
+	GetTripEventsByScooterIDs(ctx context.Context, scooterIDs []string) ([]domain.TripEvent, error)
+
+	// GetScooterTripEvents returns the trip events of the scooter created
+	// from the time until the time, in order of creation
+	GetScooterTripEvents(ctx context.Context, scooterID string, from time.Time, to time.Time) ([]domain.TripEvent, error)
+	// GetTripEventHeat returns the number of trip events created since the
+	// time within the bounding box grouped by the cells of the grid of given
+	// size in degrees
+	GetTripEventHeat(ctx context.Context, boundingBox *domain.BoundingBox, since time.Time, cellSize float64) ([]domain.HeatCell, error)
+
+	// trip functions
+	InsertTrip(ctx context.Context, trip *domain.Trip) error
+	GetTripByID(ctx context.Context, tripID string) (*domain.Trip, error)
+	// GetActiveTripByScooterID returns the trip of the scooter which has not
+	// ended, ErrRecordNotFound if there is none
+	GetActiveTripByScooterID(ctx context.Context, scooterID string) (*domain.Trip, error)
+	UpdateTrip(ctx context.Context, trip *domain.Trip) (*domain.Trip, error)
+
+	// dead letter functions
+	InsertDeadLetterEvent(ctx context.Context, event *domain.DeadLetterEvent) error
+	GetAllDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error)
+	GetDeadLetterEventByID(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error)
+	UpdateDeadLetterEvent(ctx context.Context, event *domain.DeadLetterEvent) (*domain.DeadLetterEvent, error)
+	// ClaimDeadLetterEvent sets the replayed at time of the dead letter event
+	// only if it is not replayed yet and returns the claimed event,
+	// ErrRecordNotFound if there is no such event
+	ClaimDeadLetterEvent(ctx context.Context, eventID string, claimedAt time.Time) (*domain.DeadLetterEvent, error)
 
 	// user functions
 	GetAllUsers(ctx context.Context) ([]domain.User, error)
+	UpsertUsers(ctx context.Context, users []domain.User) error
 
+	// Ping returns error if the database is not reachable
+	Ping(ctx context.Context) error
 	Disconnect(ctx context.Context) error
 }
@@ -0,0 +1,244 @@
+package geoindex
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultCellSize is about 1 km of latitude
+	DefaultCellSize       = 0.01
+	DefaultResyncInterval = 5 * time.Minute
+)
+
+// DB is the database which answers the nearby scooter searches from in
+// memory index of available scooters
+type DB interface {
+	db.DB
+	// Start loads the scooters into the index and keeps the index current in
+	// background, it returns error if the scooters could not be loaded
+	Start(ctx context.Context) error
+	// Stop stops keeping the index current
+	Stop()
+	// Len returns the number of available scooters in the index
+	Len() int
+	// LastResync returns the time the scooters were last loaded, zero time
+	// if they were never loaded
+	LastResync() time.Time
+}
+
+// indexDetails wraps the database, the scooters saved through it and the
+// changes of the scooters watched in the database are put in the index. All
+// the scooters are loaded again periodically in case a change was missed e.g.
+// the database does not support watching changes.
+type indexDetails struct {
+	db.DB
+	index          *Index
+	resyncInterval time.Duration
+	loaded         int32
+	lastResync     int64
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	logger         logrus.FieldLogger
+}
+
+// Option configures optional behaviour of the database
+type Option func(i *indexDetails)
+
+// WithLogger sets the logger used by background resync and watch
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(i *indexDetails) {
+		i.logger = l
+	}
+}
+
+// NewDB creates new database which indexes the scooters of given database in
+// the grid of cell size in degrees, the index is loaded again every resync interval
+func NewDB(database db.DB, cellSize float64, resyncInterval time.Duration, opts ...Option) (DB, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database: %w", db.ErrInvalidArg)
+	}
+
+	if resyncInterval <= 0 {
+		return nil, fmt.Errorf("resyncInterval: %w", db.ErrInvalidArg)
+	}
+
+	index, err := NewIndex(cellSize)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, db.ErrInvalidArg)
+	}
+
+	i := &indexDetails{
+		DB:             database,
+		index:          index,
+		resyncInterval: resyncInterval,
+		cancel:         func() {},
+		logger:         logger.Discard(),
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i, nil
+}
+
+// GetAvailableScootersWithinRadius returns available scooters which are
+// within radius from the location in nearest first sorted order, from the
+// index once it is loaded and from the database before
+func (i *indexDetails) GetAvailableScootersWithinRadius(ctx context.Context, location *domain.GeoLocation, radius int) ([]domain.Scooter, error) {
+	if atomic.LoadInt32(&i.loaded) == 0 {
+		return i.DB.GetAvailableScootersWithinRadius(ctx, location, radius)
+	}
+
+	if location == nil {
+		return nil, fmt.Errorf("location: %w", db.ErrInvalidArg)
+	}
+
+	if radius <= 0 {
+		return nil, fmt.Errorf("radius: %w", db.ErrInvalidArg)
+	}
+
+	return i.index.Nearby(*location, float64(radius)), nil
+}
+
+// UpdateScooter updates the scooter in the database and the index
+func (i *indexDetails) UpdateScooter(ctx context.Context, scooter *domain.Scooter) (*domain.Scooter, error) {
+	updated, err := i.DB.UpdateScooter(ctx, scooter)
+	if err != nil {
+		return nil, err
+	}
+	i.index.Put(*updated)
+	return updated, nil
+}
+
+// ReserveScooter reserves the scooter in the database and updates it in the
+// index
+func (i *indexDetails) ReserveScooter(ctx context.Context, scooterID string, userID string) (*domain.Scooter, error) {
+	reserved, err := i.DB.ReserveScooter(ctx, scooterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	i.index.Put(*reserved)
+	return reserved, nil
+}
+
+// UpdateScooterLocation updates the location of the scooter in the database
+// and the index
+func (i *indexDetails) UpdateScooterLocation(ctx context.Context, scooterID string, userID string, location domain.GeoLocation, at time.Time) (*domain.Scooter, error) {
+	updated, err := i.DB.UpdateScooterLocation(ctx, scooterID, userID, location, at)
+	if err != nil || updated == nil {
+		return updated, err
+	}
+	i.index.Put(*updated)
+	return updated, nil
+}
+
+// UpsertScooters upserts the scooters in the database and the index
+func (i *indexDetails) UpsertScooters(ctx context.Context, scooters []domain.Scooter) error {
+	err := i.DB.UpsertScooters(ctx, scooters)
+	if err != nil {
+		return err
+	}
+	for _, scooter := range scooters {
+		i.index.Put(scooter)
+	}
+	return nil
+}
+
+// Start loads the scooters into the index, then watches the changes of the
+// scooters and loads them again every resync interval in background
+func (i *indexDetails) Start(ctx context.Context) error {
+	err := i.resync(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load scooters into index: %w", err)
+	}
+	atomic.StoreInt32(&i.loaded, 1)
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+
+	i.wg.Add(2)
+	go func() {
+		defer i.wg.Done()
+		i.watch(watchCtx)
+	}()
+	go func() {
+		defer i.wg.Done()
+
+		ticker := time.NewTicker(i.resyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+				resyncCtx, cancel := context.WithTimeout(watchCtx, i.resyncInterval)
+				err := i.resync(resyncCtx)
+				cancel()
+				if err != nil && watchCtx.Err() == nil {
+					i.logger.WithError(err).Warn("scooter index resync failed")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the watch and the resync of the index
+func (i *indexDetails) Stop() {
+	i.cancel()
+	i.wg.Wait()
+}
+
+// Len returns the number of available scooters in the index
+func (i *indexDetails) Len() int {
+	return i.index.Len()
+}
+
+// LastResync returns the time the scooters were last loaded
+func (i *indexDetails) LastResync() time.Time {
+	nanos := atomic.LoadInt64(&i.lastResync)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
+
+// resync loads all the scooters into the index
+func (i *indexDetails) resync(ctx context.Context) error {
+	err := i.index.Replace(func() ([]domain.Scooter, error) {
+		return i.DB.GetAllScooters(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt64(&i.lastResync, time.Now().UnixNano())
+	return nil
+}
+
+// watch puts the changed scooters in the index until the context is done,
+// the watch is started again after resync interval if it fails
+func (i *indexDetails) watch(ctx context.Context) {
+	for {
+		err := i.DB.WatchScooters(ctx, func(scooter *domain.Scooter) {
+			i.index.Put(*scooter)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		i.logger.WithError(err).Warnf("scooter change watch stopped, retrying in %v", i.resyncInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(i.resyncInterval):
+		}
+	}
+}
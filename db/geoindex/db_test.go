@@ -0,0 +1,228 @@
+package geoindex
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func newTestDB(t *testing.T) (*mocks.MockDB, *indexDetails) {
+	t.Helper()
+	database := mocks.NewMockDB(gomock.NewController(t))
+
+	i, err := NewDB(database, DefaultCellSize, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	return database, i.(*indexDetails)
+}
+
+func TestNewDB(t *testing.T) {
+	database := mocks.NewMockDB(gomock.NewController(t))
+
+	type args struct {
+		database       db.DB
+		cellSize       float64
+		resyncInterval time.Duration
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "should return error for nil database",
+			args: args{
+				cellSize:       DefaultCellSize,
+				resyncInterval: time.Second,
+			},
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name: "should return error for invalid cell size",
+			args: args{
+				database:       database,
+				resyncInterval: time.Second,
+			},
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name: "should return error for invalid resync interval",
+			args: args{
+				database: database,
+				cellSize: DefaultCellSize,
+			},
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name: "should create database",
+			args: args{
+				database:       database,
+				cellSize:       DefaultCellSize,
+				resyncInterval: time.Second,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDB(tt.args.database, tt.args.cellSize, tt.args.resyncInterval)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewDB() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetAvailableScootersWithinRadius(t *testing.T) {
+	database, i := newTestDB(t)
+	ctx := context.Background()
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	scooter := domain.Scooter{ID: "scooterid", Location: location, IsAvailable: true}
+
+	// the database is queried until the index is loaded
+	database.EXPECT().GetAvailableScootersWithinRadius(ctx, &location, 100).Return([]domain.Scooter{scooter}, nil).Times(1)
+	got, err := i.GetAvailableScootersWithinRadius(ctx, &location, 100)
+	if err != nil || !reflect.DeepEqual(got, []domain.Scooter{scooter}) {
+		t.Fatalf("GetAvailableScootersWithinRadius() = %v, %v, want scooter from database", got, err)
+	}
+
+	watching := make(chan struct{})
+	database.EXPECT().GetAllScooters(gomock.Any()).Return([]domain.Scooter{scooter}, nil).Times(1)
+	database.EXPECT().WatchScooters(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, onChange func(*domain.Scooter)) error {
+		moved := scooter
+		moved.ID = "moved"
+		onChange(&moved)
+		close(watching)
+		<-ctx.Done()
+		return nil
+	}).Times(1)
+
+	err = i.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Stop()
+	<-watching
+
+	if i.LastResync().IsZero() {
+		t.Errorf("LastResync() is zero after start")
+	}
+
+	got, err = i.GetAvailableScootersWithinRadius(ctx, &location, 100)
+	if err != nil || !reflect.DeepEqual(scooterIDs(got), []string{"moved", "scooterid"}) {
+		t.Errorf("GetAvailableScootersWithinRadius() = %v, %v, want loaded and watched scooters", got, err)
+	}
+
+	_, err = i.GetAvailableScootersWithinRadius(ctx, nil, 100)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetAvailableScootersWithinRadius() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	_, err = i.GetAvailableScootersWithinRadius(ctx, &location, 0)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetAvailableScootersWithinRadius() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+}
+
+func TestStartLoadFailed(t *testing.T) {
+	database, i := newTestDB(t)
+	ctx := context.Background()
+
+	database.EXPECT().GetAllScooters(gomock.Any()).Return(nil, db.ErrUnavailable).Times(1)
+	err := i.Start(ctx)
+	if !errors.Is(err, db.ErrUnavailable) {
+		t.Errorf("Start() error = %v, want %v", err, db.ErrUnavailable)
+	}
+	i.Stop()
+}
+
+func TestUpdateScooter(t *testing.T) {
+	database, i := newTestDB(t)
+	ctx := context.Background()
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	scooter := domain.Scooter{ID: "scooterid", Location: location, IsAvailable: true}
+	userID := "userid"
+	inUse := domain.Scooter{ID: "scooterid", Location: location, CurrentUserID: &userID}
+
+	database.EXPECT().UpsertScooters(ctx, []domain.Scooter{scooter}).Return(nil).Times(1)
+	err := i.UpsertScooters(ctx, []domain.Scooter{scooter})
+	if err != nil || i.Len() != 1 {
+		t.Fatalf("UpsertScooters() error = %v len = %v, want scooter in index", err, i.Len())
+	}
+
+	database.EXPECT().UpdateScooter(ctx, &inUse).Return(nil, db.ErrUnavailable).Times(1)
+	_, err = i.UpdateScooter(ctx, &inUse)
+	if !errors.Is(err, db.ErrUnavailable) || i.Len() != 1 {
+		t.Fatalf("UpdateScooter() error = %v len = %v, want scooter kept in index", err, i.Len())
+	}
+
+	database.EXPECT().UpdateScooter(ctx, &inUse).Return(&inUse, nil).Times(1)
+	_, err = i.UpdateScooter(ctx, &inUse)
+	if err != nil || i.Len() != 0 {
+		t.Fatalf("UpdateScooter() error = %v len = %v, want scooter removed from index", err, i.Len())
+	}
+}
+
+func TestReserveScooter(t *testing.T) {
+	database, i := newTestDB(t)
+	ctx := context.Background()
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	scooter := domain.Scooter{ID: "scooterid", Location: location, IsAvailable: true}
+	userID := "userid"
+	inUse := domain.Scooter{ID: "scooterid", Location: location, CurrentUserID: &userID}
+
+	database.EXPECT().UpsertScooters(ctx, []domain.Scooter{scooter}).Return(nil).Times(1)
+	err := i.UpsertScooters(ctx, []domain.Scooter{scooter})
+	if err != nil || i.Len() != 1 {
+		t.Fatalf("UpsertScooters() error = %v len = %v, want scooter in index", err, i.Len())
+	}
+
+	database.EXPECT().ReserveScooter(ctx, "scooterid", userID).Return(nil, db.ErrRecordNotFound).Times(1)
+	_, err = i.ReserveScooter(ctx, "scooterid", userID)
+	if !errors.Is(err, db.ErrRecordNotFound) || i.Len() != 1 {
+		t.Fatalf("ReserveScooter() error = %v len = %v, want scooter kept in index", err, i.Len())
+	}
+
+	database.EXPECT().ReserveScooter(ctx, "scooterid", userID).Return(&inUse, nil).Times(1)
+	_, err = i.ReserveScooter(ctx, "scooterid", userID)
+	if err != nil || i.Len() != 0 {
+		t.Fatalf("ReserveScooter() error = %v len = %v, want scooter removed from index", err, i.Len())
+	}
+}
+
+func TestUpdateScooterLocation(t *testing.T) {
+	database, i := newTestDB(t)
+	ctx := context.Background()
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	moved := domain.GeoLocation{Latitude: 52.53, Longitude: 13.41}
+	now := time.Now().UTC()
+	scooter := domain.Scooter{ID: "scooterid", Location: location, IsAvailable: true}
+	userID := "userid"
+	inTrip := domain.Scooter{ID: "scooterid", Location: moved, CurrentUserID: &userID, LocationUpdatedAt: now}
+
+	database.EXPECT().UpsertScooters(ctx, []domain.Scooter{scooter}).Return(nil).Times(1)
+	err := i.UpsertScooters(ctx, []domain.Scooter{scooter})
+	if err != nil || i.Len() != 1 {
+		t.Fatalf("UpsertScooters() error = %v len = %v, want scooter in index", err, i.Len())
+	}
+
+	// the scooter which is not updated is kept as it is
+	database.EXPECT().UpdateScooterLocation(ctx, "scooterid", userID, moved, now).Return(nil, nil).Times(1)
+	_, err = i.UpdateScooterLocation(ctx, "scooterid", userID, moved, now)
+	if err != nil || i.Len() != 1 {
+		t.Fatalf("UpdateScooterLocation() error = %v len = %v, want scooter kept in index", err, i.Len())
+	}
+
+	database.EXPECT().UpdateScooterLocation(ctx, "scooterid", userID, moved, now).Return(&inTrip, nil).Times(1)
+	_, err = i.UpdateScooterLocation(ctx, "scooterid", userID, moved, now)
+	if err != nil || i.Len() != 0 {
+		t.Fatalf("UpdateScooterLocation() error = %v len = %v, want scooter in trip removed from index", err, i.Len())
+	}
+}
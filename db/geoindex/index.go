@@ -0,0 +1,253 @@
+package geoindex
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	// earthRadius is the radius in meters mongo uses for spherical distance
+	earthRadius = 6378100
+	// metersPerDegree is the length of a degree of latitude
+	metersPerDegree = earthRadius * math.Pi / 180
+)
+
+// cell is the position of the grid cell, the latitude and the longitude
+// divided by the cell size
+type cell struct {
+	latitude  int
+	longitude int
+}
+
+// entry is the scooter in the index, position is its index in the scooters
+// of its cell
+type entry struct {
+	scooter  domain.Scooter
+	cell     cell
+	position int
+}
+
+// Index is the grid of available scooter locations with cells of cellSize
+// degrees. The unavailable scooters are not kept, the nearby search looks up
+// the cells overlapping the radius and scans the scooters when the radius
+// covers more cells than there are scooters.
+type Index struct {
+	cellSize float64
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+	cells   map[cell][]*entry
+	// changed has the scooters put during replace, they are newer than the
+	// scooters loaded for the replace
+	changed map[string]struct{}
+
+	// replaceMu makes sure only one replace runs at a time
+	replaceMu sync.Mutex
+}
+
+// NewIndex creates empty index with the grid of given cell size in degrees
+func NewIndex(cellSize float64) (*Index, error) {
+	if cellSize <= 0 || cellSize > 180 {
+		return nil, fmt.Errorf("invalid cell size: %v", cellSize)
+	}
+
+	return &Index{
+		cellSize: cellSize,
+		entries:  map[string]*entry{},
+		cells:    map[cell][]*entry{},
+	}, nil
+}
+
+// Len returns the number of available scooters in the index
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.entries)
+}
+
+// Put adds the scooter to the index if it is available and removes it
+// otherwise
+func (i *Index) Put(scooter domain.Scooter) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.changed != nil {
+		i.changed[scooter.ID] = struct{}{}
+	}
+	i.put(scooter)
+}
+
+func (i *Index) put(scooter domain.Scooter) {
+	if e, ok := i.entries[scooter.ID]; ok {
+		delete(i.entries, scooter.ID)
+		i.removeFromCell(e)
+	}
+
+	if !scooter.IsAvailable {
+		return
+	}
+
+	c := i.cellOf(scooter.Location)
+	e := &entry{scooter: scooter, cell: c, position: len(i.cells[c])}
+	i.entries[scooter.ID] = e
+	i.cells[c] = append(i.cells[c], e)
+}
+
+// removeFromCell removes the entry from the scooters of its cell, the last
+// scooter of the cell takes its position
+func (i *Index) removeFromCell(e *entry) {
+	entries := i.cells[e.cell]
+	last := entries[len(entries)-1]
+	entries[e.position] = last
+	last.position = e.position
+	entries[len(entries)-1] = nil
+	entries = entries[:len(entries)-1]
+	if len(entries) == 0 {
+		delete(i.cells, e.cell)
+		return
+	}
+	i.cells[e.cell] = entries
+}
+
+// Replace replaces the scooters of the index with the loaded scooters. The
+// index answers the searches while the scooters are loaded, and the scooters
+// put during the load are kept as they are newer than the loaded ones.
+func (i *Index) Replace(load func() ([]domain.Scooter, error)) error {
+	i.replaceMu.Lock()
+	defer i.replaceMu.Unlock()
+
+	i.mu.Lock()
+	i.changed = map[string]struct{}{}
+	i.mu.Unlock()
+
+	scooters, err := load()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	changed := i.changed
+	i.changed = nil
+	if err != nil {
+		return err
+	}
+
+	current := i.entries
+	i.entries = map[string]*entry{}
+	i.cells = map[cell][]*entry{}
+	for _, scooter := range scooters {
+		if _, ok := changed[scooter.ID]; !ok {
+			i.put(scooter)
+		}
+	}
+	for id := range changed {
+		if e, ok := current[id]; ok {
+			i.put(e.scooter)
+		}
+	}
+	return nil
+}
+
+// Nearby returns the available scooters within the radius in meters from the
+// location in nearest first sorted order
+func (i *Index) Nearby(location domain.GeoLocation, radius float64) []domain.Scooter {
+	type match struct {
+		entry    *entry
+		distance float64
+	}
+	matches := []match{}
+	box, ok := boxAround(location, radius)
+	add := func(e *entry) {
+		// the scooters outside the box are not within the radius, checking the
+		// box first saves computing the distance of most scooters
+		if ok && !box.contains(e.scooter.Location) {
+			return
+		}
+		d := distance(location, e.scooter.Location)
+		if d <= radius {
+			matches = append(matches, match{entry: e, distance: d})
+		}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	from, to := i.cellOf(box.SouthWest), i.cellOf(box.NorthEast)
+	cellCount := (to.latitude - from.latitude + 1) * (to.longitude - from.longitude + 1)
+	if !ok || cellCount > len(i.entries) {
+		for _, e := range i.entries {
+			add(e)
+		}
+	} else {
+		for lat := from.latitude; lat <= to.latitude; lat++ {
+			for lng := from.longitude; lng <= to.longitude; lng++ {
+				for _, e := range i.cells[cell{latitude: lat, longitude: lng}] {
+					add(e)
+				}
+			}
+		}
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].distance != matches[b].distance {
+			return matches[a].distance < matches[b].distance
+		}
+		return matches[a].entry.scooter.ID < matches[b].entry.scooter.ID
+	})
+
+	scooters := make([]domain.Scooter, 0, len(matches))
+	for _, m := range matches {
+		scooters = append(scooters, m.entry.scooter)
+	}
+	return scooters
+}
+
+func (i *Index) cellOf(location domain.GeoLocation) cell {
+	return cell{
+		latitude:  int(math.Floor(location.Latitude / i.cellSize)),
+		longitude: int(math.Floor(location.Longitude / i.cellSize)),
+	}
+}
+
+// box is the area between the south west and the north east corner
+type box domain.BoundingBox
+
+func (b box) contains(l domain.GeoLocation) bool {
+	return l.Latitude >= b.SouthWest.Latitude && l.Latitude <= b.NorthEast.Latitude &&
+		l.Longitude >= b.SouthWest.Longitude && l.Longitude <= b.NorthEast.Longitude
+}
+
+// boxAround returns the box around the circle of the radius, it returns
+// false if the box crosses the pole or the antimeridian
+func boxAround(location domain.GeoLocation, radius float64) (box, bool) {
+	latitudeDelta := radius / metersPerDegree
+	south, north := location.Latitude-latitudeDelta, location.Latitude+latitudeDelta
+	if south < -90 || north > 90 {
+		return box{}, false
+	}
+
+	// the circle is widest in degrees of longitude at its edge closer to the pole
+	widest := math.Max(math.Abs(south), math.Abs(north))
+	longitudeDelta := latitudeDelta / math.Cos(widest*math.Pi/180)
+	west, east := location.Longitude-longitudeDelta, location.Longitude+longitudeDelta
+	if west < -180 || east > 180 {
+		return box{}, false
+	}
+
+	return box{
+		SouthWest: domain.GeoLocation{Latitude: south, Longitude: west},
+		NorthEast: domain.GeoLocation{Latitude: north, Longitude: east},
+	}, true
+}
+
+// distance returns the great circle distance in meters between the locations
+func distance(a, b domain.GeoLocation) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,103 @@
+package geoindex
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	benchmarkScooters = 100000
+)
+
+// benchmarkScooterLocations returns the scooters spread over about 20 km x 20 km
+// around Berlin, the same as the database benchmark
+func benchmarkScooterLocations(n int) []domain.Scooter {
+	r := rand.New(rand.NewSource(1))
+	scooters := make([]domain.Scooter, n)
+	for i := range scooters {
+		scooters[i] = domain.Scooter{
+			ID: fmt.Sprintf("scooter-%v", i),
+			Location: domain.GeoLocation{
+				Latitude:  52.43 + r.Float64()*0.18,
+				Longitude: 13.25 + r.Float64()*0.30,
+			},
+			IsAvailable: i%10 != 0,
+		}
+	}
+	return scooters
+}
+
+func BenchmarkIndexNearby(b *testing.B) {
+	index, err := NewIndex(DefaultCellSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	scooters := benchmarkScooterLocations(benchmarkScooters)
+	for _, s := range scooters {
+		index.Put(s)
+	}
+
+	for _, radius := range []float64{200, 1000, 5000} {
+		b.Run(fmt.Sprintf("radius=%v", radius), func(b *testing.B) {
+			begin := time.Now()
+			for i := 0; i < b.N; i++ {
+				index.Nearby(scooters[i%len(scooters)].Location, radius)
+			}
+			b.ReportMetric(float64(b.N)/time.Since(begin).Seconds(), "queries/s")
+		})
+	}
+}
+
+// BenchmarkMongoNearby is the nearby search of mongo for the same scooters
+// as BenchmarkIndexNearby, to compare the index with the database
+func BenchmarkMongoNearby(b *testing.B) {
+	ctx := context.Background()
+	scooters := benchmarkScooterLocations(benchmarkScooters)
+	mongoDB := newMongoDB(b, scooters)
+
+	for _, radius := range []int{200, 1000, 5000} {
+		b.Run(fmt.Sprintf("radius=%v", radius), func(b *testing.B) {
+			begin := time.Now()
+			for i := 0; i < b.N; i++ {
+				location := scooters[i%len(scooters)].Location
+				_, err := mongoDB.GetAvailableScootersWithinRadius(ctx, &location, radius)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N)/time.Since(begin).Seconds(), "queries/s")
+		})
+	}
+}
+
+func BenchmarkIndexNearbyParallel(b *testing.B) {
+	index, err := NewIndex(DefaultCellSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	scooters := benchmarkScooterLocations(benchmarkScooters)
+	for _, s := range scooters {
+		index.Put(s)
+	}
+
+	// one of hundred operations is the scooter update of begin or end trip
+	begin := time.Now()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		for pb.Next() {
+			s := scooters[r.Intn(len(scooters))]
+			if r.Intn(100) == 0 {
+				s.IsAvailable = !s.IsAvailable
+				index.Put(s)
+				continue
+			}
+			index.Nearby(s.Location, 1000)
+		}
+	})
+	b.ReportMetric(float64(b.N)/time.Since(begin).Seconds(), "ops/s")
+}
@@ -0,0 +1,256 @@
+package geoindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/mongodb"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/migration"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mongodb"
+	testcontainers "github.com/testcontainers/testcontainers-go"
+)
+
+// mongoUpsertBatchSize is the number of scooters saved in mongo at once
+const mongoUpsertBatchSize = 1000
+
+func scooterIDs(scooters []domain.Scooter) []string {
+	ids := []string{}
+	for _, s := range scooters {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// newMongoDB starts mongo test container migrated by the migrations of the
+// service and saves the scooters in it, the test is skipped if the container
+// is not available
+func newMongoDB(tb testing.TB, scooters []domain.Scooter) db.DB {
+	ctx := context.Background()
+	mgoC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "mongo:7.0",
+			ExposedPorts: []string{"27017/tcp"},
+		},
+		Started: true,
+	})
+	if err != nil {
+		tb.Skipf("mongo test container is not available: %v", err)
+	}
+	tb.Cleanup(func() { mgoC.Terminate(context.Background()) })
+
+	ip, err := mgoC.Host(ctx)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	port, err := mgoC.MappedPort(ctx, "27017/tcp")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	uri, dbName := fmt.Sprintf("mongodb://%s:%s", ip, port.Port()), "geoindexdb"
+
+	driver, err := migration.Source("")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	m, err := migrate.NewWithSourceInstance("migration", driver, uri+"/"+dbName)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	err = m.Up()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	mongoDB, err := mongodb.NewMongoDB(uri, dbName)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { mongoDB.Disconnect(context.Background()) })
+	for i := 0; i < len(scooters); i += mongoUpsertBatchSize {
+		end := i + mongoUpsertBatchSize
+		if end > len(scooters) {
+			end = len(scooters)
+		}
+		err = mongoDB.UpsertScooters(ctx, scooters[i:end])
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return mongoDB
+}
+
+func TestNewIndex(t *testing.T) {
+	for _, cellSize := range []float64{0, -1, 181} {
+		_, err := NewIndex(cellSize)
+		if err == nil {
+			t.Errorf("NewIndex() expected error for cell size %v", cellSize)
+		}
+	}
+}
+
+func TestIndexNearby(t *testing.T) {
+	index, err := NewIndex(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	center := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	userID := "userid"
+	scooters := []domain.Scooter{
+		// about 111 m north of the center
+		{ID: "north", Location: domain.GeoLocation{Latitude: 52.521, Longitude: 13.40}, IsAvailable: true},
+		// about 68 m east of the center, in the same cell as the center
+		{ID: "east", Location: domain.GeoLocation{Latitude: 52.52, Longitude: 13.401}, IsAvailable: true},
+		// about 1.1 km south of the center in other cell
+		{ID: "south", Location: domain.GeoLocation{Latitude: 52.51, Longitude: 13.40}, IsAvailable: true},
+		{ID: "inuse", Location: center, CurrentUserID: &userID},
+		{ID: "far", Location: domain.GeoLocation{Latitude: 48.13, Longitude: 11.58}, IsAvailable: true},
+	}
+	for _, s := range scooters {
+		index.Put(s)
+	}
+
+	if index.Len() != 4 {
+		t.Errorf("Len() = %v, want 4 available scooters", index.Len())
+	}
+
+	tests := []struct {
+		name     string
+		location domain.GeoLocation
+		radius   float64
+		want     []string
+	}{
+		{
+			name:     "should return nearest first within radius",
+			location: center,
+			radius:   200,
+			want:     []string{"east", "north"},
+		},
+		{
+			name:     "should return scooters of neighbour cells",
+			location: center,
+			radius:   2000,
+			want:     []string{"east", "north", "south"},
+		},
+		{
+			name:     "should scan all scooters for radius bigger than the grid",
+			location: center,
+			radius:   1000000,
+			want:     []string{"east", "north", "south", "far"},
+		},
+		{
+			name:     "should return no scooter",
+			location: domain.GeoLocation{Latitude: 0, Longitude: 0},
+			radius:   1000,
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scooterIDs(index.Nearby(tt.location, tt.radius))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Nearby() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the scooter which is not available any more is removed
+	inUse := scooters[1]
+	inUse.IsAvailable = false
+	inUse.CurrentUserID = &userID
+	index.Put(inUse)
+	moved := scooters[2]
+	moved.Location = center
+	index.Put(moved)
+	got := scooterIDs(index.Nearby(center, 200))
+	if !reflect.DeepEqual(got, []string{"south", "north"}) {
+		t.Errorf("Nearby() = %v after updates, want [south north]", got)
+	}
+}
+
+func TestIndexReplace(t *testing.T) {
+	index, err := NewIndex(0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	location := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	index.Put(domain.Scooter{ID: "removed", Location: location, IsAvailable: true})
+
+	// the scooter put while loading is newer than the loaded one
+	err = index.Replace(func() ([]domain.Scooter, error) {
+		index.Put(domain.Scooter{ID: "changed", Location: location})
+		return []domain.Scooter{
+			{ID: "loaded", Location: location, IsAvailable: true},
+			{ID: "changed", Location: location, IsAvailable: true},
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := scooterIDs(index.Nearby(location, 10))
+	if !reflect.DeepEqual(got, []string{"loaded"}) {
+		t.Errorf("Nearby() = %v after replace, want [loaded]", got)
+	}
+
+	// the index is kept if the load fails
+	loadErr := errors.New("load failed")
+	err = index.Replace(func() ([]domain.Scooter, error) {
+		return nil, loadErr
+	})
+	if !errors.Is(err, loadErr) || index.Len() != 1 {
+		t.Errorf("Replace() error = %v len = %v, want %v and 1", err, index.Len(), loadErr)
+	}
+}
+
+func Test_distance(t *testing.T) {
+	a := domain.GeoLocation{Latitude: 52.52, Longitude: 13.40}
+	b := domain.GeoLocation{Latitude: 48.13, Longitude: 11.58}
+	// one degree of latitude is about 111.3 km on the sphere used by mongo
+	if got := distance(a, domain.GeoLocation{Latitude: 53.52, Longitude: 13.40}); math.Abs(got-111319) > 1 {
+		t.Errorf("distance() = %v, want 111319", got)
+	}
+	if got := distance(a, b); math.Abs(got-504800) > 1000 || got != distance(b, a) {
+		t.Errorf("distance() = %v, want about 504.8 km", got)
+	}
+}
+
+// TestIndexNearbyMatchesMongo checks that the index returns the same scooters
+// in the same order as the nearby search of mongo for the same scooters
+func TestIndexNearbyMatchesMongo(t *testing.T) {
+	ctx := context.Background()
+	scooters := benchmarkScooterLocations(5000)
+	mongoDB := newMongoDB(t, scooters)
+
+	index, err := NewIndex(DefaultCellSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range scooters {
+		index.Put(s)
+	}
+
+	for _, radius := range []int{200, 1000, 5000} {
+		for i := 0; i < 50; i++ {
+			location := scooters[i*97%len(scooters)].Location
+			// the search location between the scooters
+			location.Latitude += 0.0005
+			want, err := mongoDB.GetAvailableScootersWithinRadius(ctx, &location, radius)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := scooterIDs(index.Nearby(location, float64(radius)))
+			if !reflect.DeepEqual(got, scooterIDs(want)) {
+				t.Fatalf("Nearby(%+v, %v) = %v, want mongo %v", location, radius, got, scooterIDs(want))
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeadLetterEvent represents dead letter event DB record
+type DeadLetterEvent struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Payload    string             `bson:"payload"`
+	Error      string             `bson:"error"`
+	Attempts   int                `bson:"attempts"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+	ReplayedAt *time.Time         `bson:"replayed_at,omitempty"`
+}
+
+// transformToDBDeadLetterEvent creates db dead letter event record from domain record
+func transformToDBDeadLetterEvent(event *domain.DeadLetterEvent) (*DeadLetterEvent, error) {
+	if event == nil {
+		return nil, db.ErrInvalidArg
+	}
+
+	id := primitive.NewObjectID()
+	if event.ID != "" {
+		var err error
+		id, err = primitive.ObjectIDFromHex(event.ID)
+		if err != nil {
+			return nil, fmt.Errorf("id: %w", db.ErrInvalidArg)
+		}
+	}
+
+	dbEvent := &DeadLetterEvent{
+		ID:         id,
+		Payload:    event.Payload,
+		Error:      event.Error,
+		Attempts:   event.Attempts,
+		CreatedAt:  event.CreatedAt,
+		UpdatedAt:  event.UpdatedAt,
+		ReplayedAt: event.ReplayedAt,
+	}
+	return dbEvent, nil
+}
+
+// transformToDomainDeadLetterEvent creates domain dead letter event record from db record
+func transformToDomainDeadLetterEvent(event *DeadLetterEvent) (*domain.DeadLetterEvent, error) {
+	if event == nil {
+		return nil, db.ErrInvalidArg
+	}
+
+	domainEvent := &domain.DeadLetterEvent{
+		ID:         event.ID.Hex(),
+		Payload:    event.Payload,
+		Error:      event.Error,
+		Attempts:   event.Attempts,
+		CreatedAt:  event.CreatedAt,
+		UpdatedAt:  event.UpdatedAt,
+		ReplayedAt: event.ReplayedAt,
+	}
+	return domainEvent, nil
+}
+
+// InsertDeadLetterEvent inserts event in the dead_letter_event collection
+func (m *mongoDetails) InsertDeadLetterEvent(ctx context.Context, event *domain.DeadLetterEvent) error {
+	dbEvent, err := transformToDBDeadLetterEvent(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.DeadLetterCollection.InsertOne(ctx, dbEvent)
+	return err
+}
+
+// GetAllDeadLetterEvents returns all the dead letter events
+func (m *mongoDetails) GetAllDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error) {
+	events := []DeadLetterEvent{}
+	err := m.getAllDocuments(ctx, m.DeadLetterCollection, bson.M{}, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.DeadLetterEvent{}
+	for _, event := range events {
+		r, err := transformToDomainDeadLetterEvent(&event)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *r)
+	}
+
+	return result, nil
+}
+
+// GetDeadLetterEventByID returns dead letter event for given id, if not found returns error
+func (m *mongoDetails) GetDeadLetterEventByID(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("eventID: %w", db.ErrInvalidArg)
+	}
+
+	var record DeadLetterEvent
+	err = m.DeadLetterCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, db.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return transformToDomainDeadLetterEvent(&record)
+}
+
+// UpdateDeadLetterEvent updates dead letter event with the given record
+func (m *mongoDetails) UpdateDeadLetterEvent(ctx context.Context, event *domain.DeadLetterEvent) (*domain.DeadLetterEvent, error) {
+	if event == nil {
+		return nil, fmt.Errorf("event: %w", db.ErrInvalidArg)
+	}
+
+	dbEvent, err := transformToDBDeadLetterEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	updateFields := bson.M{
+		"$set": bson.M{
+			"payload":     dbEvent.Payload,
+			"error":       dbEvent.Error,
+			"attempts":    dbEvent.Attempts,
+			"updated_at":  dbEvent.UpdatedAt,
+			"replayed_at": dbEvent.ReplayedAt,
+		},
+	}
+	result, err := m.DeadLetterCollection.UpdateOne(ctx, bson.M{"_id": dbEvent.ID}, updateFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, db.ErrRecordNotFound
+	}
+	return event, nil
+}
+
+// ClaimDeadLetterEvent sets replayed_at of the dead letter event which is not
+// replayed yet and returns the updated event, if not found returns error
+func (m *mongoDetails) ClaimDeadLetterEvent(ctx context.Context, eventID string, claimedAt time.Time) (*domain.DeadLetterEvent, error) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		return nil, fmt.Errorf("eventID: %w", db.ErrInvalidArg)
+	}
+
+	var record DeadLetterEvent
+	err = m.DeadLetterCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "replayed_at": nil},
+		bson.M{"$set": bson.M{"replayed_at": claimedAt}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, db.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return transformToDomainDeadLetterEvent(&record)
+}
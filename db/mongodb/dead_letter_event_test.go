@@ -0,0 +1,199 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (suite *MongoTestSuite) TestInsertDeadLetterEvent() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:               client,
+		dbName:               dbName,
+		DeadLetterCollection: client.Database(dbName).Collection(deadLetterCollectionName),
+	}
+
+	type args struct {
+		ctx   context.Context
+		event *domain.DeadLetterEvent
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "should return error for nil arg",
+			args: args{
+				ctx:   context.Background(),
+				event: nil,
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return success for valid input arg",
+			args: args{
+				ctx: context.Background(),
+				event: &domain.DeadLetterEvent{
+					Payload:   "{}",
+					Error:     "invalid argument",
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.InsertDeadLetterEvent(tt.args.ctx, tt.args.event); (err != nil) != tt.wantErr {
+				t.Errorf("InsertDeadLetterEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *MongoTestSuite) TestGetAndUpdateDeadLetterEvent() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:               client,
+		dbName:               dbName,
+		DeadLetterCollection: client.Database(dbName).Collection(deadLetterCollectionName),
+	}
+
+	event := &domain.DeadLetterEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Payload:   "{}",
+		Error:     "invalid argument",
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	err = m.InsertDeadLetterEvent(ctx, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.GetDeadLetterEventByID(ctx, "invalidid")
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetDeadLetterEventByID() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	_, err = m.GetDeadLetterEventByID(ctx, primitive.NewObjectID().Hex())
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("GetDeadLetterEventByID() error = %v, want %v", err, db.ErrRecordNotFound)
+	}
+
+	updatedEvent := *event
+	updatedEvent.Payload = `{"type":"trip_start"}`
+	updatedEvent.Attempts = 1
+	_, err = m.UpdateDeadLetterEvent(ctx, &updatedEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.GetDeadLetterEventByID(ctx, event.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Payload != updatedEvent.Payload || got.Attempts != updatedEvent.Attempts || got.IsReplayed() {
+		t.Errorf("GetDeadLetterEventByID() = %v, want %v", got, updatedEvent)
+	}
+
+	events, err := m.GetAllDeadLetterEvents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 {
+		t.Errorf("GetAllDeadLetterEvents() returned no events")
+	}
+}
+
+func (suite *MongoTestSuite) TestClaimDeadLetterEventConcurrently() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:               client,
+		dbName:               dbName,
+		DeadLetterCollection: client.Database(dbName).Collection(deadLetterCollectionName),
+	}
+
+	event := &domain.DeadLetterEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Payload:   "{}",
+		Error:     "invalid argument",
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+	err = m.InsertDeadLetterEvent(ctx, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.ClaimDeadLetterEvent(ctx, primitive.NewObjectID().Hex(), time.Now())
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("ClaimDeadLetterEvent() error = %v, want %v", err, db.ErrRecordNotFound)
+	}
+
+	// only one of the concurrent claims must succeed
+	var claimed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := m.ClaimDeadLetterEvent(ctx, event.ID, time.Now().UTC())
+			switch {
+			case err == nil && got.IsReplayed():
+				atomic.AddInt32(&claimed, 1)
+			case !errors.Is(err, db.ErrRecordNotFound):
+				t.Errorf("ClaimDeadLetterEvent() error = %v, want %v", err, db.ErrRecordNotFound)
+			}
+		}()
+	}
+	wg.Wait()
+	if claimed != 1 {
+		t.Errorf("ClaimDeadLetterEvent() succeeded %v times, want 1", claimed)
+	}
+
+	// the event can be claimed again once the claim is cleared
+	_, err = m.UpdateDeadLetterEvent(ctx, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.ClaimDeadLetterEvent(ctx, event.ID, time.Now().UTC()); err != nil {
+		t.Errorf("ClaimDeadLetterEvent() after clear error = %v", err)
+	}
+}
@@ -1,5 +1,7 @@
 package mongodb
 
+import "github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+
 type GeoJSONType string
 
 const (
@@ -12,3 +14,25 @@ type GeoLocation struct {
 	Type        GeoJSONType `json:"type" bson:"type"`
 	Coordinates []float64   `json:"coordinates" bson:"coordinates"`
 }
+
+// coordinatesOf returns the GeoJSON coordinates of the location, the
+// longitude comes first
+func coordinatesOf(location domain.GeoLocation) []float64 {
+	return []float64{location.Longitude, location.Latitude}
+}
+
+// newGeoLocation returns the GeoJSON point of the location
+func newGeoLocation(location domain.GeoLocation) GeoLocation {
+	return GeoLocation{
+		Type:        GeoJSONPointType,
+		Coordinates: coordinatesOf(location),
+	}
+}
+
+// domainLocation returns the domain location of the GeoJSON point
+func (l GeoLocation) domainLocation() domain.GeoLocation {
+	return domain.GeoLocation{
+		Latitude:  l.Coordinates[1],
+		Longitude: l.Coordinates[0],
+	}
+}
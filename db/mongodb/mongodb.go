@@ -2,31 +2,100 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
 )
 
 const (
-	scooterCollectionName   = "scooter"
-	userCollectionName      = "user"
-	tripEventCollectionName = "trip_event"
+	scooterCollectionName     = "scooter"
+	userCollectionName        = "user"
+	tripEventCollectionName   = "trip_event_series"
+	tripEventIDCollectionName = "trip_event_id"
+	deadLetterCollectionName  = "dead_letter_event"
+	tripCollectionName        = "trip"
+
+	// tripEventIDExpiryIndexName is the name of ttl index on created_at of
+	// trip_event_id collection
+	tripEventIDExpiryIndexName = "created_at"
 )
 
 type mongoDetails struct {
-	client              *mongo.Client
-	dbName              string
-	ScooterCollection   *mongo.Collection
-	UserCollection      *mongo.Collection
-	TripEventCollection *mongo.Collection
+	client                *mongo.Client
+	dbName                string
+	ScooterCollection     *mongo.Collection
+	UserCollection        *mongo.Collection
+	TripEventCollection   *mongo.Collection
+	TripEventIDCollection *mongo.Collection
+	DeadLetterCollection  *mongo.Collection
+	TripCollection        *mongo.Collection
+	logger                logrus.FieldLogger
+	tripEventRetention    *time.Duration
+	clientOptions         *options.ClientOptions
+}
+
+// Option configures mongo db instance
+type Option func(m *mongoDetails) error
+
+// WithTripEventRetention sets how long trip events are kept before they are
+// removed, zero retention keeps them forever. The trip event collection must
+// be migrated already
+func WithTripEventRetention(retention time.Duration) Option {
+	return func(m *mongoDetails) error {
+		if retention < 0 {
+			return fmt.Errorf("retention: %w", db.ErrInvalidArg)
+		}
+		m.tripEventRetention = &retention
+		return nil
+	}
+}
+
+// WithLogger sets the logger used to log failed mongo commands
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(m *mongoDetails) error {
+		if l == nil {
+			return fmt.Errorf("logger: %w", db.ErrInvalidArg)
+		}
+		m.logger = l
+		return nil
+	}
+}
+
+// WithConnectTimeout sets the timeout of establishing a connection
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(m *mongoDetails) error {
+		if timeout <= 0 {
+			return fmt.Errorf("connect timeout: %w", db.ErrInvalidArg)
+		}
+		m.clientOptions.SetConnectTimeout(timeout)
+		return nil
+	}
+}
+
+// WithMaxPoolSize sets the max number of connections, zero keeps the
+// driver default
+func WithMaxPoolSize(size uint64) Option {
+	return func(m *mongoDetails) error {
+		if size > 0 {
+			m.clientOptions.SetMaxPoolSize(size)
+		}
+		return nil
+	}
 }
 
 // NewMongoDB created new mongo db instance, returns error if input is invalid
-func NewMongoDB(uri string, dbName string) (db.DB, error) {
+func NewMongoDB(uri string, dbName string, opts ...Option) (db.DB, error) {
 
 	if uri == "" {
 		return nil, fmt.Errorf("NewMongoDB: empty url %w", db.ErrEmptyArg)
@@ -36,27 +105,80 @@ func NewMongoDB(uri string, dbName string) (db.DB, error) {
 		return nil, fmt.Errorf("NewMongoDB: empty db name %w", db.ErrEmptyArg)
 	}
 
-	client, err := connect(uri)
+	m := &mongoDetails{
+		dbName:        dbName,
+		logger:        logger.Discard(),
+		clientOptions: options.Client(),
+	}
+	for _, opt := range opts {
+		err := opt(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := connect(uri, m.logger, m.clientOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	scooterCollection := client.Database(dbName).Collection(scooterCollectionName)
-	userCollection := client.Database(dbName).Collection(userCollectionName)
-	tripEventCollection := client.Database(dbName).Collection(tripEventCollectionName)
+	m.client = client
+	m.ScooterCollection = client.Database(dbName).Collection(scooterCollectionName)
+	m.UserCollection = client.Database(dbName).Collection(userCollectionName)
+	m.TripEventCollection = client.Database(dbName).Collection(tripEventCollectionName)
+	m.TripEventIDCollection = client.Database(dbName).Collection(tripEventIDCollectionName)
+	m.DeadLetterCollection = client.Database(dbName).Collection(deadLetterCollectionName)
+	m.TripCollection = client.Database(dbName).Collection(tripCollectionName)
+
+	if m.tripEventRetention != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err = m.setTripEventRetention(ctx, *m.tripEventRetention)
+		if err != nil {
+			client.Disconnect(ctx)
+			return nil, err
+		}
+	}
 
-	return &mongoDetails{
-		client:              client,
-		dbName:              dbName,
-		ScooterCollection:   scooterCollection,
-		UserCollection:      userCollection,
-		TripEventCollection: tripEventCollection,
-	}, nil
+	return m, nil
 }
 
-// connect connects to mongo db using client, returns error if fails
-func connect(uri string) (*mongo.Client, error) {
-	client, err := mongo.NewClient(options.Client().ApplyURI(uri))
+// setTripEventRetention sets the expiry of trip event time series collection
+// and of the reserved event ids, so that the ids are removed along with their
+// events
+func (m *mongoDetails) setTripEventRetention(ctx context.Context, retention time.Duration) error {
+	var expireAfterSeconds interface{} = "off"
+	// the ttl index can not be turned off, its longest expiry is used instead
+	var idExpireAfterSeconds int64 = math.MaxInt32
+	if retention > 0 {
+		expireAfterSeconds = int64(retention.Seconds())
+		idExpireAfterSeconds = int64(retention.Seconds())
+	}
+
+	command := bson.D{
+		{Key: "collMod", Value: tripEventCollectionName},
+		{Key: "expireAfterSeconds", Value: expireAfterSeconds},
+	}
+	err := m.client.Database(m.dbName).RunCommand(ctx, command).Err()
+	if err != nil {
+		return err
+	}
+
+	command = bson.D{
+		{Key: "collMod", Value: tripEventIDCollectionName},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: tripEventIDExpiryIndexName},
+			{Key: "expireAfterSeconds", Value: idExpireAfterSeconds},
+		}},
+	}
+	return m.client.Database(m.dbName).RunCommand(ctx, command).Err()
+}
+
+// connect connects to mongo db using client, the given client options are
+// applied after the uri. Returns error if fails
+func connect(uri string, l logrus.FieldLogger, opts ...*options.ClientOptions) (*mongo.Client, error) {
+	clientOptions := options.MergeClientOptions(append([]*options.ClientOptions{options.Client().ApplyURI(uri)}, opts...)...)
+	client, err := mongo.NewClient(clientOptions.SetMonitor(newCommandMonitor(l)))
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +204,24 @@ func (m *mongoDetails) getAllDocuments(ctx context.Context, collection *mongo.Co
 	return cur.All(ctx, records)
 }
 
+// transformError wraps the errors caused by unreachable database with
+// db.ErrUnavailable, other errors are returned as it is
+func transformError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) || errors.Is(err, topology.ErrServerSelectionTimeout) {
+		return fmt.Errorf("%v: %w", err, db.ErrUnavailable)
+	}
+	return err
+}
+
+// Ping checks the primary of mongo db is reachable, otherwise returns error
+func (m *mongoDetails) Ping(ctx context.Context) error {
+	return transformError(m.client.Ping(ctx, readpref.Primary()))
+}
+
 // Disconnect disconnects db connection using client, otherwise returns error
 func (m *mongoDetails) Disconnect(ctx context.Context) error {
 	return m.client.Disconnect(ctx)
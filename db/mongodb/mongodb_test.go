@@ -2,16 +2,20 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/mongodb"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/stretchr/testify/suite"
 	testcontainers "github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -28,8 +32,10 @@ type MongoTestSuite struct {
 
 func getMongoTestContainer(ctx context.Context) (*mongoTestContainer, error) {
 	req := testcontainers.ContainerRequest{
-		Image:        "mongo",
+		Image:        "mongo:7.0",
 		ExposedPorts: []string{"27017/tcp"},
+		// the test commands enable the fail points
+		Cmd: []string{"--setParameter", "enableTestCommands=1"},
 	}
 
 	mgoC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
@@ -94,7 +100,7 @@ func migrateTestData(migarationFilePath string, uri string) error {
 // connectAndMigrateTestData connect to mongo test container and migrate test
 // data
 func connectAndMigrateTestData(mgoC mongoTestContainer, dbName string) (*mongo.Client, error) {
-	client, err := connect(fmt.Sprintf("mongodb://%s:%s", mgoC.Ip, mgoC.Port))
+	client, err := connect(fmt.Sprintf("mongodb://%s:%s", mgoC.Ip, mgoC.Port), logger.Discard())
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +195,7 @@ func (suite *MongoTestSuite) Test_connect() {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := connect(tt.args.uri)
+			_, err := connect(tt.args.uri, logger.Discard())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("connect() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -239,3 +245,81 @@ func (suite *MongoTestSuite) TestDisconnect() {
 		})
 	}
 }
+
+func (suite *MongoTestSuite) TestWithTripEventRetention() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := fmt.Sprintf("mongodb://%s:%s", mgoC.Ip, mgoC.Port)
+
+	_, err = NewMongoDB(uri, dbName, WithTripEventRetention(-time.Hour))
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("NewMongoDB() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	_, err = NewMongoDB(uri, dbName, WithTripEventRetention(24*time.Hour))
+	if err != nil {
+		t.Fatalf("NewMongoDB() error = %v", err)
+	}
+
+	specs, err := client.Database(dbName).ListCollectionSpecifications(ctx, bson.M{"name": tripEventCollectionName})
+	if err != nil || len(specs) != 1 {
+		t.Fatalf("ListCollectionSpecifications() = %v, %v", specs, err)
+	}
+	expireAfterSeconds, ok := specs[0].Options.Lookup("expireAfterSeconds").AsInt64OK()
+	if !ok || expireAfterSeconds != int64((24*time.Hour).Seconds()) {
+		t.Errorf("expireAfterSeconds = %v, want %v", expireAfterSeconds, int64((24 * time.Hour).Seconds()))
+	}
+	if _, ok := specs[0].Options.Lookup("timeseries").DocumentOK(); !ok {
+		t.Errorf("%v is not time series collection", tripEventCollectionName)
+	}
+
+	cur, err := client.Database(dbName).Collection(tripEventIDCollectionName).Indexes().List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := []bson.M{}
+	if err := cur.All(ctx, &indexes); err != nil {
+		t.Fatal(err)
+	}
+	idExpireAfterSeconds := int64(0)
+	for _, index := range indexes {
+		if index["name"] == tripEventIDExpiryIndexName {
+			idExpireAfterSeconds, _ = index["expireAfterSeconds"].(int64)
+			if v, ok := index["expireAfterSeconds"].(int32); ok {
+				idExpireAfterSeconds = int64(v)
+			}
+		}
+	}
+	if idExpireAfterSeconds != int64((24 * time.Hour).Seconds()) {
+		t.Errorf("%v expireAfterSeconds = %v, want %v", tripEventIDCollectionName, idExpireAfterSeconds, int64((24 * time.Hour).Seconds()))
+	}
+}
+
+func (suite *MongoTestSuite) TestPing() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	ctx := context.Background()
+
+	mongodb, err := NewMongoDB(fmt.Sprintf("mongodb://%s:%s", mgoC.Ip, mgoC.Port), "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mongodb.Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	mgoC.Container.Stop(ctx, nil)
+	pingCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := mongodb.Ping(pingCtx); !errors.Is(err, db.ErrUnavailable) {
+		t.Errorf("Ping() error = %v, want %v", err, db.ErrUnavailable)
+	}
+}
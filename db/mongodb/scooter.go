@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Scooter represents scooter DB record
@@ -20,6 +22,11 @@ type Scooter struct {
 	Location      GeoLocation        `bson:"location"`
 	CurrentUserID *string            `bson:"current_user_id,omitempty"`
 	IsAvailable   bool               `bson:"is_available"`
+	VehicleType   string             `bson:"vehicle_type,omitempty"`
+	BatteryLevel  int                `bson:"battery_level,omitempty"`
+	// LocationUpdatedAt is nil if the location is not updated since the
+	// scooter was added
+	LocationUpdatedAt *time.Time `bson:"location_updated_at,omitempty"`
 }
 
 // transformToDBScooter creates and returns scooter DB record from domain scooter record
@@ -28,20 +35,18 @@ func transformToDBScooter(scooter *domain.Scooter) (*Scooter, error) {
 		return nil, db.ErrInvalidArg
 	}
 
-	location := GeoLocation{
-		Type: GeoJSONPointType,
-		Coordinates: []float64{
-			scooter.Location.Latitude,
-			scooter.Location.Longitude,
-		},
-	}
-
 	scooterDB := &Scooter{
 		ID:            scooter.ID,
 		Name:          scooter.Name,
-		Location:      location,
+		Location:      newGeoLocation(scooter.Location),
 		IsAvailable:   scooter.IsAvailable,
 		CurrentUserID: scooter.CurrentUserID,
+		VehicleType:   scooter.VehicleType,
+		BatteryLevel:  scooter.BatteryLevel,
+	}
+	if !scooter.LocationUpdatedAt.IsZero() {
+		locationUpdatedAt := scooter.LocationUpdatedAt
+		scooterDB.LocationUpdatedAt = &locationUpdatedAt
 	}
 
 	return scooterDB, nil
@@ -54,14 +59,16 @@ func transformToDomainScooter(scooter *Scooter) (*domain.Scooter, error) {
 	}
 
 	scooterDomain := &domain.Scooter{
-		ID:   scooter.ID,
-		Name: scooter.Name,
-		Location: domain.GeoLocation{
-			Latitude:  scooter.Location.Coordinates[0],
-			Longitude: scooter.Location.Coordinates[1],
-		},
+		ID:            scooter.ID,
+		Name:          scooter.Name,
+		Location:      scooter.Location.domainLocation(),
 		CurrentUserID: scooter.CurrentUserID,
 		IsAvailable:   scooter.IsAvailable,
+		VehicleType:   scooter.VehicleType,
+		BatteryLevel:  scooter.BatteryLevel,
+	}
+	if scooter.LocationUpdatedAt != nil {
+		scooterDomain.LocationUpdatedAt = scooter.LocationUpdatedAt.UTC()
 	}
 
 	return scooterDomain, nil
@@ -113,7 +120,7 @@ func (m *mongoDetails) GetAvailableScootersWithinRadius(ctx context.Context, loc
 			"$nearSphere": bson.M{
 				"$geometry": bson.M{
 					"type":        GeoJSONPointType,
-					"coordinates": []float64{location.Latitude, location.Longitude},
+					"coordinates": coordinatesOf(*location),
 				},
 				"$maxDistance": radius,
 			},
@@ -145,6 +152,11 @@ func (m *mongoDetails) UpdateScooter(ctx context.Context, scooter *domain.Scoote
 			"location":        dbScooter.Location,
 			"is_available":    dbScooter.IsAvailable,
 			"current_user_id": dbScooter.CurrentUserID,
+			"vehicle_type":    dbScooter.VehicleType,
+			"battery_level":   dbScooter.BatteryLevel,
+			// the time is saved with the location so that later location
+			// updates are compared against it
+			"location_updated_at": dbScooter.LocationUpdatedAt,
 		},
 	}
 	_, err = m.ScooterCollection.UpdateOne(ctx, filter, updateFields)
@@ -154,12 +166,87 @@ func (m *mongoDetails) UpdateScooter(ctx context.Context, scooter *domain.Scoote
 	return scooter, nil
 }
 
+// ReserveScooter sets the user as current user of the available scooter and
+// makes it unavailable, the availability is checked in the update filter so
+// that only one of the concurrent reservations succeeds. It returns
+// db.ErrRecordNotFound if there is no available scooter with the id
+func (m *mongoDetails) ReserveScooter(ctx context.Context, scooterID string, userID string) (*domain.Scooter, error) {
+	if scooterID == "" {
+		return nil, fmt.Errorf("scooterID: %w", db.ErrEmptyArg)
+	}
+
+	if userID == "" {
+		return nil, fmt.Errorf("userID: %w", db.ErrEmptyArg)
+	}
+
+	filter := bson.M{
+		"id":           scooterID,
+		"is_available": true,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_available":    false,
+			"current_user_id": userID,
+		},
+	}
+
+	var record Scooter
+	err := m.ScooterCollection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, db.ErrRecordNotFound
+		}
+		return nil, transformError(err)
+	}
+	return transformToDomainScooter(&record)
+}
+
+// UpsertScooters inserts the scooters or replaces the scooters with same id
+func (m *mongoDetails) UpsertScooters(ctx context.Context, scooters []domain.Scooter) error {
+	if len(scooters) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(scooters))
+	for i := range scooters {
+		dbScooter, err := transformToDBScooter(&scooters[i])
+		if err != nil {
+			return err
+		}
+		if dbScooter.ID == "" {
+			return fmt.Errorf("scooter id: %w", db.ErrEmptyArg)
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"id": dbScooter.ID}).
+			SetReplacement(dbScooter).
+			SetUpsert(true))
+	}
+
+	_, err := m.ScooterCollection.BulkWrite(ctx, models)
+	return transformError(err)
+}
+
 // GetAllScooters returns all the scooters in the system
 func (m *mongoDetails) GetAllScooters(ctx context.Context) ([]domain.Scooter, error) {
 	filter := bson.M{}
 	return m.getScootersByFilter(ctx, filter)
 }
 
+// CountScooters returns the number of available scooters and the number of
+// scooters in trip, the scooter is in trip if it has current user
+func (m *mongoDetails) CountScooters(ctx context.Context) (int64, int64, error) {
+	available, err := m.ScooterCollection.CountDocuments(ctx, bson.M{"is_available": true})
+	if err != nil {
+		return 0, 0, transformError(err)
+	}
+
+	inTrip, err := m.ScooterCollection.CountDocuments(ctx, bson.M{"current_user_id": bson.M{"$nin": bson.A{nil, ""}}})
+	if err != nil {
+		return 0, 0, transformError(err)
+	}
+	return available, inTrip, nil
+}
+
 // GetScooterByID returns scooter for given id, if not found returns error
 func (m *mongoDetails) GetScooterByID(ctx context.Context, scooterID string) (*domain.Scooter, error) {
 	filter := primitive.M{"id": scooterID}
@@ -173,3 +260,97 @@ func (m *mongoDetails) GetScooterByID(ctx context.Context, scooterID string) (*d
 	}
 	return transformToDomainScooter(&record)
 }
+
+// UpdateScooterLocation sets the location of the scooter in trip of the user
+// at the time if the time is after the time of its current location, the
+// update is atomic so that the late location can not replace the newer one
+// and the location sent after the trip can not move the released scooter. It
+// returns the updated scooter, nil if the scooter was not updated as its
+// location is newer, it is not in trip of the user or it does not exist.
+func (m *mongoDetails) UpdateScooterLocation(ctx context.Context, scooterID string, userID string, location domain.GeoLocation, at time.Time) (*domain.Scooter, error) {
+	if scooterID == "" {
+		return nil, fmt.Errorf("scooterID: %w", db.ErrEmptyArg)
+	}
+
+	if userID == "" {
+		return nil, fmt.Errorf("userID: %w", db.ErrEmptyArg)
+	}
+
+	if at.IsZero() {
+		return nil, fmt.Errorf("at: %w", db.ErrInvalidArg)
+	}
+
+	filter := bson.M{
+		"id":              scooterID,
+		"is_available":    false,
+		"current_user_id": userID,
+		"$or": bson.A{
+			bson.M{"location_updated_at": nil},
+			bson.M{"location_updated_at": bson.M{"$lt": at}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"location":            newGeoLocation(location),
+			"location_updated_at": at,
+		},
+	}
+
+	var record Scooter
+	err := m.ScooterCollection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, transformError(err)
+	}
+	return transformToDomainScooter(&record)
+}
+
+// scooterChange represents the change event of the scooter collection
+type scooterChange struct {
+	FullDocument *Scooter `bson:"fullDocument"`
+}
+
+// WatchScooters calls onChange with the scooter after each insert, update or
+// replace of the scooter collection until the context is done. The change
+// streams are supported only by replica sets and sharded clusters, the error
+// is returned if the watch can not be started or fails.
+func (m *mongoDetails) WatchScooters(ctx context.Context, onChange func(scooter *domain.Scooter)) error {
+	if onChange == nil {
+		return fmt.Errorf("onChange: %w", db.ErrInvalidArg)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace"}}}}},
+	}
+	stream, err := m.ScooterCollection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return transformError(err)
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		change := scooterChange{}
+		err = stream.Decode(&change)
+		if err != nil {
+			return err
+		}
+
+		// the scooter updated and removed before the lookup has no document
+		if change.FullDocument == nil {
+			continue
+		}
+
+		scooter, err := transformToDomainScooter(change.FullDocument)
+		if err != nil {
+			return err
+		}
+		onChange(scooter)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return transformError(stream.Err())
+}
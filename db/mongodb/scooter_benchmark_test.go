@@ -0,0 +1,78 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const (
+	benchmarkNearbyScooters  = 100000
+	benchmarkUpsertBatchSize = 1000
+)
+
+// benchmarkScooterLocations returns the scooters spread over about 20 km x 20 km
+// around Berlin, the same as the scooter index benchmark
+func benchmarkScooterLocations(n int) []domain.Scooter {
+	r := rand.New(rand.NewSource(1))
+	scooters := make([]domain.Scooter, n)
+	for i := range scooters {
+		scooters[i] = domain.Scooter{
+			ID: fmt.Sprintf("scooter-%v", i),
+			Location: domain.GeoLocation{
+				Latitude:  52.43 + r.Float64()*0.18,
+				Longitude: 13.25 + r.Float64()*0.30,
+			},
+			IsAvailable: i%10 != 0,
+		}
+	}
+	return scooters
+}
+
+func BenchmarkGetAvailableScootersWithinRadius(b *testing.B) {
+	ctx := context.Background()
+	dbName := "scooterbenchmarkdb"
+
+	mgoC, err := getMongoTestContainer(ctx)
+	if err != nil {
+		b.Skip("unable to get mongo test container:", err)
+	}
+	b.Cleanup(func() { mgoC.Container.Terminate(ctx) })
+
+	client, err := connectAndMigrateTestData(*mgoC, dbName)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	scooters := benchmarkScooterLocations(benchmarkNearbyScooters)
+	for i := 0; i < len(scooters); i += benchmarkUpsertBatchSize {
+		err = m.UpsertScooters(ctx, scooters[i:i+benchmarkUpsertBatchSize])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, radius := range []int{200, 1000, 5000} {
+		b.Run(fmt.Sprintf("radius=%v", radius), func(b *testing.B) {
+			begin := time.Now()
+			for i := 0; i < b.N; i++ {
+				location := scooters[i%len(scooters)].Location
+				_, err := m.GetAvailableScootersWithinRadius(ctx, &location, radius)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N)/time.Since(begin).Seconds(), "queries/s")
+		})
+	}
+}
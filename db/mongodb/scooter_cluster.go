@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// scooterCluster represents the scooters of the grid cell, the location is
+// the centroid of the scooters and the scooter is the first of them
+type scooterCluster struct {
+	Count     int     `bson:"count"`
+	Latitude  float64 `bson:"latitude"`
+	Longitude float64 `bson:"longitude"`
+	Scooter   Scooter `bson:"scooter"`
+}
+
+// GetAvailableScooterClusters returns the available scooters within the
+// bounding box grouped by the cells of the grid of given size in degrees. The
+// grid starts at 0,0 so the clusters do not change when the map is moved. The
+// bounding box is compared with the coordinates as it is flat on the map,
+// unlike $geoWithin which does not allow area bigger than hemisphere
+func (m *mongoDetails) GetAvailableScooterClusters(ctx context.Context, boundingBox *domain.BoundingBox, cellSize float64) ([]domain.ScooterCluster, error) {
+	if boundingBox == nil {
+		return nil, fmt.Errorf("bounding box: %w", db.ErrInvalidArg)
+	}
+
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("cell size: %w", db.ErrInvalidArg)
+	}
+
+	longitude := bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 0}}
+	latitude := bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 1}}
+	sw, ne := boundingBox.SouthWest, boundingBox.NorthEast
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"is_available":           true,
+			"location.coordinates.0": bson.M{"$gte": sw.Longitude, "$lte": ne.Longitude},
+			"location.coordinates.1": bson.M{"$gte": sw.Latitude, "$lte": ne.Latitude},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"latitude":  bson.M{"$floor": bson.M{"$divide": bson.A{latitude, cellSize}}},
+				"longitude": bson.M{"$floor": bson.M{"$divide": bson.A{longitude, cellSize}}},
+			},
+			"count":     bson.M{"$sum": 1},
+			"latitude":  bson.M{"$avg": latitude},
+			"longitude": bson.M{"$avg": longitude},
+			"scooter":   bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.latitude", Value: 1}, {Key: "_id.longitude", Value: 1}}}},
+	}
+
+	cur, err := m.ScooterCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, transformError(err)
+	}
+	defer cur.Close(ctx)
+
+	records := []scooterCluster{}
+	err = cur.All(ctx, &records)
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	clusters := make([]domain.ScooterCluster, 0, len(records))
+	for i := range records {
+		cluster := domain.ScooterCluster{
+			Center: domain.GeoLocation{
+				Latitude:  records[i].Latitude,
+				Longitude: records[i].Longitude,
+			},
+			Count: records[i].Count,
+		}
+		if cluster.Count == 1 {
+			cluster.Scooter, err = transformToDomainScooter(&records[i].Scooter)
+			if err != nil {
+				return nil, err
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
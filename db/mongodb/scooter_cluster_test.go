@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func (suite *MongoTestSuite) TestGetAvailableScooterClusters() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "scooterclusterdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	userID := "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	scooters := []domain.Scooter{
+		{ID: "c1", Location: domain.GeoLocation{Latitude: 52.501, Longitude: 13.401}, IsAvailable: true},
+		{ID: "c2", Location: domain.GeoLocation{Latitude: 52.503, Longitude: 13.403}, IsAvailable: true},
+		{ID: "c3", Location: domain.GeoLocation{Latitude: 52.502, Longitude: 13.402}, CurrentUserID: &userID},
+		{ID: "c4", Location: domain.GeoLocation{Latitude: 52.551, Longitude: 13.451}, IsAvailable: true},
+		{ID: "c5", Location: domain.GeoLocation{Latitude: 53.0, Longitude: 14.0}, IsAvailable: true},
+	}
+	err = m.UpsertScooters(ctx, scooters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boundingBox := &domain.BoundingBox{
+		SouthWest: domain.GeoLocation{Latitude: 52.4, Longitude: 13.3},
+		NorthEast: domain.GeoLocation{Latitude: 52.6, Longitude: 13.5},
+	}
+
+	_, err = m.GetAvailableScooterClusters(ctx, nil, 0.01)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetAvailableScooterClusters() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	_, err = m.GetAvailableScooterClusters(ctx, boundingBox, 0)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetAvailableScooterClusters() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	// the scooters c1 and c2 are in the same cell, c3 is in use and c5 is
+	// outside the bounding box
+	got, err := m.GetAvailableScooterClusters(ctx, boundingBox, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GetAvailableScooterClusters() = %+v, want 2 clusters", got)
+	}
+	if got[0].Count != 2 || got[0].Scooter != nil || math.Abs(got[0].Center.Latitude-52.502) > 1e-9 || math.Abs(got[0].Center.Longitude-13.402) > 1e-9 {
+		t.Errorf("GetAvailableScooterClusters()[0] = %+v, want 2 scooters at their centroid", got[0])
+	}
+	if got[1].Count != 1 || got[1].Scooter == nil || got[1].Scooter.ID != "c4" {
+		t.Errorf("GetAvailableScooterClusters()[1] = %+v, want scooter c4", got[1])
+	}
+}
@@ -0,0 +1,159 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// GeoJSONPolygonType is the type of the area of the search
+	GeoJSONPolygonType GeoJSONType = "Polygon"
+
+	// distanceField is the field of the distance added by $geoNear
+	distanceField = "distance"
+)
+
+// scooterMatch represents scooter DB record with its distance from the
+// search location
+type scooterMatch struct {
+	Scooter  `bson:",inline"`
+	Distance *float64 `bson:"distance,omitempty"`
+}
+
+// SearchAvailableScooters returns the available scooters matching the query.
+// With near location, $geoNear aggregation returns them nearest first with
+// the distance, otherwise they are sorted by id so that the pages are stable.
+func (m *mongoDetails) SearchAvailableScooters(ctx context.Context, query *domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	if query == nil {
+		return nil, fmt.Errorf("query: %w", db.ErrInvalidArg)
+	}
+
+	filter, err := scooterSearchFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var cur *mongo.Cursor
+	if query.Near != nil {
+		cur, err = m.ScooterCollection.Aggregate(ctx, geoNearPipeline(query, filter))
+	} else {
+		opts := options.Find().SetSort(bson.D{{Key: "id", Value: 1}}).SetSkip(int64(query.Offset))
+		if query.Limit > 0 {
+			opts.SetLimit(int64(query.Limit))
+		}
+		cur, err = m.ScooterCollection.Find(ctx, filter, opts)
+	}
+	if err != nil {
+		return nil, transformError(err)
+	}
+	defer cur.Close(ctx)
+
+	records := []scooterMatch{}
+	err = cur.All(ctx, &records)
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	result := make([]domain.ScooterMatch, 0, len(records))
+	for i := range records {
+		s, err := transformToDomainScooter(&records[i].Scooter)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, domain.ScooterMatch{
+			Scooter:  *s,
+			Distance: records[i].Distance,
+		})
+	}
+	return result, nil
+}
+
+// geoNearPipeline returns the aggregation pipeline which sorts the scooters
+// matching the filter by distance from near of the query
+func geoNearPipeline(query *domain.ScooterQuery, filter bson.M) mongo.Pipeline {
+	geoNear := bson.M{
+		"near": bson.M{
+			"type":        GeoJSONPointType,
+			"coordinates": coordinatesOf(*query.Near),
+		},
+		"distanceField": distanceField,
+		"spherical":     true,
+		"query":         filter,
+	}
+	if query.Radius > 0 {
+		geoNear["maxDistance"] = query.Radius
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$geoNear", Value: geoNear}}}
+	if query.Offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: query.Offset}})
+	}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.Limit}})
+	}
+	return pipeline
+}
+
+// scooterSearchFilter returns the filter of the available scooters within
+// the area of the query with the vehicle type and battery level
+func scooterSearchFilter(query *domain.ScooterQuery) (bson.M, error) {
+	filter := bson.M{
+		"is_available": true,
+	}
+	if query.VehicleType != "" {
+		filter["vehicle_type"] = query.VehicleType
+	}
+	if query.MinBatteryLevel > 0 {
+		filter["battery_level"] = bson.M{"$gte": query.MinBatteryLevel}
+	}
+
+	var area []domain.GeoLocation
+	switch {
+	case query.BoundingBox != nil && len(query.Polygon) != 0:
+		return nil, fmt.Errorf("bounding box and polygon: %w", db.ErrInvalidArg)
+	case query.BoundingBox != nil:
+		sw, ne := query.BoundingBox.SouthWest, query.BoundingBox.NorthEast
+		area = []domain.GeoLocation{
+			sw,
+			{Latitude: sw.Latitude, Longitude: ne.Longitude},
+			ne,
+			{Latitude: ne.Latitude, Longitude: sw.Longitude},
+		}
+	case len(query.Polygon) != 0:
+		if len(query.Polygon) < 3 {
+			return nil, fmt.Errorf("polygon: %w", db.ErrInvalidArg)
+		}
+		area = query.Polygon
+	}
+
+	if area != nil {
+		filter["location"] = bson.M{
+			"$geoWithin": bson.M{
+				"$geometry": bson.M{
+					"type":        GeoJSONPolygonType,
+					"coordinates": [][][]float64{polygonRing(area)},
+				},
+			},
+		}
+	}
+	return filter, nil
+}
+
+// polygonRing returns the closed ring of the polygon coordinates, the first
+// point is repeated at the end if the polygon is not closed
+func polygonRing(polygon []domain.GeoLocation) [][]float64 {
+	ring := make([][]float64, 0, len(polygon)+1)
+	for _, p := range polygon {
+		ring = append(ring, coordinatesOf(p))
+	}
+	if polygon[0] != polygon[len(polygon)-1] {
+		ring = append(ring, ring[0])
+	}
+	return ring
+}
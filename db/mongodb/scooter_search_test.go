@@ -0,0 +1,174 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (suite *MongoTestSuite) TestSearchAvailableScooters() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "scootersearchdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	userID := "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	scooters := []domain.Scooter{
+		{ID: "s1", Name: "near", Location: domain.GeoLocation{Latitude: 52.5200, Longitude: 13.4050}, IsAvailable: true, VehicleType: "scooter", BatteryLevel: 80},
+		{ID: "s2", Name: "close", Location: domain.GeoLocation{Latitude: 52.5210, Longitude: 13.4050}, IsAvailable: true, VehicleType: "bike", BatteryLevel: 50},
+		{ID: "s3", Name: "far", Location: domain.GeoLocation{Latitude: 52.5400, Longitude: 13.4050}, IsAvailable: true, VehicleType: "scooter", BatteryLevel: 10},
+		{ID: "s4", Name: "in use", Location: domain.GeoLocation{Latitude: 52.5201, Longitude: 13.4050}, CurrentUserID: &userID, VehicleType: "scooter", BatteryLevel: 90},
+	}
+	err = m.UpsertScooters(ctx, scooters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	near := &domain.GeoLocation{Latitude: 52.5200, Longitude: 13.4050}
+	tests := []struct {
+		name    string
+		query   *domain.ScooterQuery
+		wantIDs []string
+		wantErr error
+	}{
+		{
+			name:    "should return error for nil query",
+			query:   nil,
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name:    "should return available scooters within radius nearest first",
+			query:   &domain.ScooterQuery{Near: near, Radius: 500},
+			wantIDs: []string{"s1", "s2"},
+		},
+		{
+			name:    "should return k nearest scooters",
+			query:   &domain.ScooterQuery{Near: near, Limit: 1},
+			wantIDs: []string{"s1"},
+		},
+		{
+			name:    "should return the page of the nearest scooters",
+			query:   &domain.ScooterQuery{Near: near, Offset: 1, Limit: 2},
+			wantIDs: []string{"s2", "s3"},
+		},
+		{
+			name:    "should return scooters filtered by vehicle type and battery level",
+			query:   &domain.ScooterQuery{Near: near, Limit: 10, VehicleType: "scooter", MinBatteryLevel: 20},
+			wantIDs: []string{"s1"},
+		},
+		{
+			name: "should return scooters within bounding box sorted by id",
+			query: &domain.ScooterQuery{BoundingBox: &domain.BoundingBox{
+				SouthWest: domain.GeoLocation{Latitude: 52.51, Longitude: 13.40},
+				NorthEast: domain.GeoLocation{Latitude: 52.53, Longitude: 13.41},
+			}},
+			wantIDs: []string{"s1", "s2"},
+		},
+		{
+			name: "should return scooters within polygon",
+			query: &domain.ScooterQuery{Polygon: []domain.GeoLocation{
+				{Latitude: 52.535, Longitude: 13.40},
+				{Latitude: 52.545, Longitude: 13.40},
+				{Latitude: 52.545, Longitude: 13.41},
+				{Latitude: 52.535, Longitude: 13.41},
+			}},
+			wantIDs: []string{"s3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.SearchAvailableScooters(ctx, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchAvailableScooters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("SearchAvailableScooters() = %+v, want ids %v", got, tt.wantIDs)
+			}
+			for i, id := range tt.wantIDs {
+				if got[i].ID != id {
+					t.Errorf("SearchAvailableScooters()[%d].ID = %v, want %v", i, got[i].ID, id)
+				}
+				if (got[i].Distance != nil) != (tt.query.Near != nil) {
+					t.Errorf("SearchAvailableScooters()[%d].Distance = %v, want distance only with near", i, got[i].Distance)
+				}
+			}
+		})
+	}
+}
+
+// TestSearchAvailableScootersDistance checks the distance of the scooters to
+// the east and to the north against the known great circle distance, the
+// distance along the longitude is shorter than along the latitude in Berlin
+func (suite *MongoTestSuite) TestSearchAvailableScootersDistance() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "scooterdistancedb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	scooters := []domain.Scooter{
+		{ID: "east", Name: "east", Location: domain.GeoLocation{Latitude: 52.52, Longitude: 13.42}, IsAvailable: true},
+		{ID: "north", Name: "north", Location: domain.GeoLocation{Latitude: 52.53, Longitude: 13.405}, IsAvailable: true},
+	}
+	err = m.UpsertScooters(ctx, scooters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	near := &domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	got, err := m.SearchAvailableScooters(ctx, &domain.ScooterQuery{Near: near, Radius: 1100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "east" || got[0].Distance == nil || math.Abs(*got[0].Distance-1016.04) > 1 {
+		t.Fatalf("SearchAvailableScooters() = %+v, want east at 1016.04 m", got)
+	}
+	if got[0].Location != scooters[0].Location {
+		t.Errorf("SearchAvailableScooters() location = %+v, want %+v", got[0].Location, scooters[0].Location)
+	}
+
+	got, err = m.SearchAvailableScooters(ctx, &domain.ScooterQuery{Near: near, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].ID != "north" || got[1].Distance == nil || math.Abs(*got[1].Distance-1113.19) > 1 {
+		t.Errorf("SearchAvailableScooters() = %+v, want north at 1113.19 m", got)
+	}
+
+	// the stored point is GeoJSON with the longitude first
+	record := Scooter{}
+	err = m.ScooterCollection.FindOne(ctx, bson.M{"id": "east"}).Decode(&record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{13.42, 52.52}; !reflect.DeepEqual(record.Location.Coordinates, want) {
+		t.Errorf("stored coordinates = %v, want %v", record.Location.Coordinates, want)
+	}
+}
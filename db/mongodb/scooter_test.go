@@ -2,9 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -411,3 +415,242 @@ func (suite *MongoTestSuite) TestGetScooterByID() {
 		})
 	}
 }
+
+func (suite *MongoTestSuite) TestUpsertScooters() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	userID := "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	_, err = m.UpdateScooter(ctx, &domain.Scooter{
+		ID:            "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+		Name:          "Scooter 1",
+		Location:      domain.GeoLocation{Latitude: 52.54664741862859, Longitude: 13.351253969417021},
+		CurrentUserID: &userID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scooters := []domain.Scooter{
+		{
+			ID:          "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+			Name:        "Scooter 1",
+			Location:    domain.GeoLocation{Latitude: 52.54664741862859, Longitude: 13.351253969417021},
+			IsAvailable: true,
+		},
+		{
+			ID:          "3c6c9e43-8f5c-4b8e-9a43-1d7a0b1e6f55",
+			Name:        "Scooter 5",
+			Location:    domain.GeoLocation{Latitude: 52.5, Longitude: 13.4},
+			IsAvailable: true,
+		},
+	}
+	// upserting twice does not duplicate the scooters
+	for i := 0; i < 2; i++ {
+		err = m.UpsertScooters(ctx, scooters)
+		if err != nil {
+			t.Fatalf("UpsertScooters() error = %v", err)
+		}
+	}
+
+	all, err := m.GetAllScooters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 {
+		t.Errorf("GetAllScooters() after UpsertScooters() returned %v scooters, want %v", len(all), 5)
+	}
+	for _, want := range scooters {
+		got, err := m.GetScooterByID(ctx, want.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("GetScooterByID() after UpsertScooters() = %v, want %v", *got, want)
+		}
+	}
+}
+
+func (suite *MongoTestSuite) TestUpdateScooterLocation() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "scooterlocationdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	scooterID := "f691fd32-9b3f-4d71-b9b7-c48213bfd232"
+	userID := "userid"
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	newer := domain.GeoLocation{Latitude: 40.85, Longitude: -73.86}
+	older := domain.GeoLocation{Latitude: 40.80, Longitude: -73.80}
+
+	_, err = m.UpdateScooterLocation(ctx, "", userID, newer, now)
+	if !errors.Is(err, db.ErrEmptyArg) {
+		t.Errorf("UpdateScooterLocation() error = %v, want %v", err, db.ErrEmptyArg)
+	}
+
+	_, err = m.UpdateScooterLocation(ctx, scooterID, "", newer, now)
+	if !errors.Is(err, db.ErrEmptyArg) {
+		t.Errorf("UpdateScooterLocation() error = %v, want %v", err, db.ErrEmptyArg)
+	}
+
+	_, err = m.UpdateScooterLocation(ctx, scooterID, userID, newer, time.Time{})
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("UpdateScooterLocation() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	// the available scooter is not moved by the location sent after the trip
+	updated, err := m.UpdateScooterLocation(ctx, scooterID, userID, newer, now)
+	if err != nil || updated != nil {
+		t.Fatalf("UpdateScooterLocation() = %v, %v, want not updated for available scooter", updated, err)
+	}
+
+	_, err = m.ReserveScooter(ctx, scooterID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err = m.UpdateScooterLocation(ctx, scooterID, "otheruserid", newer, now)
+	if err != nil || updated != nil {
+		t.Fatalf("UpdateScooterLocation() = %v, %v, want not updated for other user", updated, err)
+	}
+
+	// the scooter without location time is updated by any location
+	updated, err = m.UpdateScooterLocation(ctx, scooterID, userID, newer, now)
+	if err != nil || updated == nil || updated.Location != newer || !updated.LocationUpdatedAt.Equal(now) {
+		t.Fatalf("UpdateScooterLocation() = %v, %v, want updated", updated, err)
+	}
+
+	// the late location does not replace the newer one
+	updated, err = m.UpdateScooterLocation(ctx, scooterID, userID, older, now.Add(-time.Second))
+	if err != nil || updated != nil {
+		t.Errorf("UpdateScooterLocation() = %v, %v, want not updated for late location", updated, err)
+	}
+
+	updated, err = m.UpdateScooterLocation(ctx, "unknown", userID, newer, now)
+	if err != nil || updated != nil {
+		t.Errorf("UpdateScooterLocation() = %v, %v, want not updated for unknown scooter", updated, err)
+	}
+
+	scooter, err := m.GetScooterByID(ctx, scooterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scooter.Location != newer || !scooter.LocationUpdatedAt.Equal(now) {
+		t.Errorf("GetScooterByID() location = %v at %v, want %v at %v", scooter.Location, scooter.LocationUpdatedAt, newer, now)
+	}
+}
+
+func (suite *MongoTestSuite) TestCountScooters() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "scootercountdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	userID := "f3b9842c-182a-418b-92fd-95d4f46414c5"
+	endedTripUserID := ""
+	err = m.UpsertScooters(ctx, []domain.Scooter{
+		{ID: "0b8f6c1e-5d2a-4f3b-9c7e-1a2b3c4d5e61", Name: "Scooter in trip", CurrentUserID: &userID},
+		{ID: "0b8f6c1e-5d2a-4f3b-9c7e-1a2b3c4d5e62", Name: "Scooter after trip", IsAvailable: true, CurrentUserID: &endedTripUserID},
+		{ID: "0b8f6c1e-5d2a-4f3b-9c7e-1a2b3c4d5e63", Name: "Scooter out of service"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	available, inTrip, err := m.CountScooters(ctx)
+	if err != nil {
+		t.Fatalf("CountScooters() error = %v", err)
+	}
+	// the test data has 3 available scooters
+	if available != 4 || inTrip != 1 {
+		t.Errorf("CountScooters() = %v, %v, want %v, %v", available, inTrip, 4, 1)
+	}
+}
+
+func (suite *MongoTestSuite) TestReserveScooter() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "scooterreservedb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mongoDetails{
+		client:            client,
+		dbName:            dbName,
+		ScooterCollection: client.Database(dbName).Collection(scooterCollectionName),
+	}
+
+	scooterID := "f691fd32-9b3f-4d71-b9b7-c48213bfd232"
+	users := []string{"userid1", "userid2", "userid3", "userid4"}
+	var wg sync.WaitGroup
+	errs := make([]error, len(users))
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = m.ReserveScooter(ctx, scooterID, users[i])
+		}(i)
+	}
+	wg.Wait()
+
+	reservedBy := ""
+	for i, err := range errs {
+		switch {
+		case err == nil && reservedBy == "":
+			reservedBy = users[i]
+		case err == nil:
+			t.Errorf("ReserveScooter() reserved scooter for %v and %v", reservedBy, users[i])
+		case !errors.Is(err, db.ErrRecordNotFound):
+			t.Errorf("ReserveScooter() error = %v, want %v", err, db.ErrRecordNotFound)
+		}
+	}
+
+	got, err := m.GetScooterByID(ctx, scooterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IsAvailable || got.CurrentUserID == nil || *got.CurrentUserID != reservedBy {
+		t.Errorf("GetScooterByID() after ReserveScooter() = %v, want reserved by %v", *got, reservedBy)
+	}
+
+	_, err = m.ReserveScooter(ctx, "unknownid", "userid1")
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("ReserveScooter() error = %v, want %v", err, db.ErrRecordNotFound)
+	}
+}
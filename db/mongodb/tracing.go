@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/tracing"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandTracer starts span for each mongo command, the span is child of the
+// span in the context of the query. The failed commands are logged with the
+// request id and trace id of the query
+type commandTracer struct {
+	spans  sync.Map
+	logger logrus.FieldLogger
+}
+
+// newCommandMonitor returns command monitor which traces mongo commands
+func newCommandMonitor(l logrus.FieldLogger) *event.CommandMonitor {
+	t := &commandTracer{
+		logger: l,
+	}
+	return &event.CommandMonitor{
+		Started:   t.started,
+		Succeeded: t.succeeded,
+		Failed:    t.failed,
+	}
+}
+
+func (t *commandTracer) started(ctx context.Context, e *event.CommandStartedEvent) {
+	collection := ""
+	if value, err := e.Command.LookupErr(e.CommandName); err == nil {
+		collection, _ = value.StringValueOK()
+	}
+
+	_, span := tracing.Tracer().Start(ctx, "mongodb."+e.CommandName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", e.DatabaseName),
+			attribute.String("db.operation", e.CommandName),
+			attribute.String("db.mongodb.collection", collection),
+		),
+	)
+	t.spans.Store(e.RequestID, span)
+}
+
+func (t *commandTracer) succeeded(ctx context.Context, e *event.CommandSucceededEvent) {
+	t.end(e.RequestID, nil)
+}
+
+func (t *commandTracer) failed(ctx context.Context, e *event.CommandFailedEvent) {
+	logger.FromContext(ctx, t.logger).WithFields(logrus.Fields{
+		"command":  e.CommandName,
+		"duration": time.Duration(e.DurationNanos).String(),
+	}).WithError(errors.New(e.Failure)).Warn("mongo command failed")
+	t.end(e.RequestID, errors.New(e.Failure))
+}
+
+func (t *commandTracer) end(requestID int64, err error) {
+	span, ok := t.spans.LoadAndDelete(requestID)
+	if !ok {
+		return
+	}
+	tracing.End(span.(trace.Span), err)
+}
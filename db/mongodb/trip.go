@@ -0,0 +1,138 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Trip represents trip DB record, the trip id is the record id
+type Trip struct {
+	ID            string       `bson:"_id"`
+	UserID        string       `bson:"user_id"`
+	ScooterID     string       `bson:"scooter_id"`
+	StartLocation GeoLocation  `bson:"start_location"`
+	EndLocation   *GeoLocation `bson:"end_location"`
+	StartedAt     time.Time    `bson:"started_at"`
+	EndedAt       *time.Time   `bson:"ended_at"`
+}
+
+// transformToDBTrip creates db trip record from domain record
+func transformToDBTrip(trip *domain.Trip) (*Trip, error) {
+	if trip == nil {
+		return nil, db.ErrInvalidArg
+	}
+
+	if trip.ID == "" {
+		return nil, fmt.Errorf("id: %w", db.ErrEmptyArg)
+	}
+
+	dbTrip := &Trip{
+		ID:            trip.ID,
+		UserID:        trip.UserID,
+		ScooterID:     trip.ScooterID,
+		StartLocation: newGeoLocation(trip.StartLocation),
+		StartedAt:     trip.StartedAt,
+		EndedAt:       trip.EndedAt,
+	}
+	if trip.EndLocation != nil {
+		endLocation := newGeoLocation(*trip.EndLocation)
+		dbTrip.EndLocation = &endLocation
+	}
+	return dbTrip, nil
+}
+
+// transformToDomainTrip creates domain trip record from db record
+func transformToDomainTrip(trip *Trip) (*domain.Trip, error) {
+	if trip == nil {
+		return nil, db.ErrInvalidArg
+	}
+
+	domainTrip := &domain.Trip{
+		ID:            trip.ID,
+		UserID:        trip.UserID,
+		ScooterID:     trip.ScooterID,
+		StartLocation: trip.StartLocation.domainLocation(),
+		StartedAt:     trip.StartedAt,
+		EndedAt:       trip.EndedAt,
+	}
+	if trip.EndLocation != nil {
+		endLocation := trip.EndLocation.domainLocation()
+		domainTrip.EndLocation = &endLocation
+	}
+	return domainTrip, nil
+}
+
+// findTrip returns the trip matching the filter, the latest started if there
+// are more than one
+func (m *mongoDetails) findTrip(ctx context.Context, filter bson.M) (*domain.Trip, error) {
+	var record Trip
+	opts := options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}})
+	err := m.TripCollection.FindOne(ctx, filter, opts).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, db.ErrRecordNotFound
+		}
+		return nil, transformError(err)
+	}
+	return transformToDomainTrip(&record)
+}
+
+// InsertTrip inserts trip in the trip collection
+func (m *mongoDetails) InsertTrip(ctx context.Context, trip *domain.Trip) error {
+	dbTrip, err := transformToDBTrip(trip)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.TripCollection.InsertOne(ctx, dbTrip)
+	return transformError(err)
+}
+
+// GetTripByID returns trip for given id, if not found returns error
+func (m *mongoDetails) GetTripByID(ctx context.Context, tripID string) (*domain.Trip, error) {
+	if tripID == "" {
+		return nil, fmt.Errorf("tripID: %w", db.ErrEmptyArg)
+	}
+	return m.findTrip(ctx, bson.M{"_id": tripID})
+}
+
+// GetActiveTripByScooterID returns the trip of the scooter which has not
+// ended, if not found returns error
+func (m *mongoDetails) GetActiveTripByScooterID(ctx context.Context, scooterID string) (*domain.Trip, error) {
+	if scooterID == "" {
+		return nil, fmt.Errorf("scooterID: %w", db.ErrEmptyArg)
+	}
+	return m.findTrip(ctx, bson.M{"scooter_id": scooterID, "ended_at": nil})
+}
+
+// UpdateTrip updates the end of the trip with the given record
+func (m *mongoDetails) UpdateTrip(ctx context.Context, trip *domain.Trip) (*domain.Trip, error) {
+	dbTrip, err := transformToDBTrip(trip)
+	if err != nil {
+		return nil, err
+	}
+
+	updateFields := bson.M{
+		"$set": bson.M{
+			"end_location": dbTrip.EndLocation,
+			"ended_at":     dbTrip.EndedAt,
+		},
+	}
+	result, err := m.TripCollection.UpdateOne(ctx, bson.M{"_id": dbTrip.ID}, updateFields)
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, db.ErrRecordNotFound
+	}
+	return trip, nil
+}
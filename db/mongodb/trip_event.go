@@ -2,19 +2,33 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// duplicateKeyErrorCode is the code of write error which violates unique index
+const duplicateKeyErrorCode = 11000
+
+// TripEventMeta represents metadata of trip event DB record, the time series
+// collection groups the events with same metadata in buckets
+type TripEventMeta struct {
+	ScooterID string `bson:"scooter_id"`
+	UserID    string `bson:"user_id"`
+}
+
 // TripEvent represents trip event DB record
 type TripEvent struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	UserID    string             `bson:"user_id"`
-	ScooterID string             `bson:"scooter_id"`
+	EventID   string             `bson:"event_id,omitempty"`
+	Meta      TripEventMeta      `bson:"meta"`
 	Location  GeoLocation        `bson:"location"`
 	Type      string             `bson:"type"`
 	CreatedAt time.Time          `bson:"created_at"`
@@ -26,16 +40,20 @@ func transformToDBTripEvent(tripEvent *domain.TripEvent) (*TripEvent, error) {
 		return nil, db.ErrInvalidArg
 	}
 
-	location := GeoLocation{
-		Type:        GeoJSONPointType,
-		Coordinates: []float64{tripEvent.Location.Latitude, tripEvent.Location.Longitude},
+	id := primitive.NewObjectID()
+	eventID := tripEvent.ID
+	if eventID == "" {
+		eventID = id.Hex()
 	}
 
 	dbTripEvent := &TripEvent{
-		ID:        primitive.NewObjectID(),
-		UserID:    tripEvent.UserID,
-		ScooterID: tripEvent.ScooterID,
-		Location:  location,
+		ID:      id,
+		EventID: eventID,
+		Meta: TripEventMeta{
+			ScooterID: tripEvent.ScooterID,
+			UserID:    tripEvent.UserID,
+		},
+		Location:  newGeoLocation(tripEvent.Location),
 		Type:      string(tripEvent.Type),
 		CreatedAt: tripEvent.CreatedAt,
 	}
@@ -48,31 +66,274 @@ func transformToDomainTripEvent(tripEvent *TripEvent) (*domain.TripEvent, error)
 		return nil, db.ErrInvalidArg
 	}
 
-	location := domain.GeoLocation{
-		Latitude:  tripEvent.Location.Coordinates[0],
-		Longitude: tripEvent.Location.Coordinates[1],
+	// events saved before event ids were introduced are identified by record id
+	eventID := tripEvent.EventID
+	if eventID == "" {
+		eventID = tripEvent.ID.Hex()
 	}
 
 	domainTripEvent := &domain.TripEvent{
-		ID:        tripEvent.ID.Hex(),
-		UserID:    tripEvent.UserID,
-		ScooterID: tripEvent.ScooterID,
-		Location:  location,
+		ID:        eventID,
+		UserID:    tripEvent.Meta.UserID,
+		ScooterID: tripEvent.Meta.ScooterID,
+		Location:  tripEvent.Location.domainLocation(),
 		Type:      domain.TripEventType(tripEvent.Type),
 		CreatedAt: tripEvent.CreatedAt,
 	}
 	return domainTripEvent, nil
 }
 
-// InsertTripEvent inserts trip event in the trip_event collection
+// TripEventID represents event id DB record, the time series collection does
+// not support unique index, hence the event id is reserved in this collection
+// with the id as _id before the event is inserted
+type TripEventID struct {
+	ID        string    `bson:"_id"`
+	CreatedAt time.Time `bson:"created_at"`
+	// ReservedAt is nil for the event ids of the events saved before the
+	// reservations had time
+	ReservedAt *time.Time `bson:"reserved_at,omitempty"`
+}
+
+const (
+	// reservationTimeout is how long the event id stays reserved for the save
+	// which reserved it. The reservation whose event is not inserted by then
+	// was left by the save which failed to insert the event and release the
+	// id, it is taken over by the next save of the event
+	reservationTimeout = time.Minute
+	// reservationWait is how long the save waits for the event of the id
+	// reserved by other save to be inserted
+	reservationWait = time.Second
+	// reservationPollInterval is the interval of checking the event of the id
+	// reserved by other save
+	reservationPollInterval = 20 * time.Millisecond
+)
+
+// reserveEventIDs inserts the event ids of trip events in trip_event_id
+// collection. The unique _id makes the reservation atomic, only one of the
+// concurrent inserts of same event id reserves it. It returns whether the event
+// id is reserved by this call and the error of each event at the same index as
+// in the input, the event id of already saved event is neither reserved nor an
+// error. The already reserved event id is checked with takeOverEventID, so that
+// the event is not lost if its save failed. The error is returned if whole
+// batch failed
+func (m *mongoDetails) reserveEventIDs(ctx context.Context, dbTripEvents []*TripEvent) ([]bool, []error, error) {
+	reservedAt := time.Now()
+	documents := make([]interface{}, 0, len(dbTripEvents))
+	reserved := make([]bool, len(dbTripEvents))
+	for i, dbTripEvent := range dbTripEvents {
+		documents = append(documents, &TripEventID{ID: dbTripEvent.EventID, CreatedAt: dbTripEvent.CreatedAt, ReservedAt: &reservedAt})
+		reserved[i] = true
+	}
+
+	results := make([]error, len(dbTripEvents))
+	_, err := m.TripEventIDCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+			// the ids may be reserved even though the write failed, they are
+			// released so that the retry of the events can save them
+			eventIDs := make([]string, 0, len(dbTripEvents))
+			for _, dbTripEvent := range dbTripEvents {
+				eventIDs = append(eventIDs, dbTripEvent.EventID)
+			}
+			return nil, nil, m.releaseEventIDs(ctx, eventIDs, transformError(err))
+		}
+
+		for _, writeErr := range bulkErr.WriteErrors {
+			reserved[writeErr.Index] = false
+			if writeErr.Code != duplicateKeyErrorCode {
+				results[writeErr.Index] = writeErr
+			}
+		}
+
+		// the same event id repeated in the batch is reserved once
+		reservedIDs := map[string]bool{}
+		for i, dbTripEvent := range dbTripEvents {
+			if reserved[i] {
+				reservedIDs[dbTripEvent.EventID] = true
+			}
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			dbTripEvent := dbTripEvents[writeErr.Index]
+			if writeErr.Code != duplicateKeyErrorCode || reservedIDs[dbTripEvent.EventID] {
+				continue
+			}
+			reserved[writeErr.Index], results[writeErr.Index] = m.takeOverEventID(ctx, dbTripEvent)
+			if reserved[writeErr.Index] {
+				reservedIDs[dbTripEvent.EventID] = true
+			}
+		}
+	}
+	return reserved, results, nil
+}
+
+// takeOverEventID checks the already reserved event id of the trip event, it
+// returns true if the id is reserved for the event by this call. The id of
+// the saved event is not reserved. The id reserved by other save is waited
+// for up to reservationWait for its event to be inserted, then the error
+// wrapping db.ErrUnavailable is returned so that the event is saved again
+// later. The id whose event is not inserted within reservationTimeout is
+// taken over, the save which reserved it failed to insert the event as well
+// as to release the id.
+func (m *mongoDetails) takeOverEventID(ctx context.Context, dbTripEvent *TripEvent) (bool, error) {
+	deadline := time.Now().Add(reservationWait)
+	for {
+		reservation := TripEventID{}
+		err := m.TripEventIDCollection.FindOne(ctx, bson.M{"_id": dbTripEvent.EventID}).Decode(&reservation)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// the id is released by the failed save, it is reserved again
+			now := time.Now()
+			_, err = m.TripEventIDCollection.InsertOne(ctx, &TripEventID{ID: dbTripEvent.EventID, CreatedAt: dbTripEvent.CreatedAt, ReservedAt: &now})
+			if err == nil {
+				return true, nil
+			}
+			if !mongo.IsDuplicateKeyError(err) {
+				return false, transformError(err)
+			}
+			continue
+		}
+		if err != nil {
+			return false, transformError(err)
+		}
+
+		count, err := m.TripEventCollection.CountDocuments(ctx, bson.M{"event_id": reservation.ID, "created_at": reservation.CreatedAt}, options.Count().SetLimit(1))
+		if err != nil {
+			return false, transformError(err)
+		}
+		if count > 0 {
+			return false, nil
+		}
+
+		now := time.Now()
+		if reservation.ReservedAt == nil || now.Sub(*reservation.ReservedAt) >= reservationTimeout {
+			// the filter on reserved_at lets only one of the concurrent
+			// saves take over the id
+			filter := bson.M{"_id": reservation.ID, "reserved_at": reservation.ReservedAt}
+			update := bson.M{"$set": bson.M{"created_at": dbTripEvent.CreatedAt, "reserved_at": now}}
+			result, err := m.TripEventIDCollection.UpdateOne(ctx, filter, update)
+			if err != nil {
+				return false, transformError(err)
+			}
+			if result.ModifiedCount == 1 {
+				return true, nil
+			}
+			continue
+		}
+
+		if now.After(deadline) {
+			return false, fmt.Errorf("event id %v is being saved: %w", reservation.ID, db.ErrUnavailable)
+		}
+		select {
+		case <-ctx.Done():
+			return false, transformError(ctx.Err())
+		case <-time.After(reservationPollInterval):
+		}
+	}
+}
+
+// releaseEventIDs removes the reservation of event ids whose events are not
+// inserted because of err, so that the retry of the events can save them. It
+// returns err along with the failure of release if any
+func (m *mongoDetails) releaseEventIDs(ctx context.Context, eventIDs []string, err error) error {
+	_, releaseErr := m.TripEventIDCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": eventIDs}})
+	if releaseErr != nil {
+		return fmt.Errorf("%w, unable to release event ids: %v", err, releaseErr)
+	}
+	return err
+}
+
+// InsertTripEvent inserts trip event in the trip_event_series collection, inserting
+// the event with already saved event id is a no-op. The error wraps
+// db.ErrUnavailable if the database is unreachable
 func (m *mongoDetails) InsertTripEvent(ctx context.Context, tripEvent *domain.TripEvent) error {
 	dbTripEvent, err := transformToDBTripEvent(tripEvent)
 	if err != nil {
 		return err
 	}
 
+	reserved, results, err := m.reserveEventIDs(ctx, []*TripEvent{dbTripEvent})
+	if err != nil {
+		return err
+	}
+	if results[0] != nil {
+		return transformError(results[0])
+	}
+	if !reserved[0] {
+		return nil
+	}
+
 	_, err = m.TripEventCollection.InsertOne(ctx, dbTripEvent)
-	return err
+	if err != nil {
+		return m.releaseEventIDs(ctx, []string{dbTripEvent.EventID}, transformError(err))
+	}
+	return nil
+}
+
+// InsertTripEvents inserts trip events in the trip_event_series collection using
+// single InsertMany call. The insert is unordered, so the failure of one
+// event does not stop others from being inserted. It returns the error of
+// each event at the same index as in the input, inserting the event with
+// already saved event id is a no-op. The error is returned if whole batch failed,
+// it wraps db.ErrUnavailable if the database is unreachable.
+func (m *mongoDetails) InsertTripEvents(ctx context.Context, tripEvents []domain.TripEvent) ([]error, error) {
+	if len(tripEvents) == 0 {
+		return nil, fmt.Errorf("tripEvents: %w", db.ErrEmptyArg)
+	}
+
+	dbTripEvents := []*TripEvent{}
+	for i := range tripEvents {
+		dbTripEvent, err := transformToDBTripEvent(&tripEvents[i])
+		if err != nil {
+			return nil, err
+		}
+		dbTripEvents = append(dbTripEvents, dbTripEvent)
+	}
+
+	reserved, results, err := m.reserveEventIDs(ctx, dbTripEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	// indexes maps the index of document to the index of event in the input
+	documents := []interface{}{}
+	indexes := []int{}
+	for i, dbTripEvent := range dbTripEvents {
+		if !reserved[i] {
+			continue
+		}
+		documents = append(documents, dbTripEvent)
+		indexes = append(indexes, i)
+	}
+
+	if len(documents) == 0 {
+		return results, nil
+	}
+
+	_, err = m.TripEventCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+			eventIDs := make([]string, 0, len(indexes))
+			for _, i := range indexes {
+				eventIDs = append(eventIDs, dbTripEvents[i].EventID)
+			}
+			return nil, m.releaseEventIDs(ctx, eventIDs, transformError(err))
+		}
+
+		eventIDs := make([]string, 0, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			eventIDs = append(eventIDs, dbTripEvents[indexes[writeErr.Index]].EventID)
+		}
+		_, releaseErr := m.TripEventIDCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": eventIDs}})
+		for _, writeErr := range bulkErr.WriteErrors {
+			results[indexes[writeErr.Index]] = writeErr
+			if releaseErr != nil {
+				results[indexes[writeErr.Index]] = fmt.Errorf("%w, unable to release event id: %v", writeErr, releaseErr)
+			}
+		}
+	}
+
+	return results, nil
 }
 
 // GetAllTripEvents get all trip event
@@ -104,3 +365,65 @@ func (m *mongoDetails) GetAllTripEvents(ctx context.Context) ([]domain.TripEvent
 
 	return result, nil
 }
+
+// GetTripEventsByScooterIDs returns the trip events of given scooters, the
+// duplicate records with same event id are returned as they are stored
+func (m *mongoDetails) GetTripEventsByScooterIDs(ctx context.Context, scooterIDs []string) ([]domain.TripEvent, error) {
+	if len(scooterIDs) == 0 {
+		return nil, fmt.Errorf("scooterIDs: %w", db.ErrEmptyArg)
+	}
+
+	records := []TripEvent{}
+	filter := bson.M{"meta.scooter_id": bson.M{"$in": scooterIDs}}
+	err := m.getAllDocuments(ctx, m.TripEventCollection, filter, &records)
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	result := make([]domain.TripEvent, 0, len(records))
+	for i := range records {
+		r, err := transformToDomainTripEvent(&records[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *r)
+	}
+	return result, nil
+}
+
+// GetScooterTripEvents returns the trip events of the scooter created from the
+// time until the time, sorted by creation time
+func (m *mongoDetails) GetScooterTripEvents(ctx context.Context, scooterID string, from time.Time, to time.Time) ([]domain.TripEvent, error) {
+	if scooterID == "" {
+		return nil, fmt.Errorf("scooterID: %w", db.ErrEmptyArg)
+	}
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("to before from: %w", db.ErrInvalidArg)
+	}
+
+	filter := bson.M{
+		"meta.scooter_id": scooterID,
+		"created_at":      bson.M{"$gte": from, "$lte": to},
+	}
+	cur, err := m.TripEventCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	records := []TripEvent{}
+	err = cur.All(ctx, &records)
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	result := make([]domain.TripEvent, 0, len(records))
+	for i := range records {
+		r, err := transformToDomainTripEvent(&records[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *r)
+	}
+	return result, nil
+}
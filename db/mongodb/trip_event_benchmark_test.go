@@ -0,0 +1,194 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	legacyTripEventCollectionName = "trip_event_legacy"
+	benchmarkScooters             = 10
+	benchmarkBatchSize            = 100
+	benchmarkQueryEvents          = 10000
+)
+
+// legacyTripEvent represents trip event DB record in plain trip_event collection
+type legacyTripEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	EventID   string             `bson:"event_id,omitempty"`
+	UserID    string             `bson:"user_id"`
+	ScooterID string             `bson:"scooter_id"`
+	Location  GeoLocation        `bson:"location"`
+	Type      string             `bson:"type"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// tripEventLayout represents the way trip events are stored
+type tripEventLayout struct {
+	name   string
+	insert func(ctx context.Context, events []domain.TripEvent) error
+	query  func(ctx context.Context, scooterID string, from, to time.Time) (int, error)
+}
+
+// getTripEventLayouts starts mongo test container and returns the plain
+// collection layout used before and time series collection layout
+func getTripEventLayouts(b *testing.B) []tripEventLayout {
+	b.Helper()
+	ctx := context.Background()
+	dbName := "benchmarkdb"
+
+	mgoC, err := getMongoTestContainer(ctx)
+	if err != nil {
+		b.Skip("unable to get mongo test container:", err)
+	}
+	b.Cleanup(func() { mgoC.Container.Terminate(ctx) })
+
+	client, err := connectAndMigrateTestData(*mgoC, dbName)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	legacyCollection := client.Database(dbName).Collection(legacyTripEventCollectionName)
+	_, err = legacyCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"event_id": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "scooter_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:                client,
+		dbName:                dbName,
+		TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+		TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+	}
+
+	return []tripEventLayout{
+		{
+			name: "plain",
+			insert: func(ctx context.Context, events []domain.TripEvent) error {
+				documents := []interface{}{}
+				for _, event := range events {
+					documents = append(documents, legacyTripEvent{
+						EventID:   event.ID,
+						UserID:    event.UserID,
+						ScooterID: event.ScooterID,
+						Location:  newGeoLocation(event.Location),
+						Type:      string(event.Type),
+						CreatedAt: event.CreatedAt,
+					})
+				}
+				_, err := legacyCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+				return err
+			},
+			query: func(ctx context.Context, scooterID string, from, to time.Time) (int, error) {
+				filter := bson.M{
+					"scooter_id": scooterID,
+					"created_at": bson.M{"$gte": from, "$lt": to},
+				}
+				records := []legacyTripEvent{}
+				err := m.getAllDocuments(ctx, legacyCollection, filter, &records)
+				return len(records), err
+			},
+		},
+		{
+			name: "timeseries",
+			insert: func(ctx context.Context, events []domain.TripEvent) error {
+				_, err := m.InsertTripEvents(ctx, events)
+				return err
+			},
+			query: func(ctx context.Context, scooterID string, from, to time.Time) (int, error) {
+				filter := bson.M{
+					"meta.scooter_id": scooterID,
+					"created_at":      bson.M{"$gte": from, "$lt": to},
+				}
+				records := []TripEvent{}
+				err := m.getAllDocuments(ctx, m.TripEventCollection, filter, &records)
+				return len(records), err
+			},
+		},
+	}
+}
+
+// benchmarkTripEvents returns location update events spread across scooters,
+// one event per second starting at given time
+func benchmarkTripEvents(prefix string, n int, start time.Time) []domain.TripEvent {
+	events := make([]domain.TripEvent, n)
+	for i := range events {
+		events[i] = domain.TripEvent{
+			ID:        fmt.Sprintf("%v-%v", prefix, i),
+			UserID:    fmt.Sprintf("user-%v", i%benchmarkScooters),
+			ScooterID: fmt.Sprintf("scooter-%v", i%benchmarkScooters),
+			Location: domain.GeoLocation{
+				Latitude:  52.5 + float64(i%1000)/10000,
+				Longitude: 13.3 + float64(i%1000)/10000,
+			},
+			Type:      domain.TripLocationUpdateEvent,
+			CreatedAt: start.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return events
+}
+
+func BenchmarkTripEventInsert(b *testing.B) {
+	ctx := context.Background()
+	start := time.Now().Add(-24 * time.Hour)
+
+	for _, layout := range getTripEventLayouts(b) {
+		b.Run(layout.name, func(b *testing.B) {
+			begin := time.Now()
+			for i := 0; i < b.N; i++ {
+				events := benchmarkTripEvents(fmt.Sprintf("%v-%v", layout.name, i), benchmarkBatchSize, start)
+				err := layout.insert(ctx, events)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*benchmarkBatchSize)/time.Since(begin).Seconds(), "events/s")
+		})
+	}
+}
+
+func BenchmarkTripEventRangeQuery(b *testing.B) {
+	ctx := context.Background()
+	start := time.Now().Add(-24 * time.Hour)
+
+	for _, layout := range getTripEventLayouts(b) {
+		events := benchmarkTripEvents(layout.name, benchmarkQueryEvents, start)
+		for i := 0; i < len(events); i += benchmarkBatchSize {
+			err := layout.insert(ctx, events[i:i+benchmarkBatchSize])
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.Run(layout.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// ten minutes window of one scooter
+				from := start.Add(time.Duration(i%100) * time.Minute)
+				n, err := layout.query(ctx, fmt.Sprintf("scooter-%v", i%benchmarkScooters), from, from.Add(10*time.Minute))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if n == 0 {
+					b.Fatal("no trip events found")
+				}
+			}
+		})
+	}
+}
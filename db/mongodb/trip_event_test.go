@@ -2,11 +2,21 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (suite *MongoTestSuite) TestInsertTripEvent() {
@@ -20,11 +30,12 @@ func (suite *MongoTestSuite) TestInsertTripEvent() {
 	}
 
 	type fields struct {
-		client              *mongo.Client
-		dbName              string
-		ScooterCollection   *mongo.Collection
-		UserCollection      *mongo.Collection
-		TripEventCollection *mongo.Collection
+		client                *mongo.Client
+		dbName                string
+		ScooterCollection     *mongo.Collection
+		UserCollection        *mongo.Collection
+		TripEventCollection   *mongo.Collection
+		TripEventIDCollection *mongo.Collection
 	}
 	type args struct {
 		ctx       context.Context
@@ -52,9 +63,10 @@ func (suite *MongoTestSuite) TestInsertTripEvent() {
 		{
 			name: "should return success for valid input arg",
 			fields: fields{
-				client:              client,
-				dbName:              dbName,
-				TripEventCollection: client.Database(dbName).Collection(tripEventCollectionName),
+				client:                client,
+				dbName:                dbName,
+				TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+				TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -75,11 +87,12 @@ func (suite *MongoTestSuite) TestInsertTripEvent() {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &mongoDetails{
-				client:              tt.fields.client,
-				dbName:              tt.fields.dbName,
-				ScooterCollection:   tt.fields.ScooterCollection,
-				UserCollection:      tt.fields.UserCollection,
-				TripEventCollection: tt.fields.TripEventCollection,
+				client:                tt.fields.client,
+				dbName:                tt.fields.dbName,
+				ScooterCollection:     tt.fields.ScooterCollection,
+				UserCollection:        tt.fields.UserCollection,
+				TripEventCollection:   tt.fields.TripEventCollection,
+				TripEventIDCollection: tt.fields.TripEventIDCollection,
 			}
 			if err := m.InsertTripEvent(tt.args.ctx, tt.args.tripEvent); (err != nil) != tt.wantErr {
 				t.Errorf("InsertTripEvent() error = %v, wantErr %v", err, tt.wantErr)
@@ -144,3 +157,276 @@ func (suite *MongoTestSuite) TestGetAllTripEvents() {
 		})
 	}
 }
+
+func (suite *MongoTestSuite) TestInsertTripEvents() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:                client,
+		dbName:                dbName,
+		TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+		TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+	}
+
+	tripEvent := domain.TripEvent{
+		ID:        "8c5a1f6e-4f0b-4d1e-9a4f-2a0c3b6f7d11",
+		UserID:    "userid",
+		ScooterID: "scooterid",
+		Location: domain.GeoLocation{
+			Latitude:  0.0,
+			Longitude: 0.0,
+		},
+		Type:      domain.TripLocationUpdateEvent,
+		CreatedAt: time.Now(),
+	}
+	otherTripEvent := tripEvent
+	otherTripEvent.ID = "4a0c2d4e-8b1f-4c3a-9e5d-6f7a8b9c0d1e"
+
+	_, err = m.InsertTripEvents(ctx, nil)
+	if err == nil {
+		t.Errorf("InsertTripEvents() expected error for empty events")
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := m.InsertTripEvents(ctx, []domain.TripEvent{tripEvent, otherTripEvent, tripEvent})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, result := range results {
+			if result != nil {
+				t.Errorf("InsertTripEvents() result error = %v, want nil", result)
+			}
+		}
+	}
+
+	err = m.InsertTripEvent(ctx, &tripEvent)
+	if err != nil {
+		t.Errorf("InsertTripEvent() error = %v for already saved event", err)
+	}
+
+	count, err := m.TripEventCollection.CountDocuments(ctx, bson.M{"meta.scooter_id": "scooterid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("InsertTripEvents() saved events = %v, want %v", count, 2)
+	}
+}
+
+// TestInsertTripEventConcurrently checks that the concurrent inserts of same
+// event save it once
+func (suite *MongoTestSuite) TestInsertTripEventConcurrently() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "tripeventconcurrentdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:                client,
+		dbName:                dbName,
+		TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+		TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+	}
+
+	tripEvent := domain.TripEvent{
+		ID:        "5e2b7c1a-3d4f-4a6b-8c9d-0e1f2a3b4c5d",
+		UserID:    "userid",
+		ScooterID: "concurrentscooterid",
+		Type:      domain.TripLocationUpdateEvent,
+		CreatedAt: time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = m.InsertTripEvent(ctx, &tripEvent)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("InsertTripEvent() error = %v", err)
+		}
+	}
+
+	count, err := m.TripEventCollection.CountDocuments(ctx, bson.M{"meta.scooter_id": tripEvent.ScooterID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("InsertTripEvent() saved events = %v, want %v", count, 1)
+	}
+}
+
+// TestInsertTripEventAfterFailedSave checks that the event whose insert and
+// release of its event id failed is saved when it is saved again after the
+// reservation timeout, while it is not saved twice by the concurrent saves
+func (suite *MongoTestSuite) TestInsertTripEventAfterFailedSave() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "tripeventfailedsavedb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fail point fails the commands of the client with the app name
+	failingClient, err := connect(fmt.Sprintf("mongodb://%s:%s", mgoC.Ip, mgoC.Port), logger.Discard(), options.Client().SetAppName("failedsave"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDetails := func(client *mongo.Client) *mongoDetails {
+		return &mongoDetails{
+			client:                client,
+			dbName:                dbName,
+			TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+			TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+		}
+	}
+	failing, m := newDetails(failingClient), newDetails(client)
+
+	tripEvent := domain.TripEvent{
+		ID:        "7d3e9f1a-2b4c-4d5e-8f6a-1b2c3d4e5f6a",
+		UserID:    "userid",
+		ScooterID: "failedsavescooterid",
+		Type:      domain.TripLocationUpdateEvent,
+		CreatedAt: time.Now(),
+	}
+	countEvents := func() int64 {
+		count, err := m.TripEventCollection.CountDocuments(ctx, bson.M{"meta.scooter_id": tripEvent.ScooterID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	// the reservation of the event id is inserted, then the insert of the
+	// event and the release of the id fail
+	failPoint := bson.D{
+		{Key: "configureFailPoint", Value: "failCommand"},
+		{Key: "mode", Value: bson.M{"skip": 1}},
+		{Key: "data", Value: bson.M{
+			"failCommands": []string{"insert", "delete"},
+			"appName":      "failedsave",
+			"errorCode":    8,
+		}},
+	}
+	err = client.Database("admin").RunCommand(ctx, failPoint).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = failing.InsertTripEvent(ctx, &tripEvent)
+	offErr := client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "configureFailPoint", Value: "failCommand"},
+		{Key: "mode", Value: "off"},
+	}).Err()
+	if offErr != nil {
+		t.Fatal(offErr)
+	}
+	if err == nil || !strings.Contains(err.Error(), "unable to release event ids") {
+		t.Fatalf("InsertTripEvent() error = %v, want failed insert and release", err)
+	}
+	if count := countEvents(); count != 0 {
+		t.Fatalf("InsertTripEvent() saved events = %v, want %v", count, 0)
+	}
+
+	// the reservation is taken by the save in progress until it times out
+	err = m.InsertTripEvent(ctx, &tripEvent)
+	if !errors.Is(err, db.ErrUnavailable) {
+		t.Errorf("InsertTripEvent() error = %v before reservation timeout, want %v", err, db.ErrUnavailable)
+	}
+
+	_, err = m.TripEventIDCollection.UpdateOne(ctx, bson.M{"_id": tripEvent.ID}, bson.M{"$set": bson.M{"reserved_at": time.Now().Add(-reservationTimeout)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = m.InsertTripEvent(ctx, &tripEvent)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("InsertTripEvent() error = %v after reservation timeout", err)
+		}
+	}
+	if count := countEvents(); count != 1 {
+		t.Errorf("InsertTripEvent() saved events = %v, want %v", count, 1)
+	}
+
+	results, err := m.InsertTripEvents(ctx, []domain.TripEvent{tripEvent})
+	if err != nil || results[0] != nil {
+		t.Errorf("InsertTripEvents() = %v, %v for saved event", results, err)
+	}
+	if count := countEvents(); count != 1 {
+		t.Errorf("InsertTripEvents() saved events = %v, want %v", count, 1)
+	}
+}
+
+func (suite *MongoTestSuite) TestGetTripEventsByScooterIDs() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:                client,
+		dbName:                dbName,
+		TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+		TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+	}
+
+	createdAt := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	events := []domain.TripEvent{
+		{ID: "0d1c7c55-2a0e-4b6e-8f51-7f3f6b1c9a01", UserID: "user1", ScooterID: "scooter-a", Type: domain.TripStartEvent, CreatedAt: createdAt},
+		{ID: "0d1c7c55-2a0e-4b6e-8f51-7f3f6b1c9a02", UserID: "user1", ScooterID: "scooter-a", Type: domain.TripStopEvent, CreatedAt: createdAt.Add(time.Minute)},
+		{ID: "0d1c7c55-2a0e-4b6e-8f51-7f3f6b1c9a03", UserID: "user2", ScooterID: "scooter-b", Type: domain.TripStartEvent, CreatedAt: createdAt},
+		{ID: "0d1c7c55-2a0e-4b6e-8f51-7f3f6b1c9a04", UserID: "user3", ScooterID: "scooter-c", Type: domain.TripStartEvent, CreatedAt: createdAt},
+	}
+	_, err = m.InsertTripEvents(ctx, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.GetTripEventsByScooterIDs(ctx, []string{"scooter-a", "scooter-b"})
+	if err != nil {
+		t.Fatalf("GetTripEventsByScooterIDs() error = %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+	if !reflect.DeepEqual(got, events[:3]) {
+		t.Errorf("GetTripEventsByScooterIDs() = %v, want %v", got, events[:3])
+	}
+
+	_, err = m.GetTripEventsByScooterIDs(ctx, nil)
+	if !errors.Is(err, db.ErrEmptyArg) {
+		t.Errorf("GetTripEventsByScooterIDs() error = %v, want %v", err, db.ErrEmptyArg)
+	}
+}
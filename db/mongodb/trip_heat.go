@@ -0,0 +1,79 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// heatCell represents the trip events of the grid cell, the location is the
+// centroid of the events
+type heatCell struct {
+	Count     int     `bson:"count"`
+	Latitude  float64 `bson:"latitude"`
+	Longitude float64 `bson:"longitude"`
+}
+
+// GetTripEventHeat returns the number of trip events created since the time
+// within the bounding box grouped by the cells of the grid of given size in
+// degrees. Like the scooter clusters the grid starts at 0,0 and the bounding
+// box is compared with the coordinates.
+func (m *mongoDetails) GetTripEventHeat(ctx context.Context, boundingBox *domain.BoundingBox, since time.Time, cellSize float64) ([]domain.HeatCell, error) {
+	if boundingBox == nil {
+		return nil, fmt.Errorf("bounding box: %w", db.ErrInvalidArg)
+	}
+
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("cell size: %w", db.ErrInvalidArg)
+	}
+
+	longitude := bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 0}}
+	latitude := bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 1}}
+	sw, ne := boundingBox.SouthWest, boundingBox.NorthEast
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"created_at":             bson.M{"$gte": since},
+			"location.coordinates.0": bson.M{"$gte": sw.Longitude, "$lte": ne.Longitude},
+			"location.coordinates.1": bson.M{"$gte": sw.Latitude, "$lte": ne.Latitude},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"latitude":  bson.M{"$floor": bson.M{"$divide": bson.A{latitude, cellSize}}},
+				"longitude": bson.M{"$floor": bson.M{"$divide": bson.A{longitude, cellSize}}},
+			},
+			"count":     bson.M{"$sum": 1},
+			"latitude":  bson.M{"$avg": latitude},
+			"longitude": bson.M{"$avg": longitude},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.latitude", Value: 1}, {Key: "_id.longitude", Value: 1}}}},
+	}
+
+	cur, err := m.TripEventCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, transformError(err)
+	}
+	defer cur.Close(ctx)
+
+	records := []heatCell{}
+	err = cur.All(ctx, &records)
+	if err != nil {
+		return nil, transformError(err)
+	}
+
+	cells := make([]domain.HeatCell, 0, len(records))
+	for _, record := range records {
+		cells = append(cells, domain.HeatCell{
+			Center: domain.GeoLocation{
+				Latitude:  record.Latitude,
+				Longitude: record.Longitude,
+			},
+			Count: record.Count,
+		})
+	}
+	return cells, nil
+}
@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func (suite *MongoTestSuite) TestGetTripEventHeat() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "tripheatdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:                client,
+		dbName:                dbName,
+		TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+		TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	events := []domain.TripEvent{
+		{ID: "h1", ScooterID: "s1", Location: domain.GeoLocation{Latitude: 52.501, Longitude: 13.401}, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+		{ID: "h2", ScooterID: "s1", Location: domain.GeoLocation{Latitude: 52.503, Longitude: 13.403}, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+		{ID: "h3", ScooterID: "s2", Location: domain.GeoLocation{Latitude: 52.551, Longitude: 13.451}, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+		{ID: "h4", ScooterID: "s2", Location: domain.GeoLocation{Latitude: 52.502, Longitude: 13.402}, Type: domain.TripLocationUpdateEvent, CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "h5", ScooterID: "s3", Location: domain.GeoLocation{Latitude: 53.0, Longitude: 14.0}, Type: domain.TripLocationUpdateEvent, CreatedAt: now},
+	}
+	_, err = m.InsertTripEvents(ctx, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boundingBox := &domain.BoundingBox{
+		SouthWest: domain.GeoLocation{Latitude: 52.4, Longitude: 13.3},
+		NorthEast: domain.GeoLocation{Latitude: 52.6, Longitude: 13.5},
+	}
+	since := now.Add(-time.Hour)
+
+	_, err = m.GetTripEventHeat(ctx, nil, since, 0.01)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetTripEventHeat() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	_, err = m.GetTripEventHeat(ctx, boundingBox, since, 0)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("GetTripEventHeat() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	// the events h1 and h2 are in the same cell, h4 is too old and h5 is
+	// outside the bounding box
+	got, err := m.GetTripEventHeat(ctx, boundingBox, since, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GetTripEventHeat() = %+v, want 2 cells", got)
+	}
+	if got[0].Count != 2 || math.Abs(got[0].Center.Latitude-52.502) > 1e-9 || math.Abs(got[0].Center.Longitude-13.402) > 1e-9 {
+		t.Errorf("GetTripEventHeat()[0] = %+v, want 2 events at their centroid", got[0])
+	}
+	if got[1].Count != 1 || got[1].Center != events[2].Location {
+		t.Errorf("GetTripEventHeat()[1] = %+v, want event h3", got[1])
+	}
+}
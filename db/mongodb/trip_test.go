@@ -0,0 +1,135 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func (suite *MongoTestSuite) TestInsertGetAndUpdateTrip() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:         client,
+		dbName:         dbName,
+		TripCollection: client.Database(dbName).Collection(tripCollectionName),
+	}
+
+	err = m.InsertTrip(ctx, nil)
+	if !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("InsertTrip() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+
+	startedAt := time.Now().UTC().Truncate(time.Millisecond)
+	endedTrip := &domain.Trip{
+		ID:            "d3a5c4f6-9e1b-4a7d-8c2e-5f6a7b8c9d01",
+		UserID:        "f3b9842c-182a-418b-92fd-95d4f46414c5",
+		ScooterID:     "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+		StartLocation: domain.GeoLocation{Latitude: 52.52, Longitude: 13.405},
+		StartedAt:     startedAt.Add(-time.Hour),
+	}
+	trip := &domain.Trip{
+		ID:            "6b1f0c2d-3e4a-4b5c-9d6e-7f8091a2b3c4",
+		UserID:        "f3b9842c-182a-418b-92fd-95d4f46414c5",
+		ScooterID:     "f691fd32-9b3f-4d71-b9b7-c48213bfd232",
+		StartLocation: domain.GeoLocation{Latitude: 52.53, Longitude: 13.41},
+		StartedAt:     startedAt,
+	}
+	for _, tr := range []*domain.Trip{endedTrip, trip} {
+		err = m.InsertTrip(ctx, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	endedAt := startedAt.Add(-time.Minute)
+	endedTrip.EndedAt = &endedAt
+	endedTrip.EndLocation = &domain.GeoLocation{Latitude: 52.53, Longitude: 13.41}
+	_, err = m.UpdateTrip(ctx, endedTrip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.GetTripByID(ctx, endedTrip.ID)
+	if err != nil || !reflect.DeepEqual(got, endedTrip) {
+		t.Errorf("GetTripByID() = %+v, %v, want %+v", got, err, endedTrip)
+	}
+
+	_, err = m.GetTripByID(ctx, "missing")
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("GetTripByID() error = %v, want %v", err, db.ErrRecordNotFound)
+	}
+
+	// the trip which has not ended is the active trip of the scooter
+	got, err = m.GetActiveTripByScooterID(ctx, trip.ScooterID)
+	if err != nil || !reflect.DeepEqual(got, trip) {
+		t.Errorf("GetActiveTripByScooterID() = %+v, %v, want %+v", got, err, trip)
+	}
+
+	_, err = m.GetActiveTripByScooterID(ctx, "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed")
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("GetActiveTripByScooterID() error = %v, want %v", err, db.ErrRecordNotFound)
+	}
+
+	_, err = m.UpdateTrip(ctx, &domain.Trip{ID: "missing"})
+	if !errors.Is(err, db.ErrRecordNotFound) {
+		t.Errorf("UpdateTrip() error = %v, want %v", err, db.ErrRecordNotFound)
+	}
+}
+
+func (suite *MongoTestSuite) TestGetScooterTripEvents() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mongoDetails{
+		client:                client,
+		dbName:                dbName,
+		TripEventCollection:   client.Database(dbName).Collection(tripEventCollectionName),
+		TripEventIDCollection: client.Database(dbName).Collection(tripEventIDCollectionName),
+	}
+
+	scooterID := "f691fd32-9b3f-4d71-b9b7-c48213bfd232"
+	start := time.Now().UTC().Truncate(time.Millisecond)
+	events := []domain.TripEvent{
+		{ID: "e2", UserID: "user", ScooterID: scooterID, Type: domain.TripStopEvent, CreatedAt: start.Add(2 * time.Minute)},
+		{ID: "e1", UserID: "user", ScooterID: scooterID, Type: domain.TripStartEvent, CreatedAt: start},
+		{ID: "e0", UserID: "user", ScooterID: scooterID, Type: domain.TripStopEvent, CreatedAt: start.Add(-time.Hour)},
+		{ID: "e3", UserID: "user", ScooterID: "other", Type: domain.TripStartEvent, CreatedAt: start},
+	}
+	_, err = m.InsertTripEvents(ctx, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.GetScooterTripEvents(ctx, "", start, start)
+	if !errors.Is(err, db.ErrEmptyArg) {
+		t.Errorf("GetScooterTripEvents() error = %v, want %v", err, db.ErrEmptyArg)
+	}
+
+	got, err := m.GetScooterTripEvents(ctx, scooterID, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "e1" || got[1].ID != "e2" {
+		t.Errorf("GetScooterTripEvents() = %+v, want events e1 and e2 in order", got)
+	}
+}
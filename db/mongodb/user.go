@@ -2,11 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // User represents user DB record
@@ -29,6 +31,27 @@ func transformToDomainUser(user *User) (*domain.User, error) {
 	return domainUser, nil
 }
 
+// UpsertUsers inserts the users or replaces the users with same id
+func (m *mongoDetails) UpsertUsers(ctx context.Context, users []domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(users))
+	for _, user := range users {
+		if user.ID == "" {
+			return fmt.Errorf("user id: %w", db.ErrEmptyArg)
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"id": user.ID}).
+			SetReplacement(User{ID: user.ID, Name: user.Name}).
+			SetUpsert(true))
+	}
+
+	_, err := m.UserCollection.BulkWrite(ctx, models)
+	return transformError(err)
+}
+
 // GetAllUsers returns all the users
 func (m *mongoDetails) GetAllUsers(ctx context.Context) ([]domain.User, error) {
 	filter := bson.M{}
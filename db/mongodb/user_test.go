@@ -2,9 +2,11 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -82,3 +84,52 @@ func (suite *MongoTestSuite) Test_mongoDetails_GetAllUsers() {
 		})
 	}
 }
+
+func (suite *MongoTestSuite) TestUpsertUsers() {
+	mgoC := suite.TestContainer
+	t := suite.T()
+	dbName := "testdb"
+	ctx := context.Background()
+
+	client, err := connectAndMigrateTestData(mgoC, dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mongoDetails{
+		client:         client,
+		dbName:         dbName,
+		UserCollection: client.Database(dbName).Collection(userCollectionName),
+	}
+
+	users := []domain.User{
+		{ID: "f3b9842c-182a-418b-92fd-95d4f46414c5", Name: "Renamed User 1"},
+		{ID: "0b0e2b2b-5c4a-4c89-9f1c-2c1f1ff3d6a1", Name: "User 4"},
+	}
+	err = m.UpsertUsers(ctx, users)
+	if err != nil {
+		t.Fatalf("UpsertUsers() error = %v", err)
+	}
+	// upserting again does not duplicate the users
+	err = m.UpsertUsers(ctx, users)
+	if err != nil {
+		t.Fatalf("UpsertUsers() error = %v", err)
+	}
+
+	got, err := m.GetAllUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.User{
+		users[0],
+		{ID: "6124edb7-5099-4147-87e6-0c9b93cd1fdb", Name: "User 2"},
+		{ID: "4668a2f7-c498-4e49-a82e-380c1ede0685", Name: "User 3"},
+		users[1],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllUsers() after UpsertUsers() = %v, want %v", got, want)
+	}
+
+	if err := m.UpsertUsers(ctx, []domain.User{{Name: "No ID"}}); !errors.Is(err, db.ErrEmptyArg) {
+		t.Errorf("UpsertUsers() error = %v, want %v", err, db.ErrEmptyArg)
+	}
+}
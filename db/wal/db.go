@@ -0,0 +1,286 @@
+package wal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultMaxSegmentSize = 64 << 20
+	DefaultReplayInterval = 5 * time.Second
+)
+
+// DB is the database which saves the trip events to write ahead log during
+// database outages
+type DB interface {
+	db.DB
+	// StartReplay starts replaying write ahead log in background
+	StartReplay()
+	// StopReplay stops replaying write ahead log and closes it
+	StopReplay() error
+	// PendingSize returns the size in bytes of trip events waiting to be replayed
+	PendingSize() int64
+	// Replaying returns true if the write ahead log is being replayed in background
+	Replaying() bool
+	// OnReplay sets the handler called with the events once they are
+	// replayed, e.g. to apply their side effects skipped during the outage
+	OnReplay(handler func(ctx context.Context, events []domain.TripEvent))
+}
+
+// walDetails wraps the database, the trip events which could not be inserted
+// as the database is unavailable are appended to write ahead log and replayed
+// in order once the database is available again
+type walDetails struct {
+	db.DB
+	log            *Log
+	replayInterval time.Duration
+	replayTimeout  time.Duration
+	stop           chan struct{}
+	replaying      int32
+	wg             sync.WaitGroup
+	logger         logrus.FieldLogger
+	handlerMu      sync.Mutex
+	handler        func(ctx context.Context, events []domain.TripEvent)
+}
+
+// Option configures optional behaviour of the database
+type Option func(w *walDetails)
+
+// WithLogger sets the logger used during replay
+func WithLogger(l logrus.FieldLogger) Option {
+	return func(w *walDetails) {
+		w.logger = l
+	}
+}
+
+// NewDB creates new database which uses given write ahead log during database outages
+func NewDB(database db.DB, walLog *Log, replayInterval time.Duration, opts ...Option) (DB, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database: %w", db.ErrInvalidArg)
+	}
+
+	if walLog == nil {
+		return nil, fmt.Errorf("walLog: %w", db.ErrInvalidArg)
+	}
+
+	if replayInterval <= 0 {
+		return nil, fmt.Errorf("replayInterval: %w", db.ErrInvalidArg)
+	}
+
+	w := &walDetails{
+		DB:             database,
+		log:            walLog,
+		replayInterval: replayInterval,
+		replayTimeout:  replayInterval,
+		stop:           make(chan struct{}),
+		logger:         logger.Discard(),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
+}
+
+// InsertTripEvent inserts trip event in the database, the event is appended
+// to write ahead log if the database is unavailable or the log is not replayed yet
+func (w *walDetails) InsertTripEvent(ctx context.Context, event *domain.TripEvent) error {
+	if event == nil {
+		return fmt.Errorf("event: %w", db.ErrInvalidArg)
+	}
+
+	if w.log.PendingSize() == 0 {
+		err := w.DB.InsertTripEvent(ctx, event)
+		if !errors.Is(err, db.ErrUnavailable) {
+			return err
+		}
+	}
+
+	return w.append(*event)
+}
+
+// InsertTripEvents inserts trip events in the database, the events are appended
+// to write ahead log if the database is unavailable or the log is not replayed yet
+func (w *walDetails) InsertTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error) {
+	if w.log.PendingSize() == 0 {
+		results, err := w.DB.InsertTripEvents(ctx, events)
+		if !errors.Is(err, db.ErrUnavailable) {
+			return results, err
+		}
+	}
+
+	results := make([]error, len(events))
+	for i := range events {
+		results[i] = w.append(events[i])
+	}
+	return results, nil
+}
+
+// append appends the event to write ahead log, the event without id gets new
+// id so that replaying it more than once does not duplicate it
+func (w *walDetails) append(event domain.TripEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+
+	record, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	err = w.log.Append(record)
+	if err != nil {
+		return fmt.Errorf("unable to append trip event to write ahead log: %w", err)
+	}
+	return nil
+}
+
+// StartReplay starts replaying write ahead log in background
+func (w *walDetails) StartReplay() {
+	atomic.StoreInt32(&w.replaying, 1)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.replayInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				if w.log.PendingSize() == 0 {
+					continue
+				}
+				err := w.replay()
+				if err != nil {
+					w.logger.WithError(err).Warn("write ahead log replay stopped")
+				}
+			}
+		}
+	}()
+}
+
+// StopReplay stops replaying write ahead log and closes it, the pending
+// events are replayed once the service is started again
+func (w *walDetails) StopReplay() error {
+	atomic.StoreInt32(&w.replaying, 0)
+	close(w.stop)
+	w.wg.Wait()
+	return w.log.Close()
+}
+
+// PendingSize returns the size in bytes of trip events waiting to be replayed
+func (w *walDetails) PendingSize() int64 {
+	return w.log.PendingSize()
+}
+
+// Replaying returns true if the write ahead log is being replayed in background
+func (w *walDetails) Replaying() bool {
+	return atomic.LoadInt32(&w.replaying) == 1
+}
+
+// OnReplay sets the handler called with the events once they are replayed
+func (w *walDetails) OnReplay(handler func(ctx context.Context, events []domain.TripEvent)) {
+	w.handlerMu.Lock()
+	defer w.handlerMu.Unlock()
+	w.handler = handler
+}
+
+// replayed calls the replay handler with the replayed event
+func (w *walDetails) replayed(ctx context.Context, event domain.TripEvent) {
+	w.handlerMu.Lock()
+	handler := w.handler
+	w.handlerMu.Unlock()
+	if handler != nil {
+		handler(ctx, []domain.TripEvent{event})
+	}
+}
+
+// replay inserts the events from write ahead log in the database and calls the
+// replay handler with each inserted event, it stops at
+// the first error caused by unavailable database so that the event is replayed
+// again later. The event which could not be decoded or is rejected by the
+// database otherwise is moved to dead letter events, so that it does not block
+// the events after it
+func (w *walDetails) replay() error {
+	return w.log.Replay(func(record []byte) error {
+		ctx, cancel := context.WithTimeout(context.Background(), w.replayTimeout)
+		defer cancel()
+
+		var event domain.TripEvent
+		err := json.Unmarshal(record, &event)
+		if err != nil {
+			return w.deadLetter(ctx, string(record), err)
+		}
+
+		err = w.DB.InsertTripEvent(ctx, &event)
+		if err == nil {
+			w.replayed(ctx, event)
+			return nil
+		}
+		if isRetryable(err) {
+			return err
+		}
+		return w.deadLetter(ctx, tripEventPayload(event), err)
+	})
+}
+
+// deadLetter saves the trip event which could not be replayed to dead letter
+// events, returns error if it could not be saved so that the replay stops
+func (w *walDetails) deadLetter(ctx context.Context, payload string, reason error) error {
+	w.logger.WithError(reason).Warn("moving trip event in write ahead log to dead letter events")
+
+	now := time.Now().UTC()
+	err := w.DB.InsertDeadLetterEvent(ctx, &domain.DeadLetterEvent{
+		Payload:   payload,
+		Error:     reason.Error(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save dead letter event: %w", err)
+	}
+	return nil
+}
+
+// isRetryable returns true if the error is caused by unavailable database,
+// hence the event can be saved later
+func isRetryable(err error) bool {
+	return errors.Is(err, db.ErrUnavailable) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// tripEventPayload returns the trip event as v1 trip event payload, so that
+// the dead letter event can be fixed up and replayed by the admin
+func tripEventPayload(event domain.TripEvent) string {
+	type location struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+	payload, _ := json.Marshal(struct {
+		ID        string    `json:"id"`
+		UserID    string    `json:"user_id"`
+		ScooterID string    `json:"scooter_id"`
+		Location  location  `json:"location"`
+		CreatedAt time.Time `json:"created_at"`
+		Type      string    `json:"type"`
+	}{
+		ID:        event.ID,
+		UserID:    event.UserID,
+		ScooterID: event.ScooterID,
+		Location:  location{Latitude: event.Location.Latitude, Longitude: event.Location.Longitude},
+		CreatedAt: event.CreatedAt,
+		Type:      string(event.Type),
+	})
+	return string(payload)
+}
@@ -0,0 +1,337 @@
+package wal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func newTestDB(t *testing.T) (*mocks.MockDB, *walDetails) {
+	t.Helper()
+	database := mocks.NewMockDB(gomock.NewController(t))
+
+	l, err := Open(t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	w, err := NewDB(database, l, time.Hour)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return database, w.(*walDetails)
+}
+
+func TestNewDB(t *testing.T) {
+	l, err := Open(t.TempDir(), 1024)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer l.Close()
+	database := mocks.NewMockDB(gomock.NewController(t))
+
+	type args struct {
+		database       db.DB
+		walLog         *Log
+		replayInterval time.Duration
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "should return error for nil database",
+			args: args{
+				walLog:         l,
+				replayInterval: time.Second,
+			},
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name: "should return error for nil write ahead log",
+			args: args{
+				database:       database,
+				replayInterval: time.Second,
+			},
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name: "should return error for invalid replay interval",
+			args: args{
+				database: database,
+				walLog:   l,
+			},
+			wantErr: db.ErrInvalidArg,
+		},
+		{
+			name: "should create database",
+			args: args{
+				database:       database,
+				walLog:         l,
+				replayInterval: time.Second,
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDB(tt.args.database, tt.args.walLog, tt.args.replayInterval)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewDB() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInsertTripEventDatabaseAvailable(t *testing.T) {
+	database, w := newTestDB(t)
+	ctx := context.Background()
+	event := &domain.TripEvent{ID: "eventid1", Type: domain.TripStartEvent}
+
+	writeErr := errors.New("write error")
+	database.EXPECT().InsertTripEvent(ctx, event).Return(nil).Times(1)
+	database.EXPECT().InsertTripEvent(ctx, event).Return(writeErr).Times(1)
+
+	if err := w.InsertTripEvent(ctx, event); err != nil {
+		t.Errorf("InsertTripEvent() error = %v", err)
+	}
+	if err := w.InsertTripEvent(ctx, event); !errors.Is(err, writeErr) {
+		t.Errorf("InsertTripEvent() error = %v, want %v", err, writeErr)
+	}
+	if err := w.InsertTripEvent(ctx, nil); !errors.Is(err, db.ErrInvalidArg) {
+		t.Errorf("InsertTripEvent() error = %v, want %v", err, db.ErrInvalidArg)
+	}
+	if w.PendingSize() != 0 {
+		t.Errorf("PendingSize() = %v, want 0", w.PendingSize())
+	}
+}
+
+func TestInsertTripEventDatabaseUnavailable(t *testing.T) {
+	database, w := newTestDB(t)
+	ctx := context.Background()
+	events := []domain.TripEvent{
+		{ID: "eventid1", Type: domain.TripStartEvent},
+		{ID: "eventid2", Type: domain.TripLocationUpdateEvent},
+		{Type: domain.TripLocationUpdateEvent},
+		{ID: "eventid4", Type: domain.TripStopEvent},
+	}
+
+	unavailableErr := fmt.Errorf("connection refused: %w", db.ErrUnavailable)
+
+	replayed := []domain.TripEvent{}
+	gomock.InOrder(
+		database.EXPECT().InsertTripEvent(ctx, &events[0]).Return(unavailableErr).Times(1),
+		// replay stops at the first error and replays the event again later
+		database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).Return(unavailableErr).Times(1),
+		database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, event *domain.TripEvent) error {
+				replayed = append(replayed, *event)
+				return nil
+			}).Times(len(events)),
+	)
+
+	if err := w.InsertTripEvent(ctx, &events[0]); err != nil {
+		t.Fatalf("InsertTripEvent() error = %v", err)
+	}
+
+	// events are appended to the log until it is replayed to keep their order
+	for i := 1; i < 3; i++ {
+		if err := w.InsertTripEvent(ctx, &events[i]); err != nil {
+			t.Fatalf("InsertTripEvent() error = %v", err)
+		}
+	}
+	results, err := w.InsertTripEvents(ctx, events[3:])
+	if err != nil || results[0] != nil {
+		t.Fatalf("InsertTripEvents() = %v, %v", results, err)
+	}
+
+	if err := w.replay(); !errors.Is(err, db.ErrUnavailable) {
+		t.Errorf("replay() error = %v, want %v", err, db.ErrUnavailable)
+	}
+	if err := w.replay(); err != nil {
+		t.Errorf("replay() error = %v", err)
+	}
+
+	if len(replayed) != len(events) {
+		t.Fatalf("replayed %v events, want %v", len(replayed), len(events))
+	}
+	for i := range events {
+		if replayed[i].Type != events[i].Type {
+			t.Errorf("replayed event %v = %v, want %v", i, replayed[i].Type, events[i].Type)
+		}
+		if events[i].ID != "" && replayed[i].ID != events[i].ID {
+			t.Errorf("replayed event %v id = %v, want %v", i, replayed[i].ID, events[i].ID)
+		}
+	}
+	if replayed[2].ID == "" {
+		t.Errorf("replayed event without id, want generated id")
+	}
+	if w.PendingSize() != 0 {
+		t.Errorf("PendingSize() = %v, want 0", w.PendingSize())
+	}
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	database, w := newTestDB(t)
+	ctx := context.Background()
+	createdAt := time.Date(2022, 7, 9, 18, 59, 21, 0, time.UTC)
+	events := []domain.TripEvent{
+		{ID: "eventid1", Type: domain.TripStartEvent, CreatedAt: createdAt},
+		{ID: "eventid2", Type: domain.TripStopEvent, CreatedAt: createdAt},
+	}
+
+	unavailableErr := fmt.Errorf("connection refused: %w", db.ErrUnavailable)
+	writeErr := errors.New("write error")
+
+	replayed := []string{}
+	deadLetters := []*domain.DeadLetterEvent{}
+	gomock.InOrder(
+		// the record which could not be decoded and the event rejected by the
+		// database are moved to dead letter events, the replay stops if they
+		// could not be saved
+		database.EXPECT().InsertDeadLetterEvent(gomock.Any(), gomock.Any()).Return(unavailableErr).Times(1),
+		database.EXPECT().InsertDeadLetterEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, event *domain.DeadLetterEvent) error {
+				deadLetters = append(deadLetters, event)
+				return nil
+			}).Times(1),
+		database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).Return(writeErr).Times(1),
+		database.EXPECT().InsertDeadLetterEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, event *domain.DeadLetterEvent) error {
+				deadLetters = append(deadLetters, event)
+				return nil
+			}).Times(1),
+		database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, event *domain.TripEvent) error {
+				replayed = append(replayed, event.ID)
+				return nil
+			}).Times(1),
+	)
+
+	// the events are appended after the record which could not be decoded
+	if err := w.log.Append([]byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	for i := range events {
+		if err := w.InsertTripEvent(ctx, &events[i]); err != nil {
+			t.Fatalf("InsertTripEvent() error = %v", err)
+		}
+	}
+
+	if err := w.replay(); !errors.Is(err, db.ErrUnavailable) {
+		t.Errorf("replay() error = %v, want %v", err, db.ErrUnavailable)
+	}
+	if err := w.replay(); err != nil {
+		t.Errorf("replay() error = %v", err)
+	}
+
+	if len(replayed) != 1 || replayed[0] != "eventid2" {
+		t.Errorf("replayed events = %v, want [eventid2]", replayed)
+	}
+	if len(deadLetters) != 2 || deadLetters[0].Payload != "not json" || deadLetters[1].Error != writeErr.Error() {
+		t.Fatalf("dead letter events = %+v, want invalid record and rejected event", deadLetters)
+	}
+	wantPayload := `{"id":"eventid1","user_id":"","scooter_id":"","location":{"latitude":0,"longitude":0},"created_at":"2022-07-09T18:59:21Z","type":"trip_start"}`
+	if deadLetters[1].Payload != wantPayload {
+		t.Errorf("dead letter event payload = %v, want %v", deadLetters[1].Payload, wantPayload)
+	}
+	if w.PendingSize() != 0 {
+		t.Errorf("PendingSize() = %v, want 0", w.PendingSize())
+	}
+}
+
+func TestReplayHandler(t *testing.T) {
+	database, w := newTestDB(t)
+	ctx := context.Background()
+	events := []domain.TripEvent{
+		{ID: "eventid1", Type: domain.TripLocationUpdateEvent},
+		{ID: "eventid2", Type: domain.TripLocationUpdateEvent},
+	}
+
+	gomock.InOrder(
+		database.EXPECT().InsertTripEvent(ctx, &events[0]).Return(db.ErrUnavailable).Times(1),
+		database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).Return(errors.New("write error")).Times(1),
+		database.EXPECT().InsertDeadLetterEvent(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+		database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+	)
+	for i := range events {
+		if err := w.InsertTripEvent(ctx, &events[i]); err != nil {
+			t.Fatalf("InsertTripEvent() error = %v", err)
+		}
+	}
+
+	// only the replayed events are handled
+	handled := []string{}
+	w.OnReplay(func(ctx context.Context, events []domain.TripEvent) {
+		for _, event := range events {
+			handled = append(handled, event.ID)
+		}
+	})
+	if err := w.replay(); err != nil {
+		t.Errorf("replay() error = %v", err)
+	}
+	if len(handled) != 1 || handled[0] != "eventid2" {
+		t.Errorf("handled events = %v, want [eventid2]", handled)
+	}
+}
+
+func TestInsertTripEventsDatabaseAvailable(t *testing.T) {
+	database, w := newTestDB(t)
+	ctx := context.Background()
+	events := []domain.TripEvent{{ID: "eventid1"}, {ID: "eventid2"}}
+
+	writeErr := errors.New("write error")
+	database.EXPECT().InsertTripEvents(ctx, events).Return([]error{nil, writeErr}, nil).Times(1)
+
+	results, err := w.InsertTripEvents(ctx, events)
+	if err != nil {
+		t.Fatalf("InsertTripEvents() error = %v", err)
+	}
+	if results[0] != nil || !errors.Is(results[1], writeErr) {
+		t.Errorf("InsertTripEvents() = %v, want [nil %v]", results, writeErr)
+	}
+}
+
+func TestStartStopReplay(t *testing.T) {
+	database, w := newTestDB(t)
+	w.replayInterval = time.Millisecond
+	ctx := context.Background()
+	event := &domain.TripEvent{ID: "eventid1"}
+
+	replayed := make(chan struct{})
+	database.EXPECT().InsertTripEvent(ctx, event).Return(db.ErrUnavailable).Times(1)
+	database.EXPECT().InsertTripEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, event *domain.TripEvent) error {
+			close(replayed)
+			return nil
+		}).Times(1)
+
+	if err := w.InsertTripEvent(ctx, event); err != nil {
+		t.Fatalf("InsertTripEvent() error = %v", err)
+	}
+
+	w.StartReplay()
+	if !w.Replaying() {
+		t.Errorf("Replaying() = false after StartReplay()")
+	}
+	select {
+	case <-replayed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("event not replayed")
+	}
+	if err := w.StopReplay(); err != nil {
+		t.Errorf("StopReplay() error = %v", err)
+	}
+	if w.Replaying() {
+		t.Errorf("Replaying() = true after StopReplay()")
+	}
+}
@@ -0,0 +1,391 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentFileExt   = ".wal"
+	corruptedFileExt = ".corrupt"
+	// recordHeaderSize is the size of record length and checksum
+	recordHeaderSize = 8
+	// maxRecordSize protects from allocating huge buffer for corrupted length
+	maxRecordSize = 16 << 20
+)
+
+var (
+	ErrCorrupted = errors.New("corrupted record")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Log is append only write ahead log split into segment files. Each record is
+// stored as length, crc32 checksum and data. Only the last segment is written,
+// the sealed segments are replayed in order and removed once replayed
+type Log struct {
+	dir            string
+	maxSegmentSize int64
+
+	mu          sync.Mutex
+	active      *os.File
+	activeSeq   uint64
+	activeSize  int64
+	pendingSize int64
+
+	// replayMu makes sure only one replay runs at a time
+	replayMu sync.Mutex
+}
+
+// Open opens the write ahead log in given dir, the dir is created if it does
+// not exist. The torn record at the end of the last segment e.g. caused by crash
+// during append is truncated
+func Open(dir string, maxSegmentSize int64) (*Log, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("empty dir not allowed")
+	}
+
+	if maxSegmentSize <= 0 {
+		return nil, fmt.Errorf("invalid max segment size: %v", maxSegmentSize)
+	}
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		dir:            dir,
+		maxSegmentSize: maxSegmentSize,
+	}
+
+	seqs, err := l.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seqs) == 0 {
+		err = l.openSegment(1)
+		return l, err
+	}
+
+	lastSeq := seqs[len(seqs)-1]
+	for _, seq := range seqs[:len(seqs)-1] {
+		info, err := os.Stat(l.segmentPath(seq))
+		if err != nil {
+			return nil, err
+		}
+		l.pendingSize += info.Size()
+	}
+
+	validSize, err := repairSegment(l.segmentPath(lastSeq))
+	if err != nil {
+		return nil, err
+	}
+	l.pendingSize += validSize
+
+	err = l.openSegment(lastSeq)
+	return l, err
+}
+
+// Append appends the record to the log, the record is synced to the disk
+// before it returns
+func (l *Log) Append(record []byte) error {
+	if len(record) == 0 || len(record) > maxRecordSize {
+		return fmt.Errorf("invalid record size: %v", len(record))
+	}
+
+	buf := make([]byte, recordHeaderSize+len(record))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(record)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(record, crcTable))
+	copy(buf[recordHeaderSize:], record)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return fmt.Errorf("write ahead log is closed")
+	}
+
+	if l.activeSize > 0 && l.activeSize+int64(len(buf)) > l.maxSegmentSize {
+		err := l.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := l.active.Write(buf)
+	if err != nil {
+		// drop the partially written record so that the segment stays valid
+		l.active.Truncate(l.activeSize)
+		l.active.Seek(l.activeSize, io.SeekStart)
+		return err
+	}
+
+	err = l.active.Sync()
+	if err != nil {
+		return err
+	}
+
+	l.activeSize += int64(n)
+	l.pendingSize += int64(n)
+	return nil
+}
+
+// PendingSize returns the size in bytes of records waiting to be replayed
+func (l *Log) PendingSize() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pendingSize
+}
+
+// Replay seals the active segment and calls fn for each record in the order
+// they were appended. The segment is removed once all its records are replayed.
+// The replay stops at the first error returned by fn, the segment is kept so
+// that it is replayed again, hence fn must be idempotent. The segment with
+// corrupted record is renamed with .corrupt extension and ErrCorrupted is returned
+// once other segments are replayed
+func (l *Log) Replay(fn func(record []byte) error) error {
+	l.replayMu.Lock()
+	defer l.replayMu.Unlock()
+
+	l.mu.Lock()
+	if l.activeSize > 0 {
+		err := l.rotate()
+		if err != nil {
+			l.mu.Unlock()
+			return err
+		}
+	}
+	activeSeq := l.activeSeq
+	l.mu.Unlock()
+
+	seqs, err := l.segments()
+	if err != nil {
+		return err
+	}
+
+	var corruptedErr error
+	for _, seq := range seqs {
+		if seq >= activeSeq {
+			break
+		}
+
+		path := l.segmentPath(seq)
+		size, err := replaySegment(path, fn)
+		switch {
+		case errors.Is(err, ErrCorrupted):
+			// the records before corruption are replayed already, the segment
+			// is kept aside for inspection so that it does not block the log
+			corruptedErr = err
+			err = os.Rename(path, path+corruptedFileExt)
+		case err != nil:
+			return err
+		default:
+			err = os.Remove(path)
+		}
+		if err != nil {
+			return err
+		}
+		err = syncDir(l.dir)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(path + corruptedFileExt)
+		if err == nil {
+			size = info.Size()
+		}
+
+		l.mu.Lock()
+		l.pendingSize -= size
+		l.mu.Unlock()
+	}
+	return corruptedErr
+}
+
+// Close closes the active segment
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active == nil {
+		return nil
+	}
+	err := l.active.Close()
+	l.active = nil
+	return err
+}
+
+// rotate closes the active segment and opens the next one, must be called with lock
+func (l *Log) rotate() error {
+	err := l.active.Close()
+	if err != nil {
+		return err
+	}
+	return l.openSegment(l.activeSeq + 1)
+}
+
+// openSegment opens the segment for append, must be called with lock
+func (l *Log) openSegment(seq uint64) error {
+	f, err := os.OpenFile(l.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = syncDir(l.dir)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.active = f
+	l.activeSeq = seq
+	l.activeSize = info.Size()
+	return nil
+}
+
+// segments returns the sequence numbers of segment files in ascending order
+func (l *Log) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seqs := []uint64{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func (l *Log) segmentPath(seq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", seq, segmentFileExt))
+}
+
+// readRecord reads next record, returns io.EOF at the end of segment and
+// ErrCorrupted if the record is torn or does not match its checksum
+func readRecord(r io.Reader) ([]byte, error) {
+	header := make([]byte, recordHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, ErrCorrupted
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size == 0 || size > maxRecordSize {
+		return nil, ErrCorrupted
+	}
+
+	record := make([]byte, size)
+	_, err = io.ReadFull(r, record)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrCorrupted
+		}
+		return nil, err
+	}
+
+	if crc32.Checksum(record, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, ErrCorrupted
+	}
+	return record, nil
+}
+
+// replaySegment calls fn for each record of the segment and returns the segment size
+func replaySegment(path string, fn func(record []byte) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var size int64
+	for {
+		record, err := readRecord(r)
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("segment %v at offset %v: %w", path, size, err)
+		}
+
+		err = fn(record)
+		if err != nil {
+			return 0, err
+		}
+		size += int64(recordHeaderSize + len(record))
+	}
+}
+
+// repairSegment truncates the segment after the last valid record and returns
+// the valid size of the segment
+func repairSegment(path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var validSize int64
+	for {
+		record, err := readRecord(r)
+		if err == io.EOF {
+			return validSize, nil
+		}
+		if errors.Is(err, ErrCorrupted) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		validSize += int64(recordHeaderSize + len(record))
+	}
+
+	err = f.Truncate(validSize)
+	if err != nil {
+		return 0, err
+	}
+	return validSize, f.Sync()
+}
+
+// syncDir syncs the directory so that created and removed segments survive crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
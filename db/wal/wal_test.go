@@ -0,0 +1,212 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func replayAll(t *testing.T, l *Log) ([]string, error) {
+	t.Helper()
+	records := []string{}
+	err := l.Replay(func(record []byte) error {
+		records = append(records, string(record))
+		return nil
+	})
+	return records, err
+}
+
+func TestOpen(t *testing.T) {
+	type args struct {
+		dir            string
+		maxSegmentSize int64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "should return error for empty dir",
+			args: args{
+				dir:            "",
+				maxSegmentSize: 1024,
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error for invalid max segment size",
+			args: args{
+				dir:            t.TempDir(),
+				maxSegmentSize: 0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "should open the log in new dir",
+			args: args{
+				dir:            filepath.Join(t.TempDir(), "wal"),
+				maxSegmentSize: 1024,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := Open(tt.args.dir, tt.args.maxSegmentSize)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if l != nil {
+				l.Close()
+			}
+		})
+	}
+}
+
+func TestLogAppendReplay(t *testing.T) {
+	dir := t.TempDir()
+	// small segments so that records are spread across multiple segments
+	l, err := Open(dir, 32)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer l.Close()
+
+	want := []string{}
+	for i := 0; i < 5; i++ {
+		record := fmt.Sprintf("record-%v", i)
+		if err := l.Append([]byte(record)); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		want = append(want, record)
+	}
+
+	if err := l.Append(nil); err == nil {
+		t.Errorf("Append() empty record error = nil, want error")
+	}
+
+	if l.PendingSize() == 0 {
+		t.Errorf("PendingSize() = 0, want > 0")
+	}
+
+	// failed replay keeps the records
+	replayErr := errors.New("replay error")
+	err = l.Replay(func(record []byte) error {
+		return replayErr
+	})
+	if !errors.Is(err, replayErr) {
+		t.Errorf("Replay() error = %v, want %v", err, replayErr)
+	}
+
+	got, err := replayAll(t, l)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Replay() = %v, want %v", got, want)
+	}
+	if l.PendingSize() != 0 {
+		t.Errorf("PendingSize() = %v, want 0", l.PendingSize())
+	}
+
+	got, err = replayAll(t, l)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Replay() = %v, %v, want no records", got, err)
+	}
+}
+
+func TestLogReopen(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, 1024)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, record := range []string{"record-1", "record-2"} {
+		if err := l.Append([]byte(record)); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	size := l.PendingSize()
+	l.Close()
+
+	// simulate crash in the middle of append
+	seqs, err := l.segments()
+	if err != nil || len(seqs) != 1 {
+		t.Fatalf("segments() = %v, %v", seqs, err)
+	}
+	f, err := os.OpenFile(l.segmentPath(seqs[0]), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 10, 1, 2})
+	f.Close()
+
+	l, err = Open(dir, 1024)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer l.Close()
+
+	if l.PendingSize() != size {
+		t.Errorf("PendingSize() = %v, want %v", l.PendingSize(), size)
+	}
+
+	if err := l.Append([]byte("record-3")); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	got, err := replayAll(t, l)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	want := []string{"record-1", "record-2", "record-3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Replay() = %v, want %v", got, want)
+	}
+}
+
+func TestLogReplayCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, 1024)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer l.Close()
+
+	for _, record := range []string{"record-1", "record-2"} {
+		if err := l.Append([]byte(record)); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// flip a byte in the data of second record
+	path := l.segmentPath(l.activeSeq)
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs[len(bs)-1] ^= 0xff
+	if err := os.WriteFile(path, bs, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := replayAll(t, l)
+	if !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Replay() error = %v, want %v", err, ErrCorrupted)
+	}
+	want := []string{"record-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Replay() = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(path + corruptedFileExt); err != nil {
+		t.Errorf("corrupted segment not kept: %v", err)
+	}
+	if l.PendingSize() != 0 {
+		t.Errorf("PendingSize() = %v, want 0", l.PendingSize())
+	}
+}
@@ -1,31 +1,70 @@
-// GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
-// This file was generated by swaggo/swag at
-// 2022-07-10 12:58:16.219094 +0200 CEST m=+0.046269463
-
+// Package docs GENERATED BY SWAG; DO NOT EDIT
+// This file was generated by swaggo/swag
 package docs
 
-import (
-	"bytes"
-
-	"github.com/alecthomas/template"
-	"github.com/swaggo/swag"
-)
+import "github.com/swaggo/swag"
 
-var doc = `{
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
-        "description": "A REST server to manage scooter trips and scooter events",
-        "title": "Scootin Aboot Journey API",
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
         "contact": {},
-        "license": {},
-        "version": "1.0"
+        "version": "{{.Version}}"
     },
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/scooter/trip-event": {
-            "post": {
-                "description": "saves the events generated by scooter when trip is started, ended and during the trip",
+        "/healthz": {
+            "get": {
+                "description": "returns ok as long as the server is able to serve requests",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "liveness of the service",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "returns the result of each dependency check, fails once the service is draining",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "readiness of the service",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/admin/dead-letter-events": {
+            "get": {
+                "description": "returns trip events which could not be saved along with the reason of failure",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,23 +72,122 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scooter-api"
+                    "admin-api"
                 ],
-                "summary": "saves the trip event generated by scooter",
+                "summary": "returns dead letter events",
                 "parameters": [
                     {
-                        "description": "save trip event request",
-                        "name": "saveScooterTripEventRequest",
+                        "type": "string",
+                        "description": "admin api key",
+                        "name": "api_key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.getDeadLetterEventsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/admin/dead-letter-events/{id}": {
+            "get": {
+                "description": "returns dead letter event for given id along with its original payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-api"
+                ],
+                "summary": "returns dead letter event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "dead letter event id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "admin api key",
+                        "name": "api_key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.deadLetterEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "replaces the payload of dead letter event with the fixed one so that it can be replayed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-api"
+                ],
+                "summary": "fixes up the dead letter event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "dead letter event id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update dead letter event request",
+                        "name": "updateDeadLetterEventRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.saveScooterTripEventRequest"
+                            "$ref": "#/definitions/rest.updateDeadLetterEventRequest"
                         }
                     },
                     {
                         "type": "string",
-                        "description": "api_key",
+                        "description": "admin api key",
                         "name": "api_key",
                         "in": "query",
                         "required": true
@@ -59,37 +197,33 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.saveScooterTripEventResponse"
+                            "$ref": "#/definitions/rest.deadLetterEvent"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     }
                 }
             }
         },
-        "/auth/user/available-scooters": {
-            "get": {
-                "description": "returns available scooters within given radius sorted by nearest first",
+        "/v1/auth/admin/dead-letter-events/{id}/replay": {
+            "post": {
+                "description": "parses the payload of dead letter event and saves it as trip event. The event is marked as replayed on success.",
                 "consumes": [
                     "application/json"
                 ],
@@ -97,34 +231,77 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user-api"
+                    "admin-api"
                 ],
-                "summary": "returns available scooters within given area",
+                "summary": "replays the dead letter event",
                 "parameters": [
                     {
-                        "type": "number",
-                        "description": "latitude",
-                        "name": "latitude",
-                        "in": "query",
+                        "type": "string",
+                        "description": "dead letter event id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "longitude",
-                        "name": "longitude",
+                        "type": "string",
+                        "description": "admin api key",
+                        "name": "api_key",
                         "in": "query",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.replayDeadLetterEventResponse"
+                        }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/admin/scooters/{id}/position": {
+            "get": {
+                "description": "returns last known location of the scooter updated by its trip location events, along with the time of the location and whether it is stale. The location is stale if its time is unknown or the scooter in trip has not sent its location for 5 minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-api"
+                ],
+                "summary": "returns last known position of the scooter",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "radius(in meters)",
-                        "name": "radius",
-                        "in": "query",
+                        "type": "string",
+                        "description": "scooter id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "api_key",
+                        "description": "admin api key",
                         "name": "api_key",
                         "in": "query",
                         "required": true
@@ -134,37 +311,33 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.getAvailableScootersResponse"
+                            "$ref": "#/definitions/rest.scooterPosition"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     }
                 }
             }
         },
-        "/auth/user/begin-trip": {
-            "put": {
-                "description": "begins the trip for given user with given scooter, scooter becomes unavailable for other users once the trip begins",
+        "/v1/auth/scooter/trip-event": {
+            "post": {
+                "description": "saves the events generated by scooter when trip is started, ended and during the trip. The events which could not be saved are moved to dead letter events.",
                 "consumes": [
                     "application/json"
                 ],
@@ -172,18 +345,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user-api"
+                    "scooter-api"
                 ],
-                "summary": "begins the trip",
+                "summary": "saves the trip event generated by scooter",
                 "parameters": [
                     {
-                        "description": "begin trip request",
-                        "name": "beginTripRequest",
+                        "description": "save trip event request",
+                        "name": "saveScooterTripEventRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.beginTripRequest"
+                            "$ref": "#/definitions/rest.saveScooterTripEventRequest"
                         }
                     },
                     {
@@ -198,37 +370,45 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.beginTripResponse"
+                            "$ref": "#/definitions/rest.saveScooterTripEventResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
                         }
                     }
                 }
             }
         },
-        "/auth/user/end-trip": {
-            "put": {
-                "description": "ends the trip for given user with given scooter, scooter becomes available for other users once the trip ends. The scooter location is updated with current location.",
+        "/v1/auth/scooter/trip-events": {
+            "post": {
+                "description": "saves the batch of events generated by scooter e.g. offline backlog of the scooter. Each event is validated independently and must have an id, saving already saved event is a no-op. The result of each event is returned at the same index as in the request. The events which could not be saved are moved to dead letter events.",
                 "consumes": [
                     "application/json"
                 ],
@@ -236,18 +416,17 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user-api"
+                    "scooter-api"
                 ],
-                "summary": "ends the trip",
+                "summary": "saves the batch of trip events generated by scooter",
                 "parameters": [
                     {
-                        "description": "end trip request",
-                        "name": "endTripRequest",
+                        "description": "save trip events request",
+                        "name": "saveScooterTripEventsRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.endTripRequest"
+                            "$ref": "#/definitions/rest.saveScooterTripEventsRequest"
                         }
                     },
                     {
@@ -262,74 +441,1073 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.endTripResponse"
+                            "$ref": "#/definitions/rest.saveScooterTripEventsResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/rest.errorRespose"
+                            "$ref": "#/definitions/rest.problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "rest.beginTripRequest": {
-            "type": "object",
-            "required": [
-                "scooter_id",
-                "user_id"
-            ],
-            "properties": {
-                "scooter_id": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "rest.beginTripResponse": {
-            "type": "object",
-            "properties": {
-                "scooter_id": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
-                }
-            }
         },
-        "rest.endTripRequest": {
-            "type": "object",
-            "required": [
-                "scooter_id",
-                "user_id"
-            ],
-            "properties": {
-                "location": {
-                    "type": "object",
-                    "$ref": "#/definitions/rest.geoLocation"
-                },
-                "scooter_id": {
+        "/v1/auth/user/available-scooters": {
+            "get": {
+                "description": "returns available scooters within given radius sorted by nearest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-api"
+                ],
+                "summary": "returns available scooters within given area",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "latitude",
+                        "name": "latitude",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "longitude",
+                        "name": "longitude",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "radius(in meters)",
+                        "name": "radius",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "api_key",
+                        "name": "api_key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.getAvailableScootersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/user/begin-trip": {
+            "put": {
+                "description": "begins the trip for given user with given scooter, scooter becomes unavailable for other users once the trip begins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-api"
+                ],
+                "summary": "begins the trip",
+                "parameters": [
+                    {
+                        "description": "begin trip request",
+                        "name": "beginTripRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.beginTripRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "api_key",
+                        "name": "api_key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.beginTripResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/user/end-trip": {
+            "put": {
+                "description": "ends the trip for given user with given scooter, scooter becomes available for other users once the trip ends. The scooter location is updated with current location.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-api"
+                ],
+                "summary": "ends the trip",
+                "parameters": [
+                    {
+                        "description": "end trip request",
+                        "name": "endTripRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.endTripRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "api_key",
+                        "name": "api_key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.endTripResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/scooters": {
+            "get": {
+                "description": "searches available scooters near the location, within the bounding box e.g. map viewport or within the polygon. With near the scooters are sorted by nearest first and have the distance(in meters), with near and without radius, bbox or polygon the nearest scooters up to limit are returned. Without near the scooters are sorted by id. The scooters are paginated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-scooters"
+                ],
+                "summary": "searches available scooters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "location as latitude,longitude e.g. 52.52,13.405",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius(in meters) from near",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "bounding box as south west latitude,longitude,north east latitude,longitude e.g. 52.51,13.39,52.53,13.42",
+                        "name": "bbox",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "polygon as latitude,longitude of each point e.g. 52.51,13.39,52.53,13.39,52.53,13.42",
+                        "name": "polygon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "vehicle type e.g. scooter",
+                        "name": "vehicle_type",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "min battery level(in percent)",
+                        "name": "min_battery",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "max number of scooters of the page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.scooterPage"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/scooters/clusters": {
+            "get": {
+                "description": "returns the available scooters within the bounding box e.g. map viewport grouped into clusters for the zoom level of the map, the cluster has the number of scooters and their centroid. The cluster of one scooter has the scooter, above zoom level 16 each scooter is returned as its own cluster up to 500 scooters. The bounding box more than 8 map tiles of the zoom level across is rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-scooters"
+                ],
+                "summary": "returns the clusters of available scooters within the viewport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "bounding box as south west latitude,longitude,north east latitude,longitude e.g. 52.51,13.39,52.53,13.42",
+                        "name": "bbox",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 22,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "zoom level of the map",
+                        "name": "zoom",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.scooterClustersResponse"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/scooters/{id}": {
+            "get": {
+                "description": "returns the scooter for given id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-scooters"
+                ],
+                "summary": "returns the scooter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "scooter id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.scooter"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/scooters/{id}/events": {
+            "post": {
+                "description": "saves the event generated by the scooter when trip is started, ended and during the trip. The id of the event is derived from the event if it is not set, so that the retried event gets the same id, saving already saved event is a no-op. Hence the identical events (same user, location, created_at and type) sent without id are saved once, the scooter which can send such events must set the id. The events which could not be saved are moved to dead letter events.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-scooters"
+                ],
+                "summary": "saves the trip event generated by the scooter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "scooter id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "trip event",
+                        "name": "createScooterEventRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.createScooterEventRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/rest.tripEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/tiles/{z}/{x}/{y}.mvt": {
+            "get": {
+                "description": "returns Mapbox Vector Tile of the Web Mercator tile grid. The scooters layer has the available scooters, the scooters within the same pixel of the rendered tile are merged into one point with their count. The trip_heat layer has the number of trip events of the last 24 hours in cells of 4 pixels. The service has no geofence zones, hence there is no zones layer. The layers without features are left out of the tile.",
+                "produces": [
+                    "application/vnd.mapbox-vector-tile"
+                ],
+                "tags": [
+                    "v2-tiles"
+                ],
+                "summary": "returns the map tile of available scooters",
+                "parameters": [
+                    {
+                        "maximum": 22,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "zoom level",
+                        "name": "z",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tile column",
+                        "name": "x",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tile row with .mvt extension e.g. 1343.mvt",
+                        "name": "y",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "scooters",
+                        "description": "comma separated layers, scooters and trip_heat",
+                        "name": "layers",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/trips": {
+            "post": {
+                "description": "starts the trip for given user with given scooter, scooter becomes unavailable for other users once the trip starts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-trips"
+                ],
+                "summary": "starts the trip",
+                "parameters": [
+                    {
+                        "description": "start trip request",
+                        "name": "startTripRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.startTripRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/rest.trip"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "url of the trip"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/trips/{id}": {
+            "get": {
+                "description": "returns the trip for given id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-trips"
+                ],
+                "summary": "returns the trip",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "trip id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.trip"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/trips/{id}/end": {
+            "post": {
+                "description": "ends the trip for given id, scooter becomes available for other users at the location once the trip ends",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-trips"
+                ],
+                "summary": "ends the trip",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "trip id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "end trip request",
+                        "name": "finishTripRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.finishTripRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.trip"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/trips/{id}/events": {
+            "get": {
+                "description": "lists the events sent by the scooter during the trip in order of creation, the events are paginated",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2-trips"
+                ],
+                "summary": "lists the events of the trip",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "trip id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "trip_start",
+                            "trip_stop",
+                            "trip_location_update"
+                        ],
+                        "type": "string",
+                        "description": "event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "events created at or after the time(RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "events created before the time(RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "max number of events of the page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key",
+                        "name": "X-API-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.tripEventPage"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.beginTripRequest": {
+            "type": "object",
+            "required": [
+                "scooter_id",
+                "user_id"
+            ],
+            "properties": {
+                "scooter_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.beginTripResponse": {
+            "type": "object",
+            "properties": {
+                "scooter_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.createScooterEventRequest": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "location",
+                "type",
+                "user_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.deadLetterEvent": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "replayed_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.endTripRequest": {
+            "type": "object",
+            "required": [
+                "location",
+                "scooter_id",
+                "user_id"
+            ],
+            "properties": {
+                "location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "scooter_id": {
                     "type": "string"
                 },
                 "user_id": {
@@ -341,7 +1519,6 @@ var doc = `{
             "type": "object",
             "properties": {
                 "location": {
-                    "type": "object",
                     "$ref": "#/definitions/rest.geoLocation"
                 },
                 "scooter_id": {
@@ -352,14 +1529,31 @@ var doc = `{
                 }
             }
         },
-        "rest.errorRespose": {
+        "rest.fieldError": {
             "type": "object",
             "properties": {
-                "errorMessage": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
                     "type": "string"
                 }
             }
         },
+        "rest.finishTripRequest": {
+            "type": "object",
+            "required": [
+                "location"
+            ],
+            "properties": {
+                "location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                }
+            }
+        },
         "rest.geoLocation": {
             "type": "object",
             "required": [
@@ -386,10 +1580,62 @@ var doc = `{
                 }
             }
         },
+        "rest.getDeadLetterEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.deadLetterEvent"
+                    }
+                }
+            }
+        },
+        "rest.problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.fieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "traceId": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.replayDeadLetterEventResponse": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
         "rest.saveScooterTripEventRequest": {
             "type": "object",
             "required": [
                 "created_at",
+                "location",
                 "scooter_id",
                 "type",
                 "user_id"
@@ -398,8 +1644,10 @@ var doc = `{
                 "created_at": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
                 "location": {
-                    "type": "object",
                     "$ref": "#/definitions/rest.geoLocation"
                 },
                 "scooter_id": {
@@ -421,9 +1669,38 @@ var doc = `{
                 }
             }
         },
+        "rest.saveScooterTripEventsRequest": {
+            "type": "object",
+            "required": [
+                "events"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "rest.saveScooterTripEventsResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.tripEventResult"
+                    }
+                }
+            }
+        },
         "rest.scooter": {
             "type": "object",
             "properties": {
+                "battery_level": {
+                    "type": "integer"
+                },
                 "current_user_id": {
                     "type": "string"
                 },
@@ -434,44 +1711,242 @@ var doc = `{
                     "type": "boolean"
                 },
                 "location": {
-                    "type": "object",
                     "$ref": "#/definitions/rest.geoLocation"
                 },
                 "name": {
                     "type": "string"
+                },
+                "vehicle_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.scooterCluster": {
+            "type": "object",
+            "properties": {
+                "center": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "scooter": {
+                    "$ref": "#/definitions/rest.scooter"
+                }
+            }
+        },
+        "rest.scooterClustersResponse": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.scooterCluster"
+                    }
+                },
+                "zoom": {
+                    "type": "integer"
+                }
+            }
+        },
+        "rest.scooterMatch": {
+            "type": "object",
+            "properties": {
+                "battery_level": {
+                    "type": "integer"
+                },
+                "current_user_id": {
+                    "type": "string"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "vehicle_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.scooterPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.scooterMatch"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.scooterPosition": {
+            "type": "object",
+            "properties": {
+                "age_seconds": {
+                    "type": "number"
+                },
+                "in_trip": {
+                    "type": "boolean"
+                },
+                "location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "scooter_id": {
+                    "type": "string"
+                },
+                "stale": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is null if the time of the location is unknown",
+                    "type": "string"
+                }
+            }
+        },
+        "rest.startTripRequest": {
+            "type": "object",
+            "required": [
+                "scooter_id",
+                "user_id"
+            ],
+            "properties": {
+                "scooter_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.trip": {
+            "type": "object",
+            "properties": {
+                "end_location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "scooter_id": {
+                    "type": "string"
+                },
+                "start_location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "in_progress",
+                        "ended"
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.tripEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/rest.geoLocation"
+                },
+                "scooter_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.tripEventPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rest.tripEvent"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.tripEventResult": {
+            "type": "object",
+            "properties": {
+                "errorCode": {
+                    "type": "string"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "rest.updateDeadLetterEventRequest": {
+            "type": "object",
+            "required": [
+                "payload"
+            ],
+            "properties": {
+                "payload": {
+                    "type": "string"
                 }
             }
         }
     }
 }`
 
-type swaggerInfo struct {
-	Version     string
-	Host        string
-	BasePath    string
-	Title       string
-	Description string
-}
-
 // SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo swaggerInfo
-
-type s struct{}
-
-func (s *s) ReadDoc() string {
-	t, err := template.New("swagger_info").Parse(doc)
-	if err != nil {
-		return doc
-	}
-
-	var tpl bytes.Buffer
-	if err := t.Execute(&tpl, SwaggerInfo); err != nil {
-		return doc
-	}
-
-	return tpl.String()
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "",
+	Schemes:          []string{},
+	Title:            "Scootin Aboot Journey API",
+	Description:      "A REST server to manage scooter trips and scooter events",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
 }
 
 func init() {
-	swag.Register(swag.Name, &s{})
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
 }
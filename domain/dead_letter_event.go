@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// DeadLetterEvent represents trip event which could not be saved, it keeps
+// the original payload along with the reason of failure so that it can be
+// fixed and replayed later
+type DeadLetterEvent struct {
+	ID         string
+	Payload    string
+	Error      string
+	Attempts   int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ReplayedAt *time.Time
+}
+
+// IsReplayed returns true if the event is already replayed successfully
+func (d *DeadLetterEvent) IsReplayed() bool {
+	return d.ReplayedAt != nil
+}
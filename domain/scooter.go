@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // Scooter represents scooter details
 type Scooter struct {
 	ID            string
@@ -7,4 +9,29 @@ type Scooter struct {
 	Location      GeoLocation
 	CurrentUserID *string
 	IsAvailable   bool
+	// VehicleType is the kind of the vehicle e.g. scooter or bike, empty if
+	// it is not known
+	VehicleType string
+	// BatteryLevel is the charge of the battery in percent
+	BatteryLevel int
+	// LocationUpdatedAt is the time of the location, zero if the location is
+	// not updated since the scooter was added
+	LocationUpdatedAt time.Time
+}
+
+// ScooterPosition is the last known location of the scooter and how fresh
+// it is
+type ScooterPosition struct {
+	ScooterID string
+	Location  GeoLocation
+	// UpdatedAt is zero if the time of the location is unknown
+	UpdatedAt time.Time
+	// Age is the time since the location was updated
+	Age time.Duration
+	// InTrip is true if the scooter is used by the user, its location is
+	// expected to be updated by the trip events
+	InTrip bool
+	// Stale is true if the location is unknown or the scooter in trip has
+	// not sent location for a while
+	Stale bool
 }
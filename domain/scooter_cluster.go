@@ -0,0 +1,10 @@
+package domain
+
+// ScooterCluster represents the available scooters close to each other on the
+// map, the center is the centroid of the scooters. The scooter is set if the
+// cluster has only one scooter
+type ScooterCluster struct {
+	Center  GeoLocation
+	Count   int
+	Scooter *Scooter
+}
@@ -0,0 +1,31 @@
+package domain
+
+// BoundingBox represents the area between the south west and the north east
+// corner e.g. map viewport
+type BoundingBox struct {
+	SouthWest GeoLocation
+	NorthEast GeoLocation
+}
+
+// ScooterQuery represents the search of available scooters. The scooters are
+// within the bounding box or the polygon if set and within the radius(in
+// meters) of near if set. The scooters are sorted by nearest first when near
+// is set, with limit and without radius it returns the k nearest scooters.
+// Offset and limit select the page of the result, zero limit is no limit.
+type ScooterQuery struct {
+	Near            *GeoLocation
+	Radius          float64
+	BoundingBox     *BoundingBox
+	Polygon         []GeoLocation
+	VehicleType     string
+	MinBatteryLevel int
+	Offset          int
+	Limit           int
+}
+
+// ScooterMatch represents the scooter found by the search with its distance
+// in meters from near of the query, the distance is nil if near is not set
+type ScooterMatch struct {
+	Scooter
+	Distance *float64
+}
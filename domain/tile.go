@@ -0,0 +1,61 @@
+package domain
+
+import "math"
+
+// Tile represents the map tile of the Web Mercator tile grid, the zoom level
+// z has 2^z x 2^z tiles numbered from the north west corner of the map
+type Tile struct {
+	Z int
+	X int
+	Y int
+}
+
+// IsValid returns true if the tile is on the tile grid of its zoom level
+func (t Tile) IsValid() bool {
+	if t.Z < 0 || t.Z > 30 {
+		return false
+	}
+	n := 1 << uint(t.Z)
+	return t.X >= 0 && t.X < n && t.Y >= 0 && t.Y < n
+}
+
+// Bounds returns the area covered by the tile
+func (t Tile) Bounds() BoundingBox {
+	return BoundingBox{
+		SouthWest: GeoLocation{
+			Latitude:  tileLatitude(t.Y+1, t.Z),
+			Longitude: tileLongitude(t.X, t.Z),
+		},
+		NorthEast: GeoLocation{
+			Latitude:  tileLatitude(t.Y, t.Z),
+			Longitude: tileLongitude(t.X+1, t.Z),
+		},
+	}
+}
+
+// tileLongitude returns the longitude of the west edge of the tile column
+func tileLongitude(x int, z int) float64 {
+	return float64(x)/math.Exp2(float64(z))*360 - 180
+}
+
+// tileLatitude returns the latitude of the north edge of the tile row
+func tileLatitude(y int, z int) float64 {
+	n := math.Pi * (1 - 2*float64(y)/math.Exp2(float64(z)))
+	return math.Atan(math.Sinh(n)) * 180 / math.Pi
+}
+
+// HeatCell represents the number of trip events within the cell of the grid,
+// the center is the centroid of the events
+type HeatCell struct {
+	Center GeoLocation
+	Count  int
+}
+
+// MapTile represents the content of the map tile, the available scooters
+// close to each other are grouped into clusters and the trip heat is set only
+// if it was requested
+type MapTile struct {
+	Tile     Tile
+	Scooters []ScooterCluster
+	TripHeat []HeatCell
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Trip represents the ride of user with scooter from the begin to the end of
+// the trip, the end is not set while the trip is in progress
+type Trip struct {
+	ID            string
+	UserID        string
+	ScooterID     string
+	StartLocation GeoLocation
+	EndLocation   *GeoLocation
+	StartedAt     time.Time
+	EndedAt       *time.Time
+}
+
+// IsEnded returns true if the trip has ended
+func (t *Trip) IsEnded() bool {
+	return t.EndedAt != nil
+}
@@ -0,0 +1,115 @@
+package fixtures
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+var (
+	//go:embed *.json
+	files embed.FS
+)
+
+type geoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type scooter struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Location     geoLocation `json:"location"`
+	IsAvailable  bool        `json:"is_available"`
+	VehicleType  string      `json:"vehicle_type"`
+	BatteryLevel int         `json:"battery_level"`
+}
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Scooters returns the sample scooters, they are not on trip
+func Scooters() ([]domain.Scooter, error) {
+	records := []scooter{}
+	err := load("scooters.json", &records)
+	if err != nil {
+		return nil, err
+	}
+
+	scooters := make([]domain.Scooter, 0, len(records))
+	for _, r := range records {
+		scooters = append(scooters, domain.Scooter{
+			ID:   r.ID,
+			Name: r.Name,
+			Location: domain.GeoLocation{
+				Latitude:  r.Location.Latitude,
+				Longitude: r.Location.Longitude,
+			},
+			IsAvailable:  r.IsAvailable,
+			VehicleType:  r.VehicleType,
+			BatteryLevel: r.BatteryLevel,
+		})
+	}
+	return scooters, nil
+}
+
+// Users returns the sample users
+func Users() ([]domain.User, error) {
+	records := []user{}
+	err := load("users.json", &records)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, 0, len(records))
+	for _, r := range records {
+		users = append(users, domain.User{
+			ID:   r.ID,
+			Name: r.Name,
+		})
+	}
+	return users, nil
+}
+
+// Seed saves the sample scooters and users in the database, the records with
+// same id are replaced so seeding again resets them
+func Seed(ctx context.Context, database db.DB) error {
+	scooters, err := Scooters()
+	if err != nil {
+		return err
+	}
+	err = database.UpsertScooters(ctx, scooters)
+	if err != nil {
+		return fmt.Errorf("unable to seed scooters: %w", err)
+	}
+
+	users, err := Users()
+	if err != nil {
+		return err
+	}
+	err = database.UpsertUsers(ctx, users)
+	if err != nil {
+		return fmt.Errorf("unable to seed users: %w", err)
+	}
+	return nil
+}
+
+// load decodes the fixture file
+func load(name string, records interface{}) error {
+	bs, err := files.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(bs, records)
+	if err != nil {
+		return fmt.Errorf("invalid fixture %v: %w", name, err)
+	}
+	return nil
+}
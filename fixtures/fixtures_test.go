@@ -0,0 +1,87 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestScooters(t *testing.T) {
+	scooters, err := Scooters()
+	if err != nil {
+		t.Fatalf("Scooters() error = %v", err)
+	}
+	if len(scooters) == 0 {
+		t.Fatalf("Scooters() returned no scooters")
+	}
+	for _, s := range scooters {
+		if s.ID == "" || s.Name == "" || !s.IsAvailable || s.CurrentUserID != nil {
+			t.Errorf("Scooters() invalid scooter %+v", s)
+		}
+	}
+}
+
+func TestUsers(t *testing.T) {
+	users, err := Users()
+	if err != nil {
+		t.Fatalf("Users() error = %v", err)
+	}
+	if len(users) == 0 {
+		t.Fatalf("Users() returned no users")
+	}
+	for _, u := range users {
+		if u.ID == "" || u.Name == "" {
+			t.Errorf("Users() invalid user %+v", u)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	scooters, _ := Scooters()
+	users, _ := Users()
+
+	tests := []struct {
+		name    string
+		prepare func(database *mocks.MockDB)
+		wantErr error
+	}{
+		{
+			name: "should save scooters and users",
+			prepare: func(database *mocks.MockDB) {
+				database.EXPECT().UpsertScooters(ctx, scooters).Return(nil).Times(1)
+				database.EXPECT().UpsertUsers(ctx, users).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "should return error if scooters are not saved",
+			prepare: func(database *mocks.MockDB) {
+				database.EXPECT().UpsertScooters(ctx, gomock.Any()).Return(db.ErrUnavailable).Times(1)
+			},
+			wantErr: db.ErrUnavailable,
+		},
+		{
+			name: "should return error if users are not saved",
+			prepare: func(database *mocks.MockDB) {
+				database.EXPECT().UpsertScooters(ctx, gomock.Any()).Return(nil).Times(1)
+				database.EXPECT().UpsertUsers(ctx, gomock.Any()).Return(db.ErrUnavailable).Times(1)
+			},
+			wantErr: db.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := mocks.NewMockDB(gomock.NewController(t))
+			tt.prepare(database)
+
+			err := Seed(ctx, database)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Seed() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+
+	// checkTimeout is the max time of one check
+	checkTimeout = 2 * time.Second
+)
+
+var (
+	ErrDraining = errors.New("service is draining")
+)
+
+// Check checks one dependency, it returns optional detail of the dependency
+// state e.g. migration version and error if the dependency is not ready
+type Check func(ctx context.Context) (string, error)
+
+// CheckResult represents the result of one check
+type CheckResult struct {
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report represents the result of all checks
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs the readiness checks, it reports failure once it is draining
+type Checker struct {
+	mu       sync.RWMutex
+	checks   map[string]Check
+	draining int32
+}
+
+// NewChecker creates checker without checks
+func NewChecker() *Checker {
+	return &Checker{
+		checks: map[string]Check{},
+	}
+}
+
+// Add adds the check with given name, the check with same name is replaced
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Drain makes the readiness fail so that load balancers stop sending requests
+func (c *Checker) Drain() {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+// Draining returns true once the checker is draining
+func (c *Checker) Draining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+// Check runs all the checks concurrently, the report status is ok only if all
+// the checks passed. The checks are not run once the checker is draining
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: map[string]CheckResult{},
+	}
+	if c.Draining() {
+		report.Status = StatusDraining
+		return report
+	}
+
+	c.mu.RLock()
+	names := []string{}
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = run(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run runs the check with timeout
+func run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	result := CheckResult{
+		Status:     StatusOK,
+		Detail:     detail,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		drain      bool
+		wantStatus string
+		wantChecks map[string]CheckResult
+	}{
+		{
+			name:       "should return ok without checks",
+			wantStatus: StatusOK,
+			wantChecks: map[string]CheckResult{},
+		},
+		{
+			name: "should return ok if all checks pass",
+			checks: map[string]Check{
+				"mongo":     func(ctx context.Context) (string, error) { return "", nil },
+				"migration": func(ctx context.Context) (string, error) { return "version 5", nil },
+			},
+			wantStatus: StatusOK,
+			wantChecks: map[string]CheckResult{
+				"mongo":     {Status: StatusOK},
+				"migration": {Status: StatusOK, Detail: "version 5"},
+			},
+		},
+		{
+			name: "should return fail with error detail if one check fails",
+			checks: map[string]Check{
+				"mongo":     func(ctx context.Context) (string, error) { return "", errors.New("unreachable") },
+				"migration": func(ctx context.Context) (string, error) { return "version 5", nil },
+			},
+			wantStatus: StatusFail,
+			wantChecks: map[string]CheckResult{
+				"mongo":     {Status: StatusFail, Error: "unreachable"},
+				"migration": {Status: StatusOK, Detail: "version 5"},
+			},
+		},
+		{
+			name: "should return draining without running checks",
+			checks: map[string]Check{
+				"mongo": func(ctx context.Context) (string, error) {
+					t.Errorf("check run while draining")
+					return "", nil
+				},
+			},
+			drain:      true,
+			wantStatus: StatusDraining,
+			wantChecks: map[string]CheckResult{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker()
+			for name, check := range tt.checks {
+				c.Add(name, check)
+			}
+			if tt.drain {
+				c.Drain()
+			}
+
+			got := c.Check(context.Background())
+			if got.Status != tt.wantStatus {
+				t.Errorf("Check() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if len(got.Checks) != len(tt.wantChecks) {
+				t.Fatalf("Check() checks = %v, want %v", got.Checks, tt.wantChecks)
+			}
+			for name, want := range tt.wantChecks {
+				result := got.Checks[name]
+				result.DurationMS = 0
+				if result != want {
+					t.Errorf("Check() %v = %v, want %v", name, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	c := NewChecker()
+	c.Add("slow", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	got := c.Check(ctx)
+	if got.Status != StatusFail || got.Checks["slow"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Check() = %v, want failed slow check", got)
+	}
+}
+
+func TestMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"00001_scooter.up.mongodb",
+		"00001_scooter.down.mongodb",
+		"00002_user.up.mongodb",
+		"00005_trip_event.up.mongodb",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	driver, err := source.Open("file://" + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close()
+	got, err := MigrationVersion(driver)
+	if err != nil {
+		t.Fatalf("MigrationVersion() error = %v", err)
+	}
+	if got != 5 {
+		t.Errorf("MigrationVersion() = %v, want %v", got, 5)
+	}
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// MigrationVersion returns the version of migrations which is expected to
+// be applied i.e. the latest version in the migration source
+func MigrationVersion(driver source.Driver) (uint, error) {
+	version, err := driver.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := driver.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// NewMigrationCheck returns check which fails if the applied migration is
+// not at expected version or it is dirty
+func NewMigrationCheck(m *migrate.Migrate, expected uint) Check {
+	return func(ctx context.Context) (string, error) {
+		version, dirty, err := m.Version()
+		if err != nil {
+			return "", err
+		}
+
+		detail := fmt.Sprintf("version %v, expected %v", version, expected)
+		if dirty {
+			return detail, fmt.Errorf("migration %v is dirty", version)
+		}
+		if version != expected {
+			return detail, fmt.Errorf("migration version %v, expected %v", version, expected)
+		}
+		return detail, nil
+	}
+}
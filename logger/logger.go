@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	// RequestIDHeader is the header used to propagate request id
+	RequestIDHeader = "X-Request-ID"
+
+	redacted = "REDACTED"
+)
+
+var (
+	// sensitiveKeys are the query params and log fields which are redacted
+	sensitiveKeys = map[string]bool{
+		"api_key":       true,
+		"apikey":        true,
+		"authorization": true,
+		"password":      true,
+		"token":         true,
+	}
+)
+
+var (
+	discardLogger = &logrus.Logger{
+		Out:       io.Discard,
+		Formatter: &logrus.JSONFormatter{},
+		Hooks:     logrus.LevelHooks{},
+		Level:     logrus.PanicLevel,
+	}
+)
+
+type requestIDKey struct{}
+
+// New creates structured logger which writes to given writer in given format
+// and level, the sensitive fields are redacted
+func New(out io.Writer, format string, level string) (*logrus.Logger, error) {
+	l := logrus.New()
+	l.SetOutput(out)
+	l.AddHook(redactHook{})
+
+	switch format {
+	case FormatJSON:
+		l.SetFormatter(&logrus.JSONFormatter{})
+	case FormatText:
+		l.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return nil, fmt.Errorf("invalid log format: %v", format)
+	}
+
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+	l.SetLevel(logLevel)
+	return l, nil
+}
+
+// Discard returns logger which drops all the logs, useful as default logger
+func Discard() logrus.FieldLogger {
+	return discardLogger
+}
+
+// WithRequestID returns context with given request id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns request id of the context, empty if there is none
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns logger with request id and trace id of the context
+func FromContext(ctx context.Context, l logrus.FieldLogger) logrus.FieldLogger {
+	fields := logrus.Fields{}
+	if requestID := RequestID(ctx); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		fields["trace_id"] = spanContext.TraceID().String()
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.WithFields(fields)
+}
+
+// RedactQuery returns the query with values of sensitive params e.g. api_key redacted
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+	for key := range query {
+		if sensitiveKeys[strings.ToLower(key)] {
+			query[key] = []string{redacted}
+		}
+	}
+	return query.Encode()
+}
+
+// redactHook redacts the values of sensitive log fields
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	for key := range entry.Data {
+		if sensitiveKeys[strings.ToLower(key)] {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	type args struct {
+		format string
+		level  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "should return error for invalid format",
+			args: args{
+				format: "xml",
+				level:  "info",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return error for invalid level",
+			args: args{
+				format: FormatJSON,
+				level:  "verbose",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should return text logger",
+			args: args{
+				format: FormatText,
+				level:  "debug",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tt.args.format, tt.args.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	out := &bytes.Buffer{}
+	l, err := New(out, FormatJSON, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = WithRequestID(ctx, "requestid")
+
+	FromContext(ctx, l).WithField("api_key", "secretkey").Info("test")
+
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"request_id": "requestid",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"api_key":    redacted,
+		"msg":        "test",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("log field %v = %v, want %v", key, entry[key], value)
+		}
+	}
+
+	if got := FromContext(context.Background(), l); got != l {
+		t.Errorf("FromContext() without request id = %v, want logger", got)
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+		want     string
+	}{
+		{
+			name:     "should return empty query",
+			rawQuery: "",
+			want:     "",
+		},
+		{
+			name:     "should redact api key",
+			rawQuery: "radius=10&api_key=secretkey",
+			want:     "api_key=REDACTED&radius=10",
+		},
+		{
+			name:     "should redact invalid query",
+			rawQuery: "api_key=%zz",
+			want:     redacted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactQuery(tt.rawQuery); got != tt.want {
+				t.Errorf("RedactQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
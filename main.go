@@ -1,118 +1,102 @@
 package main
 
 import (
-	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
-	"github.com/ganeshdipdumbare/scootin-aboot-journey/api/rest"
-	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
 	"github.com/ganeshdipdumbare/scootin-aboot-journey/config"
-	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/mongodb"
-	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
-	"github.com/ganeshdipdumbare/scootin-aboot-journey/testclient"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/mongodb"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/spf13/pflag"
+)
+
+const usage = `usage: scootin-aboot-journey [flags] <command>
+
+commands:
+  serve                   run the server, the database is migrated up if migration.auto is set
+  migrate up [N]          apply all or next N migrations
+  migrate down [N|all]    revert last N (default 1) or all migrations
+  migrate status          print applied and latest migration version
+  migrate force V         set migration version without running it, used to fix dirty migration
+  seed                    save sample scooters and users in the database
+  simulate                run simulated riders of simulator.scenario_file and print report
+  config print            print effective config with secrets redacted
+
+run with --help to list the flags
+`
+
+var (
+	errUsage = errors.New("invalid command")
 )
 
 // @title Scootin Aboot Journey API
 // @version 1.0
 // @description A REST server to manage scooter trips and scooter events
 func main() {
-	// migrate reference data - product collection
-	m, err := migrate.New(
-		config.Get().MigrationFilesPath,
-		config.Get().MongoUri+"/"+config.Get().MongoDb)
-	if err != nil {
-		log.Fatal(err)
+	args, err := config.Load(os.Args[1:])
+	if errors.Is(err, pflag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return
 	}
-	if err := m.Up(); err != nil {
-		if err != migrate.ErrNoChange {
-			log.Fatal(err)
-		}
-	}
-	// complete migration
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	database, err := mongodb.NewMongoDB(config.Get().MongoUri, config.Get().MongoDb)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
-	defer database.Disconnect(ctx)
 
-	scooterApp, err := app.NewApp(database)
-	if err != nil {
-		log.Fatal(err)
+	err = run(args)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "%v: %v\n\n%v", err, strings.Join(args, " "), usage)
+		os.Exit(2)
 	}
-
-	restApi, err := rest.NewApi(scooterApp, config.Get().Port, config.Get().ApiKey)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	restApi.StartServer()
-
-	startTestClients()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Println("Shutting down server...")
-	restApi.GracefulStopServer()
 }
 
-func startTestClients() {
-	port := config.Get().Port
-	apiKey := config.Get().ApiKey
+// run runs the command in args
+func run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
 
-	testClientRequests := []*testclient.NewTestClientReq{
-		{
-			Port:   port,
-			UserID: "6124edb7-5099-4147-87e6-0c9b93cd1fdb",
-			CurrentLocation: &domain.GeoLocation{
-				Latitude:  52.54664741862859,
-				Longitude: 13.351253969417021,
-			},
-			TravelTime: 10 * time.Second,
-			RestTime:   2 * time.Second,
-			ApiKey:     apiKey,
-			Radius:     1,
-		},
-		{
-			Port:   port,
-			UserID: "f3b9842c-182a-418b-92fd-95d4f46414c5",
-			CurrentLocation: &domain.GeoLocation{
-				Latitude:  -73.961704,
-				Longitude: 40.662942,
-			},
-			TravelTime: 12 * time.Second,
-			RestTime:   3 * time.Second,
-			ApiKey:     apiKey,
-			Radius:     1,
-		},
-		{
-			Port:   port,
-			UserID: "4668a2f7-c498-4e49-a82e-380c1ede0685",
-			CurrentLocation: &domain.GeoLocation{
-				Latitude:  -73.98241999999999,
-				Longitude: 40.579505,
-			},
-			TravelTime: 15 * time.Second,
-			RestTime:   5 * time.Second,
-			ApiKey:     apiKey,
-			Radius:     1,
-		},
+	if args[0] == "config" {
+		if len(args) != 2 || args[1] != "print" {
+			return errUsage
+		}
+		return config.Get().Print(os.Stdout)
 	}
 
-	for _, v := range testClientRequests {
-		req := v
-		go testclient.NewTestClient(req).StartJourney()
+	serviceLogger, err := logger.New(os.Stdout, config.Get().Log.Format, config.Get().Log.Level)
+	if err != nil {
+		return err
 	}
+	// the logs of libraries using standard logger are written as structured logs
+	log.SetFlags(0)
+	log.SetOutput(serviceLogger.Writer())
 
+	switch args[0] {
+	case "serve":
+		if len(args) != 1 {
+			return errUsage
+		}
+		serve(serviceLogger)
+		return nil
+	case "migrate":
+		return runMigrate(serviceLogger, os.Stdout, args[1:])
+	case "seed":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return seed(serviceLogger)
+	case "simulate":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return simulate(serviceLogger, os.Stdout)
+	default:
+		return errUsage
+	}
 }
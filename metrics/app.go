@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// appMetrics wraps the app and counts the outcomes of use cases
+type appMetrics struct {
+	app app.App
+}
+
+// NewApp returns the app which counts the outcomes of given app use cases
+func NewApp(a app.App) app.App {
+	return &appMetrics{
+		app: a,
+	}
+}
+
+// observe counts the use case call by outcome of the error
+func observe(useCase string, err error) {
+	useCaseTotal.WithLabelValues(useCase, appOutcome(err)).Inc()
+}
+
+func (a *appMetrics) GetNearbyAvailableScooters(ctx context.Context, location domain.GeoLocation, radius int) ([]domain.Scooter, error) {
+	scooters, err := a.app.GetNearbyAvailableScooters(ctx, location, radius)
+	observe("get_nearby_available_scooters", err)
+	return scooters, err
+}
+
+func (a *appMetrics) SearchAvailableScooters(ctx context.Context, query domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	scooters, err := a.app.SearchAvailableScooters(ctx, query)
+	observe("search_available_scooters", err)
+	return scooters, err
+}
+
+func (a *appMetrics) GetScooterClusters(ctx context.Context, boundingBox domain.BoundingBox, zoom int) ([]domain.ScooterCluster, error) {
+	clusters, err := a.app.GetScooterClusters(ctx, boundingBox, zoom)
+	observe("get_scooter_clusters", err)
+	return clusters, err
+}
+
+func (a *appMetrics) GetMapTile(ctx context.Context, tile domain.Tile, withTripHeat bool) (*domain.MapTile, error) {
+	mapTile, err := a.app.GetMapTile(ctx, tile, withTripHeat)
+	observe("get_map_tile", err)
+	return mapTile, err
+}
+
+func (a *appMetrics) BeginTrip(ctx context.Context, userID string, scooterID string) error {
+	err := a.app.BeginTrip(ctx, userID, scooterID)
+	observe("begin_trip", err)
+	return err
+}
+
+func (a *appMetrics) EndTrip(ctx context.Context, userID string, scooterID string, location domain.GeoLocation) error {
+	err := a.app.EndTrip(ctx, userID, scooterID, location)
+	observe("end_trip", err)
+	return err
+}
+
+func (a *appMetrics) SaveScooterTripEvent(ctx context.Context, event *domain.TripEvent) error {
+	err := a.app.SaveScooterTripEvent(ctx, event)
+	observe("save_scooter_trip_event", err)
+	if err == nil {
+		tripEventsIngestedTotal.Inc()
+	}
+	return err
+}
+
+// SaveScooterTripEvents counts the outcome of whole batch and the outcome of each event
+func (a *appMetrics) SaveScooterTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error) {
+	results, err := a.app.SaveScooterTripEvents(ctx, events)
+	observe("save_scooter_trip_events", err)
+	for _, result := range results {
+		observe("save_scooter_trip_events_item", result)
+		if result == nil {
+			tripEventsIngestedTotal.Inc()
+		}
+	}
+	return results, err
+}
+
+func (a *appMetrics) GetScooter(ctx context.Context, scooterID string) (*domain.Scooter, error) {
+	scooter, err := a.app.GetScooter(ctx, scooterID)
+	observe("get_scooter", err)
+	return scooter, err
+}
+
+func (a *appMetrics) GetScooterPosition(ctx context.Context, scooterID string) (*domain.ScooterPosition, error) {
+	position, err := a.app.GetScooterPosition(ctx, scooterID)
+	observe("get_scooter_position", err)
+	return position, err
+}
+
+func (a *appMetrics) StartTrip(ctx context.Context, userID string, scooterID string) (*domain.Trip, error) {
+	trip, err := a.app.StartTrip(ctx, userID, scooterID)
+	observe("start_trip", err)
+	return trip, err
+}
+
+func (a *appMetrics) GetTrip(ctx context.Context, tripID string) (*domain.Trip, error) {
+	trip, err := a.app.GetTrip(ctx, tripID)
+	observe("get_trip", err)
+	return trip, err
+}
+
+func (a *appMetrics) FinishTrip(ctx context.Context, tripID string, location domain.GeoLocation) (*domain.Trip, error) {
+	trip, err := a.app.FinishTrip(ctx, tripID, location)
+	observe("finish_trip", err)
+	return trip, err
+}
+
+func (a *appMetrics) GetTripEvents(ctx context.Context, tripID string) ([]domain.TripEvent, error) {
+	events, err := a.app.GetTripEvents(ctx, tripID)
+	observe("get_trip_events", err)
+	return events, err
+}
+
+func (a *appMetrics) SaveDeadLetterEvent(ctx context.Context, payload string, reason string) error {
+	err := a.app.SaveDeadLetterEvent(ctx, payload, reason)
+	observe("save_dead_letter_event", err)
+	return err
+}
+
+func (a *appMetrics) GetDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error) {
+	events, err := a.app.GetDeadLetterEvents(ctx)
+	observe("get_dead_letter_events", err)
+	return events, err
+}
+
+func (a *appMetrics) GetDeadLetterEvent(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error) {
+	event, err := a.app.GetDeadLetterEvent(ctx, eventID)
+	observe("get_dead_letter_event", err)
+	return event, err
+}
+
+func (a *appMetrics) UpdateDeadLetterEventPayload(ctx context.Context, eventID string, payload string) (*domain.DeadLetterEvent, error) {
+	event, err := a.app.UpdateDeadLetterEventPayload(ctx, eventID, payload)
+	observe("update_dead_letter_event_payload", err)
+	return event, err
+}
+
+func (a *appMetrics) ReplayDeadLetterEvent(ctx context.Context, eventID string, event *domain.TripEvent) error {
+	err := a.app.ReplayDeadLetterEvent(ctx, eventID, event)
+	observe("replay_dead_letter_event", err)
+	return err
+}
+
+func (a *appMetrics) Ready(ctx context.Context) error {
+	return a.app.Ready(ctx)
+}
+
+func (a *appMetrics) Stop(ctx context.Context) error {
+	return a.app.Stop(ctx)
+}
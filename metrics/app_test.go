@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_appOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "should return success for nil error",
+			err:  nil,
+			want: outcomeSuccess,
+		},
+		{
+			name: "should return error class for wrapped app error",
+			err:  fmt.Errorf("scooter not found: %w", app.ErrRecordNotFound),
+			want: "record_not_found",
+		},
+		{
+			name: "should return operation not allowed",
+			err:  app.ErrOperationNotAllowed,
+			want: "operation_not_allowed",
+		},
+		{
+			name: "should return error for unknown error",
+			err:  errors.New("unknown error"),
+			want: "error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appOutcome(tt.err); got != tt.want {
+				t.Errorf("appOutcome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppUseCaseOutcomes(t *testing.T) {
+	ctx := context.Background()
+	mockApp := mocks.NewMockApp(gomock.NewController(t))
+	a := NewApp(mockApp)
+
+	mockApp.EXPECT().BeginTrip(ctx, "userid", "scooterid").Return(nil).Times(1)
+	mockApp.EXPECT().BeginTrip(ctx, "userid", "scooterid").Return(fmt.Errorf("scooter in use: %w", app.ErrOperationNotAllowed)).Times(1)
+
+	success := testutil.ToFloat64(useCaseTotal.WithLabelValues("begin_trip", outcomeSuccess))
+	notAllowed := testutil.ToFloat64(useCaseTotal.WithLabelValues("begin_trip", "operation_not_allowed"))
+
+	if err := a.BeginTrip(ctx, "userid", "scooterid"); err != nil {
+		t.Errorf("BeginTrip() error = %v", err)
+	}
+	if err := a.BeginTrip(ctx, "userid", "scooterid"); !errors.Is(err, app.ErrOperationNotAllowed) {
+		t.Errorf("BeginTrip() error = %v, want %v", err, app.ErrOperationNotAllowed)
+	}
+
+	if got := testutil.ToFloat64(useCaseTotal.WithLabelValues("begin_trip", outcomeSuccess)) - success; got != 1 {
+		t.Errorf("begin_trip success count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(useCaseTotal.WithLabelValues("begin_trip", "operation_not_allowed")) - notAllowed; got != 1 {
+		t.Errorf("begin_trip operation_not_allowed count = %v, want 1", got)
+	}
+}
+
+func TestAppTripEventsIngested(t *testing.T) {
+	ctx := context.Background()
+	mockApp := mocks.NewMockApp(gomock.NewController(t))
+	a := NewApp(mockApp)
+
+	event := &domain.TripEvent{ID: "eventid1"}
+	events := []domain.TripEvent{{ID: "eventid2"}, {ID: "eventid3"}}
+	mockApp.EXPECT().SaveScooterTripEvent(ctx, event).Return(nil).Times(1)
+	mockApp.EXPECT().SaveScooterTripEvents(ctx, events).Return([]error{nil, app.ErrInvalidArg}, nil).Times(1)
+
+	ingested := testutil.ToFloat64(tripEventsIngestedTotal)
+
+	if err := a.SaveScooterTripEvent(ctx, event); err != nil {
+		t.Errorf("SaveScooterTripEvent() error = %v", err)
+	}
+	results, err := a.SaveScooterTripEvents(ctx, events)
+	if err != nil || len(results) != 2 {
+		t.Errorf("SaveScooterTripEvents() = %v, %v", results, err)
+	}
+
+	if got := testutil.ToFloat64(tripEventsIngestedTotal) - ingested; got != 2 {
+		t.Errorf("ingested trip events = %v, want 2", got)
+	}
+}
@@ -0,0 +1,228 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// dbMetrics wraps the database and records the latency of queries
+type dbMetrics struct {
+	database db.DB
+}
+
+// NewDB returns the database which records the query latencies of given database
+func NewDB(database db.DB) db.DB {
+	return &dbMetrics{
+		database: database,
+	}
+}
+
+// observeQuery records the latency of query started at given time by outcome of the error
+func observeQuery(query string, start time.Time, err error) {
+	dbQueryDuration.WithLabelValues(query, dbOutcome(err)).Observe(time.Since(start).Seconds())
+}
+
+func (d *dbMetrics) GetAvailableScootersWithinRadius(ctx context.Context, location *domain.GeoLocation, radius int) ([]domain.Scooter, error) {
+	start := time.Now()
+	scooters, err := d.database.GetAvailableScootersWithinRadius(ctx, location, radius)
+	observeQuery("get_available_scooters_within_radius", start, err)
+	return scooters, err
+}
+
+func (d *dbMetrics) SearchAvailableScooters(ctx context.Context, query *domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	start := time.Now()
+	scooters, err := d.database.SearchAvailableScooters(ctx, query)
+	observeQuery("search_available_scooters", start, err)
+	return scooters, err
+}
+
+func (d *dbMetrics) GetAvailableScooterClusters(ctx context.Context, boundingBox *domain.BoundingBox, cellSize float64) ([]domain.ScooterCluster, error) {
+	start := time.Now()
+	clusters, err := d.database.GetAvailableScooterClusters(ctx, boundingBox, cellSize)
+	observeQuery("get_available_scooter_clusters", start, err)
+	return clusters, err
+}
+
+func (d *dbMetrics) GetScooterByID(ctx context.Context, scooterID string) (*domain.Scooter, error) {
+	start := time.Now()
+	scooter, err := d.database.GetScooterByID(ctx, scooterID)
+	observeQuery("get_scooter_by_id", start, err)
+	return scooter, err
+}
+
+func (d *dbMetrics) UpdateScooter(ctx context.Context, updatedScooter *domain.Scooter) (*domain.Scooter, error) {
+	start := time.Now()
+	scooter, err := d.database.UpdateScooter(ctx, updatedScooter)
+	observeQuery("update_scooter", start, err)
+	return scooter, err
+}
+
+func (d *dbMetrics) ReserveScooter(ctx context.Context, scooterID string, userID string) (*domain.Scooter, error) {
+	start := time.Now()
+	scooter, err := d.database.ReserveScooter(ctx, scooterID, userID)
+	observeQuery("reserve_scooter", start, err)
+	return scooter, err
+}
+
+func (d *dbMetrics) UpdateScooterLocation(ctx context.Context, scooterID string, userID string, location domain.GeoLocation, at time.Time) (*domain.Scooter, error) {
+	start := time.Now()
+	scooter, err := d.database.UpdateScooterLocation(ctx, scooterID, userID, location, at)
+	observeQuery("update_scooter_location", start, err)
+	return scooter, err
+}
+
+func (d *dbMetrics) GetAllScooters(ctx context.Context) ([]domain.Scooter, error) {
+	start := time.Now()
+	scooters, err := d.database.GetAllScooters(ctx)
+	observeQuery("get_all_scooters", start, err)
+	return scooters, err
+}
+
+func (d *dbMetrics) CountScooters(ctx context.Context) (int64, int64, error) {
+	start := time.Now()
+	available, inTrip, err := d.database.CountScooters(ctx)
+	observeQuery("count_scooters", start, err)
+	return available, inTrip, err
+}
+
+func (d *dbMetrics) InsertTripEvent(ctx context.Context, event *domain.TripEvent) error {
+	start := time.Now()
+	err := d.database.InsertTripEvent(ctx, event)
+	observeQuery("insert_trip_event", start, err)
+	return err
+}
+
+func (d *dbMetrics) InsertTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error) {
+	start := time.Now()
+	results, err := d.database.InsertTripEvents(ctx, events)
+	observeQuery("insert_trip_events", start, err)
+	return results, err
+}
+
+func (d *dbMetrics) GetAllTripEvents(ctx context.Context) ([]domain.TripEvent, error) {
+	start := time.Now()
+	events, err := d.database.GetAllTripEvents(ctx)
+	observeQuery("get_all_trip_events", start, err)
+	return events, err
+}
+
+func (d *dbMetrics) GetTripEventsByScooterIDs(ctx context.Context, scooterIDs []string) ([]domain.TripEvent, error) {
+	start := time.Now()
+	events, err := d.database.GetTripEventsByScooterIDs(ctx, scooterIDs)
+	observeQuery("get_trip_events_by_scooter_ids", start, err)
+	return events, err
+}
+
+func (d *dbMetrics) GetScooterTripEvents(ctx context.Context, scooterID string, from time.Time, to time.Time) ([]domain.TripEvent, error) {
+	start := time.Now()
+	events, err := d.database.GetScooterTripEvents(ctx, scooterID, from, to)
+	observeQuery("get_scooter_trip_events", start, err)
+	return events, err
+}
+
+func (d *dbMetrics) GetTripEventHeat(ctx context.Context, boundingBox *domain.BoundingBox, since time.Time, cellSize float64) ([]domain.HeatCell, error) {
+	start := time.Now()
+	cells, err := d.database.GetTripEventHeat(ctx, boundingBox, since, cellSize)
+	observeQuery("get_trip_event_heat", start, err)
+	return cells, err
+}
+
+func (d *dbMetrics) InsertTrip(ctx context.Context, trip *domain.Trip) error {
+	start := time.Now()
+	err := d.database.InsertTrip(ctx, trip)
+	observeQuery("insert_trip", start, err)
+	return err
+}
+
+func (d *dbMetrics) GetTripByID(ctx context.Context, tripID string) (*domain.Trip, error) {
+	start := time.Now()
+	trip, err := d.database.GetTripByID(ctx, tripID)
+	observeQuery("get_trip_by_id", start, err)
+	return trip, err
+}
+
+func (d *dbMetrics) GetActiveTripByScooterID(ctx context.Context, scooterID string) (*domain.Trip, error) {
+	start := time.Now()
+	trip, err := d.database.GetActiveTripByScooterID(ctx, scooterID)
+	observeQuery("get_active_trip_by_scooter_id", start, err)
+	return trip, err
+}
+
+func (d *dbMetrics) UpdateTrip(ctx context.Context, trip *domain.Trip) (*domain.Trip, error) {
+	start := time.Now()
+	updatedTrip, err := d.database.UpdateTrip(ctx, trip)
+	observeQuery("update_trip", start, err)
+	return updatedTrip, err
+}
+
+func (d *dbMetrics) InsertDeadLetterEvent(ctx context.Context, event *domain.DeadLetterEvent) error {
+	start := time.Now()
+	err := d.database.InsertDeadLetterEvent(ctx, event)
+	observeQuery("insert_dead_letter_event", start, err)
+	return err
+}
+
+func (d *dbMetrics) GetAllDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error) {
+	start := time.Now()
+	events, err := d.database.GetAllDeadLetterEvents(ctx)
+	observeQuery("get_all_dead_letter_events", start, err)
+	return events, err
+}
+
+func (d *dbMetrics) GetDeadLetterEventByID(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error) {
+	start := time.Now()
+	event, err := d.database.GetDeadLetterEventByID(ctx, eventID)
+	observeQuery("get_dead_letter_event_by_id", start, err)
+	return event, err
+}
+
+func (d *dbMetrics) UpdateDeadLetterEvent(ctx context.Context, event *domain.DeadLetterEvent) (*domain.DeadLetterEvent, error) {
+	start := time.Now()
+	updatedEvent, err := d.database.UpdateDeadLetterEvent(ctx, event)
+	observeQuery("update_dead_letter_event", start, err)
+	return updatedEvent, err
+}
+
+func (d *dbMetrics) ClaimDeadLetterEvent(ctx context.Context, eventID string, claimedAt time.Time) (*domain.DeadLetterEvent, error) {
+	start := time.Now()
+	event, err := d.database.ClaimDeadLetterEvent(ctx, eventID, claimedAt)
+	observeQuery("claim_dead_letter_event", start, err)
+	return event, err
+}
+
+func (d *dbMetrics) GetAllUsers(ctx context.Context) ([]domain.User, error) {
+	start := time.Now()
+	users, err := d.database.GetAllUsers(ctx)
+	observeQuery("get_all_users", start, err)
+	return users, err
+}
+
+func (d *dbMetrics) UpsertScooters(ctx context.Context, scooters []domain.Scooter) error {
+	start := time.Now()
+	err := d.database.UpsertScooters(ctx, scooters)
+	observeQuery("upsert_scooters", start, err)
+	return err
+}
+
+// WatchScooters is not observed as the watch runs until it is stopped
+func (d *dbMetrics) WatchScooters(ctx context.Context, onChange func(scooter *domain.Scooter)) error {
+	return d.database.WatchScooters(ctx, onChange)
+}
+
+func (d *dbMetrics) UpsertUsers(ctx context.Context, users []domain.User) error {
+	start := time.Now()
+	err := d.database.UpsertUsers(ctx, users)
+	observeQuery("upsert_users", start, err)
+	return err
+}
+
+func (d *dbMetrics) Ping(ctx context.Context) error {
+	return d.database.Ping(ctx)
+}
+
+func (d *dbMetrics) Disconnect(ctx context.Context) error {
+	return d.database.Disconnect(ctx)
+}
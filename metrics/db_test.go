@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// queryCount returns the number of observed queries with given outcome
+func queryCount(t *testing.T, query string, outcome string) uint64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	err := dbQueryDuration.WithLabelValues(query, outcome).(prometheus.Histogram).Write(metric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestDBQueryLatency(t *testing.T) {
+	ctx := context.Background()
+	mockDB := mocks.NewMockDB(gomock.NewController(t))
+	d := NewDB(mockDB)
+
+	unavailableErr := fmt.Errorf("connection refused: %w", db.ErrUnavailable)
+	mockDB.EXPECT().InsertTripEvent(ctx, gomock.Any()).Return(unavailableErr).Times(1)
+	mockDB.EXPECT().GetScooterByID(ctx, "scooterid").Return(&domain.Scooter{ID: "scooterid"}, nil).Times(1)
+
+	unavailable := queryCount(t, "insert_trip_event", "unavailable")
+	success := queryCount(t, "get_scooter_by_id", outcomeSuccess)
+
+	if err := d.InsertTripEvent(ctx, &domain.TripEvent{}); err != unavailableErr {
+		t.Errorf("InsertTripEvent() error = %v, want %v", err, unavailableErr)
+	}
+	if scooter, err := d.GetScooterByID(ctx, "scooterid"); err != nil || scooter.ID != "scooterid" {
+		t.Errorf("GetScooterByID() = %v, %v", scooter, err)
+	}
+
+	if got := queryCount(t, "insert_trip_event", "unavailable") - unavailable; got != 1 {
+		t.Errorf("insert_trip_event unavailable count = %v, want 1", got)
+	}
+	if got := queryCount(t, "get_scooter_by_id", outcomeSuccess) - success; got != 1 {
+		t.Errorf("get_scooter_by_id success count = %v, want 1", got)
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	namespace = "scootin"
+
+	outcomeSuccess = "success"
+)
+
+var (
+	useCaseTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "app",
+		Name:      "use_case_total",
+		Help:      "Number of use case calls by outcome.",
+	}, []string{"use_case", "outcome"})
+
+	tripEventsIngestedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "app",
+		Name:      "trip_events_ingested_total",
+		Help:      "Number of trip events accepted for saving.",
+	})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of database queries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query", "outcome"})
+)
+
+// appOutcome returns the outcome label for the error returned by app
+func appOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(err, app.ErrInvalidArg):
+		return "invalid_argument"
+	case errors.Is(err, app.ErrEmptyArg):
+		return "empty_argument"
+	case errors.Is(err, app.ErrRecordNotFound):
+		return "record_not_found"
+	case errors.Is(err, app.ErrOperationNotAllowed):
+		return "operation_not_allowed"
+	case errors.Is(err, app.ErrServiceUnavailable):
+		return "service_unavailable"
+	default:
+		return "error"
+	}
+}
+
+// dbOutcome returns the outcome label for the error returned by database
+func dbOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(err, db.ErrInvalidArg):
+		return "invalid_argument"
+	case errors.Is(err, db.ErrEmptyArg):
+		return "empty_argument"
+	case errors.Is(err, db.ErrRecordNotFound):
+		return "record_not_found"
+	case errors.Is(err, db.ErrUnavailable):
+		return "unavailable"
+	default:
+		return "error"
+	}
+}
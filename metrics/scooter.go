@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// scooterCollectTimeout is the max time to count scooters during scrape
+	scooterCollectTimeout = 5 * time.Second
+)
+
+var (
+	availableScootersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "available_scooters"),
+		"Number of scooters available for trip.",
+		nil, nil,
+	)
+	activeTripsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_trips"),
+		"Number of trips in progress.",
+		nil, nil,
+	)
+)
+
+// scooterCollector collects the scooter gauges from database on each scrape
+type scooterCollector struct {
+	database db.DB
+	logger   logrus.FieldLogger
+}
+
+// NewScooterCollector returns the collector of available scooters and
+// active trips gauges, the failure to count scooters is logged with l
+func NewScooterCollector(database db.DB, l logrus.FieldLogger) prometheus.Collector {
+	return &scooterCollector{
+		database: database,
+		logger:   l,
+	}
+}
+
+func (s *scooterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- availableScootersDesc
+	ch <- activeTripsDesc
+}
+
+// Collect counts the scooters by availability, no gauges are reported if
+// the scooters could not be counted
+func (s *scooterCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scooterCollectTimeout)
+	defer cancel()
+
+	available, activeTrips, err := s.database.CountScooters(ctx)
+	if err != nil {
+		s.logger.WithError(err).Error("unable to collect scooter metrics")
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(availableScootersDesc, prometheus.GaugeValue, float64(available))
+	ch <- prometheus.MustNewConstMetric(activeTripsDesc, prometheus.GaugeValue, float64(activeTrips))
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/logger"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScooterCollector(t *testing.T) {
+	tests := []struct {
+		name     string
+		prepare  func(database *mocks.MockDB)
+		wantText string
+		want     int
+	}{
+		{
+			name: "should collect available scooters and active trips",
+			prepare: func(database *mocks.MockDB) {
+				database.EXPECT().CountScooters(gomock.Any()).Return(int64(2), int64(1), nil).Times(1)
+			},
+			wantText: `
+# HELP scootin_active_trips Number of trips in progress.
+# TYPE scootin_active_trips gauge
+scootin_active_trips 1
+# HELP scootin_available_scooters Number of scooters available for trip.
+# TYPE scootin_available_scooters gauge
+scootin_available_scooters 2
+`,
+			want: 2,
+		},
+		{
+			name: "should collect nothing if scooters could not be counted",
+			prepare: func(database *mocks.MockDB) {
+				database.EXPECT().CountScooters(gomock.Any()).Return(int64(0), int64(0), errors.New("db error")).Times(1)
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := mocks.NewMockDB(gomock.NewController(t))
+			tt.prepare(database)
+			collector := NewScooterCollector(database, logger.Discard())
+
+			if tt.wantText == "" {
+				if n := testutil.CollectAndCount(collector); n != tt.want {
+					t.Errorf("CollectAndCount() = %v, want %v", n, tt.want)
+				}
+				return
+			}
+			if err := testutil.CollectAndCompare(collector, strings.NewReader(tt.wantText)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
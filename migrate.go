@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/config"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/migration"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mongodb"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/sirupsen/logrus"
+)
+
+// newMigrate creates the migration of configured database
+func newMigrate() (*migrate.Migrate, error) {
+	sourceDriver, err := migration.Source(config.Get().Migration.FilesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.NewWithSourceInstance(
+		"migration",
+		sourceDriver,
+		config.Get().Mongo.Uri+"/"+config.Get().Mongo.Db)
+}
+
+// latestMigrationVersion returns the latest version of configured migrations
+func latestMigrationVersion() (uint, error) {
+	sourceDriver, err := migration.Source(config.Get().Migration.FilesPath)
+	if err != nil {
+		return 0, err
+	}
+	defer sourceDriver.Close()
+
+	return health.MigrationVersion(sourceDriver)
+}
+
+// runMigrate runs the migrate subcommand
+//   - up [N] applies all or next N migrations
+//   - down [N|all] reverts last N (default 1) or all migrations
+//   - status prints applied and latest migration version
+//   - force V sets the version without running migration, used to fix dirty migration
+func runMigrate(serviceLogger *logrus.Logger, out io.Writer, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errUsage
+	}
+
+	var action func(m *migrate.Migrate) error
+	switch args[0] {
+	case "up":
+		steps, err := migrateSteps(args[1:], 0)
+		if err != nil {
+			return err
+		}
+		action = func(m *migrate.Migrate) error {
+			if steps == 0 {
+				return logMigrateResult(serviceLogger, m, m.Up())
+			}
+			return logMigrateResult(serviceLogger, m, m.Steps(steps))
+		}
+	case "down":
+		if len(args) == 2 && args[1] == "all" {
+			action = func(m *migrate.Migrate) error {
+				return logMigrateResult(serviceLogger, m, m.Down())
+			}
+			break
+		}
+		steps, err := migrateSteps(args[1:], 1)
+		if err != nil {
+			return err
+		}
+		action = func(m *migrate.Migrate) error {
+			return logMigrateResult(serviceLogger, m, m.Steps(-steps))
+		}
+	case "status":
+		if len(args) != 1 {
+			return errUsage
+		}
+		action = func(m *migrate.Migrate) error {
+			return printMigrateStatus(out, m)
+		}
+	case "force":
+		if len(args) != 2 {
+			return errUsage
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			return fmt.Errorf("invalid version %q: %w", args[1], errUsage)
+		}
+		action = func(m *migrate.Migrate) error {
+			return logMigrateResult(serviceLogger, m, m.Force(version))
+		}
+	default:
+		return errUsage
+	}
+
+	m, err := newMigrate()
+	if err != nil {
+		return fmt.Errorf("unable to create migration: %w", err)
+	}
+	defer m.Close()
+	return action(m)
+}
+
+// migrateSteps returns the number of steps in args, otherwise default steps
+func migrateSteps(args []string, defaultSteps int) (int, error) {
+	if len(args) == 0 {
+		return defaultSteps, nil
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps <= 0 {
+		return 0, fmt.Errorf("invalid number of migrations %q: %w", args[0], errUsage)
+	}
+	return steps, nil
+}
+
+// logMigrateResult logs the version after migration, no change is not an error
+func logMigrateResult(serviceLogger *logrus.Logger, m *migrate.Migrate, err error) error {
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		serviceLogger.Info("no migration applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	serviceLogger.WithFields(logrus.Fields{"version": version, "dirty": dirty}).Info("migration done")
+	return nil
+}
+
+// printMigrateStatus prints the applied and latest migration version
+func printMigrateStatus(out io.Writer, m *migrate.Migrate) error {
+	latest, err := latestMigrationVersion()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Fprintf(out, "version: none\nlatest: %v\n", latest)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "version: %v\ndirty: %v\nlatest: %v\n", version, dirty, latest)
+	return nil
+}
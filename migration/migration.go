@@ -0,0 +1,24 @@
+package migration
+
+import (
+	"embed"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+var (
+	// files are the migrations embedded in the binary
+	//go:embed *.mongodb
+	files embed.FS
+)
+
+// Source returns the source of migrations, the embedded migrations are
+// used if sourceURL is empty, otherwise the migrations at sourceURL e.g.
+// file://migration are used
+func Source(sourceURL string) (source.Driver, error) {
+	if sourceURL == "" {
+		return iofs.New(files, ".")
+	}
+	return source.Open(sourceURL)
+}
@@ -0,0 +1,181 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mongodb"
+	testcontainers "github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSource(t *testing.T) {
+	driver, err := Source("")
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+	defer driver.Close()
+
+	version, err := driver.First()
+	for err == nil {
+		for name, read := range map[string]func(uint) (io.ReadCloser, string, error){
+			"up":   driver.ReadUp,
+			"down": driver.ReadDown,
+		} {
+			r, _, err := read(version)
+			if err != nil {
+				t.Errorf("migration %v has no %v script: %v", version, name, err)
+				continue
+			}
+			// the script is decoded as the mongodb driver of migrate does
+			var commands []bson.D
+			bs, err := io.ReadAll(r)
+			r.Close()
+			if err == nil {
+				err = bson.UnmarshalExtJSON(bs, true, &commands)
+			}
+			if err != nil || len(commands) == 0 {
+				t.Errorf("migration %v %v script has no commands: %v", version, name, err)
+			}
+		}
+		version, err = driver.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Next() error = %v", err)
+	}
+}
+
+// TestUpDownUp checks that migrating down and up again leaves the same
+// collections and indexes as migrating up
+func TestUpDownUp(t *testing.T) {
+	ctx := context.Background()
+	uri, terminate, err := startMongo(ctx)
+	if err != nil {
+		t.Skipf("mongo test container is not available: %v", err)
+	}
+	defer terminate()
+
+	dbName := "migrationdb"
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+	database := client.Database(dbName)
+
+	driver, err := Source("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithSourceInstance("migration", driver, uri+"/"+dbName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	want := schema(t, database)
+
+	if err := m.Down(); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	for name := range schema(t, database) {
+		if name != "trip_event" {
+			t.Errorf("collection %v is not dropped by Down()", name)
+		}
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up() after Down() error = %v", err)
+	}
+	got := schema(t, database)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schema after up, down and up = %v, want %v", got, want)
+	}
+}
+
+// collectionSchema represents the options and indexes of collection
+type collectionSchema struct {
+	Type    string
+	Options bson.M
+	Indexes []bson.M
+}
+
+// schema returns the schema of collections in the database except the
+// migration version collection
+func schema(t *testing.T, database *mongo.Database) map[string]collectionSchema {
+	t.Helper()
+	ctx := context.Background()
+
+	specs, err := database.ListCollectionSpecifications(ctx, bson.M{"name": bson.M{"$ne": "schema_migrations"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := map[string]collectionSchema{}
+	for _, spec := range specs {
+		options := bson.M{}
+		err := bson.Unmarshal(spec.Options, &options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		indexes := []bson.M{}
+		if spec.Type == "collection" {
+			cur, err := database.Collection(spec.Name).Indexes().List(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = cur.All(ctx, &indexes)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		result[spec.Name] = collectionSchema{
+			Type:    spec.Type,
+			Options: options,
+			Indexes: indexes,
+		}
+	}
+	return result
+}
+
+// startMongo starts mongo test container, returns its uri and func to terminate it
+func startMongo(ctx context.Context) (string, func(), error) {
+	mgoC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "mongo:7.0",
+			ExposedPorts: []string{"27017/tcp"},
+		},
+		Started: true,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	terminate := func() {
+		mgoC.Terminate(context.Background())
+	}
+
+	ip, err := mgoC.Host(ctx)
+	if err != nil {
+		terminate()
+		return "", nil, err
+	}
+	port, err := mgoC.MappedPort(ctx, "27017/tcp")
+	if err != nil {
+		terminate()
+		return "", nil, err
+	}
+	return fmt.Sprintf("mongodb://%s:%s", ip, port.Port()), terminate, nil
+}
@@ -63,6 +63,66 @@ func (mr *MockAppMockRecorder) EndTrip(arg0, arg1, arg2, arg3 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndTrip", reflect.TypeOf((*MockApp)(nil).EndTrip), arg0, arg1, arg2, arg3)
 }
 
+// FinishTrip mocks base method.
+func (m *MockApp) FinishTrip(arg0 context.Context, arg1 string, arg2 domain.GeoLocation) (*domain.Trip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishTrip", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Trip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishTrip indicates an expected call of FinishTrip.
+func (mr *MockAppMockRecorder) FinishTrip(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishTrip", reflect.TypeOf((*MockApp)(nil).FinishTrip), arg0, arg1, arg2)
+}
+
+// GetDeadLetterEvent mocks base method.
+func (m *MockApp) GetDeadLetterEvent(arg0 context.Context, arg1 string) (*domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetterEvent", arg0, arg1)
+	ret0, _ := ret[0].(*domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetterEvent indicates an expected call of GetDeadLetterEvent.
+func (mr *MockAppMockRecorder) GetDeadLetterEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetterEvent", reflect.TypeOf((*MockApp)(nil).GetDeadLetterEvent), arg0, arg1)
+}
+
+// GetDeadLetterEvents mocks base method.
+func (m *MockApp) GetDeadLetterEvents(arg0 context.Context) ([]domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetterEvents", arg0)
+	ret0, _ := ret[0].([]domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetterEvents indicates an expected call of GetDeadLetterEvents.
+func (mr *MockAppMockRecorder) GetDeadLetterEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetterEvents", reflect.TypeOf((*MockApp)(nil).GetDeadLetterEvents), arg0)
+}
+
+// GetMapTile mocks base method.
+func (m *MockApp) GetMapTile(arg0 context.Context, arg1 domain.Tile, arg2 bool) (*domain.MapTile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMapTile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.MapTile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMapTile indicates an expected call of GetMapTile.
+func (mr *MockAppMockRecorder) GetMapTile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMapTile", reflect.TypeOf((*MockApp)(nil).GetMapTile), arg0, arg1, arg2)
+}
+
 // GetNearbyAvailableScooters mocks base method.
 func (m *MockApp) GetNearbyAvailableScooters(arg0 context.Context, arg1 domain.GeoLocation, arg2 int) ([]domain.Scooter, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +138,123 @@ func (mr *MockAppMockRecorder) GetNearbyAvailableScooters(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNearbyAvailableScooters", reflect.TypeOf((*MockApp)(nil).GetNearbyAvailableScooters), arg0, arg1, arg2)
 }
 
+// GetScooter mocks base method.
+func (m *MockApp) GetScooter(arg0 context.Context, arg1 string) (*domain.Scooter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScooter", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Scooter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScooter indicates an expected call of GetScooter.
+func (mr *MockAppMockRecorder) GetScooter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooter", reflect.TypeOf((*MockApp)(nil).GetScooter), arg0, arg1)
+}
+
+// GetScooterClusters mocks base method.
+func (m *MockApp) GetScooterClusters(arg0 context.Context, arg1 domain.BoundingBox, arg2 int) ([]domain.ScooterCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScooterClusters", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.ScooterCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScooterClusters indicates an expected call of GetScooterClusters.
+func (mr *MockAppMockRecorder) GetScooterClusters(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooterClusters", reflect.TypeOf((*MockApp)(nil).GetScooterClusters), arg0, arg1, arg2)
+}
+
+// GetScooterPosition mocks base method.
+func (m *MockApp) GetScooterPosition(arg0 context.Context, arg1 string) (*domain.ScooterPosition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScooterPosition", arg0, arg1)
+	ret0, _ := ret[0].(*domain.ScooterPosition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScooterPosition indicates an expected call of GetScooterPosition.
+func (mr *MockAppMockRecorder) GetScooterPosition(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooterPosition", reflect.TypeOf((*MockApp)(nil).GetScooterPosition), arg0, arg1)
+}
+
+// GetTrip mocks base method.
+func (m *MockApp) GetTrip(arg0 context.Context, arg1 string) (*domain.Trip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrip", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Trip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrip indicates an expected call of GetTrip.
+func (mr *MockAppMockRecorder) GetTrip(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrip", reflect.TypeOf((*MockApp)(nil).GetTrip), arg0, arg1)
+}
+
+// GetTripEvents mocks base method.
+func (m *MockApp) GetTripEvents(arg0 context.Context, arg1 string) ([]domain.TripEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTripEvents", arg0, arg1)
+	ret0, _ := ret[0].([]domain.TripEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTripEvents indicates an expected call of GetTripEvents.
+func (mr *MockAppMockRecorder) GetTripEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTripEvents", reflect.TypeOf((*MockApp)(nil).GetTripEvents), arg0, arg1)
+}
+
+// Ready mocks base method.
+func (m *MockApp) Ready(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockAppMockRecorder) Ready(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockApp)(nil).Ready), arg0)
+}
+
+// ReplayDeadLetterEvent mocks base method.
+func (m *MockApp) ReplayDeadLetterEvent(arg0 context.Context, arg1 string, arg2 *domain.TripEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDeadLetterEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayDeadLetterEvent indicates an expected call of ReplayDeadLetterEvent.
+func (mr *MockAppMockRecorder) ReplayDeadLetterEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDeadLetterEvent", reflect.TypeOf((*MockApp)(nil).ReplayDeadLetterEvent), arg0, arg1, arg2)
+}
+
+// SaveDeadLetterEvent mocks base method.
+func (m *MockApp) SaveDeadLetterEvent(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeadLetterEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeadLetterEvent indicates an expected call of SaveDeadLetterEvent.
+func (mr *MockAppMockRecorder) SaveDeadLetterEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeadLetterEvent", reflect.TypeOf((*MockApp)(nil).SaveDeadLetterEvent), arg0, arg1, arg2)
+}
+
 // SaveScooterTripEvent mocks base method.
 func (m *MockApp) SaveScooterTripEvent(arg0 context.Context, arg1 *domain.TripEvent) error {
 	m.ctrl.T.Helper()
@@ -91,3 +268,77 @@ func (mr *MockAppMockRecorder) SaveScooterTripEvent(arg0, arg1 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveScooterTripEvent", reflect.TypeOf((*MockApp)(nil).SaveScooterTripEvent), arg0, arg1)
 }
+
+// SaveScooterTripEvents mocks base method.
+func (m *MockApp) SaveScooterTripEvents(arg0 context.Context, arg1 []domain.TripEvent) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveScooterTripEvents", arg0, arg1)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveScooterTripEvents indicates an expected call of SaveScooterTripEvents.
+func (mr *MockAppMockRecorder) SaveScooterTripEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveScooterTripEvents", reflect.TypeOf((*MockApp)(nil).SaveScooterTripEvents), arg0, arg1)
+}
+
+// SearchAvailableScooters mocks base method.
+func (m *MockApp) SearchAvailableScooters(arg0 context.Context, arg1 domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAvailableScooters", arg0, arg1)
+	ret0, _ := ret[0].([]domain.ScooterMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAvailableScooters indicates an expected call of SearchAvailableScooters.
+func (mr *MockAppMockRecorder) SearchAvailableScooters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailableScooters", reflect.TypeOf((*MockApp)(nil).SearchAvailableScooters), arg0, arg1)
+}
+
+// StartTrip mocks base method.
+func (m *MockApp) StartTrip(arg0 context.Context, arg1, arg2 string) (*domain.Trip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTrip", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Trip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartTrip indicates an expected call of StartTrip.
+func (mr *MockAppMockRecorder) StartTrip(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTrip", reflect.TypeOf((*MockApp)(nil).StartTrip), arg0, arg1, arg2)
+}
+
+// Stop mocks base method.
+func (m *MockApp) Stop(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockAppMockRecorder) Stop(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockApp)(nil).Stop), arg0)
+}
+
+// UpdateDeadLetterEventPayload mocks base method.
+func (m *MockApp) UpdateDeadLetterEventPayload(arg0 context.Context, arg1, arg2 string) (*domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeadLetterEventPayload", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDeadLetterEventPayload indicates an expected call of UpdateDeadLetterEventPayload.
+func (mr *MockAppMockRecorder) UpdateDeadLetterEventPayload(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeadLetterEventPayload", reflect.TypeOf((*MockApp)(nil).UpdateDeadLetterEventPayload), arg0, arg1, arg2)
+}
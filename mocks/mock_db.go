@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,37 @@ func (m *MockDB) EXPECT() *MockDBMockRecorder {
 	return m.recorder
 }
 
+// ClaimDeadLetterEvent mocks base method.
+func (m *MockDB) ClaimDeadLetterEvent(arg0 context.Context, arg1 string, arg2 time.Time) (*domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeadLetterEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDeadLetterEvent indicates an expected call of ClaimDeadLetterEvent.
+func (mr *MockDBMockRecorder) ClaimDeadLetterEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeadLetterEvent", reflect.TypeOf((*MockDB)(nil).ClaimDeadLetterEvent), arg0, arg1, arg2)
+}
+
+// CountScooters mocks base method.
+func (m *MockDB) CountScooters(arg0 context.Context) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountScooters", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountScooters indicates an expected call of CountScooters.
+func (mr *MockDBMockRecorder) CountScooters(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountScooters", reflect.TypeOf((*MockDB)(nil).CountScooters), arg0)
+}
+
 // Disconnect mocks base method.
 func (m *MockDB) Disconnect(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -49,6 +81,36 @@ func (mr *MockDBMockRecorder) Disconnect(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disconnect", reflect.TypeOf((*MockDB)(nil).Disconnect), arg0)
 }
 
+// GetActiveTripByScooterID mocks base method.
+func (m *MockDB) GetActiveTripByScooterID(arg0 context.Context, arg1 string) (*domain.Trip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveTripByScooterID", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Trip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveTripByScooterID indicates an expected call of GetActiveTripByScooterID.
+func (mr *MockDBMockRecorder) GetActiveTripByScooterID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveTripByScooterID", reflect.TypeOf((*MockDB)(nil).GetActiveTripByScooterID), arg0, arg1)
+}
+
+// GetAllDeadLetterEvents mocks base method.
+func (m *MockDB) GetAllDeadLetterEvents(arg0 context.Context) ([]domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllDeadLetterEvents", arg0)
+	ret0, _ := ret[0].([]domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllDeadLetterEvents indicates an expected call of GetAllDeadLetterEvents.
+func (mr *MockDBMockRecorder) GetAllDeadLetterEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllDeadLetterEvents", reflect.TypeOf((*MockDB)(nil).GetAllDeadLetterEvents), arg0)
+}
+
 // GetAllScooters mocks base method.
 func (m *MockDB) GetAllScooters(arg0 context.Context) ([]domain.Scooter, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +156,21 @@ func (mr *MockDBMockRecorder) GetAllUsers(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockDB)(nil).GetAllUsers), arg0)
 }
 
+// GetAvailableScooterClusters mocks base method.
+func (m *MockDB) GetAvailableScooterClusters(arg0 context.Context, arg1 *domain.BoundingBox, arg2 float64) ([]domain.ScooterCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAvailableScooterClusters", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.ScooterCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAvailableScooterClusters indicates an expected call of GetAvailableScooterClusters.
+func (mr *MockDBMockRecorder) GetAvailableScooterClusters(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailableScooterClusters", reflect.TypeOf((*MockDB)(nil).GetAvailableScooterClusters), arg0, arg1, arg2)
+}
+
 // GetAvailableScootersWithinRadius mocks base method.
 func (m *MockDB) GetAvailableScootersWithinRadius(arg0 context.Context, arg1 *domain.GeoLocation, arg2 int) ([]domain.Scooter, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +186,21 @@ func (mr *MockDBMockRecorder) GetAvailableScootersWithinRadius(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailableScootersWithinRadius", reflect.TypeOf((*MockDB)(nil).GetAvailableScootersWithinRadius), arg0, arg1, arg2)
 }
 
+// GetDeadLetterEventByID mocks base method.
+func (m *MockDB) GetDeadLetterEventByID(arg0 context.Context, arg1 string) (*domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetterEventByID", arg0, arg1)
+	ret0, _ := ret[0].(*domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetterEventByID indicates an expected call of GetDeadLetterEventByID.
+func (mr *MockDBMockRecorder) GetDeadLetterEventByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetterEventByID", reflect.TypeOf((*MockDB)(nil).GetDeadLetterEventByID), arg0, arg1)
+}
+
 // GetScooterByID mocks base method.
 func (m *MockDB) GetScooterByID(arg0 context.Context, arg1 string) (*domain.Scooter, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +216,94 @@ func (mr *MockDBMockRecorder) GetScooterByID(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooterByID", reflect.TypeOf((*MockDB)(nil).GetScooterByID), arg0, arg1)
 }
 
+// GetScooterTripEvents mocks base method.
+func (m *MockDB) GetScooterTripEvents(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]domain.TripEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScooterTripEvents", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]domain.TripEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScooterTripEvents indicates an expected call of GetScooterTripEvents.
+func (mr *MockDBMockRecorder) GetScooterTripEvents(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScooterTripEvents", reflect.TypeOf((*MockDB)(nil).GetScooterTripEvents), arg0, arg1, arg2, arg3)
+}
+
+// GetTripByID mocks base method.
+func (m *MockDB) GetTripByID(arg0 context.Context, arg1 string) (*domain.Trip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTripByID", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Trip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTripByID indicates an expected call of GetTripByID.
+func (mr *MockDBMockRecorder) GetTripByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTripByID", reflect.TypeOf((*MockDB)(nil).GetTripByID), arg0, arg1)
+}
+
+// GetTripEventHeat mocks base method.
+func (m *MockDB) GetTripEventHeat(arg0 context.Context, arg1 *domain.BoundingBox, arg2 time.Time, arg3 float64) ([]domain.HeatCell, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTripEventHeat", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]domain.HeatCell)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTripEventHeat indicates an expected call of GetTripEventHeat.
+func (mr *MockDBMockRecorder) GetTripEventHeat(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTripEventHeat", reflect.TypeOf((*MockDB)(nil).GetTripEventHeat), arg0, arg1, arg2, arg3)
+}
+
+// GetTripEventsByScooterIDs mocks base method.
+func (m *MockDB) GetTripEventsByScooterIDs(arg0 context.Context, arg1 []string) ([]domain.TripEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTripEventsByScooterIDs", arg0, arg1)
+	ret0, _ := ret[0].([]domain.TripEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTripEventsByScooterIDs indicates an expected call of GetTripEventsByScooterIDs.
+func (mr *MockDBMockRecorder) GetTripEventsByScooterIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTripEventsByScooterIDs", reflect.TypeOf((*MockDB)(nil).GetTripEventsByScooterIDs), arg0, arg1)
+}
+
+// InsertDeadLetterEvent mocks base method.
+func (m *MockDB) InsertDeadLetterEvent(arg0 context.Context, arg1 *domain.DeadLetterEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDeadLetterEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertDeadLetterEvent indicates an expected call of InsertDeadLetterEvent.
+func (mr *MockDBMockRecorder) InsertDeadLetterEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDeadLetterEvent", reflect.TypeOf((*MockDB)(nil).InsertDeadLetterEvent), arg0, arg1)
+}
+
+// InsertTrip mocks base method.
+func (m *MockDB) InsertTrip(arg0 context.Context, arg1 *domain.Trip) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTrip", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertTrip indicates an expected call of InsertTrip.
+func (mr *MockDBMockRecorder) InsertTrip(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTrip", reflect.TypeOf((*MockDB)(nil).InsertTrip), arg0, arg1)
+}
+
 // InsertTripEvent mocks base method.
 func (m *MockDB) InsertTripEvent(arg0 context.Context, arg1 *domain.TripEvent) error {
 	m.ctrl.T.Helper()
@@ -138,6 +318,80 @@ func (mr *MockDBMockRecorder) InsertTripEvent(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTripEvent", reflect.TypeOf((*MockDB)(nil).InsertTripEvent), arg0, arg1)
 }
 
+// InsertTripEvents mocks base method.
+func (m *MockDB) InsertTripEvents(arg0 context.Context, arg1 []domain.TripEvent) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTripEvents", arg0, arg1)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTripEvents indicates an expected call of InsertTripEvents.
+func (mr *MockDBMockRecorder) InsertTripEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTripEvents", reflect.TypeOf((*MockDB)(nil).InsertTripEvents), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockDB) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockDBMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockDB)(nil).Ping), arg0)
+}
+
+// ReserveScooter mocks base method.
+func (m *MockDB) ReserveScooter(arg0 context.Context, arg1, arg2 string) (*domain.Scooter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveScooter", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Scooter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveScooter indicates an expected call of ReserveScooter.
+func (mr *MockDBMockRecorder) ReserveScooter(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveScooter", reflect.TypeOf((*MockDB)(nil).ReserveScooter), arg0, arg1, arg2)
+}
+
+// SearchAvailableScooters mocks base method.
+func (m *MockDB) SearchAvailableScooters(arg0 context.Context, arg1 *domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAvailableScooters", arg0, arg1)
+	ret0, _ := ret[0].([]domain.ScooterMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAvailableScooters indicates an expected call of SearchAvailableScooters.
+func (mr *MockDBMockRecorder) SearchAvailableScooters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAvailableScooters", reflect.TypeOf((*MockDB)(nil).SearchAvailableScooters), arg0, arg1)
+}
+
+// UpdateDeadLetterEvent mocks base method.
+func (m *MockDB) UpdateDeadLetterEvent(arg0 context.Context, arg1 *domain.DeadLetterEvent) (*domain.DeadLetterEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeadLetterEvent", arg0, arg1)
+	ret0, _ := ret[0].(*domain.DeadLetterEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDeadLetterEvent indicates an expected call of UpdateDeadLetterEvent.
+func (mr *MockDBMockRecorder) UpdateDeadLetterEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeadLetterEvent", reflect.TypeOf((*MockDB)(nil).UpdateDeadLetterEvent), arg0, arg1)
+}
+
 // UpdateScooter mocks base method.
 func (m *MockDB) UpdateScooter(arg0 context.Context, arg1 *domain.Scooter) (*domain.Scooter, error) {
 	m.ctrl.T.Helper()
@@ -152,3 +406,75 @@ func (mr *MockDBMockRecorder) UpdateScooter(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScooter", reflect.TypeOf((*MockDB)(nil).UpdateScooter), arg0, arg1)
 }
+
+// UpdateScooterLocation mocks base method.
+func (m *MockDB) UpdateScooterLocation(arg0 context.Context, arg1, arg2 string, arg3 domain.GeoLocation, arg4 time.Time) (*domain.Scooter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScooterLocation", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*domain.Scooter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScooterLocation indicates an expected call of UpdateScooterLocation.
+func (mr *MockDBMockRecorder) UpdateScooterLocation(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScooterLocation", reflect.TypeOf((*MockDB)(nil).UpdateScooterLocation), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateTrip mocks base method.
+func (m *MockDB) UpdateTrip(arg0 context.Context, arg1 *domain.Trip) (*domain.Trip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTrip", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Trip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTrip indicates an expected call of UpdateTrip.
+func (mr *MockDBMockRecorder) UpdateTrip(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTrip", reflect.TypeOf((*MockDB)(nil).UpdateTrip), arg0, arg1)
+}
+
+// UpsertScooters mocks base method.
+func (m *MockDB) UpsertScooters(arg0 context.Context, arg1 []domain.Scooter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertScooters", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertScooters indicates an expected call of UpsertScooters.
+func (mr *MockDBMockRecorder) UpsertScooters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertScooters", reflect.TypeOf((*MockDB)(nil).UpsertScooters), arg0, arg1)
+}
+
+// UpsertUsers mocks base method.
+func (m *MockDB) UpsertUsers(arg0 context.Context, arg1 []domain.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUsers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertUsers indicates an expected call of UpsertUsers.
+func (mr *MockDBMockRecorder) UpsertUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUsers", reflect.TypeOf((*MockDB)(nil).UpsertUsers), arg0, arg1)
+}
+
+// WatchScooters mocks base method.
+func (m *MockDB) WatchScooters(arg0 context.Context, arg1 func(*domain.Scooter)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchScooters", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchScooters indicates an expected call of WatchScooters.
+func (mr *MockDBMockRecorder) WatchScooters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchScooters", reflect.TypeOf((*MockDB)(nil).WatchScooters), arg0, arg1)
+}
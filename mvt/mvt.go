@@ -0,0 +1,236 @@
+// Package mvt encodes point features into Mapbox Vector Tiles version 2,
+// see https://github.com/mapbox/vector-tile-spec/tree/master/2.1
+package mvt
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	ContentType = "application/vnd.mapbox-vector-tile"
+
+	// Extent is the size of the tile in tile coordinates
+	Extent = 4096
+	// Buffer is the size of the area around the tile in tile coordinates in
+	// which the features are kept, so that the symbols close to the edge are
+	// drawn on both tiles
+	Buffer = Extent / 16
+
+	version = 2
+
+	// field numbers of vector_tile.proto
+	tileLayersField      = 3
+	layerNameField       = 1
+	layerFeaturesField   = 2
+	layerKeysField       = 3
+	layerValuesField     = 4
+	layerExtentField     = 5
+	layerVersionField    = 15
+	featureIDField       = 1
+	featureTagsField     = 2
+	featureTypeField     = 3
+	featureGeometryField = 4
+	valueStringField     = 1
+	valueDoubleField     = 3
+	valueIntField        = 4
+	valueBoolField       = 7
+
+	pointGeometryType = 1
+	moveToCommand     = 1
+)
+
+// Point represents the location in tile coordinates, 0,0 is the north west
+// corner of the tile and Extent,Extent is the south east corner
+type Point struct {
+	X int
+	Y int
+}
+
+// Property represents the attribute of the feature, the value is string,
+// bool, int or float64
+type Property struct {
+	Key   string
+	Value interface{}
+}
+
+// Feature represents the point on the tile with its properties, zero id means
+// the feature has no id
+type Feature struct {
+	ID         uint64
+	Point      Point
+	Properties []Property
+}
+
+// Layer represents the named set of features of the tile
+type Layer struct {
+	Name     string
+	Features []Feature
+}
+
+// Project returns the location in the tile coordinates of the tile using Web
+// Mercator projection
+func Project(location domain.GeoLocation, tile domain.Tile) Point {
+	n := math.Exp2(float64(tile.Z))
+	x := (location.Longitude + 180) / 360 * n
+	latitude := location.Latitude * math.Pi / 180
+	y := (1 - math.Log(math.Tan(latitude)+1/math.Cos(latitude))/math.Pi) / 2 * n
+	return Point{
+		X: int(math.Round((x - float64(tile.X)) * Extent)),
+		Y: int(math.Round((y - float64(tile.Y)) * Extent)),
+	}
+}
+
+// InTile returns true if the point is within the tile or its buffer
+func (p Point) InTile() bool {
+	return p.X >= -Buffer && p.X <= Extent+Buffer && p.Y >= -Buffer && p.Y <= Extent+Buffer
+}
+
+// Encode returns the tile with the layers encoded as protocol buffers. The
+// features outside the tile and its buffer are left out, and so are the
+// layers without features as the spec recommends.
+func Encode(layers []Layer) ([]byte, error) {
+	var b []byte
+	for _, l := range layers {
+		layer, err := encodeLayer(l)
+		if err != nil {
+			return nil, fmt.Errorf("layer %v: %w", l.Name, err)
+		}
+		if layer == nil {
+			continue
+		}
+		b = protowire.AppendTag(b, tileLayersField, protowire.BytesType)
+		b = protowire.AppendBytes(b, layer)
+	}
+	return b, nil
+}
+
+// layerEncoder keeps the keys and values of the layer, the features refer to
+// them by index
+type layerEncoder struct {
+	keys         []string
+	keyIndexes   map[string]uint64
+	values       [][]byte
+	valueIndexes map[string]uint64
+}
+
+func encodeLayer(l Layer) ([]byte, error) {
+	e := &layerEncoder{
+		keyIndexes:   map[string]uint64{},
+		valueIndexes: map[string]uint64{},
+	}
+
+	var features []byte
+	for i := range l.Features {
+		f := &l.Features[i]
+		if !f.Point.InTile() {
+			continue
+		}
+
+		feature, err := e.encodeFeature(f)
+		if err != nil {
+			return nil, err
+		}
+		features = protowire.AppendTag(features, layerFeaturesField, protowire.BytesType)
+		features = protowire.AppendBytes(features, feature)
+	}
+	if features == nil {
+		return nil, nil
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, layerVersionField, protowire.VarintType)
+	b = protowire.AppendVarint(b, version)
+	b = protowire.AppendTag(b, layerNameField, protowire.BytesType)
+	b = protowire.AppendString(b, l.Name)
+	b = append(b, features...)
+	for _, key := range e.keys {
+		b = protowire.AppendTag(b, layerKeysField, protowire.BytesType)
+		b = protowire.AppendString(b, key)
+	}
+	for _, value := range e.values {
+		b = protowire.AppendTag(b, layerValuesField, protowire.BytesType)
+		b = protowire.AppendBytes(b, value)
+	}
+	b = protowire.AppendTag(b, layerExtentField, protowire.VarintType)
+	b = protowire.AppendVarint(b, Extent)
+	return b, nil
+}
+
+func (e *layerEncoder) encodeFeature(f *Feature) ([]byte, error) {
+	var tags []byte
+	for _, p := range f.Properties {
+		value, err := encodeValue(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("property %v: %w", p.Key, err)
+		}
+		tags = protowire.AppendVarint(tags, e.keyIndex(p.Key))
+		tags = protowire.AppendVarint(tags, e.valueIndex(value))
+	}
+
+	// the point is one MoveTo command with the zigzag encoded coordinates
+	var geometry []byte
+	geometry = protowire.AppendVarint(geometry, moveToCommand|1<<3)
+	geometry = protowire.AppendVarint(geometry, protowire.EncodeZigZag(int64(f.Point.X)))
+	geometry = protowire.AppendVarint(geometry, protowire.EncodeZigZag(int64(f.Point.Y)))
+
+	var b []byte
+	if f.ID != 0 {
+		b = protowire.AppendTag(b, featureIDField, protowire.VarintType)
+		b = protowire.AppendVarint(b, f.ID)
+	}
+	if tags != nil {
+		b = protowire.AppendTag(b, featureTagsField, protowire.BytesType)
+		b = protowire.AppendBytes(b, tags)
+	}
+	b = protowire.AppendTag(b, featureTypeField, protowire.VarintType)
+	b = protowire.AppendVarint(b, pointGeometryType)
+	b = protowire.AppendTag(b, featureGeometryField, protowire.BytesType)
+	b = protowire.AppendBytes(b, geometry)
+	return b, nil
+}
+
+func (e *layerEncoder) keyIndex(key string) uint64 {
+	i, ok := e.keyIndexes[key]
+	if !ok {
+		i = uint64(len(e.keys))
+		e.keyIndexes[key] = i
+		e.keys = append(e.keys, key)
+	}
+	return i
+}
+
+func (e *layerEncoder) valueIndex(value []byte) uint64 {
+	i, ok := e.valueIndexes[string(value)]
+	if !ok {
+		i = uint64(len(e.values))
+		e.valueIndexes[string(value)] = i
+		e.values = append(e.values, value)
+	}
+	return i
+}
+
+// encodeValue returns the value message of the property value
+func encodeValue(v interface{}) ([]byte, error) {
+	var b []byte
+	switch value := v.(type) {
+	case string:
+		b = protowire.AppendTag(b, valueStringField, protowire.BytesType)
+		b = protowire.AppendString(b, value)
+	case bool:
+		b = protowire.AppendTag(b, valueBoolField, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(value))
+	case int:
+		b = protowire.AppendTag(b, valueIntField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(value))
+	case float64:
+		b = protowire.AppendTag(b, valueDoubleField, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(value))
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+	return b, nil
+}
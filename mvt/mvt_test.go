@@ -0,0 +1,188 @@
+package mvt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// field represents the decoded field of protocol buffers message, the value
+// is the number or the bytes of embedded message, string or packed numbers
+type field struct {
+	number protowire.Number
+	value  interface{}
+}
+
+func decodeMessage(t *testing.T, b []byte) []field {
+	fields := []field{}
+	for len(b) > 0 {
+		number, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		if n < 0 {
+			t.Fatalf("invalid value: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		fields = append(fields, field{number: number, value: value})
+	}
+	return fields
+}
+
+func decodePacked(t *testing.T, b []byte) []uint64 {
+	values := []uint64{}
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			t.Fatalf("invalid packed value: %v", protowire.ParseError(n))
+		}
+		values = append(values, v)
+		b = b[n:]
+	}
+	return values
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		location domain.GeoLocation
+		tile     domain.Tile
+		want     Point
+	}{
+		{
+			name:     "should project center of map to center of world tile",
+			location: domain.GeoLocation{Latitude: 0, Longitude: 0},
+			tile:     domain.Tile{Z: 0, X: 0, Y: 0},
+			want:     Point{X: Extent / 2, Y: Extent / 2},
+		},
+		{
+			name:     "should project north east corner of tile to origin of next tile",
+			location: domain.Tile{Z: 12, X: 2200, Y: 1343}.Bounds().NorthEast,
+			tile:     domain.Tile{Z: 12, X: 2201, Y: 1343},
+			want:     Point{X: 0, Y: 0},
+		},
+		{
+			name:     "should project location to tile coordinates",
+			location: domain.GeoLocation{Latitude: 52.520008, Longitude: 13.404954},
+			tile:     domain.Tile{Z: 12, X: 2200, Y: 1343},
+			want:     Point{X: 2124, Y: 1246},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Project(tt.location, tt.tile); got != tt.want {
+				t.Errorf("Project() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	layers := []Layer{
+		{
+			Name: "scooters",
+			Features: []Feature{
+				{ID: 7, Point: Point{X: 10, Y: 20}, Properties: []Property{{Key: "name", Value: "Scooter"}, {Key: "count", Value: 1}}},
+				{Point: Point{X: -5, Y: 4000}, Properties: []Property{{Key: "count", Value: 1}, {Key: "available", Value: true}}},
+				{Point: Point{X: Extent * 2, Y: 0}, Properties: []Property{{Key: "count", Value: 3}}},
+			},
+		},
+		{
+			Name:     "empty",
+			Features: []Feature{{Point: Point{X: -Extent, Y: 0}}},
+		},
+	}
+
+	b, err := Encode(layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tile := decodeMessage(t, b)
+	if len(tile) != 1 || tile[0].number != tileLayersField {
+		t.Fatalf("Encode() tile = %v, want one layer", tile)
+	}
+
+	layer := decodeMessage(t, tile[0].value.([]byte))
+	name := ""
+	keys := []string{}
+	values := []field{}
+	features := [][]field{}
+	extent := uint64(0)
+	for _, f := range layer {
+		switch f.number {
+		case layerNameField:
+			name = string(f.value.([]byte))
+		case layerKeysField:
+			keys = append(keys, string(f.value.([]byte)))
+		case layerValuesField:
+			values = append(values, decodeMessage(t, f.value.([]byte))[0])
+		case layerFeaturesField:
+			features = append(features, decodeMessage(t, f.value.([]byte)))
+		case layerExtentField:
+			extent = f.value.(uint64)
+		}
+	}
+
+	if name != "scooters" || extent != Extent {
+		t.Errorf("Encode() layer name = %v extent = %v, want scooters %v", name, extent, Extent)
+	}
+	if !reflect.DeepEqual(keys, []string{"name", "count", "available"}) {
+		t.Errorf("Encode() keys = %v", keys)
+	}
+	wantValues := []field{
+		{number: valueStringField, value: []byte("Scooter")},
+		{number: valueIntField, value: uint64(1)},
+		{number: valueBoolField, value: uint64(1)},
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("Encode() values = %v, want %v", values, wantValues)
+	}
+
+	// the third feature is outside of the tile buffer
+	wantFeatures := [][]field{
+		{
+			{number: featureIDField, value: uint64(7)},
+			{number: featureTagsField, value: []uint64{0, 0, 1, 1}},
+			{number: featureTypeField, value: uint64(pointGeometryType)},
+			{number: featureGeometryField, value: []uint64{9, 20, 40}},
+		},
+		{
+			{number: featureTagsField, value: []uint64{1, 1, 2, 2}},
+			{number: featureTypeField, value: uint64(pointGeometryType)},
+			{number: featureGeometryField, value: []uint64{9, 9, 8000}},
+		},
+	}
+	for _, feature := range features {
+		for i := range feature {
+			if feature[i].number == featureTagsField || feature[i].number == featureGeometryField {
+				feature[i].value = decodePacked(t, feature[i].value.([]byte))
+			}
+		}
+	}
+	if !reflect.DeepEqual(features, wantFeatures) {
+		t.Errorf("Encode() features = %v, want %v", features, wantFeatures)
+	}
+}
+
+func TestEncodeUnsupportedValue(t *testing.T) {
+	_, err := Encode([]Layer{{Name: "scooters", Features: []Feature{{Properties: []Property{{Key: "id", Value: []string{}}}}}}})
+	if err == nil {
+		t.Errorf("Encode() expected error for unsupported value")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/config"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/mongodb"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/fixtures"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// seedTimeout is the max time to save the fixtures
+	seedTimeout = 30 * time.Second
+)
+
+// seed saves the sample scooters and users in the database, the database
+// must be migrated already
+func seed(serviceLogger *logrus.Logger) error {
+	database, err := mongodb.NewMongoDB(config.Get().Mongo.Uri, config.Get().Mongo.Db,
+		mongodb.WithConnectTimeout(config.Get().Mongo.ConnectTimeout),
+		mongodb.WithLogger(serviceLogger))
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+	defer cancel()
+	defer database.Disconnect(ctx)
+
+	err = fixtures.Seed(ctx, database)
+	if err != nil {
+		return err
+	}
+	serviceLogger.Info("fixtures seeded")
+	return nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/api"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/api/rest"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/config"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/geoindex"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/mongodb"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/wal"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/health"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/metrics"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/tracing"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// serve migrates the database if auto migration is enabled and runs the
+// server until it is stopped with SIGINT or SIGTERM
+func serve(serviceLogger *logrus.Logger) {
+	m, err := newMigrate()
+	if err != nil {
+		serviceLogger.WithError(err).Fatal("unable to create migration")
+	}
+	if config.Get().Migration.Auto {
+		err = m.Up()
+		if err != nil && err != migrate.ErrNoChange {
+			serviceLogger.WithError(err).Fatal("unable to migrate")
+		}
+	}
+	migrationVersion, err := latestMigrationVersion()
+	if err != nil {
+		serviceLogger.WithError(err).Fatal("unable to get migration version")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stopTracing, err := tracing.Setup(ctx, config.Get().Tracing.Exporter)
+	if err != nil {
+		serviceLogger.WithError(err).Fatal("unable to setup tracing")
+	}
+
+	mongoDatabase, err := mongodb.NewMongoDB(config.Get().Mongo.Uri, config.Get().Mongo.Db,
+		mongodb.WithTripEventRetention(config.Get().TripEvent.Retention),
+		mongodb.WithConnectTimeout(config.Get().Mongo.ConnectTimeout),
+		mongodb.WithMaxPoolSize(config.Get().Mongo.MaxPoolSize),
+		mongodb.WithLogger(serviceLogger))
+	if err != nil {
+		serviceLogger.WithError(err).Fatal("unable to connect to database")
+	}
+	defer mongoDatabase.Disconnect(ctx)
+
+	database := metrics.NewDB(mongoDatabase)
+	prometheus.MustRegister(metrics.NewScooterCollector(database, serviceLogger))
+
+	var walDatabase wal.DB
+	if config.Get().Wal.Dir != "" {
+		walLog, err := wal.Open(config.Get().Wal.Dir, config.Get().Wal.MaxSegmentSize)
+		if err != nil {
+			serviceLogger.WithError(err).Fatal("unable to open write ahead log")
+		}
+
+		walDatabase, err = wal.NewDB(database, walLog, config.Get().Wal.ReplayInterval, wal.WithLogger(serviceLogger))
+		if err != nil {
+			serviceLogger.WithError(err).Fatal("unable to create write ahead log database")
+		}
+		walDatabase.StartReplay()
+		database = walDatabase
+	}
+
+	var indexDatabase geoindex.DB
+	if config.Get().ScooterIndex.Enabled {
+		indexDatabase, err = geoindex.NewDB(database, config.Get().ScooterIndex.CellSize, config.Get().ScooterIndex.ResyncInterval,
+			geoindex.WithLogger(serviceLogger))
+		if err != nil {
+			serviceLogger.WithError(err).Fatal("unable to create scooter index")
+		}
+
+		indexCtx, indexCancel := context.WithTimeout(context.Background(), config.Get().ScooterIndex.ResyncInterval)
+		err = indexDatabase.Start(indexCtx)
+		indexCancel()
+		if err != nil {
+			serviceLogger.WithError(err).Fatal("unable to start scooter index")
+		}
+		database = indexDatabase
+	}
+
+	appOpts := []app.Option{app.WithLogger(serviceLogger)}
+	if walDatabase != nil {
+		appOpts = append(appOpts, app.WithTripEventReplayer(walDatabase))
+	}
+	if config.Get().TripEvent.IngestionMode == "async" {
+		writeBehind := config.Get().TripEvent.WriteBehind
+		appOpts = append(appOpts, app.WithWriteBehind(app.WriteBehindConfig{
+			QueueSize:     writeBehind.QueueSize,
+			BatchSize:     writeBehind.BatchSize,
+			FlushInterval: writeBehind.FlushInterval,
+			FlushTimeout:  writeBehind.FlushTimeout,
+			Workers:       writeBehind.Workers,
+		}))
+	}
+
+	scooterApp, err := app.NewApp(database, appOpts...)
+	if err != nil {
+		serviceLogger.WithError(err).Fatal("unable to create app")
+	}
+
+	checker := health.NewChecker()
+	checker.Add("mongo", func(ctx context.Context) (string, error) {
+		return "", mongoDatabase.Ping(ctx)
+	})
+	checker.Add("migration", health.NewMigrationCheck(m, migrationVersion))
+	checker.Add("trip_event_ingestion", func(ctx context.Context) (string, error) {
+		return config.Get().TripEvent.IngestionMode, scooterApp.Ready(ctx)
+	})
+	if walDatabase != nil {
+		checker.Add("write_ahead_log", func(ctx context.Context) (string, error) {
+			detail := fmt.Sprintf("%v bytes pending", walDatabase.PendingSize())
+			if !walDatabase.Replaying() {
+				return detail, errors.New("write ahead log replay is not running")
+			}
+			return detail, nil
+		})
+	}
+
+	if indexDatabase != nil {
+		checker.Add("scooter_index", func(ctx context.Context) (string, error) {
+			lastResync := indexDatabase.LastResync()
+			detail := fmt.Sprintf("%v available scooters, resynced at %v", indexDatabase.Len(), lastResync.Format(time.RFC3339))
+			if time.Since(lastResync) > 2*config.Get().ScooterIndex.ResyncInterval {
+				return detail, errors.New("scooter index resync is late")
+			}
+			return detail, nil
+		})
+	}
+
+	restApi, err := rest.NewApi(metrics.NewApp(tracing.NewApp(scooterApp)), config.Get().Server.Port, config.Get().Server.ApiKey,
+		rest.WithLogger(serviceLogger),
+		rest.WithAdminApiKey(config.Get().Server.AdminApiKey),
+		rest.WithHealthChecker(checker),
+		rest.WithDrainDelay(config.Get().Server.DrainDelay),
+		rest.WithStopTimeouts(config.Get().Server.ShutdownTimeout, config.Get().Server.AppStopTimeout))
+	if err != nil {
+		serviceLogger.WithError(err).Fatal("unable to create api")
+	}
+	restApi.StartServer()
+
+	var configChanges <-chan struct{}
+	if config.Get().File() != "" {
+		configWatcher, err := config.WatchFile(config.Get().File())
+		if err != nil {
+			serviceLogger.WithError(err).Fatal("unable to watch config file")
+		}
+		defer configWatcher.Close()
+		configChanges = configWatcher.Changes()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for running := true; running; {
+		select {
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				running = false
+				continue
+			}
+			reloadConfig(serviceLogger, restApi)
+		case <-configChanges:
+			reloadConfig(serviceLogger, restApi)
+		}
+	}
+
+	serviceLogger.Info("shutting down server")
+	restApi.GracefulStopServer()
+
+	if walDatabase != nil {
+		err = walDatabase.StopReplay()
+		if err != nil {
+			serviceLogger.WithError(err).Error("unable to stop write ahead log replay")
+		}
+	}
+
+	if indexDatabase != nil {
+		indexDatabase.Stop()
+	}
+
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer tracingCancel()
+	err = stopTracing(tracingCtx)
+	if err != nil {
+		serviceLogger.WithError(err).Error("unable to stop tracing")
+	}
+}
+
+// reloadConfig reloads the config and applies the reloadable settings, the
+// current config is kept if the reloaded config is invalid
+func reloadConfig(serviceLogger *logrus.Logger, restApi api.Api) {
+	c, restartRequired, err := config.Reload()
+	if err != nil {
+		serviceLogger.WithError(err).Error("config reload rejected, keeping current config")
+		return
+	}
+
+	err = restApi.SetApiKey(c.Server.ApiKey)
+	if err != nil {
+		serviceLogger.WithError(err).Error("unable to set api key")
+	}
+	err = restApi.SetAdminApiKey(c.Server.AdminApiKey)
+	if err != nil {
+		serviceLogger.WithError(err).Error("unable to set admin api key")
+	}
+	level, err := logrus.ParseLevel(c.Log.Level)
+	if err != nil {
+		serviceLogger.WithError(err).Error("unable to set log level")
+	} else {
+		serviceLogger.SetLevel(level)
+	}
+
+	if restartRequired {
+		serviceLogger.Warn("config reloaded, settings other than server.api_key, server.admin_api_key and log.level are applied after restart")
+		return
+	}
+	serviceLogger.Info("config reloaded")
+}
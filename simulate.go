@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"syscall"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/config"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db/mongodb"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/simulator"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	errInvariantsViolated = errors.New("invariants violated")
+	errContentionFailed   = errors.New("contention check failed")
+)
+
+// simulate runs the riders of the simulator scenario until the scenario
+// duration is over or it is stopped with SIGINT or SIGTERM, and prints the
+// report to out. The scooters and riders generated by the scenario are saved
+// in the database before the riders start. With invariants enabled in the
+// scenario the database is checked after the riders stop and the error is
+// returned if any invariant is violated. In contention mode the riders race
+// for the same scooter instead of taking trips
+func simulate(serviceLogger *logrus.Logger, out io.Writer) error {
+	scenario, err := simulator.DefaultScenario()
+	if config.Get().Simulator.ScenarioFile != "" {
+		scenario, err = simulator.LoadScenario(config.Get().Simulator.ScenarioFile)
+	}
+	if err != nil {
+		return err
+	}
+	if scenario.BaseURL == "" {
+		scenario.BaseURL = config.Get().Simulator.BaseURL
+	}
+
+	sim, err := simulator.New(scenario, config.Get().Server.ApiKey, simulator.WithLogger(serviceLogger))
+	if err != nil {
+		return err
+	}
+
+	var database db.DB
+	if len(sim.Scooters()) > 0 || scenario.Invariants.Enabled || scenario.Mode == simulator.ModeContention {
+		database, err = mongodb.NewMongoDB(config.Get().Mongo.Uri, config.Get().Mongo.Db,
+			mongodb.WithConnectTimeout(config.Get().Mongo.ConnectTimeout),
+			mongodb.WithLogger(serviceLogger))
+		if err != nil {
+			return fmt.Errorf("unable to connect to database: %w", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+			defer cancel()
+			database.Disconnect(ctx)
+		}()
+	}
+	if len(sim.Scooters()) > 0 {
+		err = seedSimulation(serviceLogger, database, sim)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if scenario.Mode == simulator.ModeContention {
+		return contend(ctx, sim, database, out)
+	}
+
+	report := sim.Run(ctx)
+	if scenario.Invariants.Enabled {
+		report.Invariants, err = sim.CheckInvariants(ctx, database)
+		if err != nil {
+			return fmt.Errorf("unable to check invariants: %w", err)
+		}
+	}
+
+	err = report.Print(out)
+	if err != nil {
+		return err
+	}
+	if report.Invariants != nil && !report.Invariants.Passed {
+		return errInvariantsViolated
+	}
+	return nil
+}
+
+// contend runs the contention rounds and prints the report, it returns error
+// if any scooter was granted to more than one rider at a time
+func contend(ctx context.Context, sim *simulator.Simulator, database db.DB, out io.Writer) error {
+	report, err := sim.RunContention(ctx, database)
+	if err != nil {
+		return fmt.Errorf("unable to check contention: %w", err)
+	}
+
+	err = report.Print(out)
+	if err != nil {
+		return err
+	}
+	if !report.Contention.Passed {
+		return errContentionFailed
+	}
+	return nil
+}
+
+// seedSimulation saves the scooters and riders of the simulation in the
+// database, the records of previous run with same seed are replaced
+func seedSimulation(serviceLogger *logrus.Logger, database db.DB, sim *simulator.Simulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+	defer cancel()
+
+	err := database.UpsertScooters(ctx, sim.Scooters())
+	if err != nil {
+		return fmt.Errorf("unable to save simulated scooters: %w", err)
+	}
+	err = database.UpsertUsers(ctx, sim.Users())
+	if err != nil {
+		return fmt.Errorf("unable to save simulated riders: %w", err)
+	}
+	serviceLogger.WithField("scooters", len(sim.Scooters())).Info("simulated scooters saved")
+	return nil
+}
@@ -0,0 +1,300 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/client"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// modes of the simulation
+const (
+	// ModeRide runs the riders taking trips in the spawn areas
+	ModeRide = "ride"
+	// ModeContention runs rounds in which all the riders race to begin trip
+	// with the same scooter
+	ModeContention = "contention"
+)
+
+// Contention configures the contention mode
+type Contention struct {
+	// Rounds is the number of times the riders race for the scooter
+	Rounds int `yaml:"rounds"`
+	// HoldTime is the time the scooter is kept by the granted rider before
+	// the trip is ended, the database is checked meanwhile
+	HoldTime time.Duration `yaml:"hold_time"`
+}
+
+func (c *Contention) validate(key string, invalid func(key string, format string, args ...interface{})) {
+	if c.Rounds <= 0 {
+		invalid(key+".rounds", "must be greater than 0")
+	}
+	if c.HoldTime < 0 {
+		invalid(key+".hold_time", "must not be negative")
+	}
+}
+
+// ContentionReport is the result of the contention mode, it passes if each
+// contended scooter was granted to at most one rider at a time as per the
+// api responses and the database
+type ContentionReport struct {
+	Passed bool
+	Rounds []ContentionRound
+	// Violations describe each time the scooter was granted to more than one
+	// rider or the database disagrees with the api responses
+	Violations []string
+}
+
+// ContentionRound is the result of the race for one scooter in a round, the
+// riders which found different nearest scooter race in a separate row
+type ContentionRound struct {
+	Round      int
+	ScooterID  string
+	Contenders int
+	// Granted are the riders whose begin trip succeeded
+	Granted []string
+	// Rejected is the number of begin trips rejected as the scooter is not
+	// available
+	Rejected int
+	// Errors is the number of begin trips failed otherwise, their outcome is
+	// unknown
+	Errors int
+	// Holder is the current user of the scooter in the database while it is
+	// held
+	Holder string
+}
+
+// contender is a rider racing for the scooter in a round
+type contender struct {
+	userID  string
+	scooter client.Scooter
+	found   bool
+	err     error
+}
+
+// RunContention places all the riders at the center of the first spawn area,
+// so that they find the same nearest scooter, and makes them begin trip with
+// it at the same time for the scenario contention rounds. The granted riders
+// hold the scooter while the database is checked and end the trip before the
+// next round. It stops after the rounds, the scenario duration or when the
+// context is done.
+func (s *Simulator) RunContention(ctx context.Context, database db.DB) (*Report, error) {
+	if s.scenario.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.scenario.Duration)
+		defer cancel()
+	}
+
+	area := s.scenario.SpawnAreas[0]
+	spot := domain.GeoLocation{Latitude: area.Latitude, Longitude: area.Longitude}
+	s.logger.WithFields(logrus.Fields{
+		"base_url": s.scenario.BaseURL,
+		"riders":   len(s.riders),
+		"rounds":   s.scenario.Contention.Rounds,
+		"area":     area.Name,
+	}).Info("starting contention")
+
+	s.recorder.begin()
+	contention := &ContentionReport{
+		Rounds:     []ContentionRound{},
+		Violations: []string{},
+	}
+	var err error
+	for round := 1; round <= s.scenario.Contention.Rounds && ctx.Err() == nil; round++ {
+		var rounds []ContentionRound
+		rounds, err = s.contentionRound(ctx, database, round, spot, contention)
+		contention.Rounds = append(contention.Rounds, rounds...)
+		if err != nil {
+			break
+		}
+	}
+	s.recorder.stop()
+	s.logger.Info("contention stopped")
+
+	report := s.recorder.report(len(s.riders))
+	report.Contention = contention
+	if err != nil {
+		return report, err
+	}
+
+	contended := false
+	for _, r := range contention.Rounds {
+		contended = contended || r.Contenders > 1
+	}
+	if !contended {
+		contention.Violations = append(contention.Violations, "no scooter was contended by more than one rider")
+	}
+	contention.Passed = len(contention.Violations) == 0
+	return report, nil
+}
+
+// contentionRound runs one round, the scooters granted in the round are
+// released before it returns
+func (s *Simulator) contentionRound(ctx context.Context, database db.DB, round int, spot domain.GeoLocation, report *ContentionReport) ([]ContentionRound, error) {
+	violation := func(format string, args ...interface{}) {
+		report.Violations = append(report.Violations, fmt.Sprintf("round %v: ", round)+fmt.Sprintf(format, args...))
+	}
+
+	contenders := make([]contender, len(s.riders))
+	s.race(func(i int) {
+		contenders[i].userID = s.riders[i].ID
+		scooters, err := s.client.GetAvailableScooters(ctx, clientLocation(spot), s.scenario.SearchRadius)
+		if err == nil && len(scooters) > 0 {
+			contenders[i].scooter = scooters[0]
+			contenders[i].found = true
+		}
+	})
+
+	// the begin trips are not cancelled when the simulation is stopped,
+	// otherwise the granted scooter would not be released
+	s.race(func(i int) {
+		if !contenders[i].found {
+			return
+		}
+		beginCtx, cancel := context.WithTimeout(context.Background(), s.scenario.RequestTimeout)
+		defer cancel()
+		_, contenders[i].err = s.client.BeginTrip(beginCtx, contenders[i].userID, contenders[i].scooter.ID)
+	})
+
+	rows := map[string]*ContentionRound{}
+	scooters := map[string]client.Scooter{}
+	rejected, errored := map[string]bool{}, map[string]bool{}
+	for _, c := range contenders {
+		if !c.found {
+			continue
+		}
+		row, ok := rows[c.scooter.ID]
+		if !ok {
+			row = &ContentionRound{Round: round, ScooterID: c.scooter.ID, Granted: []string{}}
+			rows[c.scooter.ID] = row
+			scooters[c.scooter.ID] = c.scooter
+		}
+		row.Contenders++
+
+		switch {
+		case c.err == nil:
+			row.Granted = append(row.Granted, c.userID)
+		case errors.Is(c.err, client.ErrBadRequest):
+			row.Rejected++
+			rejected[c.userID] = true
+		default:
+			row.Errors++
+			errored[c.userID] = true
+		}
+	}
+	if len(rows) == 0 {
+		s.recorder.scooterNotFound()
+		return nil, nil
+	}
+
+	result := make([]ContentionRound, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ScooterID < result[j].ScooterID })
+
+	// the granted riders hold the scooter while the database is checked
+	sleep(ctx, s.scenario.Contention.HoldTime)
+	checkCtx, cancel := context.WithTimeout(context.Background(), s.scenario.RequestTimeout)
+	defer cancel()
+	var dbErr error
+	for i := range result {
+		row := &result[i]
+		if len(row.Granted) > 1 {
+			violation("scooter %v granted to %v riders %v", row.ScooterID, len(row.Granted), row.Granted)
+		}
+
+		scooter, err := database.GetScooterByID(checkCtx, row.ScooterID)
+		if err != nil {
+			dbErr = fmt.Errorf("unable to get scooter %v: %w", row.ScooterID, err)
+			continue
+		}
+		row.Holder = currentUser(*scooter)
+		switch {
+		case scooter.IsAvailable != (row.Holder == ""):
+			violation("scooter %v has is_available %v with current user %q in database", row.ScooterID, scooter.IsAvailable, row.Holder)
+		case len(row.Granted) > 0 && !contains(row.Granted, row.Holder):
+			violation("scooter %v granted to %v but current user in database is %q", row.ScooterID, row.Granted, row.Holder)
+		case rejected[row.Holder]:
+			violation("begin trip of %v with scooter %v was rejected but it is current user in database", row.Holder, row.ScooterID)
+		}
+
+		// the rider whose begin trip failed without response may still have
+		// got the scooter
+		if row.Holder != "" && errored[row.Holder] {
+			row.Granted = append(row.Granted, row.Holder)
+		}
+	}
+
+	for _, row := range result {
+		s.release(row, scooters[row.ScooterID].Location)
+	}
+	return result, dbErr
+}
+
+// race runs f for each rider concurrently, all of them start at the same time
+func (s *Simulator) race(f func(i int)) {
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := range s.riders {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			f(i)
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+// release ends the trip with the scooter at the location it was found, so that
+// it is the nearest scooter again in the next round. If the scooter was granted
+// to more than one rider, only the current user in the database is able to
+// end the trip, so it is tried first.
+func (s *Simulator) release(row ContentionRound, location client.GeoLocation) {
+	riders := []string{}
+	if contains(row.Granted, row.Holder) {
+		riders = append(riders, row.Holder)
+	}
+	for _, userID := range row.Granted {
+		if userID != row.Holder {
+			riders = append(riders, userID)
+		}
+	}
+
+	var err error
+	for _, userID := range riders {
+		endCtx, cancel := context.WithTimeout(context.Background(), (s.scenario.RequestTimeout+endTripBackoff)*endTripAttempts)
+		for attempt := 1; ; attempt++ {
+			_, err = s.client.EndTrip(endCtx, userID, row.ScooterID, location)
+			if err == nil || attempt == endTripAttempts || !sleep(endCtx, endTripBackoff) {
+				break
+			}
+		}
+		cancel()
+		if err == nil {
+			return
+		}
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("scooter_id", row.ScooterID).Warn("unable to end trip")
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
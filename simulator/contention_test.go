@@ -0,0 +1,175 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func contentionScenario(baseURL string) Scenario {
+	scenario := testScenario(baseURL)
+	scenario.Mode = ModeContention
+	scenario.Duration = 0
+	scenario.Contention = Contention{Rounds: 3, HoldTime: 10 * time.Millisecond}
+	return scenario
+}
+
+// fakeDB returns the scooters of the fake service
+func fakeDB(t *testing.T, fake *fakeService) db.DB {
+	ctrl := gomock.NewController(t)
+	database := mocks.NewMockDB(ctrl)
+	database.EXPECT().GetScooterByID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id string) (*domain.Scooter, error) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		s, ok := fake.scooters[id]
+		if !ok {
+			return nil, db.ErrRecordNotFound
+		}
+		scooter := *s
+		return &scooter, nil
+	}).AnyTimes()
+	return database
+}
+
+func TestRunContention(t *testing.T) {
+	sim, err := New(contentionScenario("http://localhost"), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeService(sim.Scooters(), 0)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	scenario := contentionScenario(server.URL)
+	sim, err = New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := sim.RunContention(context.Background(), fakeDB(t, fake))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Contention.Passed || len(report.Contention.Violations) != 0 {
+		t.Errorf("Contention = %+v, want passed", report.Contention)
+	}
+	if len(report.Contention.Rounds) != scenario.Contention.Rounds {
+		t.Fatalf("Rounds = %+v, want %v rounds", report.Contention.Rounds, scenario.Contention.Rounds)
+	}
+	// the scooter is released at the same place, so it is contended again
+	for _, r := range report.Contention.Rounds {
+		if r.ScooterID != report.Contention.Rounds[0].ScooterID || r.Contenders != scenario.Riders ||
+			len(r.Granted) != 1 || r.Rejected != scenario.Riders-1 || r.Holder != r.Granted[0] {
+			t.Errorf("round = %+v, want all riders racing for one scooter granted to one rider", r)
+		}
+	}
+	for id, s := range fake.scooters {
+		if !s.IsAvailable {
+			t.Errorf("scooter %v is not available after contention", id)
+		}
+	}
+
+	out := bytes.Buffer{}
+	err = report.Print(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"contenders", report.Contention.Rounds[0].ScooterID, "contention: PASS"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Print() = %v, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunContentionDoubleBooking(t *testing.T) {
+	sim, err := New(contentionScenario("http://localhost"), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeService(sim.Scooters(), 0)
+	fake.racyBegin = true
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	sim, err = New(contentionScenario(server.URL), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := sim.RunContention(context.Background(), fakeDB(t, fake))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Contention.Passed || len(report.Contention.Violations) == 0 ||
+		!strings.Contains(report.Contention.Violations[0], "granted to") {
+		t.Errorf("Contention = %+v, want double booking violations", report.Contention)
+	}
+	// the rider holding the scooter in database ends the trip
+	for id, s := range fake.scooters {
+		if !s.IsAvailable {
+			t.Errorf("scooter %v is not available after contention", id)
+		}
+	}
+
+	out := bytes.Buffer{}
+	err = report.Print(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "contention: FAIL") {
+		t.Errorf("Print() = %v, want it to contain %q", out.String(), "contention: FAIL")
+	}
+}
+
+func TestRunContentionErrors(t *testing.T) {
+	sim, err := New(contentionScenario("http://localhost"), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeService(sim.Scooters(), 0)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	sim, err = New(contentionScenario(server.URL), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctrl := gomock.NewController(t)
+	database := mocks.NewMockDB(ctrl)
+	database.EXPECT().GetScooterByID(gomock.Any(), gomock.Any()).Return(nil, errors.New("database unavailable"))
+	_, err = sim.RunContention(context.Background(), database)
+	if err == nil || !strings.Contains(err.Error(), "database unavailable") {
+		t.Errorf("RunContention() error = %v, want database error", err)
+	}
+	// the granted scooter is released even if the database check fails
+	for id, s := range fake.scooters {
+		if !s.IsAvailable {
+			t.Errorf("scooter %v is not available after contention", id)
+		}
+	}
+
+	// no scooter in search radius to race for
+	fake = newFakeService(nil, 0)
+	server = httptest.NewServer(fake)
+	defer server.Close()
+	sim, err = New(contentionScenario(server.URL), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := sim.RunContention(context.Background(), fakeDB(t, fake))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Contention.Passed || report.NoScooterFound != 3 {
+		t.Errorf("Contention = %+v, NoScooterFound = %v, want failed with 3 rounds without scooter", report.Contention, report.NoScooterFound)
+	}
+}
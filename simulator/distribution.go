@@ -0,0 +1,153 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// distribution types
+const (
+	DistributionConstant    = "constant"
+	DistributionUniform     = "uniform"
+	DistributionNormal      = "normal"
+	DistributionExponential = "exponential"
+)
+
+// Distribution describes random durations. Constant uses mean, uniform uses
+// min and max, normal uses mean and stddev and exponential uses mean. The
+// sampled durations are limited to min and max if they are set.
+type Distribution struct {
+	Type   string        `yaml:"type"`
+	Mean   time.Duration `yaml:"mean"`
+	StdDev time.Duration `yaml:"stddev"`
+	Min    time.Duration `yaml:"min"`
+	Max    time.Duration `yaml:"max"`
+}
+
+// NumberDistribution describes random numbers e.g. speed, the settings are
+// same as of Distribution
+type NumberDistribution struct {
+	Type   string  `yaml:"type"`
+	Mean   float64 `yaml:"mean"`
+	StdDev float64 `yaml:"stddev"`
+	Min    float64 `yaml:"min"`
+	Max    float64 `yaml:"max"`
+}
+
+// UnmarshalYAML replaces the whole distribution, so that the settings of the
+// default distribution are not mixed with the ones in the scenario file
+func (d *Distribution) UnmarshalYAML(value *yaml.Node) error {
+	err := checkDistributionKeys(value)
+	if err != nil {
+		return err
+	}
+
+	type plain Distribution
+	p := plain{}
+	err = value.Decode(&p)
+	if err != nil {
+		return err
+	}
+	*d = Distribution(p)
+	return nil
+}
+
+// UnmarshalYAML replaces the whole distribution, see Distribution.UnmarshalYAML
+func (d *NumberDistribution) UnmarshalYAML(value *yaml.Node) error {
+	err := checkDistributionKeys(value)
+	if err != nil {
+		return err
+	}
+
+	type plain NumberDistribution
+	p := plain{}
+	err = value.Decode(&p)
+	if err != nil {
+		return err
+	}
+	*d = NumberDistribution(p)
+	return nil
+}
+
+// checkDistributionKeys returns error for unknown key, the decoder of custom
+// unmarshaler does not check them
+func checkDistributionKeys(value *yaml.Node) error {
+	known := map[string]bool{"type": true, "mean": true, "stddev": true, "min": true, "max": true}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i]
+		if !known[key.Value] {
+			return fmt.Errorf("line %v: field %v not found in distribution", key.Line, key.Value)
+		}
+	}
+	return nil
+}
+
+func (d *Distribution) numbers() NumberDistribution {
+	return NumberDistribution{
+		Type:   d.Type,
+		Mean:   float64(d.Mean),
+		StdDev: float64(d.StdDev),
+		Min:    float64(d.Min),
+		Max:    float64(d.Max),
+	}
+}
+
+func (d *Distribution) validate(key string, invalid func(key string, format string, args ...interface{})) {
+	n := d.numbers()
+	n.validate(key, invalid)
+}
+
+// Sample returns random duration from the distribution
+func (d *Distribution) Sample(rng *rand.Rand) time.Duration {
+	n := d.numbers()
+	return time.Duration(n.Sample(rng))
+}
+
+func (d *NumberDistribution) validate(key string, invalid func(key string, format string, args ...interface{})) {
+	if d.Min < 0 || d.Max < 0 || d.Mean < 0 || d.StdDev < 0 {
+		invalid(key, "values must not be negative")
+	}
+	if d.Max > 0 && d.Max < d.Min {
+		invalid(key+".max", "must not be less than min")
+	}
+
+	switch d.Type {
+	case DistributionConstant, DistributionExponential:
+	case DistributionUniform:
+		if d.Max == 0 {
+			invalid(key+".max", "is required for uniform distribution")
+		}
+	case DistributionNormal:
+		if d.StdDev == 0 {
+			invalid(key+".stddev", "is required for normal distribution")
+		}
+	default:
+		invalid(key+".type", "%q must be one of %v, %v, %v or %v", d.Type,
+			DistributionConstant, DistributionUniform, DistributionNormal, DistributionExponential)
+	}
+}
+
+// Sample returns random number from the distribution
+func (d *NumberDistribution) Sample(rng *rand.Rand) float64 {
+	var v float64
+	switch d.Type {
+	case DistributionUniform:
+		v = d.Min + rng.Float64()*(d.Max-d.Min)
+	case DistributionNormal:
+		v = d.Mean + rng.NormFloat64()*d.StdDev
+	case DistributionExponential:
+		v = rng.ExpFloat64() * d.Mean
+	default:
+		v = d.Mean
+	}
+
+	v = math.Max(v, d.Min)
+	if d.Max > 0 {
+		v = math.Min(v, d.Max)
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+const earthRadius = 6371000.0
+
+// move returns the location at distance(in meters) from the location in the
+// direction of heading(in degrees clockwise from north)
+func move(location domain.GeoLocation, heading float64, distance float64) domain.GeoLocation {
+	lat := location.Latitude * math.Pi / 180
+	lon := location.Longitude * math.Pi / 180
+	bearing := heading * math.Pi / 180
+	angle := distance / earthRadius
+
+	newLat := math.Asin(math.Sin(lat)*math.Cos(angle) + math.Cos(lat)*math.Sin(angle)*math.Cos(bearing))
+	newLon := lon + math.Atan2(math.Sin(bearing)*math.Sin(angle)*math.Cos(lat),
+		math.Cos(angle)-math.Sin(lat)*math.Sin(newLat))
+
+	// the longitude is normalised to [-180, 180)
+	return domain.GeoLocation{
+		Latitude:  newLat * 180 / math.Pi,
+		Longitude: math.Mod(newLon*180/math.Pi+540, 360) - 180,
+	}
+}
+
+// distance returns haversine distance(in meters) between the locations
+func distance(a, b domain.GeoLocation) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// bearing returns the initial heading(in degrees clockwise from north) from
+// location a to b
+func bearing(a, b domain.GeoLocation) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// randomLocation returns uniformly distributed random location in the area
+func randomLocation(rng *rand.Rand, area SpawnArea) domain.GeoLocation {
+	center := domain.GeoLocation{
+		Latitude:  area.Latitude,
+		Longitude: area.Longitude,
+	}
+	return move(center, rng.Float64()*360, area.Radius*math.Sqrt(rng.Float64()))
+}
+
+// pickArea returns random area chosen proportionally to the area weights
+func pickArea(rng *rand.Rand, areas []SpawnArea) SpawnArea {
+	total := 0.0
+	for _, a := range areas {
+		total += a.Weight
+	}
+
+	v := rng.Float64() * total
+	for _, a := range areas {
+		if v < a.Weight {
+			return a
+		}
+		v -= a.Weight
+	}
+	return areas[len(areas)-1]
+}
@@ -0,0 +1,75 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func TestMove(t *testing.T) {
+	start := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	tests := []struct {
+		name     string
+		location domain.GeoLocation
+		heading  float64
+		distance float64
+		check    func(got domain.GeoLocation) bool
+	}{
+		{
+			name:     "should move north",
+			location: start,
+			heading:  0,
+			distance: 10,
+			check: func(got domain.GeoLocation) bool {
+				return got.Latitude > start.Latitude && math.Abs(got.Longitude-start.Longitude) < 1e-9
+			},
+		},
+		{
+			name:     "should move east",
+			location: start,
+			heading:  90,
+			distance: 1000,
+			check: func(got domain.GeoLocation) bool {
+				return got.Longitude > start.Longitude && math.Abs(got.Latitude-start.Latitude) < 1e-3
+			},
+		},
+		{
+			name:     "should wrap longitude at antimeridian",
+			location: domain.GeoLocation{Latitude: 0, Longitude: 179.9999},
+			heading:  90,
+			distance: 100,
+			check: func(got domain.GeoLocation) bool {
+				return got.Longitude < -179.999
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := move(tt.location, tt.heading, tt.distance)
+			if !tt.check(got) {
+				t.Errorf("move() = %+v", got)
+			}
+			if d := distance(tt.location, got); math.Abs(d-tt.distance) > 0.01 {
+				t.Errorf("moved %v meters, want %v", d, tt.distance)
+			}
+		})
+	}
+}
+
+func TestPickArea(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	areas := []SpawnArea{
+		{Name: "a", Weight: 3},
+		{Name: "b", Weight: 0},
+		{Name: "c", Weight: 1},
+	}
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[pickArea(rng, areas).Name]++
+	}
+	if counts["b"] != 0 || counts["a"] < 2800 || counts["a"] > 3200 {
+		t.Errorf("pickArea() counts = %v, want about 3000 a, 1000 c", counts)
+	}
+}
@@ -0,0 +1,277 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/db"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/testclient"
+)
+
+// invariants checked after the simulation
+const (
+	// InvariantNoDoubleBooking checks that no scooter was granted to a rider
+	// while another trip with it was in progress, and that no user holds more
+	// than one scooter
+	InvariantNoDoubleBooking = "no_double_booking"
+	// InvariantScooterReleased checks that the scooters are available once
+	// their trips ended and the availability matches the current user
+	InvariantScooterReleased = "scooter_released"
+	// InvariantEventStoredOnce checks that each acknowledged trip event is
+	// stored exactly once
+	InvariantEventStoredOnce = "event_stored_once"
+	// InvariantTripStartStop checks that each ended trip has one start and one
+	// stop event of the rider and the events are in order of time
+	InvariantTripStartStop = "trip_start_stop"
+
+	// maxExamples is the number of violations kept as examples per invariant
+	maxExamples = 5
+)
+
+var (
+	// ErrInvariantsNotRecorded is returned if the invariants are checked for
+	// the simulation without invariants enabled
+	ErrInvariantsNotRecorded = errors.New("trips are not recorded, enable invariants in scenario")
+)
+
+// Invariants configures the invariant check after the simulation
+type Invariants struct {
+	Enabled bool `yaml:"enabled"`
+	// SettleTime is waited before the check, so that the events accepted
+	// asynchronously by the service are saved
+	SettleTime time.Duration `yaml:"settle_time"`
+}
+
+// InvariantReport is the result of the invariant check, it passes if there
+// are no unexpected violations. The violations caused by the injected faults
+// or failed requests of the simulation are expected.
+type InvariantReport struct {
+	Passed bool
+	Checks []InvariantCheck
+}
+
+// InvariantCheck is the result of one invariant
+type InvariantCheck struct {
+	Name       string
+	Checked    int
+	Violations int
+	// Expected is the number of violations caused by the simulation itself
+	Expected int
+	Examples []string
+}
+
+// Unexpected returns the number of violations not caused by the simulation
+func (c *InvariantCheck) Unexpected() int {
+	return c.Violations - c.Expected
+}
+
+func (c *InvariantCheck) violation(expected bool, format string, args ...interface{}) {
+	c.Violations++
+	if expected {
+		c.Expected++
+	}
+	if len(c.Examples) < maxExamples {
+		msg := fmt.Sprintf(format, args...)
+		if expected {
+			msg += " (expected)"
+		}
+		c.Examples = append(c.Examples, msg)
+	}
+}
+
+// CheckInvariants checks the recorded trips and trip events against the
+// scooters and trip events saved in the database
+func (s *Simulator) CheckInvariants(ctx context.Context, database db.DB) (*InvariantReport, error) {
+	if s.ledger == nil {
+		return nil, ErrInvariantsNotRecorded
+	}
+	if !sleep(ctx, s.scenario.Invariants.SettleTime) {
+		return nil, ctx.Err()
+	}
+
+	s.ledger.mu.Lock()
+	defer s.ledger.mu.Unlock()
+
+	tripsByScooter := map[string][]*tripRecord{}
+	scooterIDs := []string{}
+	for _, trip := range s.ledger.trips {
+		if _, ok := tripsByScooter[trip.scooterID]; !ok {
+			scooterIDs = append(scooterIDs, trip.scooterID)
+		}
+		tripsByScooter[trip.scooterID] = append(tripsByScooter[trip.scooterID], trip)
+	}
+
+	scooters, err := database.GetAllScooters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get scooters: %w", err)
+	}
+	storedEvents := map[string][]domain.TripEvent{}
+	if len(scooterIDs) > 0 {
+		events, err := database.GetTripEventsByScooterIDs(ctx, scooterIDs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get trip events: %w", err)
+		}
+		for _, e := range events {
+			storedEvents[e.ID] = append(storedEvents[e.ID], e)
+		}
+	}
+
+	report := &InvariantReport{
+		Checks: []InvariantCheck{
+			s.checkNoDoubleBooking(tripsByScooter, scooters),
+			s.checkScooterReleased(tripsByScooter, scooters),
+			s.checkEventStoredOnce(storedEvents),
+			s.checkTripStartStop(storedEvents),
+		},
+	}
+	report.Passed = true
+	for _, c := range report.Checks {
+		if c.Unexpected() > 0 {
+			report.Passed = false
+		}
+	}
+	return report, nil
+}
+
+func (s *Simulator) checkNoDoubleBooking(tripsByScooter map[string][]*tripRecord, scooters []domain.Scooter) InvariantCheck {
+	check := InvariantCheck{Name: InvariantNoDoubleBooking}
+	for scooterID, trips := range tripsByScooter {
+		sort.Slice(trips, func(i, j int) bool { return trips[i].begun.Before(trips[j].begun) })
+		// the scooter is surely held by the rider from the successful begin
+		// trip until the end trip is sent
+		var holder *tripRecord
+		for _, trip := range trips {
+			check.Checked++
+			if holder != nil && (holder.endSent.IsZero() || trip.begun.Before(holder.endSent)) {
+				check.violation(false, "scooter %v granted to user %v at %v while trip of user %v begun at %v was in progress",
+					scooterID, trip.userID, trip.begun.Format(time.RFC3339Nano), holder.userID, holder.begun.Format(time.RFC3339Nano))
+			}
+			if holder == nil || holder.endSent.IsZero() || (!trip.endSent.IsZero() && trip.endSent.After(holder.endSent)) {
+				holder = trip
+			}
+		}
+	}
+
+	holders := map[string][]string{}
+	for _, scooter := range scooters {
+		if userID := currentUser(scooter); userID != "" {
+			holders[userID] = append(holders[userID], scooter.ID)
+		}
+	}
+	for userID, scooterIDs := range holders {
+		check.Checked++
+		if len(scooterIDs) > 1 {
+			check.violation(false, "user %v holds %v scooters %v", userID, len(scooterIDs), scooterIDs)
+		}
+	}
+	return check
+}
+
+func (s *Simulator) checkScooterReleased(tripsByScooter map[string][]*tripRecord, scooters []domain.Scooter) InvariantCheck {
+	check := InvariantCheck{Name: InvariantScooterReleased}
+	for _, scooter := range scooters {
+		trips, ok := tripsByScooter[scooter.ID]
+		if !ok {
+			continue
+		}
+
+		check.Checked++
+		if scooter.IsAvailable != (currentUser(scooter) == "") {
+			check.violation(false, "scooter %v is_available %v does not match current user %q",
+				scooter.ID, scooter.IsAvailable, currentUser(scooter))
+			continue
+		}
+		if scooter.IsAvailable {
+			continue
+		}
+
+		// the scooter stays booked if the simulation could not end the trip
+		ended := true
+		for _, trip := range trips {
+			ended = ended && trip.ended
+		}
+		check.violation(!ended, "scooter %v is still booked by user %v after its trips", scooter.ID, currentUser(scooter))
+	}
+	return check
+}
+
+func (s *Simulator) checkEventStoredOnce(storedEvents map[string][]domain.TripEvent) InvariantCheck {
+	check := InvariantCheck{Name: InvariantEventStoredOnce}
+	for id, e := range s.ledger.events {
+		if !e.acked {
+			continue
+		}
+
+		check.Checked++
+		switch stored := len(storedEvents[id]); {
+		case stored == 0:
+			check.violation(false, "acknowledged %v event %v of scooter %v is not stored", e.original.Type, id, e.original.ScooterID)
+		case stored > 1:
+			check.violation(false, "%v event %v of scooter %v is stored %v times", e.original.Type, id, e.original.ScooterID, stored)
+		}
+	}
+	return check
+}
+
+func (s *Simulator) checkTripStartStop(storedEvents map[string][]domain.TripEvent) InvariantCheck {
+	check := InvariantCheck{Name: InvariantTripStartStop}
+	for _, trip := range s.ledger.trips {
+		if !trip.ended {
+			continue
+		}
+		check.Checked++
+
+		// expected is true if the violation can be caused by the faults
+		// injected in the events or by the events which were not acknowledged
+		expected := false
+		starts, stops, updates := []domain.TripEvent{}, []domain.TripEvent{}, []domain.TripEvent{}
+		for _, id := range trip.eventIDs {
+			e := s.ledger.events[id]
+			if !e.acked || e.faults[testclient.FaultDrop] || e.faults[testclient.FaultMismatchUser] || e.faults[testclient.FaultClockSkew] {
+				expected = true
+			}
+			for _, stored := range storedEvents[id] {
+				if stored.UserID != trip.userID {
+					continue
+				}
+				switch stored.Type {
+				case domain.TripStartEvent:
+					starts = append(starts, stored)
+				case domain.TripStopEvent:
+					stops = append(stops, stored)
+				default:
+					updates = append(updates, stored)
+				}
+			}
+		}
+
+		if len(starts) != 1 || len(stops) != 1 {
+			check.violation(expected, "trip of user %v with scooter %v begun at %v has %v start and %v stop events",
+				trip.userID, trip.scooterID, trip.begun.Format(time.RFC3339Nano), len(starts), len(stops))
+			continue
+		}
+		start, stop := starts[0].CreatedAt, stops[0].CreatedAt
+		inOrder := !stop.Before(start)
+		for _, u := range updates {
+			inOrder = inOrder && !u.CreatedAt.Before(start) && !u.CreatedAt.After(stop)
+		}
+		if !inOrder {
+			check.violation(expected, "events of trip of user %v with scooter %v begun at %v are not in order of time",
+				trip.userID, trip.scooterID, trip.begun.Format(time.RFC3339Nano))
+		}
+	}
+	return check
+}
+
+// currentUser returns the user id of the scooter, the service clears it to
+// empty string when the trip ends
+func currentUser(scooter domain.Scooter) string {
+	if scooter.CurrentUserID == nil {
+		return ""
+	}
+	return *scooter.CurrentUserID
+}
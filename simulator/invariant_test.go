@@ -0,0 +1,207 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/testclient"
+	"github.com/golang/mock/gomock"
+)
+
+func chaosScenario(baseURL string) Scenario {
+	scenario := testScenario(baseURL)
+	scenario.Chaos = testclient.Faults{
+		Duplicate:    0.2,
+		Drop:         0.1,
+		Delay:        0.2,
+		MaxDelay:     50 * time.Millisecond,
+		Reorder:      0.2,
+		ClockSkew:    0.1,
+		MaxClockSkew: time.Minute,
+		MismatchUser: 0.1,
+	}
+	scenario.Invariants = Invariants{Enabled: true}
+	return scenario
+}
+
+func checks(report *InvariantReport) map[string]InvariantCheck {
+	result := map[string]InvariantCheck{}
+	for _, c := range report.Checks {
+		result[c.Name] = c
+	}
+	return result
+}
+
+func TestCheckInvariantsWithChaos(t *testing.T) {
+	sim, err := New(chaosScenario("http://localhost"), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeService(sim.Scooters(), 0)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	sim, err = New(chaosScenario(server.URL), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := sim.Run(context.Background())
+	for _, fault := range []string{testclient.FaultDuplicate, testclient.FaultDrop, testclient.FaultDelay, testclient.FaultReorder} {
+		if report.Faults[fault] == 0 {
+			t.Errorf("Faults = %v, want %v injected", report.Faults, fault)
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	database := mocks.NewMockDB(ctrl)
+	database.EXPECT().GetAllScooters(gomock.Any()).DoAndReturn(func(context.Context) ([]domain.Scooter, error) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		scooters := []domain.Scooter{}
+		for _, s := range fake.scooters {
+			scooters = append(scooters, *s)
+		}
+		return scooters, nil
+	})
+	database.EXPECT().GetTripEventsByScooterIDs(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, []string) ([]domain.TripEvent, error) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return fake.storedEvents, nil
+	})
+
+	report.Invariants, err = sim.CheckInvariants(context.Background(), database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the service stores each event once and grants each scooter to one
+	// rider, the violations are caused by the injected faults only
+	if !report.Invariants.Passed {
+		t.Errorf("Invariants = %+v, want passed", report.Invariants)
+	}
+	got := checks(report.Invariants)
+	if got[InvariantTripStartStop].Checked == 0 || got[InvariantEventStoredOnce].Checked == 0 {
+		t.Errorf("Invariants = %+v, want trips and events checked", report.Invariants)
+	}
+
+	out := bytes.Buffer{}
+	err = report.Print(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"injected fault", testclient.FaultReorder, InvariantNoDoubleBooking, "invariants: PASS"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Print() = %v, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestCheckInvariantsViolations(t *testing.T) {
+	scenario := testScenario("http://localhost")
+	scenario.Invariants = Invariants{Enabled: true}
+	sim, err := New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice, bob := "alice", "bob"
+	begun := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	event := func(id string, userID string, eventType domain.TripEventType, at time.Duration) domain.TripEvent {
+		return domain.TripEvent{ID: id, UserID: userID, ScooterID: "s1", Type: eventType, CreatedAt: begun.Add(at)}
+	}
+
+	l := sim.ledger
+	// bob is granted s1 while the trip of alice is in progress
+	first := l.tripBegun(alice, "s1", begun)
+	second := l.tripBegun(bob, "s1", begun.Add(time.Second))
+	for _, e := range []domain.TripEvent{
+		event("e1", alice, domain.TripStartEvent, 0),
+		event("e2", alice, domain.TripStopEvent, time.Minute),
+	} {
+		l.eventGenerated(first, e)
+		l.observeTripEvent(e, e, nil, nil)
+	}
+	l.endSent(first, begun.Add(time.Minute))
+	l.tripEnded(first)
+	// the stop event of bob was dropped, so its missing record is expected
+	l.eventGenerated(second, event("e3", bob, domain.TripStartEvent, time.Second))
+	l.observeTripEvent(event("e3", bob, domain.TripStartEvent, time.Second), event("e3", bob, domain.TripStartEvent, time.Second), nil, nil)
+	l.eventGenerated(second, event("e4", bob, domain.TripStopEvent, 2*time.Minute))
+	l.observeTripEvent(event("e4", bob, domain.TripStopEvent, 2*time.Minute), event("e4", bob, domain.TripStopEvent, 2*time.Minute),
+		[]string{testclient.FaultDrop}, testclient.ErrEventDropped)
+	l.endSent(second, begun.Add(2*time.Minute))
+	l.tripEnded(second)
+
+	ctrl := gomock.NewController(t)
+	database := mocks.NewMockDB(ctrl)
+	database.EXPECT().GetAllScooters(gomock.Any()).Return([]domain.Scooter{
+		{ID: "s1", IsAvailable: false, CurrentUserID: &bob},
+		{ID: "s2", IsAvailable: true, CurrentUserID: new(string)},
+	}, nil)
+	database.EXPECT().GetTripEventsByScooterIDs(gomock.Any(), []string{"s1"}).Return([]domain.TripEvent{
+		event("e1", alice, domain.TripStartEvent, 0),
+		event("e1", alice, domain.TripStartEvent, 0),
+		event("e2", alice, domain.TripStopEvent, time.Minute),
+	}, nil)
+
+	report, err := sim.CheckInvariants(context.Background(), database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed {
+		t.Errorf("Passed = true, want false")
+	}
+
+	tests := []struct {
+		name       string
+		violations int
+		expected   int
+	}{
+		{name: InvariantNoDoubleBooking, violations: 1, expected: 0},
+		{name: InvariantScooterReleased, violations: 1, expected: 0},
+		// e1 is stored twice and acknowledged e3 is not stored
+		{name: InvariantEventStoredOnce, violations: 2, expected: 0},
+		// alice has two start records, bob has none
+		{name: InvariantTripStartStop, violations: 2, expected: 1},
+	}
+	got := checks(report)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := got[tt.name]
+			if c.Violations != tt.violations || c.Expected != tt.expected || len(c.Examples) != c.Violations {
+				t.Errorf("%v = %+v, want %v violations with %v expected", tt.name, c, tt.violations, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckInvariantsErrors(t *testing.T) {
+	sim, err := New(testScenario("http://localhost"), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = sim.CheckInvariants(context.Background(), nil)
+	if !errors.Is(err, ErrInvariantsNotRecorded) {
+		t.Errorf("CheckInvariants() error = %v, want %v", err, ErrInvariantsNotRecorded)
+	}
+
+	scenario := testScenario("http://localhost")
+	scenario.Invariants = Invariants{Enabled: true}
+	sim, err = New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctrl := gomock.NewController(t)
+	database := mocks.NewMockDB(ctrl)
+	database.EXPECT().GetAllScooters(gomock.Any()).Return(nil, errors.New("database unavailable"))
+	_, err = sim.CheckInvariants(context.Background(), database)
+	if err == nil || !strings.Contains(err.Error(), "database unavailable") {
+		t.Errorf("CheckInvariants() error = %v, want database error", err)
+	}
+}
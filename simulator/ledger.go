@@ -0,0 +1,124 @@
+package simulator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// tripRecord is a trip as seen by the rider
+type tripRecord struct {
+	userID    string
+	scooterID string
+	// begun is the time the begin trip succeeded
+	begun time.Time
+	// endSent is the time the first end trip request was sent
+	endSent time.Time
+	ended   bool
+	// eventIDs are the ids of the trip events generated during the trip
+	eventIDs []string
+}
+
+// eventRecord is a trip event as generated by the scooter and as sent
+type eventRecord struct {
+	original domain.TripEvent
+	sent     domain.TripEvent
+	faults   map[string]bool
+	// acked is true if any send of the event succeeded
+	acked bool
+}
+
+// ledger keeps the trips and trip events of the simulation for the invariant
+// checker, nil ledger records nothing
+type ledger struct {
+	mu     sync.Mutex
+	trips  []*tripRecord
+	events map[string]*eventRecord
+}
+
+func newLedger() *ledger {
+	return &ledger{
+		events: map[string]*eventRecord{},
+	}
+}
+
+// tripBegun records the trip which has begun successfully
+func (l *ledger) tripBegun(userID string, scooterID string, begun time.Time) *tripRecord {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trip := &tripRecord{
+		userID:    userID,
+		scooterID: scooterID,
+		begun:     begun,
+	}
+	l.trips = append(l.trips, trip)
+	return trip
+}
+
+func (l *ledger) endSent(trip *tripRecord, sent time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trip.endSent = sent
+}
+
+func (l *ledger) tripEnded(trip *tripRecord) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trip.ended = true
+}
+
+func (l *ledger) eventGenerated(trip *tripRecord, event domain.TripEvent) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	trip.eventIDs = append(trip.eventIDs, event.ID)
+	if _, ok := l.events[event.ID]; !ok {
+		l.events[event.ID] = &eventRecord{
+			original: event,
+			sent:     event,
+			faults:   map[string]bool{},
+		}
+	}
+}
+
+// observeTripEvent records the sent event, it is used as
+// testclient.TripEventObserver
+func (l *ledger) observeTripEvent(original domain.TripEvent, sent domain.TripEvent, faults []string, err error) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.events[original.ID]
+	if !ok {
+		e = &eventRecord{
+			original: original,
+			faults:   map[string]bool{},
+		}
+		l.events[original.ID] = e
+	}
+	e.sent = sent
+	for _, f := range faults {
+		e.faults[f] = true
+	}
+	if err == nil {
+		e.acked = true
+	}
+}
@@ -0,0 +1,186 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// movement models
+const (
+	// MovementStraight moves in random heading which is kept for whole trip
+	MovementStraight = "straight"
+	// MovementRandomWalk moves in random heading which changes on each
+	// location update, less with higher heading persistence
+	MovementRandomWalk = "random_walk"
+	// MovementReplay moves along random track of the track file
+	MovementReplay = "replay"
+)
+
+// Movement describes how scooter moves during trip
+type Movement struct {
+	Model string `yaml:"model"`
+	// Speed is the distribution of speed in meters per second, it is sampled
+	// on each location update
+	Speed NumberDistribution `yaml:"speed"`
+	// HeadingPersistence is between 0 and 1, 1 keeps the heading and 0 turns
+	// by normally distributed angle with 180 degrees standard deviation on
+	// each location update. Used by random walk.
+	HeadingPersistence float64 `yaml:"heading_persistence"`
+	// GPSNoise is the standard deviation(in meters) of the error of reported
+	// locations, the scooter itself moves without error
+	GPSNoise float64 `yaml:"gps_noise"`
+	// TrackFile is the GPX or GeoJSON file of the tracks used by replay. In
+	// scenario file it is relative to the scenario file.
+	TrackFile string `yaml:"track_file"`
+	// TranslateTrack moves the track to start at the scooter location, by
+	// default the scooter jumps to the start of the track
+	TranslateTrack bool `yaml:"translate_track"`
+}
+
+func (m *Movement) validate(key string, invalid func(key string, format string, args ...interface{})) {
+	switch m.Model {
+	case MovementStraight, MovementRandomWalk:
+	case MovementReplay:
+		if m.TrackFile == "" {
+			invalid(key+".track_file", "is required for %v model", MovementReplay)
+		}
+	default:
+		invalid(key+".model", "%q must be one of %v, %v or %v", m.Model, MovementStraight, MovementRandomWalk, MovementReplay)
+	}
+
+	m.Speed.validate(key+".speed", invalid)
+	if m.Speed.Type != "" && m.Speed.Mean == 0 && m.Speed.Max == 0 {
+		invalid(key+".speed", "must be greater than 0")
+	}
+	if m.HeadingPersistence < 0 || m.HeadingPersistence > 1 {
+		invalid(key+".heading_persistence", "%v is out of range [0, 1]", m.HeadingPersistence)
+	}
+	if m.GPSNoise < 0 {
+		invalid(key+".gps_noise", "must not be negative")
+	}
+}
+
+// mover creates the paths of the trips with the movement model
+type mover struct {
+	movement Movement
+	// tracks are loaded from the track file for replay
+	tracks [][]domain.GeoLocation
+}
+
+func newMover(m Movement) (*mover, error) {
+	mv := &mover{
+		movement: m,
+	}
+	if m.Model != MovementReplay {
+		return mv, nil
+	}
+
+	tracks, err := LoadTracks(m.TrackFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("no track with at least two points in %v", m.TrackFile)
+	}
+	mv.tracks = tracks
+	return mv, nil
+}
+
+// path is the movement of one trip
+type path struct {
+	mover   *mover
+	rng     *rand.Rand
+	current domain.GeoLocation
+	heading float64
+	// track and next point on it for replay
+	track []domain.GeoLocation
+	next  int
+}
+
+// start begins the path of the trip at the location
+func (m *mover) start(rng *rand.Rand, location domain.GeoLocation) *path {
+	p := &path{
+		mover:   m,
+		rng:     rng,
+		current: location,
+		heading: rng.Float64() * 360,
+	}
+	if m.movement.Model != MovementReplay {
+		return p
+	}
+
+	track := m.tracks[rng.Intn(len(m.tracks))]
+	if m.movement.TranslateTrack {
+		dLat := location.Latitude - track[0].Latitude
+		dLon := location.Longitude - track[0].Longitude
+		translated := make([]domain.GeoLocation, 0, len(track))
+		for _, t := range track {
+			lon := t.Longitude + dLon
+			if lon < -180 || lon >= 180 {
+				lon = math.Mod(lon+540, 360) - 180
+			}
+			translated = append(translated, domain.GeoLocation{
+				Latitude:  math.Max(-90, math.Min(90, t.Latitude+dLat)),
+				Longitude: lon,
+			})
+		}
+		track = translated
+	}
+	p.track = track
+	p.current = track[0]
+	p.next = 1
+	return p
+}
+
+// location returns the actual location of the scooter
+func (p *path) location() domain.GeoLocation {
+	return p.current
+}
+
+// reported returns the location reported by the scooter, it has the gps noise
+func (p *path) reported() domain.GeoLocation {
+	noise := p.mover.movement.GPSNoise
+	if noise == 0 {
+		return p.current
+	}
+	// the offset is normally distributed in both directions
+	offset := math.Hypot(p.rng.NormFloat64(), p.rng.NormFloat64()) * noise
+	return move(p.current, p.rng.Float64()*360, offset)
+}
+
+// advance moves the scooter for the elapsed time, returns false once the
+// replayed track is finished
+func (p *path) advance(elapsed time.Duration) bool {
+	movement := p.mover.movement
+	dist := movement.Speed.Sample(p.rng) * elapsed.Seconds()
+
+	switch movement.Model {
+	case MovementReplay:
+		return p.follow(dist)
+	case MovementRandomWalk:
+		turn := p.rng.NormFloat64() * 180 * (1 - movement.HeadingPersistence)
+		p.heading = math.Mod(math.Mod(p.heading+turn, 360)+360, 360)
+	}
+	p.current = move(p.current, p.heading, dist)
+	return true
+}
+
+// follow moves the distance along the track
+func (p *path) follow(dist float64) bool {
+	for p.next < len(p.track) {
+		target := p.track[p.next]
+		remaining := distance(p.current, target)
+		if dist < remaining {
+			p.current = move(p.current, bearing(p.current, target), dist)
+			return true
+		}
+		dist -= remaining
+		p.current = target
+		p.next++
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package simulator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func TestPathAdvance(t *testing.T) {
+	start := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	speed := NumberDistribution{Type: DistributionConstant, Mean: 5}
+
+	tests := []struct {
+		name     string
+		movement Movement
+		// check gets the locations after each of 20 advances by 3s
+		check func(t *testing.T, locations []domain.GeoLocation)
+	}{
+		{
+			name:     "straight should keep heading and speed",
+			movement: Movement{Model: MovementStraight, Speed: speed},
+			check: func(t *testing.T, locations []domain.GeoLocation) {
+				if d := distance(start, locations[len(locations)-1]); math.Abs(d-300) > 0.1 {
+					t.Errorf("moved %v meters, want 300", d)
+				}
+			},
+		},
+		{
+			name:     "random walk with full persistence should not turn",
+			movement: Movement{Model: MovementRandomWalk, Speed: speed, HeadingPersistence: 1},
+			check: func(t *testing.T, locations []domain.GeoLocation) {
+				if d := distance(start, locations[len(locations)-1]); math.Abs(d-300) > 0.1 {
+					t.Errorf("moved %v meters, want 300", d)
+				}
+			},
+		},
+		{
+			name:     "random walk should turn but move with speed on each step",
+			movement: Movement{Model: MovementRandomWalk, Speed: speed, HeadingPersistence: 0.5},
+			check: func(t *testing.T, locations []domain.GeoLocation) {
+				previous := start
+				for _, l := range locations {
+					if d := distance(previous, l); math.Abs(d-15) > 0.01 {
+						t.Errorf("step of %v meters, want 15", d)
+					}
+					previous = l
+				}
+				if d := distance(start, previous); d > 299 {
+					t.Errorf("moved %v meters from start, want turns", d)
+				}
+			},
+		},
+		{
+			name:     "speed should be sampled from distribution",
+			movement: Movement{Model: MovementStraight, Speed: NumberDistribution{Type: DistributionUniform, Min: 2, Max: 4}},
+			check: func(t *testing.T, locations []domain.GeoLocation) {
+				previous := start
+				for _, l := range locations {
+					if d := distance(previous, l); d < 6-0.01 || d > 12+0.01 {
+						t.Errorf("step of %v meters, want between 6 and 12", d)
+					}
+					previous = l
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMover(tt.movement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := m.start(rand.New(rand.NewSource(1)), start)
+			locations := []domain.GeoLocation{}
+			for i := 0; i < 20; i++ {
+				if !p.advance(3 * time.Second) {
+					t.Fatalf("advance() = false, want true")
+				}
+				locations = append(locations, p.location())
+			}
+			tt.check(t, locations)
+		})
+	}
+}
+
+func TestPathReported(t *testing.T) {
+	start := domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}
+	m, err := newMover(Movement{Model: MovementStraight, Speed: NumberDistribution{Type: DistributionConstant, Mean: 5}, GPSNoise: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := m.start(rand.New(rand.NewSource(1)), start)
+
+	sum := 0.0
+	for i := 0; i < 1000; i++ {
+		sum += distance(start, p.reported())
+	}
+	// the error distance of 2d normal noise has mean sigma*sqrt(pi/2)
+	mean := sum / 1000
+	if want := 4 * math.Sqrt(math.Pi/2); math.Abs(mean-want) > 0.5 {
+		t.Errorf("mean gps error = %v meters, want about %v", mean, want)
+	}
+	if p.location() != start {
+		t.Errorf("location() = %v, want actual location %v without noise", p.location(), start)
+	}
+}
+
+func TestPathReplay(t *testing.T) {
+	tracks, err := LoadTracks("testdata/ride.gpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scooter := domain.GeoLocation{Latitude: 52.53, Longitude: 13.42}
+
+	tests := []struct {
+		name      string
+		translate bool
+		wantStart func(track []domain.GeoLocation) domain.GeoLocation
+	}{
+		{
+			name: "should jump to track start",
+			wantStart: func(track []domain.GeoLocation) domain.GeoLocation {
+				return track[0]
+			},
+		},
+		{
+			name:      "should translate track to scooter location",
+			translate: true,
+			wantStart: func(track []domain.GeoLocation) domain.GeoLocation {
+				return scooter
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMover(Movement{
+				Model:          MovementReplay,
+				Speed:          NumberDistribution{Type: DistributionConstant, Mean: 5},
+				TrackFile:      "testdata/ride.gpx",
+				TranslateTrack: tt.translate,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for seed := int64(1); seed <= 10; seed++ {
+				p := m.start(rand.New(rand.NewSource(seed)), scooter)
+				if len(p.track) != len(tracks[0]) && len(p.track) != len(tracks[1]) {
+					t.Fatalf("track = %v, want one of %v", p.track, tracks)
+				}
+				if want := tt.wantStart(p.track); p.location() != want || p.track[0] != want {
+					t.Fatalf("start location = %v, want %v", p.location(), want)
+				}
+				length := 0.0
+				for i := 1; i < len(p.track); i++ {
+					length += distance(p.track[i-1], p.track[i])
+				}
+
+				travelled := 0.0
+				steps := 0
+				for previous := p.location(); p.advance(time.Second); previous = p.location() {
+					travelled += distance(previous, p.location())
+					steps++
+				}
+				if end := p.track[len(p.track)-1]; p.location() != end {
+					t.Errorf("end location = %v, want track end %v", p.location(), end)
+				}
+				if wantSteps := int(length / 5); steps != wantSteps {
+					t.Errorf("advanced %v times before the end, want %v", steps, wantSteps)
+				}
+				if math.Abs(travelled-float64(steps)*5) > 0.5 {
+					t.Errorf("travelled %v meters in %v steps, want 5 meters per step", travelled, steps)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMover(t *testing.T) {
+	_, err := newMover(Movement{Model: MovementReplay, TrackFile: "testdata/missing.gpx"})
+	if err == nil {
+		t.Errorf("newMover() error = nil, want error for missing track file")
+	}
+}
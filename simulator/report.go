@@ -0,0 +1,308 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Report summarises the simulation
+type Report struct {
+	Duration time.Duration
+	Riders   int
+	Requests int
+	// Throughput is the number of requests per second
+	Throughput     float64
+	TripsStarted   int
+	TripsCompleted int
+	// NoScooterFound is the number of searches which found no available scooter
+	NoScooterFound int
+	Endpoints      []EndpointReport
+	Errors         []ErrorCount
+	// Faults is the number of trip events by injected fault
+	Faults map[string]int
+	// Invariants is the result of the invariant check, nil if not checked
+	Invariants *InvariantReport
+	// Contention is the result of the contention mode, nil in ride mode
+	Contention *ContentionReport
+}
+
+// EndpointReport summarises the requests of an endpoint, the latencies
+// include the failed requests
+type EndpointReport struct {
+	Endpoint   string
+	Requests   int
+	Errors     int
+	Throughput float64
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// ErrorCount is the number of failed requests of an endpoint by reason
+type ErrorCount struct {
+	Endpoint string
+	Reason   string
+	Count    int
+}
+
+// Print writes the report in human readable form
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "duration: %v, riders: %v, requests: %v, throughput: %.1f req/s\n",
+		r.Duration.Round(time.Millisecond), r.Riders, r.Requests, r.Throughput)
+	fmt.Fprintf(tw, "trips started: %v, trips completed: %v, searches without scooter: %v\n\n",
+		r.TripsStarted, r.TripsCompleted, r.NoScooterFound)
+
+	fmt.Fprintln(tw, "endpoint\trequests\terrors\treq/s\tp50\tp90\tp99\tmax")
+	for _, e := range r.Endpoints {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%.1f\t%v\t%v\t%v\t%v\n", e.Endpoint, e.Requests, e.Errors, e.Throughput,
+			roundLatency(e.P50), roundLatency(e.P90), roundLatency(e.P99), roundLatency(e.Max))
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(tw, "\nendpoint\terror\tcount")
+		for _, e := range r.Errors {
+			fmt.Fprintf(tw, "%v\t%v\t%v\n", e.Endpoint, e.Reason, e.Count)
+		}
+	}
+
+	if len(r.Faults) > 0 {
+		faults := make([]string, 0, len(r.Faults))
+		for f := range r.Faults {
+			faults = append(faults, f)
+		}
+		sort.Strings(faults)
+		fmt.Fprintln(tw, "\ninjected fault\tevents")
+		for _, f := range faults {
+			fmt.Fprintf(tw, "%v\t%v\n", f, r.Faults[f])
+		}
+	}
+
+	if r.Invariants != nil {
+		fmt.Fprintln(tw, "\ninvariant\tchecked\tviolations\texpected\tresult")
+		for _, c := range r.Invariants.Checks {
+			result := "pass"
+			if c.Unexpected() > 0 {
+				result = "FAIL"
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", c.Name, c.Checked, c.Violations, c.Expected, result)
+		}
+		for _, c := range r.Invariants.Checks {
+			for _, example := range c.Examples {
+				fmt.Fprintf(tw, "  %v: %v\n", c.Name, example)
+			}
+		}
+		result := "PASS"
+		if !r.Invariants.Passed {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "invariants: %v\n", result)
+	}
+
+	if r.Contention != nil {
+		fmt.Fprintln(tw, "\nround\tscooter\tcontenders\tgranted\trejected\terrors\tholder")
+		for _, c := range r.Contention.Rounds {
+			holder := c.Holder
+			if holder == "" {
+				holder = "-"
+			}
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", c.Round, c.ScooterID, c.Contenders, len(c.Granted), c.Rejected, c.Errors, holder)
+		}
+		for _, v := range r.Contention.Violations {
+			fmt.Fprintf(tw, "  %v\n", v)
+		}
+		result := "PASS"
+		if !r.Contention.Passed {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "contention: %v\n", result)
+	}
+	return tw.Flush()
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
+
+type errorKey struct {
+	endpoint string
+	reason   string
+}
+
+// recorder collects the request latencies and errors of the riders
+type recorder struct {
+	mu             sync.Mutex
+	start          time.Time
+	end            time.Time
+	latencies      map[string][]time.Duration
+	errors         map[string]int
+	reasons        map[errorKey]int
+	tripsStarted   int
+	tripsCompleted int
+	noScooterFound int
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		latencies: map[string][]time.Duration{},
+		errors:    map[string]int{},
+		reasons:   map[errorKey]int{},
+	}
+}
+
+func (r *recorder) begin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start = time.Now()
+}
+
+// stop marks the end of the simulation, the requests which fail without
+// response after it are cancelled by the simulation and not recorded
+func (r *recorder) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.end.IsZero() {
+		r.end = time.Now()
+	}
+}
+
+// observe records the request, it is used as client.Observer
+func (r *recorder) observe(endpoint string, latency time.Duration, statusCode int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil && statusCode == 0 && !r.end.IsZero() {
+		return
+	}
+
+	r.latencies[endpoint] = append(r.latencies[endpoint], latency)
+	if err != nil {
+		r.errors[endpoint]++
+		r.reasons[errorKey{endpoint: endpoint, reason: errorReason(statusCode, err)}]++
+	}
+}
+
+func (r *recorder) tripStarted() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tripsStarted++
+}
+
+func (r *recorder) tripCompleted() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tripsCompleted++
+}
+
+func (r *recorder) scooterNotFound() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.noScooterFound++
+}
+
+// errorReason groups the errors by status code or kind of transport error
+func errorReason(statusCode int, err error) string {
+	if statusCode != 0 {
+		return fmt.Sprintf("status %v", statusCode)
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, syscall.ECONNRESET):
+		return "connection reset"
+	default:
+		return "transport error"
+	}
+}
+
+// report builds the report of the recorded requests
+func (r *recorder) report(riders int) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	end := r.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	report := &Report{
+		Duration:       end.Sub(r.start),
+		Riders:         riders,
+		TripsStarted:   r.tripsStarted,
+		TripsCompleted: r.tripsCompleted,
+		NoScooterFound: r.noScooterFound,
+		Endpoints:      []EndpointReport{},
+		Errors:         []ErrorCount{},
+	}
+	seconds := report.Duration.Seconds()
+	throughput := func(requests int) float64 {
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(requests) / seconds
+	}
+
+	for endpoint, latencies := range r.latencies {
+		sorted := make([]time.Duration, len(latencies))
+		copy(sorted, latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		report.Requests += len(sorted)
+		report.Endpoints = append(report.Endpoints, EndpointReport{
+			Endpoint:   endpoint,
+			Requests:   len(sorted),
+			Errors:     r.errors[endpoint],
+			Throughput: throughput(len(sorted)),
+			P50:        percentile(sorted, 50),
+			P90:        percentile(sorted, 90),
+			P99:        percentile(sorted, 99),
+			Max:        sorted[len(sorted)-1],
+		})
+	}
+	report.Throughput = throughput(report.Requests)
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		return report.Endpoints[i].Endpoint < report.Endpoints[j].Endpoint
+	})
+
+	for k, count := range r.reasons {
+		report.Errors = append(report.Errors, ErrorCount{
+			Endpoint: k.endpoint,
+			Reason:   k.reason,
+			Count:    count,
+		})
+	}
+	sort.Slice(report.Errors, func(i, j int) bool {
+		a, b := report.Errors[i], report.Errors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		return a.Reason < b.Reason
+	})
+
+	return report
+}
+
+// percentile returns the nearest rank percentile of the sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,71 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{}
+	for i := 1; i <= 200; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      int
+		want   time.Duration
+	}{
+		{name: "empty", p: 50, want: 0},
+		{name: "single", sorted: sorted[:1], p: 99, want: time.Millisecond},
+		{name: "p50", sorted: sorted, p: 50, want: 100 * time.Millisecond},
+		{name: "p99", sorted: sorted, p: 99, want: 198 * time.Millisecond},
+		{name: "p100", sorted: sorted, p: 100, want: 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := newRecorder()
+	r.begin()
+	r.observe("begin_trip", 10*time.Millisecond, 200, nil)
+	r.observe("begin_trip", 30*time.Millisecond, 403, errors.New("forbidden"))
+	r.observe("begin_trip", 20*time.Millisecond, 403, errors.New("forbidden"))
+	r.observe("end_trip", 5*time.Millisecond, 0, errors.New("EOF"))
+	r.tripStarted()
+	r.stop()
+	// cancelled request after stop is not recorded
+	r.observe("end_trip", time.Millisecond, 0, context.Canceled)
+	r.observe("end_trip", 7*time.Millisecond, 200, nil)
+
+	report := r.report(2)
+	if report.Requests != 5 || report.Riders != 2 || report.TripsStarted != 1 {
+		t.Errorf("report() = %+v", report)
+	}
+	wantEndpoints := []EndpointReport{
+		{Endpoint: "begin_trip", Requests: 3, Errors: 2, P50: 20 * time.Millisecond, P90: 30 * time.Millisecond, P99: 30 * time.Millisecond, Max: 30 * time.Millisecond},
+		{Endpoint: "end_trip", Requests: 2, Errors: 1, P50: 5 * time.Millisecond, P90: 7 * time.Millisecond, P99: 7 * time.Millisecond, Max: 7 * time.Millisecond},
+	}
+	for i := range report.Endpoints {
+		report.Endpoints[i].Throughput = 0
+	}
+	if !reflect.DeepEqual(report.Endpoints, wantEndpoints) {
+		t.Errorf("Endpoints = %+v, want %+v", report.Endpoints, wantEndpoints)
+	}
+	wantErrors := []ErrorCount{
+		{Endpoint: "begin_trip", Reason: "status 403", Count: 2},
+		{Endpoint: "end_trip", Reason: "transport error", Count: 1},
+	}
+	if !reflect.DeepEqual(report.Errors, wantErrors) {
+		t.Errorf("Errors = %+v, want %+v", report.Errors, wantErrors)
+	}
+}
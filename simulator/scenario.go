@@ -0,0 +1,194 @@
+package simulator
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/testclient"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	//go:embed default_scenario.yaml
+	defaultScenario []byte
+)
+
+// Scenario describes the simulated riders and scooters and how they travel
+type Scenario struct {
+	// Mode is ride or contention
+	Mode string `yaml:"mode"`
+	// BaseURL is the url of the service e.g. http://localhost:8080
+	BaseURL string `yaml:"base_url"`
+	// Duration is the time after which the simulation is stopped, 0 runs it
+	// until it is stopped
+	Duration time.Duration `yaml:"duration"`
+	// Seed makes the generated riders, scooters and their trips reproducible
+	Seed int64 `yaml:"seed"`
+	// Riders is the number of concurrent riders
+	Riders int `yaml:"riders"`
+	// Scooters is the number of scooters generated in the spawn areas, 0 uses
+	// the scooters already saved in the service
+	Scooters int `yaml:"scooters"`
+	// RampUp is the time over which the riders are started
+	RampUp time.Duration `yaml:"ramp_up"`
+	// RequestTimeout is the timeout of each request
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// SearchRadius is the radius(in meters) in which rider looks for scooter
+	SearchRadius int `yaml:"search_radius"`
+	// LocationUpdateInterval is the interval of location update events sent
+	// by scooter during trip
+	LocationUpdateInterval time.Duration `yaml:"location_update_interval"`
+	// SpawnAreas are the areas in which riders and scooters are placed
+	SpawnAreas []SpawnArea `yaml:"spawn_areas"`
+	// TripDuration is the distribution of the trip durations
+	TripDuration Distribution `yaml:"trip_duration"`
+	// ThinkTime is the distribution of the time rider waits between trips
+	ThinkTime Distribution `yaml:"think_time"`
+	// Movement is the way scooter moves during trip
+	Movement Movement `yaml:"movement"`
+	// Chaos are the faults injected in the trip events sent by the scooters
+	Chaos testclient.Faults `yaml:"chaos"`
+	// Invariants configures the invariant check against the database after
+	// the simulation
+	Invariants Invariants `yaml:"invariants"`
+	// Contention configures the contention mode
+	Contention Contention `yaml:"contention"`
+}
+
+// SpawnArea is a circular area, the riders and scooters are placed in it
+// proportionally to its weight
+type SpawnArea struct {
+	Name      string  `yaml:"name"`
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+	// Radius is the radius of the area in meters
+	Radius float64 `yaml:"radius"`
+	Weight float64 `yaml:"weight"`
+}
+
+// DefaultScenario returns the scenario used when no scenario file is given
+func DefaultScenario() (Scenario, error) {
+	s := Scenario{}
+	err := decodeScenario(bytes.NewReader(defaultScenario), &s)
+	return s, err
+}
+
+// LoadScenario loads the scenario file, the settings missing in the file are
+// taken from the default scenario
+func LoadScenario(file string) (Scenario, error) {
+	s, err := DefaultScenario()
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defer f.Close()
+
+	err = decodeScenario(f, &s)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("invalid scenario file %v: %w", file, err)
+	}
+	// the track file is relative to the scenario file
+	if s.Movement.TrackFile != "" && !filepath.IsAbs(s.Movement.TrackFile) {
+		s.Movement.TrackFile = filepath.Join(filepath.Dir(file), s.Movement.TrackFile)
+	}
+	return s, nil
+}
+
+func decodeScenario(r io.Reader, s *Scenario) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	err := decoder.Decode(s)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// Validate returns error listing all the invalid settings of the scenario
+func (s *Scenario) Validate() error {
+	problems := []string{}
+	invalid := func(key string, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%v: %v", key, fmt.Sprintf(format, args...)))
+	}
+
+	if s.Mode != ModeRide && s.Mode != ModeContention {
+		invalid("mode", "%q is not one of %v, %v", s.Mode, ModeRide, ModeContention)
+	}
+	if u, err := url.Parse(s.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		invalid("base_url", "%q is not a valid url", s.BaseURL)
+	}
+	if s.Duration < 0 {
+		invalid("duration", "must not be negative")
+	}
+	if s.Riders <= 0 {
+		invalid("riders", "must be greater than 0")
+	}
+	if s.Scooters < 0 {
+		invalid("scooters", "must not be negative")
+	}
+	if s.RampUp < 0 {
+		invalid("ramp_up", "must not be negative")
+	}
+	if s.RequestTimeout <= 0 {
+		invalid("request_timeout", "must be greater than 0")
+	}
+	if s.SearchRadius <= 0 {
+		invalid("search_radius", "must be greater than 0")
+	}
+	if s.LocationUpdateInterval <= 0 {
+		invalid("location_update_interval", "must be greater than 0")
+	}
+
+	if len(s.SpawnAreas) == 0 {
+		invalid("spawn_areas", "at least one area is required")
+	}
+	totalWeight := 0.0
+	for i, a := range s.SpawnAreas {
+		key := fmt.Sprintf("spawn_areas[%v]", i)
+		if a.Latitude < -90 || a.Latitude > 90 {
+			invalid(key+".latitude", "%v is out of range [-90, 90]", a.Latitude)
+		}
+		if a.Longitude < -180 || a.Longitude > 180 {
+			invalid(key+".longitude", "%v is out of range [-180, 180]", a.Longitude)
+		}
+		if a.Radius <= 0 {
+			invalid(key+".radius", "must be greater than 0")
+		}
+		if a.Weight < 0 {
+			invalid(key+".weight", "must not be negative")
+		}
+		totalWeight += a.Weight
+	}
+	if len(s.SpawnAreas) > 0 && totalWeight == 0 {
+		invalid("spawn_areas", "at least one area must have weight greater than 0")
+	}
+
+	s.TripDuration.validate("trip_duration", invalid)
+	s.ThinkTime.validate("think_time", invalid)
+	s.Movement.validate("movement", invalid)
+	if err := s.Chaos.Validate(); err != nil {
+		invalid("chaos", "%v", err)
+	}
+	if s.Invariants.SettleTime < 0 {
+		invalid("invariants.settle_time", "must not be negative")
+	}
+	if s.Mode == ModeContention {
+		s.Contention.validate("contention", invalid)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid scenario:\n  %v", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package simulator
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/testclient"
+)
+
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "scenario.yaml")
+	err := os.WriteFile(file, []byte(`
+base_url: http://localhost:9090
+riders: 5000
+spawn_areas:
+  - name: kreuzberg
+    latitude: 52.4986
+    longitude: 13.4030
+    radius: 1000
+    weight: 1
+think_time:
+  type: exponential
+  mean: 30s
+movement:
+  model: replay
+  track_file: tracks/ride.gpx
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownKeyFile := filepath.Join(dir, "unknown.yaml")
+	err = os.WriteFile(unknownKeyFile, []byte("rider: 10\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaultScenario, err := DefaultScenario()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadScenario(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.BaseURL != "http://localhost:9090" || s.Riders != 5000 || len(s.SpawnAreas) != 1 ||
+		s.ThinkTime != (Distribution{Type: DistributionExponential, Mean: 30 * time.Second}) {
+		t.Errorf("LoadScenario() = %+v, want settings of file", s)
+	}
+	if s.Movement.TrackFile != filepath.Join(dir, "tracks", "ride.gpx") {
+		t.Errorf("TrackFile = %v, want it relative to scenario file", s.Movement.TrackFile)
+	}
+	if s.Scooters != defaultScenario.Scooters || s.TripDuration != defaultScenario.TripDuration ||
+		s.Movement.Speed != defaultScenario.Movement.Speed {
+		t.Errorf("LoadScenario() = %+v, want default settings missing in file", s)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	_, err = LoadScenario(unknownKeyFile)
+	if err == nil || !strings.Contains(err.Error(), "rider") {
+		t.Errorf("LoadScenario() error = %v, want unknown key error", err)
+	}
+	_, err = LoadScenario(filepath.Join(dir, "missing.yaml"))
+	if err == nil {
+		t.Errorf("LoadScenario() error = nil, want error for missing file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Scenario{
+		BaseURL:      "localhost",
+		Riders:       0,
+		SpawnAreas:   []SpawnArea{{Latitude: 91, Longitude: 13, Radius: 0}},
+		TripDuration: Distribution{Type: "poisson"},
+		ThinkTime:    Distribution{Type: DistributionUniform, Min: time.Second},
+		Movement:     Movement{Model: "teleport", GPSNoise: -1},
+		Chaos:        testclient.Faults{Drop: 1.5},
+		Invariants:   Invariants{SettleTime: -time.Second},
+	}
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want error")
+	}
+	for _, want := range []string{
+		"mode", "base_url", "riders", "request_timeout", "search_radius", "location_update_interval",
+		"spawn_areas[0].latitude", "spawn_areas[0].radius", "weight greater than 0",
+		"trip_duration.type", "think_time.max", "movement.model", "movement.speed.type", "movement.gps_noise",
+		"chaos: drop rate", "invariants.settle_time",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name         string
+		distribution Distribution
+		wantMin      time.Duration
+		wantMax      time.Duration
+	}{
+		{
+			name:         "constant",
+			distribution: Distribution{Type: DistributionConstant, Mean: time.Second},
+			wantMin:      time.Second,
+			wantMax:      time.Second,
+		},
+		{
+			name:         "uniform",
+			distribution: Distribution{Type: DistributionUniform, Min: time.Second, Max: 2 * time.Second},
+			wantMin:      time.Second,
+			wantMax:      2 * time.Second,
+		},
+		{
+			name:         "normal limited to min and max",
+			distribution: Distribution{Type: DistributionNormal, Mean: time.Minute, StdDev: time.Minute, Min: 10 * time.Second, Max: 2 * time.Minute},
+			wantMin:      10 * time.Second,
+			wantMax:      2 * time.Minute,
+		},
+		{
+			name:         "exponential",
+			distribution: Distribution{Type: DistributionExponential, Mean: time.Second, Max: 5 * time.Second},
+			wantMin:      0,
+			wantMax:      5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			var sum time.Duration
+			for i := 0; i < 1000; i++ {
+				got := tt.distribution.Sample(rng)
+				if got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("Sample() = %v, want in [%v, %v]", got, tt.wantMin, tt.wantMax)
+				}
+				sum += got
+			}
+			if tt.distribution.Mean > 0 {
+				mean := sum / 1000
+				if mean < tt.distribution.Mean*8/10 || mean > tt.distribution.Mean*12/10 {
+					t.Errorf("mean of samples = %v, want about %v", mean, tt.distribution.Mean)
+				}
+			}
+		})
+	}
+}
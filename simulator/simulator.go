@@ -0,0 +1,342 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/client"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/testclient"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/tracing"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// scooterChoices is the number of nearest scooters from which rider picks
+	// one, so that riders at same place do not always compete for one scooter
+	scooterChoices = 3
+	// endTripAttempts is the number of attempts to end the trip, the trip is
+	// ended even if the simulation is stopped so that the scooter is available
+	endTripAttempts = 3
+	endTripBackoff  = time.Second
+)
+
+// Simulator runs the riders of the scenario against the service
+type Simulator struct {
+	scenario Scenario
+	mover    *mover
+	client   *testclient.Client
+	recorder *recorder
+	// ledger records the trips for the invariant check, nil if invariants are
+	// not enabled
+	ledger   *ledger
+	riders   []domain.User
+	scooters []domain.Scooter
+	logger   *logrus.Logger
+}
+
+// Option configures the simulator
+type Option func(*Simulator)
+
+// WithLogger sets the logger, default is logrus standard logger
+func WithLogger(l *logrus.Logger) Option {
+	return func(s *Simulator) {
+		s.logger = l
+	}
+}
+
+// New creates simulator for the scenario, the riders and scooters are
+// generated from the scenario seed
+func New(scenario Scenario, apiKey string, opts ...Option) (*Simulator, error) {
+	err := scenario.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	mv, err := newMover(scenario.Movement)
+	if err != nil {
+		return nil, err
+	}
+
+	var l *ledger
+	if scenario.Invariants.Enabled {
+		l = newLedger()
+	}
+	rec := newRecorder()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// the simulated users share the client, so keep enough idle connections
+	// to avoid opening new connection for most of the requests
+	transport.MaxIdleConns = 0
+	transport.MaxIdleConnsPerHost = 1024
+	// the failed requests are part of the report and the riders carry on
+	// with the next request, so they are not retried
+	apiClient, err := client.NewClient(scenario.BaseURL, apiKey,
+		client.WithObserver(rec.observe),
+		client.WithTimeout(scenario.RequestTimeout),
+		client.WithTransport(tracing.NewTransport(transport)),
+		client.WithRetry(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	clientOpts := []testclient.Option{
+		testclient.WithTripEventObserver(l.observeTripEvent),
+	}
+	if scenario.Chaos.Enabled() {
+		clientOpts = append(clientOpts, testclient.WithFaults(scenario.Chaos, scenario.Seed))
+	}
+
+	s := &Simulator{
+		scenario: scenario,
+		mover:    mv,
+		recorder: rec,
+		ledger:   l,
+		client:   testclient.NewClient(apiClient, clientOpts...),
+		logger:   logrus.StandardLogger(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	rng := rand.New(rand.NewSource(scenario.Seed))
+	s.riders = make([]domain.User, 0, scenario.Riders)
+	for i := 0; i < scenario.Riders; i++ {
+		s.riders = append(s.riders, domain.User{
+			ID:   newID(rng),
+			Name: fmt.Sprintf("Simulated Rider %v", i+1),
+		})
+	}
+	s.scooters = make([]domain.Scooter, 0, scenario.Scooters)
+	for i := 0; i < scenario.Scooters; i++ {
+		s.scooters = append(s.scooters, domain.Scooter{
+			ID:          newID(rng),
+			Name:        fmt.Sprintf("Simulated Scooter %v", i+1),
+			Location:    randomLocation(rng, pickArea(rng, scenario.SpawnAreas)),
+			IsAvailable: true,
+		})
+	}
+	return s, nil
+}
+
+// Users returns the simulated riders, they have to be saved in the service
+// before the simulation is run
+func (s *Simulator) Users() []domain.User {
+	return s.riders
+}
+
+// Scooters returns the scooters generated in the spawn areas, they have to be
+// saved in the service before the simulation is run
+func (s *Simulator) Scooters() []domain.Scooter {
+	return s.scooters
+}
+
+// Run runs the riders until the context is done or the scenario duration is
+// over and returns the report. The trips in progress are ended before it
+// returns.
+func (s *Simulator) Run(ctx context.Context) *Report {
+	if s.scenario.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.scenario.Duration)
+		defer cancel()
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"base_url": s.scenario.BaseURL,
+		"riders":   len(s.riders),
+		"scooters": len(s.scooters),
+	}).Info("starting simulation")
+
+	s.recorder.begin()
+	go func() {
+		<-ctx.Done()
+		s.recorder.stop()
+	}()
+
+	wg := sync.WaitGroup{}
+	for i, user := range s.riders {
+		delay := time.Duration(0)
+		if len(s.riders) > 1 {
+			delay = s.scenario.RampUp * time.Duration(i) / time.Duration(len(s.riders)-1)
+		}
+		r := &rider{
+			sim:    s,
+			userID: user.ID,
+			rng:    rand.New(rand.NewSource(s.scenario.Seed + int64(i) + 1)),
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !sleep(ctx, delay) {
+				return
+			}
+			r.run(ctx)
+		}()
+	}
+	wg.Wait()
+	s.recorder.stop()
+	// the events held back or delayed by fault injection are sent before the
+	// report, so that they are included in it
+	s.client.Flush(context.Background())
+
+	s.logger.Info("simulation stopped")
+	report := s.recorder.report(len(s.riders))
+	report.Faults = s.client.InjectedFaults()
+	return report
+}
+
+// rider looks for nearby scooter, travels with it and rests before next trip
+type rider struct {
+	sim      *Simulator
+	userID   string
+	rng      *rand.Rand
+	location domain.GeoLocation
+}
+
+func (r *rider) run(ctx context.Context) {
+	r.respawn()
+	for ctx.Err() == nil {
+		r.trip(ctx)
+		sleep(ctx, r.sim.scenario.ThinkTime.Sample(r.rng))
+	}
+}
+
+// respawn places the rider at random location in one of the spawn areas
+func (r *rider) respawn() {
+	r.location = randomLocation(r.rng, pickArea(r.rng, r.sim.scenario.SpawnAreas))
+}
+
+// trip takes one trip, the failed requests are recorded by the observer and
+// the rider tries again with next trip
+func (r *rider) trip(ctx context.Context) {
+	apiClient := r.sim.client
+	scenario := r.sim.scenario
+
+	scooters, err := apiClient.GetAvailableScooters(ctx, clientLocation(r.location), scenario.SearchRadius)
+	if err != nil {
+		return
+	}
+	if len(scooters) == 0 {
+		r.sim.recorder.scooterNotFound()
+		r.respawn()
+		return
+	}
+	scooter := scooters[r.rng.Intn(min(len(scooters), scooterChoices))]
+
+	// begin trip is not cancelled when the simulation is stopped, otherwise
+	// the scooter taken by cancelled request would not be released
+	beginCtx, cancelBegin := context.WithTimeout(context.Background(), scenario.RequestTimeout)
+	defer cancelBegin()
+	_, err = apiClient.BeginTrip(beginCtx, r.userID, scooter.ID)
+	if err != nil {
+		return
+	}
+	r.sim.recorder.tripStarted()
+	trip := r.sim.ledger.tripBegun(r.userID, scooter.ID, time.Now())
+	p := r.sim.mover.start(r.rng, domainLocation(scooter.Location))
+	r.saveTripEvent(ctx, trip, scooter.ID, domain.TripStartEvent, p.reported())
+
+	// the trip ends after the trip duration or when the replayed track is
+	// finished
+	tripEnd := time.Now().Add(scenario.TripDuration.Sample(r.rng))
+	for moving := true; moving && ctx.Err() == nil; {
+		interval := scenario.LocationUpdateInterval
+		if remaining := time.Until(tripEnd); remaining < interval {
+			interval = remaining
+		}
+		if interval <= 0 || !sleep(ctx, interval) {
+			break
+		}
+		moving = p.advance(interval)
+		r.saveTripEvent(ctx, trip, scooter.ID, domain.TripLocationUpdateEvent, p.reported())
+	}
+	r.location = p.location()
+	endLocation := p.reported()
+
+	// the trip is ended even if the simulation is stopped meanwhile, so that
+	// the scooter does not stay unavailable
+	endCtx, cancelEnd := context.WithTimeout(context.Background(), (scenario.RequestTimeout+endTripBackoff)*endTripAttempts)
+	defer cancelEnd()
+	r.sim.ledger.endSent(trip, time.Now())
+	for attempt := 1; ; attempt++ {
+		_, err = apiClient.EndTrip(endCtx, r.userID, scooter.ID, clientLocation(endLocation))
+		if err == nil || attempt == endTripAttempts || !sleep(endCtx, endTripBackoff) {
+			break
+		}
+	}
+	if err != nil {
+		r.sim.logger.WithError(err).WithField("scooter_id", scooter.ID).Warn("unable to end trip")
+		return
+	}
+	r.sim.recorder.tripCompleted()
+	r.sim.ledger.tripEnded(trip)
+	r.saveTripEvent(endCtx, trip, scooter.ID, domain.TripStopEvent, endLocation)
+}
+
+func (r *rider) saveTripEvent(ctx context.Context, trip *tripRecord, scooterID string, eventType domain.TripEventType, location domain.GeoLocation) {
+	// the event ids are random, not from the seed, so that the events of
+	// repeated simulations are not taken as duplicates
+	event := domain.TripEvent{
+		ID:        uuid.NewString(),
+		UserID:    r.userID,
+		ScooterID: scooterID,
+		Location:  location,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+	}
+	r.sim.ledger.eventGenerated(trip, event)
+	// the error is recorded by the observer, the scooter keeps sending the
+	// next events
+	_ = r.sim.client.SaveTripEvent(ctx, event)
+}
+
+// newID returns random uuid v4 read from rng, so that the ids are
+// reproducible with same seed
+func newID(rng *rand.Rand) string {
+	id, err := uuid.NewRandomFromReader(rng)
+	if err != nil {
+		// reading from math/rand never fails
+		panic(err)
+	}
+	return id.String()
+}
+
+// sleep waits for the duration, returns false if the context is done before
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+func clientLocation(l domain.GeoLocation) client.GeoLocation {
+	return client.GeoLocation{
+		Latitude:  l.Latitude,
+		Longitude: l.Longitude,
+	}
+}
+
+func domainLocation(l client.GeoLocation) domain.GeoLocation {
+	return domain.GeoLocation{
+		Latitude:  l.Latitude,
+		Longitude: l.Longitude,
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,303 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/client"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// fakeService serves the user and scooter apis with scooters kept in memory,
+// the first failBeginTrips begin trip requests fail with 500. The trip events
+// are stored once by id. With racyBegin the availability of the scooter is
+// checked and updated separately in begin trip.
+type fakeService struct {
+	mu             sync.Mutex
+	scooters       map[string]*domain.Scooter
+	failBeginTrips int
+	racyBegin      bool
+	tripEvents     map[domain.TripEventType]int
+	storedEvents   []domain.TripEvent
+	storedIDs      map[string]bool
+}
+
+func newFakeService(scooters []domain.Scooter, failBeginTrips int) *fakeService {
+	f := &fakeService{
+		scooters:       map[string]*domain.Scooter{},
+		failBeginTrips: failBeginTrips,
+		tripEvents:     map[domain.TripEventType]int{},
+		storedIDs:      map[string]bool{},
+	}
+	for i := range scooters {
+		s := scooters[i]
+		f.scooters[s.ID] = &s
+	}
+	return f
+}
+
+func (f *fakeService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Query().Get("api_key") != "secretkey" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body := struct {
+		ID        string    `json:"id"`
+		UserID    string    `json:"user_id"`
+		ScooterID string    `json:"scooter_id"`
+		Type      string    `json:"type"`
+		CreatedAt time.Time `json:"created_at"`
+	}{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.URL.Path {
+	case "/api/v1/auth/user/available-scooters":
+		latitude, _ := strconv.ParseFloat(r.URL.Query().Get("latitude"), 64)
+		longitude, _ := strconv.ParseFloat(r.URL.Query().Get("longitude"), 64)
+		location := domain.GeoLocation{Latitude: latitude, Longitude: longitude}
+		available := []*domain.Scooter{}
+		for _, s := range f.scooters {
+			if s.IsAvailable {
+				available = append(available, s)
+			}
+		}
+		sort.Slice(available, func(i, j int) bool {
+			return distance(location, available[i].Location) < distance(location, available[j].Location)
+		})
+		scooters := []map[string]interface{}{}
+		for _, s := range available {
+			scooters = append(scooters, map[string]interface{}{
+				"id":           s.ID,
+				"location":     map[string]float64{"latitude": s.Location.Latitude, "longitude": s.Location.Longitude},
+				"is_available": true,
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"scooters": scooters})
+	case "/api/v1/auth/user/begin-trip":
+		if f.failBeginTrips > 0 {
+			f.failBeginTrips--
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"errorMessage":"database unavailable"}`))
+			return
+		}
+		s, ok := f.scooters[body.ScooterID]
+		if !ok || !s.IsAvailable {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if f.racyBegin {
+			f.mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			f.mu.Lock()
+		}
+		s.IsAvailable = false
+		s.CurrentUserID = &body.UserID
+		_ = json.NewEncoder(w).Encode(map[string]string{"user_id": body.UserID, "scooter_id": body.ScooterID})
+	case "/api/v1/auth/user/end-trip":
+		s, ok := f.scooters[body.ScooterID]
+		if !ok || s.IsAvailable || currentUser(*s) != body.UserID {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.IsAvailable = true
+		s.CurrentUserID = new(string)
+		_ = json.NewEncoder(w).Encode(map[string]string{"user_id": body.UserID, "scooter_id": body.ScooterID})
+	case "/api/v1/auth/scooter/trip-event":
+		if !f.storedIDs[body.ID] {
+			f.storedIDs[body.ID] = true
+			f.tripEvents[domain.TripEventType(body.Type)]++
+			f.storedEvents = append(f.storedEvents, domain.TripEvent{
+				ID:        body.ID,
+				UserID:    body.UserID,
+				ScooterID: body.ScooterID,
+				Type:      domain.TripEventType(body.Type),
+				CreatedAt: body.CreatedAt,
+			})
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testScenario(baseURL string) Scenario {
+	return Scenario{
+		Mode:                   ModeRide,
+		BaseURL:                baseURL,
+		Duration:               time.Second,
+		Seed:                   7,
+		Riders:                 20,
+		Scooters:               10,
+		RequestTimeout:         time.Second,
+		SearchRadius:           500,
+		LocationUpdateInterval: 20 * time.Millisecond,
+		SpawnAreas: []SpawnArea{
+			{Name: "berlin", Latitude: 52.52, Longitude: 13.405, Radius: 200, Weight: 1},
+		},
+		TripDuration: Distribution{Type: DistributionUniform, Min: 50 * time.Millisecond, Max: 100 * time.Millisecond},
+		ThinkTime:    Distribution{Type: DistributionConstant, Mean: 10 * time.Millisecond},
+		Movement: Movement{
+			Model:              MovementRandomWalk,
+			Speed:              NumberDistribution{Type: DistributionConstant, Mean: 5},
+			HeadingPersistence: 0.8,
+			GPSNoise:           3,
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	sim, err := New(testScenario("http://localhost"), "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeService(sim.Scooters(), 5)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	scenario := testScenario(server.URL)
+	sim, err = New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	report := sim.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > scenario.Duration+2*time.Second {
+		t.Errorf("Run() took %v, want about %v", elapsed, scenario.Duration)
+	}
+
+	if report.Riders != scenario.Riders {
+		t.Errorf("Riders = %v, want %v", report.Riders, scenario.Riders)
+	}
+	// the riders keep travelling after the failed begin trips
+	if report.TripsCompleted == 0 || report.TripsCompleted != report.TripsStarted {
+		t.Errorf("TripsStarted = %v, TripsCompleted = %v, want equal and greater than 0", report.TripsStarted, report.TripsCompleted)
+	}
+
+	endpoints := map[string]EndpointReport{}
+	requests := 0
+	for _, e := range report.Endpoints {
+		endpoints[e.Endpoint] = e
+		requests += e.Requests
+		if e.P50 > e.P90 || e.P90 > e.P99 || e.P99 > e.Max {
+			t.Errorf("%v latency percentiles are not ordered: %+v", e.Endpoint, e)
+		}
+	}
+	if requests != report.Requests || report.Throughput <= 0 {
+		t.Errorf("Requests = %v, Throughput = %v, want %v requests", report.Requests, report.Throughput, requests)
+	}
+	for _, endpoint := range []string{client.EndpointAvailableScooters, client.EndpointBeginTrip, client.EndpointEndTrip, client.EndpointTripEvent} {
+		if endpoints[endpoint].Requests == 0 {
+			t.Errorf("no requests recorded for %v", endpoint)
+		}
+	}
+	if endpoints[client.EndpointEndTrip].Errors != 0 {
+		t.Errorf("end trip errors = %v, want 0", endpoints[client.EndpointEndTrip].Errors)
+	}
+
+	found := false
+	for _, e := range report.Errors {
+		if e.Endpoint == client.EndpointBeginTrip && e.Reason == "status 500" {
+			found = e.Count == 5
+		}
+	}
+	if !found {
+		t.Errorf("Errors = %+v, want 5 begin trip errors with status 500", report.Errors)
+	}
+
+	// all the trips are ended, so the scooters are available again
+	for id, s := range fake.scooters {
+		if !s.IsAvailable {
+			t.Errorf("scooter %v is not available after simulation", id)
+		}
+	}
+	// the start event of the trip begun just before the stop may be cancelled
+	if fake.tripEvents[domain.TripStartEvent] > report.TripsStarted || fake.tripEvents[domain.TripStopEvent] != report.TripsCompleted {
+		t.Errorf("trip events = %v, want at most %v starts and %v stops", fake.tripEvents, report.TripsStarted, report.TripsCompleted)
+	}
+
+	out := bytes.Buffer{}
+	err = report.Print(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"throughput", "p99", client.EndpointBeginTrip, "status 500"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Print() = %v, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunUnavailableService(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	scenario := testScenario(server.URL)
+	scenario.Duration = 300 * time.Millisecond
+	sim, err := New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := sim.Run(context.Background())
+	if report.TripsStarted != 0 {
+		t.Errorf("TripsStarted = %v, want 0", report.TripsStarted)
+	}
+	refused := 0
+	for _, e := range report.Errors {
+		if e.Endpoint != client.EndpointAvailableScooters {
+			t.Errorf("Errors = %+v, want available scooters errors only", report.Errors)
+		}
+		if e.Reason == "connection refused" {
+			refused = e.Count
+		}
+	}
+	// the riders keep trying after the first error
+	if refused <= scenario.Riders {
+		t.Errorf("connection refused count = %v, want more than %v", refused, scenario.Riders)
+	}
+}
+
+func TestNew(t *testing.T) {
+	scenario := testScenario("http://localhost:8080")
+	a, err := New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(scenario, "secretkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a.Users()) != scenario.Riders || len(a.Scooters()) != scenario.Scooters {
+		t.Fatalf("got %v users and %v scooters, want %v and %v", len(a.Users()), len(a.Scooters()), scenario.Riders, scenario.Scooters)
+	}
+	// same seed generates same riders and scooters
+	if a.Users()[0] != b.Users()[0] || a.Scooters()[0].ID != b.Scooters()[0].ID {
+		t.Errorf("generated records differ with same seed")
+	}
+	for _, s := range a.Scooters() {
+		if !s.IsAvailable || distance(s.Location, domain.GeoLocation{Latitude: 52.52, Longitude: 13.405}) > 200.5 {
+			t.Errorf("scooter %+v is not available in spawn area", s)
+		}
+	}
+
+	scenario.Riders = 0
+	_, err = New(scenario, "secretkey")
+	if err == nil || !strings.Contains(err.Error(), "riders") {
+		t.Errorf("New() error = %v, want riders error", err)
+	}
+}
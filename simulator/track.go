@@ -0,0 +1,177 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+type gpx struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type gpxPoint struct {
+	Latitude  float64 `xml:"lat,attr"`
+	Longitude float64 `xml:"lon,attr"`
+}
+
+// geoJSON is geometry, feature or feature collection
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Geometries  []geoJSON       `json:"geometries"`
+	Features    []geoJSON       `json:"features"`
+}
+
+// LoadTracks returns the tracks of GPX file (track segments and routes) or
+// GeoJSON file (LineString and MultiLineString geometries), the tracks with
+// less than two points are skipped
+func LoadTracks(file string) ([][]domain.GeoLocation, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks [][]domain.GeoLocation
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		tracks, err = parseGPX(data)
+	} else {
+		tracks, err = parseGeoJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid track file %v: %w", file, err)
+	}
+
+	result := [][]domain.GeoLocation{}
+	for _, t := range tracks {
+		if len(t) >= 2 {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func parseGPX(data []byte) ([][]domain.GeoLocation, error) {
+	doc := gpx{}
+	err := xml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	toTrack := func(points []gpxPoint) []domain.GeoLocation {
+		track := make([]domain.GeoLocation, 0, len(points))
+		for _, p := range points {
+			track = append(track, domain.GeoLocation{
+				Latitude:  p.Latitude,
+				Longitude: p.Longitude,
+			})
+		}
+		return track
+	}
+
+	tracks := [][]domain.GeoLocation{}
+	for _, t := range doc.Tracks {
+		for _, s := range t.Segments {
+			tracks = append(tracks, toTrack(s.Points))
+		}
+	}
+	for _, r := range doc.Routes {
+		tracks = append(tracks, toTrack(r.Points))
+	}
+	return tracks, nil
+}
+
+func parseGeoJSON(data []byte) ([][]domain.GeoLocation, error) {
+	doc := geoJSON{}
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc.tracks()
+}
+
+func (g *geoJSON) tracks() ([][]domain.GeoLocation, error) {
+	switch g.Type {
+	case "FeatureCollection":
+		tracks := [][]domain.GeoLocation{}
+		for i := range g.Features {
+			t, err := g.Features[i].tracks()
+			if err != nil {
+				return nil, err
+			}
+			tracks = append(tracks, t...)
+		}
+		return tracks, nil
+	case "Feature":
+		if g.Geometry == nil {
+			return nil, nil
+		}
+		return g.Geometry.tracks()
+	case "GeometryCollection":
+		tracks := [][]domain.GeoLocation{}
+		for i := range g.Geometries {
+			t, err := g.Geometries[i].tracks()
+			if err != nil {
+				return nil, err
+			}
+			tracks = append(tracks, t...)
+		}
+		return tracks, nil
+	case "LineString":
+		positions := [][]float64{}
+		err := json.Unmarshal(g.Coordinates, &positions)
+		if err != nil {
+			return nil, err
+		}
+		track, err := toLocations(positions)
+		if err != nil {
+			return nil, err
+		}
+		return [][]domain.GeoLocation{track}, nil
+	case "MultiLineString":
+		lines := [][][]float64{}
+		err := json.Unmarshal(g.Coordinates, &lines)
+		if err != nil {
+			return nil, err
+		}
+		tracks := [][]domain.GeoLocation{}
+		for _, positions := range lines {
+			track, err := toLocations(positions)
+			if err != nil {
+				return nil, err
+			}
+			tracks = append(tracks, track)
+		}
+		return tracks, nil
+	default:
+		// points and polygons are not tracks
+		return nil, nil
+	}
+}
+
+// toLocations converts GeoJSON positions [longitude, latitude] to locations
+func toLocations(positions [][]float64) ([]domain.GeoLocation, error) {
+	locations := make([]domain.GeoLocation, 0, len(positions))
+	for _, p := range positions {
+		if len(p) < 2 {
+			return nil, fmt.Errorf("position %v must have longitude and latitude", p)
+		}
+		locations = append(locations, domain.GeoLocation{
+			Latitude:  p[1],
+			Longitude: p[0],
+		})
+	}
+	return locations, nil
+}
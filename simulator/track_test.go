@@ -0,0 +1,84 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+func TestLoadTracks(t *testing.T) {
+	dir := t.TempDir()
+	invalidFile := filepath.Join(dir, "invalid.geojson")
+	err := os.WriteFile(invalidFile, []byte(`{"type": "LineString", "coordinates": [[13.4]]}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    [][]domain.GeoLocation
+		wantErr bool
+	}{
+		{
+			name: "should load gpx track segments and routes",
+			file: "testdata/ride.gpx",
+			want: [][]domain.GeoLocation{
+				{
+					{Latitude: 52.521918, Longitude: 13.413215},
+					{Latitude: 52.5226, Longitude: 13.411},
+					{Latitude: 52.5233, Longitude: 13.4083},
+					{Latitude: 52.5229, Longitude: 13.4026},
+				},
+				{
+					{Latitude: 52.516275, Longitude: 13.377704},
+					{Latitude: 52.5186, Longitude: 13.3761},
+				},
+			},
+		},
+		{
+			name: "should load geojson line strings",
+			file: "testdata/ride.geojson",
+			want: [][]domain.GeoLocation{
+				{
+					{Latitude: 52.5145, Longitude: 13.35},
+					{Latitude: 52.514, Longitude: 13.36},
+					{Latitude: 52.5148, Longitude: 13.37},
+				},
+				{
+					{Latitude: 52.543, Longitude: 13.402},
+					{Latitude: 52.545, Longitude: 13.404},
+				},
+				{
+					{Latitude: 52.545, Longitude: 13.404},
+					{Latitude: 52.546, Longitude: 13.401},
+					{Latitude: 52.544, Longitude: 13.4},
+				},
+			},
+		},
+		{
+			name:    "should return error for position without latitude",
+			file:    invalidFile,
+			wantErr: true,
+		},
+		{
+			name:    "should return error for missing file",
+			file:    filepath.Join(dir, "missing.gpx"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadTracks(tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTracks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadTracks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package testclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"github.com/google/uuid"
+)
+
+// faults injected in the trip events
+const (
+	FaultDuplicate    = "duplicate"
+	FaultDrop         = "drop"
+	FaultDelay        = "delay"
+	FaultReorder      = "reorder"
+	FaultClockSkew    = "clock_skew"
+	FaultMismatchUser = "mismatch_user"
+)
+
+var (
+	// ErrEventDropped is passed to trip event observer for the event which is
+	// dropped by fault injection, it is not returned by SaveTripEvent
+	ErrEventDropped = errors.New("trip event dropped by fault injection")
+)
+
+// Faults are the rates(0 to 1) of the faults injected in the trip events,
+// each fault is decided independently for each event
+type Faults struct {
+	// Duplicate sends the event again concurrently
+	Duplicate float64 `yaml:"duplicate"`
+	// Drop does not send the event
+	Drop float64 `yaml:"drop"`
+	// Delay sends the event in background after random delay up to MaxDelay
+	Delay    float64       `yaml:"delay"`
+	MaxDelay time.Duration `yaml:"max_delay"`
+	// Reorder sends the event after the next event of the scooter, the trip
+	// stop event is not held back
+	Reorder float64 `yaml:"reorder"`
+	// ClockSkew shifts the event time by random duration up to MaxClockSkew
+	// in either direction
+	ClockSkew    float64       `yaml:"clock_skew"`
+	MaxClockSkew time.Duration `yaml:"max_clock_skew"`
+	// MismatchUser sends the event with random user id
+	MismatchUser float64 `yaml:"mismatch_user"`
+}
+
+// Enabled returns true if any fault has rate greater than 0
+func (f *Faults) Enabled() bool {
+	return f.Duplicate > 0 || f.Drop > 0 || f.Delay > 0 || f.Reorder > 0 || f.ClockSkew > 0 || f.MismatchUser > 0
+}
+
+// Validate returns error if any rate is out of range
+func (f *Faults) Validate() error {
+	rates := map[string]float64{
+		FaultDuplicate:    f.Duplicate,
+		FaultDrop:         f.Drop,
+		FaultDelay:        f.Delay,
+		FaultReorder:      f.Reorder,
+		FaultClockSkew:    f.ClockSkew,
+		FaultMismatchUser: f.MismatchUser,
+	}
+	for _, fault := range []string{FaultDuplicate, FaultDrop, FaultDelay, FaultReorder, FaultClockSkew, FaultMismatchUser} {
+		if rates[fault] < 0 || rates[fault] > 1 {
+			return fmt.Errorf("%v rate %v is out of range [0, 1]", fault, rates[fault])
+		}
+	}
+	if f.MaxDelay < 0 || f.MaxClockSkew < 0 {
+		return errors.New("max delay and max clock skew must not be negative")
+	}
+	return nil
+}
+
+// TripEventObserver is called for each trip event sent to the service with
+// the event given to SaveTripEvent, the event as sent after the faults are
+// applied, the injected faults and the error. It is called with
+// ErrEventDropped for dropped event.
+type TripEventObserver func(original domain.TripEvent, sent domain.TripEvent, faults []string, err error)
+
+// WithTripEventObserver sets the observer of sent trip events
+func WithTripEventObserver(o TripEventObserver) Option {
+	return func(c *Client) {
+		c.observeTripEvent = o
+	}
+}
+
+// WithFaults injects the faults in the trip events, the faults are decided
+// with random generator seeded with seed
+func WithFaults(faults Faults, seed int64) Option {
+	return func(c *Client) {
+		c.chaos = &chaos{
+			faults:   faults,
+			rng:      rand.New(rand.NewSource(seed)),
+			held:     map[string][]heldEvent{},
+			injected: map[string]int{},
+		}
+	}
+}
+
+// heldEvent is the event held back for reorder
+type heldEvent struct {
+	original domain.TripEvent
+	sent     domain.TripEvent
+	faults   []string
+}
+
+// chaos injects faults in the trip events
+type chaos struct {
+	faults Faults
+
+	mu  sync.Mutex
+	rng *rand.Rand
+	// held are the events held back for reorder by scooter id
+	held map[string][]heldEvent
+	// background are the delayed and duplicate sends in progress
+	background sync.WaitGroup
+	// injected is the number of events by injected fault
+	injected map[string]int
+}
+
+// decide returns the event with the faults applied and the injected faults
+func (ch *chaos) decide(event domain.TripEvent) (domain.TripEvent, []string) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	faults := []string{}
+	defer func() {
+		for _, f := range faults {
+			ch.injected[f]++
+		}
+	}()
+	hit := func(rate float64) bool {
+		return rate > 0 && ch.rng.Float64() < rate
+	}
+	if hit(ch.faults.Drop) {
+		faults = append(faults, FaultDrop)
+		return event, faults
+	}
+	if hit(ch.faults.ClockSkew) {
+		skew := time.Duration((ch.rng.Float64()*2 - 1) * float64(ch.faults.MaxClockSkew))
+		event.CreatedAt = event.CreatedAt.Add(skew)
+		faults = append(faults, FaultClockSkew)
+	}
+	if hit(ch.faults.MismatchUser) {
+		id, _ := uuid.NewRandomFromReader(ch.rng)
+		event.UserID = id.String()
+		faults = append(faults, FaultMismatchUser)
+	}
+	if hit(ch.faults.Duplicate) {
+		faults = append(faults, FaultDuplicate)
+	}
+	if hit(ch.faults.Delay) {
+		faults = append(faults, FaultDelay)
+	} else if event.Type != domain.TripStopEvent && hit(ch.faults.Reorder) {
+		faults = append(faults, FaultReorder)
+	}
+	return event, faults
+}
+
+func (ch *chaos) delay() time.Duration {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return time.Duration(ch.rng.Float64() * float64(ch.faults.MaxDelay))
+}
+
+func hasFault(faults []string, fault string) bool {
+	for _, f := range faults {
+		if f == fault {
+			return true
+		}
+	}
+	return false
+}
+
+// saveTripEventWithFaults applies the faults and sends the event, the error
+// of the event sent in background is reported to the observer only
+func (c *Client) saveTripEventWithFaults(ctx context.Context, event domain.TripEvent) error {
+	ch := c.chaos
+	sent, faults := ch.decide(event)
+
+	var err error
+	switch {
+	case hasFault(faults, FaultDrop):
+		c.observeTripEvent(event, sent, faults, ErrEventDropped)
+	case hasFault(faults, FaultDelay):
+		delay := ch.delay()
+		c.background(func(ctx context.Context) {
+			time.Sleep(delay)
+			c.send(ctx, event, sent, faults)
+		})
+	case hasFault(faults, FaultReorder):
+		ch.mu.Lock()
+		ch.held[event.ScooterID] = append(ch.held[event.ScooterID], heldEvent{original: event, sent: sent, faults: faults})
+		ch.mu.Unlock()
+		return nil
+	default:
+		err = c.send(ctx, event, sent, faults)
+	}
+
+	// the held events of the scooter are sent after the next event
+	ch.mu.Lock()
+	held := ch.held[event.ScooterID]
+	delete(ch.held, event.ScooterID)
+	ch.mu.Unlock()
+	for _, h := range held {
+		c.send(ctx, h.original, h.sent, h.faults)
+	}
+	return err
+}
+
+// send sends the event and its duplicate if it has duplicate fault
+func (c *Client) send(ctx context.Context, original domain.TripEvent, sent domain.TripEvent, faults []string) error {
+	if hasFault(faults, FaultDuplicate) {
+		c.background(func(ctx context.Context) {
+			err := c.saveTripEvent(ctx, sent)
+			c.observeTripEvent(original, sent, faults, err)
+		})
+	}
+	err := c.saveTripEvent(ctx, sent)
+	c.observeTripEvent(original, sent, faults, err)
+	return err
+}
+
+// background runs the send in background, it is not cancelled with the
+// context of the event as the scooter has already moved on
+func (c *Client) background(send func(ctx context.Context)) {
+	c.chaos.background.Add(1)
+	go func() {
+		defer c.chaos.background.Done()
+		send(context.Background())
+	}()
+}
+
+// Flush sends the events held back for reorder and waits for the delayed
+// and duplicate events sent in background
+func (c *Client) Flush(ctx context.Context) {
+	if c.chaos == nil {
+		return
+	}
+
+	c.chaos.mu.Lock()
+	held := c.chaos.held
+	c.chaos.held = map[string][]heldEvent{}
+	c.chaos.mu.Unlock()
+	for _, events := range held {
+		for _, h := range events {
+			c.send(ctx, h.original, h.sent, h.faults)
+		}
+	}
+	c.chaos.background.Wait()
+}
+
+// InjectedFaults returns the number of events by injected fault
+func (c *Client) InjectedFaults() map[string]int {
+	injected := map[string]int{}
+	if c.chaos == nil {
+		return injected
+	}
+
+	c.chaos.mu.Lock()
+	defer c.chaos.mu.Unlock()
+	for f, count := range c.chaos.injected {
+		injected[f] = count
+	}
+	return injected
+}
@@ -0,0 +1,171 @@
+package testclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/client"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+)
+
+// eventServer records the trip events in order of arrival
+type eventServer struct {
+	mu     sync.Mutex
+	events []client.TripEvent
+}
+
+func (s *eventServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	event := client.TripEvent{}
+	_ = json.NewDecoder(r.Body).Decode(&event)
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte(`{"success":true}`))
+}
+
+func (s *eventServer) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := []string{}
+	for _, e := range s.events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func tripEvent(id string, eventType domain.TripEventType) domain.TripEvent {
+	return domain.TripEvent{
+		ID:        id,
+		UserID:    "c5b8c5a6-7a37-4b07-9cbb-17b3bcf8a4a0",
+		ScooterID: "a3e1f2b4-9f63-4c37-8d1b-6e2f0c1a5b7d",
+		Type:      eventType,
+		CreatedAt: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSaveTripEventWithFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		faults  Faults
+		events  []domain.TripEvent
+		wantIDs []string
+		check   func(t *testing.T, sent []client.TripEvent)
+	}{
+		{
+			name:    "drop",
+			faults:  Faults{Drop: 1},
+			events:  []domain.TripEvent{tripEvent("e1", domain.TripStartEvent)},
+			wantIDs: []string{},
+		},
+		{
+			name:    "duplicate",
+			faults:  Faults{Duplicate: 1},
+			events:  []domain.TripEvent{tripEvent("e1", domain.TripStartEvent)},
+			wantIDs: []string{"e1", "e1"},
+		},
+		{
+			name:   "reorder holds event until next event of scooter",
+			faults: Faults{Reorder: 1},
+			events: []domain.TripEvent{
+				tripEvent("e1", domain.TripStartEvent),
+				tripEvent("e2", domain.TripLocationUpdateEvent),
+				tripEvent("e3", domain.TripStopEvent),
+			},
+			wantIDs: []string{"e3", "e1", "e2"},
+		},
+		{
+			name:    "delay",
+			faults:  Faults{Delay: 1, MaxDelay: 20 * time.Millisecond},
+			events:  []domain.TripEvent{tripEvent("e1", domain.TripStartEvent)},
+			wantIDs: []string{"e1"},
+		},
+		{
+			name:    "clock skew and mismatch user",
+			faults:  Faults{ClockSkew: 1, MaxClockSkew: time.Minute, MismatchUser: 1},
+			events:  []domain.TripEvent{tripEvent("e1", domain.TripStartEvent)},
+			wantIDs: []string{"e1"},
+			check: func(t *testing.T, sent []client.TripEvent) {
+				want := tripEvent("e1", domain.TripStartEvent)
+				skew := sent[0].CreatedAt.Sub(want.CreatedAt)
+				if sent[0].UserID == want.UserID || skew == 0 || skew > time.Minute || skew < -time.Minute {
+					t.Errorf("sent event = %+v, want skewed time and other user", sent[0])
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &eventServer{}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			observed := map[string]error{}
+			mu := sync.Mutex{}
+			apiClient, err := client.NewClient(ts.URL, "secretkey", client.WithRetry(1, 0, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := NewClient(apiClient,
+				WithFaults(tt.faults, 1),
+				WithTripEventObserver(func(original domain.TripEvent, sent domain.TripEvent, faults []string, err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					observed[original.ID] = err
+				}))
+			for _, e := range tt.events {
+				err := c.SaveTripEvent(context.Background(), e)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			c.Flush(context.Background())
+
+			ids := server.ids()
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("sent events = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("sent events = %v, want %v", ids, tt.wantIDs)
+					break
+				}
+			}
+			for _, e := range tt.events {
+				if _, ok := observed[e.ID]; !ok {
+					t.Errorf("event %v is not observed", e.ID)
+				}
+			}
+			if tt.faults.Drop == 1 && !errors.Is(observed["e1"], ErrEventDropped) {
+				t.Errorf("observed error = %v, want %v", observed["e1"], ErrEventDropped)
+			}
+			if tt.check != nil {
+				tt.check(t, server.events)
+			}
+			if len(c.InjectedFaults()) == 0 {
+				t.Errorf("InjectedFaults() is empty")
+			}
+		})
+	}
+}
+
+func TestFaultsValidate(t *testing.T) {
+	f := Faults{}
+	if f.Enabled() || f.Validate() != nil {
+		t.Errorf("zero faults are enabled or invalid")
+	}
+	f.Reorder = 2
+	if err := f.Validate(); err == nil {
+		t.Errorf("Validate() error = nil, want out of range error")
+	}
+	f = Faults{Delay: 0.5, MaxDelay: -time.Second}
+	if !f.Enabled() || f.Validate() == nil {
+		t.Errorf("Validate() error = nil, want negative max delay error")
+	}
+}
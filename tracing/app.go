@@ -0,0 +1,192 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/domain"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// appTracing wraps the app and starts span for each use case
+type appTracing struct {
+	app app.App
+}
+
+// NewApp returns the app which traces the use cases of given app
+func NewApp(a app.App) app.App {
+	return &appTracing{
+		app: a,
+	}
+}
+
+func (a *appTracing) GetNearbyAvailableScooters(ctx context.Context, location domain.GeoLocation, radius int) ([]domain.Scooter, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetNearbyAvailableScooters")
+	span.SetAttributes(attribute.Int("radius", radius))
+	scooters, err := a.app.GetNearbyAvailableScooters(ctx, location, radius)
+	End(span, err)
+	return scooters, err
+}
+
+func (a *appTracing) SearchAvailableScooters(ctx context.Context, query domain.ScooterQuery) ([]domain.ScooterMatch, error) {
+	ctx, span := Tracer().Start(ctx, "app.SearchAvailableScooters")
+	span.SetAttributes(
+		attribute.Bool("near", query.Near != nil),
+		attribute.Float64("radius", query.Radius),
+		attribute.Bool("bounding_box", query.BoundingBox != nil),
+		attribute.Int("polygon_points", len(query.Polygon)),
+		attribute.Int("limit", query.Limit),
+	)
+	scooters, err := a.app.SearchAvailableScooters(ctx, query)
+	span.SetAttributes(attribute.Int("scooters", len(scooters)))
+	End(span, err)
+	return scooters, err
+}
+
+func (a *appTracing) GetScooterClusters(ctx context.Context, boundingBox domain.BoundingBox, zoom int) ([]domain.ScooterCluster, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetScooterClusters")
+	span.SetAttributes(attribute.Int("zoom", zoom))
+	clusters, err := a.app.GetScooterClusters(ctx, boundingBox, zoom)
+	span.SetAttributes(attribute.Int("clusters", len(clusters)))
+	End(span, err)
+	return clusters, err
+}
+
+func (a *appTracing) GetMapTile(ctx context.Context, tile domain.Tile, withTripHeat bool) (*domain.MapTile, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetMapTile")
+	span.SetAttributes(attribute.Int("z", tile.Z), attribute.Int("x", tile.X), attribute.Int("y", tile.Y), attribute.Bool("trip_heat", withTripHeat))
+	mapTile, err := a.app.GetMapTile(ctx, tile, withTripHeat)
+	End(span, err)
+	return mapTile, err
+}
+
+func (a *appTracing) BeginTrip(ctx context.Context, userID string, scooterID string) error {
+	ctx, span := Tracer().Start(ctx, "app.BeginTrip")
+	span.SetAttributes(attribute.String("user_id", userID), attribute.String("scooter_id", scooterID))
+	err := a.app.BeginTrip(ctx, userID, scooterID)
+	End(span, err)
+	return err
+}
+
+func (a *appTracing) EndTrip(ctx context.Context, userID string, scooterID string, location domain.GeoLocation) error {
+	ctx, span := Tracer().Start(ctx, "app.EndTrip")
+	span.SetAttributes(attribute.String("user_id", userID), attribute.String("scooter_id", scooterID))
+	err := a.app.EndTrip(ctx, userID, scooterID, location)
+	End(span, err)
+	return err
+}
+
+func (a *appTracing) SaveScooterTripEvent(ctx context.Context, event *domain.TripEvent) error {
+	ctx, span := Tracer().Start(ctx, "app.SaveScooterTripEvent")
+	if event != nil {
+		span.SetAttributes(attribute.String("scooter_id", event.ScooterID), attribute.String("event_type", string(event.Type)))
+	}
+	err := a.app.SaveScooterTripEvent(ctx, event)
+	End(span, err)
+	return err
+}
+
+func (a *appTracing) SaveScooterTripEvents(ctx context.Context, events []domain.TripEvent) ([]error, error) {
+	ctx, span := Tracer().Start(ctx, "app.SaveScooterTripEvents")
+	span.SetAttributes(attribute.Int("events", len(events)))
+	results, err := a.app.SaveScooterTripEvents(ctx, events)
+	End(span, err)
+	return results, err
+}
+
+func (a *appTracing) GetScooter(ctx context.Context, scooterID string) (*domain.Scooter, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetScooter")
+	span.SetAttributes(attribute.String("scooter_id", scooterID))
+	scooter, err := a.app.GetScooter(ctx, scooterID)
+	End(span, err)
+	return scooter, err
+}
+
+func (a *appTracing) GetScooterPosition(ctx context.Context, scooterID string) (*domain.ScooterPosition, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetScooterPosition")
+	span.SetAttributes(attribute.String("scooter_id", scooterID))
+	position, err := a.app.GetScooterPosition(ctx, scooterID)
+	End(span, err)
+	return position, err
+}
+
+func (a *appTracing) StartTrip(ctx context.Context, userID string, scooterID string) (*domain.Trip, error) {
+	ctx, span := Tracer().Start(ctx, "app.StartTrip")
+	span.SetAttributes(attribute.String("user_id", userID), attribute.String("scooter_id", scooterID))
+	trip, err := a.app.StartTrip(ctx, userID, scooterID)
+	if trip != nil {
+		span.SetAttributes(attribute.String("trip_id", trip.ID))
+	}
+	End(span, err)
+	return trip, err
+}
+
+func (a *appTracing) GetTrip(ctx context.Context, tripID string) (*domain.Trip, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetTrip")
+	span.SetAttributes(attribute.String("trip_id", tripID))
+	trip, err := a.app.GetTrip(ctx, tripID)
+	End(span, err)
+	return trip, err
+}
+
+func (a *appTracing) FinishTrip(ctx context.Context, tripID string, location domain.GeoLocation) (*domain.Trip, error) {
+	ctx, span := Tracer().Start(ctx, "app.FinishTrip")
+	span.SetAttributes(attribute.String("trip_id", tripID))
+	trip, err := a.app.FinishTrip(ctx, tripID, location)
+	End(span, err)
+	return trip, err
+}
+
+func (a *appTracing) GetTripEvents(ctx context.Context, tripID string) ([]domain.TripEvent, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetTripEvents")
+	span.SetAttributes(attribute.String("trip_id", tripID))
+	events, err := a.app.GetTripEvents(ctx, tripID)
+	End(span, err)
+	return events, err
+}
+
+func (a *appTracing) SaveDeadLetterEvent(ctx context.Context, payload string, reason string) error {
+	ctx, span := Tracer().Start(ctx, "app.SaveDeadLetterEvent")
+	err := a.app.SaveDeadLetterEvent(ctx, payload, reason)
+	End(span, err)
+	return err
+}
+
+func (a *appTracing) GetDeadLetterEvents(ctx context.Context) ([]domain.DeadLetterEvent, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetDeadLetterEvents")
+	events, err := a.app.GetDeadLetterEvents(ctx)
+	End(span, err)
+	return events, err
+}
+
+func (a *appTracing) GetDeadLetterEvent(ctx context.Context, eventID string) (*domain.DeadLetterEvent, error) {
+	ctx, span := Tracer().Start(ctx, "app.GetDeadLetterEvent")
+	span.SetAttributes(attribute.String("event_id", eventID))
+	event, err := a.app.GetDeadLetterEvent(ctx, eventID)
+	End(span, err)
+	return event, err
+}
+
+func (a *appTracing) UpdateDeadLetterEventPayload(ctx context.Context, eventID string, payload string) (*domain.DeadLetterEvent, error) {
+	ctx, span := Tracer().Start(ctx, "app.UpdateDeadLetterEventPayload")
+	span.SetAttributes(attribute.String("event_id", eventID))
+	event, err := a.app.UpdateDeadLetterEventPayload(ctx, eventID, payload)
+	End(span, err)
+	return event, err
+}
+
+func (a *appTracing) ReplayDeadLetterEvent(ctx context.Context, eventID string, event *domain.TripEvent) error {
+	ctx, span := Tracer().Start(ctx, "app.ReplayDeadLetterEvent")
+	span.SetAttributes(attribute.String("event_id", eventID))
+	err := a.app.ReplayDeadLetterEvent(ctx, eventID, event)
+	End(span, err)
+	return err
+}
+
+func (a *appTracing) Ready(ctx context.Context) error {
+	return a.app.Ready(ctx)
+}
+
+func (a *appTracing) Stop(ctx context.Context) error {
+	return a.app.Stop(ctx)
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/app"
+	"github.com/ganeshdipdumbare/scootin-aboot-journey/mocks"
+	"github.com/golang/mock/gomock"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAppBeginTrip(t *testing.T) {
+	recorder := setupTestTracing(t)
+	mockApp := mocks.NewMockApp(gomock.NewController(t))
+	a := NewApp(mockApp)
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	wantErr := fmt.Errorf("scooter in use: %w", app.ErrOperationNotAllowed)
+	mockApp.EXPECT().BeginTrip(gomock.Any(), "userid", "scooterid").DoAndReturn(
+		func(ctx context.Context, userID string, scooterID string) error {
+			// app receives context with use case span
+			if trace.SpanContextFromContext(ctx).SpanID() == parent.SpanContext().SpanID() {
+				t.Errorf("BeginTrip() context has parent span, want use case span")
+			}
+			return wantErr
+		}).Times(1)
+
+	if err := a.BeginTrip(ctx, "userid", "scooterid"); err != wantErr {
+		t.Errorf("BeginTrip() error = %v, want %v", err, wantErr)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended spans = %v, want 2", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "app.BeginTrip" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("span = %v with parent %v, want app.BeginTrip child of parent", span.Name(), span.Parent().SpanID())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want %v", span.Status().Code, codes.Error)
+	}
+}
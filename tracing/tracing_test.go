@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// setupTestTracing sets global tracer provider which records the spans in memory
+func setupTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{
+			name:     "should return error for invalid exporter",
+			exporter: "invalid",
+			wantErr:  true,
+		},
+		{
+			name:     "should setup tracing without exporter",
+			exporter: ExporterNone,
+			wantErr:  false,
+		},
+		{
+			name:     "should setup tracing with stdout exporter",
+			exporter: ExporterStdout,
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stop, err := Setup(context.Background(), tt.exporter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Setup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if stop != nil {
+				if err := stop(context.Background()); err != nil {
+					t.Errorf("stop() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTraceIDAndEnd(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("TraceID() = %v, want empty", got)
+	}
+
+	ctx, span := Tracer().Start(context.Background(), "test")
+	if got := TraceID(ctx); got != span.SpanContext().TraceID().String() {
+		t.Errorf("TraceID() = %v, want %v", got, span.SpanContext().TraceID())
+	}
+	End(span, errors.New("test error"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Errorf("ended spans = %v, want one span with error status", spans)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	traceParent := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent <- r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := Tracer().Start(context.Background(), "parent")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1?api_key=secret", nil)
+	resp, err := (&http.Client{Transport: NewTransport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	span.End()
+
+	got := <-traceParent
+	if got == "" {
+		t.Fatalf("traceparent header not sent")
+	}
+	if want := span.SpanContext().TraceID().String(); len(got) < 35 || got[3:35] != want {
+		t.Errorf("traceparent = %v, want trace id %v", got, want)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Errorf("original request modified")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "HTTP GET" || spans[0].Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("ended spans = %v, want client span as child of parent", spans)
+	}
+	// the query is not recorded as it has the api key
+	gotURL := ""
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "http.url" {
+			gotURL = attr.Value.AsString()
+		}
+	}
+	if want := server.URL + "/api/v1"; gotURL != want {
+		t.Errorf("http.url = %v, want %v", gotURL, want)
+	}
+}